@@ -2,6 +2,10 @@ package config
 
 import (
 	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/spf13/viper"
@@ -9,12 +13,23 @@ import (
 
 // Config - the config struct for global variables
 type Config struct {
-	Port       string `mapstructure:"PORT"`
-	DBHost     string `mapstructure:"DB_HOST"`
-	DBPort     string `mapstructure:"DB_PORT"`
-	DBUser     string `mapstructure:"DB_USER"`
-	DBPassword string `mapstructure:"DB_PASSWORD"`
-	DBName     string `mapstructure:"DB_NAME"`
+	Port          string `mapstructure:"PORT"`
+	DBDriver      string `mapstructure:"DB_DRIVER"` // postgres by default, with sqlite DB_NAME is the path of the database file
+	DBHost        string `mapstructure:"DB_HOST"`
+	DBPort        string `mapstructure:"DB_PORT"`
+	DBUser        string `mapstructure:"DB_USER"`
+	DBPassword    string `mapstructure:"DB_PASSWORD"`
+	DBName        string `mapstructure:"DB_NAME"`
+	EncryptionKey string `mapstructure:"ENCRYPTION_KEY"`                           // base64 of 32 bytes, when set the uploaded certificate files are kept encrypted
+	FielAlertDays string `mapstructure:"FIEL_ALERT_DAYS"`                          // days before a FIEL or CSD expires the users are notified, comma separated
+	JobsHour      int    `mapstructure:"JOBS_HOUR" validate:"min=0,max=23"`        // local hour the daily jobs run at
+	StorageDriver string `mapstructure:"STORAGE_DRIVER" validate:"oneof=local s3"` // where the client documents are kept, local by default
+	StoragePath   string `mapstructure:"STORAGE_PATH"`                             // directory of the local storage
+	S3Endpoint    string `mapstructure:"S3_ENDPOINT" validate:"required_if=StorageDriver s3"`
+	S3Region      string `mapstructure:"S3_REGION"`
+	S3Bucket      string `mapstructure:"S3_BUCKET" validate:"required_if=StorageDriver s3"`
+	S3AccessKey   string `mapstructure:"S3_ACCESS_KEY" validate:"required_if=StorageDriver s3"`
+	S3SecretKey   string `mapstructure:"S3_SECRET_KEY" validate:"required_if=StorageDriver s3"`
 }
 
 // Load pulls the config data from the config file
@@ -23,6 +38,11 @@ func Load() (Config, error) {
 	viper.SetConfigType("env")
 	viper.AddConfigPath(".")
 	viper.AutomaticEnv()
+	viper.SetDefault("FIEL_ALERT_DAYS", "60,30,7")
+	viper.SetDefault("JOBS_HOUR", 7)
+	viper.SetDefault("STORAGE_DRIVER", "local")
+	viper.SetDefault("STORAGE_PATH", "data/documents")
+	viper.SetDefault("S3_REGION", "us-east-1")
 
 	if err := viper.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
@@ -31,11 +51,22 @@ func Load() (Config, error) {
 
 		// No config file found. Using environment variables
 		viper.BindEnv("PORT")
+		viper.BindEnv("DB_DRIVER")
 		viper.BindEnv("DB_HOST")
 		viper.BindEnv("DB_PORT")
 		viper.BindEnv("DB_USER")
 		viper.BindEnv("DB_PASSWORD")
 		viper.BindEnv("DB_NAME")
+		viper.BindEnv("ENCRYPTION_KEY")
+		viper.BindEnv("FIEL_ALERT_DAYS")
+		viper.BindEnv("JOBS_HOUR")
+		viper.BindEnv("STORAGE_DRIVER")
+		viper.BindEnv("STORAGE_PATH")
+		viper.BindEnv("S3_ENDPOINT")
+		viper.BindEnv("S3_REGION")
+		viper.BindEnv("S3_BUCKET")
+		viper.BindEnv("S3_ACCESS_KEY")
+		viper.BindEnv("S3_SECRET_KEY")
 
 		for _, key := range viper.AllKeys() {
 			val := viper.Get(key)
@@ -53,5 +84,29 @@ func Load() (Config, error) {
 		return Config{}, fmt.Errorf("invalid config file: %w", err)
 	}
 
+	if _, err := config.FielAlertThresholds(); err != nil {
+		return Config{}, fmt.Errorf("invalid config file: %w", err)
+	}
+
 	return config, nil
 }
+
+// FielAlertThresholds returns the days of FIEL_ALERT_DAYS from the largest to the smallest
+func (c Config) FielAlertThresholds() ([]int, error) {
+	var days []int
+	for _, d := range strings.Split(c.FielAlertDays, ",") {
+		if d = strings.TrimSpace(d); d == "" {
+			continue
+		}
+
+		n, err := strconv.Atoi(d)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("FIEL_ALERT_DAYS must be positive numbers separated by commas, got %q", c.FielAlertDays)
+		}
+		days = append(days, n)
+	}
+
+	sort.Sort(sort.Reverse(sort.IntSlice(days)))
+
+	return slices.Compact(days), nil
+}
@@ -22,15 +22,22 @@ func NewAccountancyController(accountancyUseCase AccountancyUseCase, logger *log
 	}
 }
 
-// GetClientsBySupervisor retrieves all the clients of a specific supervisor
+// GetClientsBySupervisor retrieves all the clients of a specific supervisor as json or as a file
 func (ac *AccountancyController) GetClientsBySupervisor(g *gin.Context) {
 	supervisorID := g.Param("supervisor_id")
+	e, ok := bindExport(g, ac.logger)
+	if !ok {
+		return
+	}
+
 	clients, err := ac.accountancyUseCase.GetClientsBySupervisor(supervisorID)
 	if err != nil {
-		ac.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("GetClientsBySupervisor(): Error fetching clients info")
-		g.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching clients info"})
+		renderError(g, ac.logger, err, "Error fetching clients info")
+		return
+	}
+
+	if e.format != "" {
+		renderExport(g, ac.logger, e, "supervisor-clients", "Clients of the supervisor", clients)
 		return
 	}
 
@@ -41,10 +48,7 @@ func (ac *AccountancyController) GetClientsBySupervisor(g *gin.Context) {
 func (ac *AccountancyController) GetClientAssignmentsMatrix(g *gin.Context) {
 	assignments, err := ac.accountancyUseCase.GetClientAssignmentsMatrix()
 	if err != nil {
-		ac.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("GetClientAssignmentsMatrix(): Error fetching clients accountancy assignments")
-		g.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching clients accountancy assignments"})
+		renderError(g, ac.logger, err, "Error fetching clients accountancy assignments")
 		return
 	}
 
@@ -57,34 +61,35 @@ func (ac *AccountancyController) UpdateClientAssignments(g *gin.Context) {
 
 	var assignments []models.AssignmentSelection
 	if err := g.ShouldBindJSON(&assignments); err != nil {
-		ac.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("Error binding client data")
-		g.JSON(http.StatusBadRequest, gin.H{"error": "Error binding client data"})
+		renderBindError(g, ac.logger, err, "Error binding client data")
 		return
 	}
 
-	err := ac.accountancyUseCase.UpdateClientAssignments(clientID, assignments)
+	err := ac.accountancyUseCase.UpdateClientAssignments(clientID, assignments, g.GetHeader("If-Match"))
 	if err != nil {
-		ac.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("UpdateClientAssignments(): error updating client accountancy assignments")
-		g.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating client accountancy assignments"})
+		renderError(g, ac.logger, err, "Error updating client accountancy assignments")
 		return
 	}
 
-	g.JSON(http.StatusOK, "Client accountancy assignments updated successfully")
+	renderMessage(g, http.StatusOK, "Client accountancy assignments updated successfully")
 }
 
 // GetClientsBySResonsible retrieves all the clients of a specific responsible
 func (ac *AccountancyController) GetClientsByResonsible(g *gin.Context) {
 	supervisorID := g.Param("responsible_id")
+	e, ok := bindExport(g, ac.logger)
+	if !ok {
+		return
+	}
+
 	clients, err := ac.accountancyUseCase.GetClientsByResonsible(supervisorID)
 	if err != nil {
-		ac.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("GetClientsByResonsible(): Error fetching clients info")
-		g.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching clients info"})
+		renderError(g, ac.logger, err, "Error fetching clients info")
+		return
+	}
+
+	if e.format != "" {
+		renderExport(g, ac.logger, e, "responsible-clients", "Clients of the responsible", clients)
 		return
 	}
 
@@ -96,40 +101,57 @@ func (ac *AccountancyController) CreateClientAccountancyStatusWithAssignments(g
 	var req models.ClientAccountancyHistoryEntry
 
 	if err := g.ShouldBindJSON(&req); err != nil {
-		ac.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("Error binding accountancy status data")
-		g.JSON(http.StatusBadRequest, gin.H{"error": "Error binding accountancy status data"})
+		renderBindError(g, ac.logger, err, "Error binding accountancy status data")
 		return
 	}
 
 	err := ac.accountancyUseCase.CreateClientAccountancyStatusWithAssignments(req.Status, req.Assignments)
 	if err != nil {
-		ac.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("CreateClientAccountancyStatusWithAssignments(): error creating accountancy status and assignments")
-		g.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating accountancy status and assignments"})
+		renderError(g, ac.logger, err, "Error creating accountancy status and assignments")
 		return
 	}
 
-	g.JSON(http.StatusCreated, "Client accountancy status and assignments created successfully")
+	renderMessage(g, http.StatusCreated, "Client accountancy status and assignments created successfully")
 }
 
 // GetClientAccountancyHistory gets the hisotory for a client accountancy behavior
 func (ac *AccountancyController) GetClientAccountancyHistory(g *gin.Context) {
 	clientID := g.Param("client_id")
+
+	if !ac.setETag(g, clientID) {
+		return
+	}
+
 	result, err := ac.accountancyUseCase.GetClientAccountancyHistory(clientID)
 	if err != nil {
-		ac.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("GetClientAccountancyHistory(): Error fetching clients info")
-		g.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching clients info"})
+		renderError(g, ac.logger, err, "Error fetching clients info")
 		return
 	}
 
 	g.JSON(http.StatusOK, result)
 }
 
+// GetClientAccountancyStatus gets a monthly record of a client with its ETag,
+// the one its update checks
+func (ac *AccountancyController) GetClientAccountancyStatus(g *gin.Context) {
+	clientID := g.Param("client_id")
+
+	var statusID int
+	if _, err := fmt.Sscanf(g.Param("status_id"), "%d", &statusID); err != nil {
+		renderBindError(g, ac.logger, err, "Invalid status_id")
+		return
+	}
+
+	entry, err := ac.accountancyUseCase.GetClientAccountancyStatus(clientID, statusID)
+	if err != nil {
+		renderError(g, ac.logger, err, "Error fetching accountancy status")
+		return
+	}
+
+	g.Header("ETag", models.StatusETag(entry.Status.Version))
+	g.JSON(http.StatusOK, entry)
+}
+
 // UpdateClientAccountancyStatusWithAssignments updates an existing monthly record for a client
 func (ac *AccountancyController) UpdateClientAccountancyStatusWithAssignments(g *gin.Context) {
 	clientID := g.Param("client_id")
@@ -137,53 +159,63 @@ func (ac *AccountancyController) UpdateClientAccountancyStatusWithAssignments(g
 
 	var req models.ClientAccountancyHistoryEntry
 	if err := g.ShouldBindJSON(&req); err != nil {
-		ac.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("Error binding accountancy status update data")
-		g.JSON(http.StatusBadRequest, gin.H{"error": "Error binding accountancy status update data"})
+		renderBindError(g, ac.logger, err, "Error binding accountancy status update data")
 		return
 	}
 
 	// Validar que el status pertenece al cliente especificado
 	if req.Status.ClientID != clientID {
-		ac.logger.WithFields(logrus.Fields{
-			"client_id":        clientID,
-			"status_client_id": req.Status.ClientID,
-		}).Error("Status does not belong to the specified client")
-		g.JSON(http.StatusForbidden, gin.H{"error": "Status does not belong to this client"})
+		renderError(g, ac.logger, models.NewForbiddenError("Status does not belong to this client"), "Status does not belong to this client")
 		return
 	}
 
 	// Convertir statusID a int
 	var statusIDInt int
 	if _, err := fmt.Sscanf(statusID, "%d", &statusIDInt); err != nil {
-		ac.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("Error parsing status_id")
-		g.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status_id"})
+		renderBindError(g, ac.logger, err, "Invalid status_id")
 		return
 	}
 
-	err := ac.accountancyUseCase.UpdateClientAccountancyStatusWithAssignments(statusIDInt, clientID, req.Status, req.Assignments)
+	err := ac.accountancyUseCase.UpdateClientAccountancyStatusWithAssignments(statusIDInt, clientID, req.Status, req.Assignments, g.GetHeader("If-Match"))
 	if err != nil {
-		ac.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("UpdateClientAccountancyStatusWithAssignments(): error updating accountancy status and assignments")
-		g.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating accountancy status and assignments"})
+		renderError(g, ac.logger, err, "Error updating accountancy status and assignments")
 		return
 	}
 
-	g.JSON(http.StatusOK, "Client accountancy status and assignments updated successfully")
+	renderMessage(g, http.StatusOK, "Client accountancy status and assignments updated successfully")
 }
 
-// GetAllClients retrieves all the clients
+// GetAllClients retrieves a page of the active clients, or every matching
+// client when a file format is requested
 func (ac *AccountancyController) GetAllClients(g *gin.Context) {
-	clients, err := ac.accountancyUseCase.GetAllClients()
+	var params models.ClientListParams
+	if err := g.ShouldBindQuery(&params); err != nil {
+		renderBindError(g, ac.logger, err, "Error binding list parameters")
+		return
+	}
+
+	e, ok := bindExport(g, ac.logger)
+	if !ok {
+		return
+	}
+
+	if e.format != "" {
+		clients, err := allPages(params, func(p models.ClientListParams) ([]models.AccountancyClientInfo, int, error) {
+			page, err := ac.accountancyUseCase.GetAllClients(p)
+			return page.Items, page.Total, err
+		})
+		if err != nil {
+			renderError(g, ac.logger, err, "Error fetching clients info")
+			return
+		}
+
+		renderExport(g, ac.logger, e, "accountancy-clients", "Accountancy clients", clients)
+		return
+	}
+
+	clients, err := ac.accountancyUseCase.GetAllClients(params)
 	if err != nil {
-		ac.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("GetAllClients(): Error fetching clients info")
-		g.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching clients info"})
+		renderError(g, ac.logger, err, "Error fetching clients info")
 		return
 	}
 
@@ -195,14 +227,11 @@ func (ac *AccountancyController) UpdateClientResponsible(g *gin.Context) {
 	clientID := g.Param("client_id")
 	responsibleID := g.Param("responsible_id")
 
-	err := ac.accountancyUseCase.UpdateClientResponsible(clientID, responsibleID)
+	err := ac.accountancyUseCase.UpdateClientResponsible(clientID, responsibleID, g.GetHeader("If-Match"))
 	if err != nil {
-		ac.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("UpdateClientResponsible(): Error updating client responsible")
-		g.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating client responsible"})
+		renderError(g, ac.logger, err, "Error updating client responsible")
 		return
 	}
 
-	g.JSON(http.StatusOK, "Client responsible updated successfully")
+	renderMessage(g, http.StatusOK, "Client responsible updated successfully")
 }
@@ -1,7 +1,11 @@
 package controller
 
 import (
+	"bytes"
 	"contabi-be/models"
+	"contabi-be/spreadsheet"
+	"fmt"
+	"io"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -21,44 +25,119 @@ func NewClientsController(clientsUseCase ClientsUsecase, logger *logrus.Logger)
 	}
 }
 
-// GetClientsInfo returns all clients with complete information
+// GetClientsInfo returns a page of the clients with complete information, or
+// every matching client when a file format is requested
 func (cc *ClientsController) GetClientsInfo(c *gin.Context) {
-	clients, err := cc.clientsUseCase.GetAllClientsInfo()
+	var params models.ClientListParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		renderBindError(c, cc.logger, err, "Error binding list parameters")
+		return
+	}
+
+	e, ok := bindExport(c, cc.logger)
+	if !ok {
+		return
+	}
+
+	if e.format != "" {
+		clients, err := allPages(params, func(p models.ClientListParams) ([]models.ClientInfo, int, error) {
+			page, err := cc.clientsUseCase.GetAllClientsInfo(p)
+			return page.Items, page.Total, err
+		})
+		if err != nil {
+			renderError(c, cc.logger, err, "Error fetching clients info")
+			return
+		}
+
+		renderExport(c, cc.logger, e, "clients", "Clients", clients)
+		return
+	}
+
+	clients, err := cc.clientsUseCase.GetAllClientsInfo(params)
 	if err != nil {
-		cc.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("GetAllClientsInfo(): Error fetching clients info")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching clients info"})
+		renderError(c, cc.logger, err, "Error fetching clients info")
 		return
 	}
 
 	c.JSON(http.StatusOK, clients)
 }
 
-// GetActiveClientsInfo returns only active clients with complete information
+// GetActiveClientsInfo returns a page of the active clients with complete
+// information, or every matching client when a file format is requested
 func (cc *ClientsController) GetActiveClientsInfo(c *gin.Context) {
-	clients, err := cc.clientsUseCase.GetActiveClientsInfo()
+	var params models.ClientListParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		renderBindError(c, cc.logger, err, "Error binding list parameters")
+		return
+	}
+
+	e, ok := bindExport(c, cc.logger)
+	if !ok {
+		return
+	}
+
+	if e.format != "" {
+		clients, err := allPages(params, func(p models.ClientListParams) ([]models.ClientInfo, int, error) {
+			page, err := cc.clientsUseCase.GetActiveClientsInfo(p)
+			return page.Items, page.Total, err
+		})
+		if err != nil {
+			renderError(c, cc.logger, err, "Error fetching active clients info")
+			return
+		}
+
+		renderExport(c, cc.logger, e, "active-clients", "Active clients", clients)
+		return
+	}
+
+	clients, err := cc.clientsUseCase.GetActiveClientsInfo(params)
+	if err != nil {
+		renderError(c, cc.logger, err, "Error fetching active clients info")
+		return
+	}
+
+	c.JSON(http.StatusOK, clients)
+}
+
+// SearchClients returns the clients that match a query ranked by relevance
+func (cc *ClientsController) SearchClients(c *gin.Context) {
+	var params models.ClientSearchParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		renderBindError(c, cc.logger, err, "Error binding search parameters")
+		return
+	}
+
+	clients, err := cc.clientsUseCase.SearchClients(params)
 	if err != nil {
-		cc.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("GetActiveClientsInfo(): Error fetching active clients info")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching active clients info"})
+		renderError(c, cc.logger, err, "Error searching clients")
 		return
 	}
 
 	c.JSON(http.StatusOK, clients)
 }
 
+// GetDuplicateClients returns the groups of clients that share an RFC or a similar name
+func (cc *ClientsController) GetDuplicateClients(c *gin.Context) {
+	groups, err := cc.clientsUseCase.GetDuplicateClients()
+	if err != nil {
+		renderError(c, cc.logger, err, "Error fetching duplicate clients")
+		return
+	}
+
+	c.JSON(http.StatusOK, groups)
+}
+
 // GetClientInfo returns complete information for a specific client
 func (cc *ClientsController) GetClientInfo(c *gin.Context) {
 	clientID := c.Param("id")
 
+	if !cc.setETag(c, clientID) {
+		return
+	}
+
 	client, err := cc.clientsUseCase.GetClientInfo(clientID)
 	if err != nil {
-		cc.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("GetClientInfo(): Error fetching client info")
-		c.JSON(http.StatusNotFound, gin.H{"error": "Error fetching client info"})
+		renderError(c, cc.logger, err, "Error fetching client info")
 		return
 	}
 
@@ -67,29 +146,20 @@ func (cc *ClientsController) GetClientInfo(c *gin.Context) {
 
 // CreateClient creates a new client with assignments
 func (cc *ClientsController) CreateClient(c *gin.Context) {
-	var request struct {
-		Client      models.Client            `json:"client"`
-		Assignments models.ClientAssignments `json:"assignments"`
-	}
+	var request models.CreateClientRequest
 
 	if err := c.ShouldBindJSON(&request); err != nil {
-		cc.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("Error binding client data")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Error binding client data"})
+		renderBindError(c, cc.logger, err, "Error binding client data")
 		return
 	}
 
 	err := cc.clientsUseCase.CreateClient(request.Client, request.Assignments)
 	if err != nil {
-		cc.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("CreateClient(): Error creating client")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating client"})
+		renderError(c, cc.logger, err, "Error creating client")
 		return
 	}
 
-	c.JSON(http.StatusCreated, "Client created successfully")
+	renderMessage(c, http.StatusCreated, "Client created successfully")
 }
 
 // UpdateClient updates client basic information
@@ -98,55 +168,488 @@ func (cc *ClientsController) UpdateClient(c *gin.Context) {
 
 	var client models.Client
 	if err := c.ShouldBindJSON(&client); err != nil {
-		cc.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("Error binding client data")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Error binding client data"})
+		renderBindError(c, cc.logger, err, "Error binding client data")
 		return
 	}
 
-	err := cc.clientsUseCase.UpdateClient(clientID, client)
+	err := cc.clientsUseCase.UpdateClient(clientID, client, c.GetHeader("If-Match"))
 	if err != nil {
-		cc.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("UpdateClient(): error updating client")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating client"})
+		renderError(c, cc.logger, err, "Error updating client")
+		return
+	}
+
+	renderMessage(c, http.StatusOK, "Client updated successfully")
+}
+
+// PatchClient updates only the fields of a client sent in a JSON merge patch
+func (cc *ClientsController) PatchClient(c *gin.Context) {
+	clientID := c.Param("id")
+
+	var patch models.ClientPatch
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		renderBindError(c, cc.logger, err, "Error binding client data")
+		return
+	}
+
+	if err := cc.clientsUseCase.PatchClient(clientID, patch, c.GetHeader("If-Match")); err != nil {
+		renderError(c, cc.logger, err, "Error updating client")
 		return
 	}
 
-	c.JSON(http.StatusOK, "Client updated successfully")
+	renderMessage(c, http.StatusOK, "Client updated successfully")
 }
 
-// DeactivateClient sets a client as inactive (soft delete)
+// DeactivateClient terminates a client (soft delete)
 func (cc *ClientsController) DeactivateClient(c *gin.Context) {
 	clientID := c.Param("id")
 
-	err := cc.clientsUseCase.DeactivateClient(clientID)
+	err := cc.clientsUseCase.DeactivateClient(clientID, c.GetHeader("If-Match"))
 	if err != nil {
-		cc.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("DeactivateClient(): error while deleting client")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "error while deleting client"})
+		renderError(c, cc.logger, err, "error while deleting client")
 		return
 	}
 
-	c.JSON(http.StatusOK, "Client deactivated successfully")
+	renderMessage(c, http.StatusOK, "Client deactivated successfully")
 }
 
-// ActivateClient sets a client as active
+// ActivateClient makes a client active
 func (cc *ClientsController) ActivateClient(c *gin.Context) {
 	clientID := c.Param("id")
 
-	err := cc.clientsUseCase.ActivateClient(clientID)
+	err := cc.clientsUseCase.ActivateClient(clientID, c.GetHeader("If-Match"))
+	if err != nil {
+		renderError(c, cc.logger, err, "error while activating client")
+		return
+	}
+
+	renderMessage(c, http.StatusOK, "Client activated successfully")
+}
+
+// ChangeClientState moves a client to another state of its lifecycle with the
+// reason of the change
+func (cc *ClientsController) ChangeClientState(c *gin.Context) {
+	clientID := c.Param("id")
+
+	var request models.ClientStateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		renderBindError(c, cc.logger, err, "Error binding client state")
+		return
+	}
+
+	user, _ := currentUser(c)
+	change, err := cc.clientsUseCase.ChangeClientState(clientID, user.ID, request, c.GetHeader("If-Match"))
+	if err != nil {
+		renderError(c, cc.logger, err, "Error changing client state")
+		return
+	}
+
+	c.JSON(http.StatusOK, change)
+}
+
+// OffboardClient terminates a client that leaves, it returns the balance it
+// owes and the documents to hand over
+func (cc *ClientsController) OffboardClient(c *gin.Context) {
+	clientID := c.Param("id")
+
+	var request models.OffboardingRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		renderBindError(c, cc.logger, err, "Error binding client offboarding")
+		return
+	}
+
+	user, _ := currentUser(c)
+	offboarding, err := cc.clientsUseCase.OffboardClient(clientID, user.ID, request, c.GetHeader("If-Match"))
+	if err != nil {
+		renderError(c, cc.logger, err, "Error offboarding client")
+		return
+	}
+
+	c.JSON(http.StatusCreated, offboarding)
+}
+
+// GetClientOffboarding returns the latest offboarding of a client
+func (cc *ClientsController) GetClientOffboarding(c *gin.Context) {
+	clientID := c.Param("id")
+
+	offboarding, err := cc.clientsUseCase.GetClientOffboarding(clientID)
+	if err != nil {
+		renderError(c, cc.logger, err, "Error fetching client offboarding")
+		return
+	}
+
+	c.JSON(http.StatusOK, offboarding)
+}
+
+// GetClientStateHistory returns the changes of state of a client
+func (cc *ClientsController) GetClientStateHistory(c *gin.Context) {
+	clientID := c.Param("id")
+
+	if !cc.setETag(c, clientID) {
+		return
+	}
+
+	changes, err := cc.clientsUseCase.GetClientStateHistory(clientID)
+	if err != nil {
+		renderError(c, cc.logger, err, "Error fetching client state history")
+		return
+	}
+
+	c.JSON(http.StatusOK, changes)
+}
+
+// ImportClients validates a CSV or XLSX file of clients sent in the file
+// field and creates the valid ones unless dry_run is true, the default
+func (cc *ClientsController) ImportClients(c *gin.Context) {
+	var params models.ClientImportParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		renderBindError(c, cc.logger, err, "Error binding import parameters")
+		return
+	}
+	dryRun := params.DryRun == nil || *params.DryRun
+
+	data, name, err := readFormFile(c, "file", models.MaxImportSize)
+	if err != nil {
+		renderError(c, cc.logger, err, "Error reading import file")
+		return
+	}
+
+	// the header and one client past the limit, so the import rejects the file
+	rows, err := spreadsheet.Read(name, bytes.NewReader(data), models.MaxImportRows+2)
+	if err != nil {
+		renderError(c, cc.logger, models.NewBadRequestError("The file is not a valid .csv or .xlsx spreadsheet", err), "Error reading import file")
+		return
+	}
+
+	report, err := cc.clientsUseCase.ImportClients(rows, dryRun)
+	if err != nil {
+		renderError(c, cc.logger, err, "Error importing clients")
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// ReadCSF reads the PDF of a constancia de situación fiscal sent in the file
+// field and returns the data of the client it belongs to, nothing is stored
+func (cc *ClientsController) ReadCSF(c *gin.Context) {
+	data, _, err := readFormFile(c, "file", models.MaxDocumentSize)
+	if err != nil {
+		renderError(c, cc.logger, err, "Error reading constancia")
+		return
+	}
+
+	draft, err := cc.clientsUseCase.ReadCSF(data)
+	if err != nil {
+		renderError(c, cc.logger, err, "Error reading constancia")
+		return
+	}
+
+	c.JSON(http.StatusOK, draft)
+}
+
+// MergeClients moves the history of the source client to the client of the path
+func (cc *ClientsController) MergeClients(c *gin.Context) {
+	clientID := c.Param("id")
+
+	var request models.MergeClientsRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		renderBindError(c, cc.logger, err, "Error binding merge data")
+		return
+	}
+
+	result, err := cc.clientsUseCase.MergeClients(clientID, request)
+	if err != nil {
+		renderError(c, cc.logger, err, "Error merging clients")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// UploadFielCertificate reads the FIEL certificate sent in the file field and
+// stores its data, the FIEL expiration of the client is taken from it
+func (cc *ClientsController) UploadFielCertificate(c *gin.Context) {
+	clientID := c.Param("id")
+
+	data, _, err := readFormFile(c, "file", models.MaxCertificateSize)
+	if err != nil {
+		renderError(c, cc.logger, err, "Error reading fiel certificate")
+		return
+	}
+
+	cert, err := cc.clientsUseCase.UploadFielCertificate(clientID, data)
+	if err != nil {
+		renderError(c, cc.logger, err, "Error uploading fiel certificate")
+		return
+	}
+
+	c.JSON(http.StatusCreated, cert)
+}
+
+// GetFielCertificates returns the FIEL certificates uploaded for a client
+func (cc *ClientsController) GetFielCertificates(c *gin.Context) {
+	clientID := c.Param("id")
+
+	if !cc.setETag(c, clientID) {
+		return
+	}
+
+	certs, err := cc.clientsUseCase.GetFielCertificates(clientID)
+	if err != nil {
+		renderError(c, cc.logger, err, "Error getting fiel certificates")
+		return
+	}
+
+	c.JSON(http.StatusOK, certs)
+}
+
+// GetExpiringFiel returns the active clients whose FIEL expires in the next
+// days or already expired
+func (cc *ClientsController) GetExpiringFiel(c *gin.Context) {
+	var params models.ExpiringParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		renderBindError(c, cc.logger, err, "Error binding expiring fiel parameters")
+		return
+	}
+
+	expirations, err := cc.clientsUseCase.GetExpiringFiel(params)
+	if err != nil {
+		renderError(c, cc.logger, err, "Error getting expiring fiel")
+		return
+	}
+
+	c.JSON(http.StatusOK, expirations)
+}
+
+// SaveFielRenewal sets the renewal status of the FIEL of a client
+func (cc *ClientsController) SaveFielRenewal(c *gin.Context) {
+	clientID := c.Param("id")
+
+	var request models.FielRenewalRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		renderBindError(c, cc.logger, err, "Error binding fiel renewal data")
+		return
+	}
+
+	renewal, err := cc.clientsUseCase.SaveFielRenewal(clientID, request, c.GetHeader("If-Match"))
+	if err != nil {
+		renderError(c, cc.logger, err, "Error saving fiel renewal")
+		return
+	}
+
+	c.JSON(http.StatusOK, renewal)
+}
+
+// UploadCSDCertificate reads the .cer and .key files of a CSD sent in the
+// certificate and key fields with the password of the key
+func (cc *ClientsController) UploadCSDCertificate(c *gin.Context) {
+	clientID := c.Param("id")
+
+	certificate, _, err := readFormFile(c, "certificate", models.MaxCertificateSize)
+	if err != nil {
+		renderError(c, cc.logger, err, "Error reading csd certificate")
+		return
+	}
+
+	key, _, err := readFormFile(c, "key", models.MaxCertificateSize)
+	if err != nil {
+		renderError(c, cc.logger, err, "Error reading csd key")
+		return
+	}
+
+	password := c.PostForm("password")
+	if password == "" {
+		renderError(c, cc.logger, models.NewValidationError("The password of the key is required", []models.FieldError{{
+			Field:   "password",
+			Rule:    "required",
+			Message: "is required",
+		}}), "Error reading csd password")
+		return
+	}
+
+	cert, err := cc.clientsUseCase.UploadCSDCertificate(clientID, certificate, key, password)
+	if err != nil {
+		renderError(c, cc.logger, err, "Error uploading csd certificate")
+		return
+	}
+
+	c.JSON(http.StatusCreated, cert)
+}
+
+// GetCSDCertificates returns the CSD uploaded for a client
+func (cc *ClientsController) GetCSDCertificates(c *gin.Context) {
+	clientID := c.Param("id")
+
+	certs, err := cc.clientsUseCase.GetCSDCertificates(clientID)
+	if err != nil {
+		renderError(c, cc.logger, err, "Error getting csd certificates")
+		return
+	}
+
+	c.JSON(http.StatusOK, certs)
+}
+
+// DeleteCSDCertificate removes a CSD of a client with its key
+func (cc *ClientsController) DeleteCSDCertificate(c *gin.Context) {
+	clientID := c.Param("id")
+	csdID := c.Param("csd_id")
+
+	if err := cc.clientsUseCase.DeleteCSDCertificate(clientID, csdID); err != nil {
+		renderError(c, cc.logger, err, "Error deleting csd certificate")
+		return
+	}
+
+	renderMessage(c, http.StatusOK, "CSD certificate deleted successfully")
+}
+
+// GetExpiringCSD returns the CSD of the active clients that expire in the
+// next days or already expired
+func (cc *ClientsController) GetExpiringCSD(c *gin.Context) {
+	var params models.ExpiringParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		renderBindError(c, cc.logger, err, "Error binding expiring csd parameters")
+		return
+	}
+
+	expirations, err := cc.clientsUseCase.GetExpiringCSD(params)
+	if err != nil {
+		renderError(c, cc.logger, err, "Error getting expiring csd")
+		return
+	}
+
+	c.JSON(http.StatusOK, expirations)
+}
+
+// GetClientContacts returns the contacts of a client
+func (cc *ClientsController) GetClientContacts(c *gin.Context) {
+	clientID := c.Param("id")
+
+	if !cc.setETag(c, clientID) {
+		return
+	}
+
+	contacts, err := cc.clientsUseCase.GetClientContacts(clientID)
+	if err != nil {
+		renderError(c, cc.logger, err, "Error getting client contacts")
+		return
+	}
+
+	c.JSON(http.StatusOK, contacts)
+}
+
+// CreateClientContact adds a contact to a client
+func (cc *ClientsController) CreateClientContact(c *gin.Context) {
+	clientID := c.Param("id")
+
+	var request models.ClientContactRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		renderBindError(c, cc.logger, err, "Error binding client contact data")
+		return
+	}
+
+	contact, err := cc.clientsUseCase.CreateClientContact(clientID, request)
+	if err != nil {
+		renderError(c, cc.logger, err, "Error creating client contact")
+		return
+	}
+
+	c.JSON(http.StatusCreated, contact)
+}
+
+// UpdateClientContact replaces the data of a contact of a client
+func (cc *ClientsController) UpdateClientContact(c *gin.Context) {
+	clientID := c.Param("id")
+	contactID := c.Param("contact_id")
+
+	var request models.ClientContactRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		renderBindError(c, cc.logger, err, "Error binding client contact data")
+		return
+	}
+
+	contact, err := cc.clientsUseCase.UpdateClientContact(clientID, contactID, request, c.GetHeader("If-Match"))
+	if err != nil {
+		renderError(c, cc.logger, err, "Error updating client contact")
+		return
+	}
+
+	c.JSON(http.StatusOK, contact)
+}
+
+// DeleteClientContact removes a contact of a client
+func (cc *ClientsController) DeleteClientContact(c *gin.Context) {
+	clientID := c.Param("id")
+	contactID := c.Param("contact_id")
+
+	if err := cc.clientsUseCase.DeleteClientContact(clientID, contactID); err != nil {
+		renderError(c, cc.logger, err, "Error deleting client contact")
+		return
+	}
+
+	renderMessage(c, http.StatusOK, "Contact deleted successfully")
+}
+
+// AddClientNote adds a note of the logged in user to the timeline of a client
+func (cc *ClientsController) AddClientNote(c *gin.Context) {
+	clientID := c.Param("id")
+
+	var request models.ClientNoteRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		renderBindError(c, cc.logger, err, "Error binding client note")
+		return
+	}
+
+	user, _ := currentUser(c)
+	note, err := cc.clientsUseCase.AddClientNote(clientID, user.ID, request)
+	if err != nil {
+		renderError(c, cc.logger, err, "Error adding client note")
+		return
+	}
+
+	c.JSON(http.StatusCreated, note)
+}
+
+// GetClientTimeline returns a page of the notes and recorded events of a client
+func (cc *ClientsController) GetClientTimeline(c *gin.Context) {
+	clientID := c.Param("id")
+
+	var params models.TimelineParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		renderBindError(c, cc.logger, err, "Error binding timeline parameters")
+		return
+	}
+
+	timeline, err := cc.clientsUseCase.GetClientTimeline(clientID, params)
 	if err != nil {
-		cc.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("ActivateClient(): error while activating client")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "error while activating client"})
+		renderError(c, cc.logger, err, "Error fetching client timeline")
 		return
 	}
 
-	c.JSON(http.StatusOK, "Client activated successfully")
+	c.JSON(http.StatusOK, timeline)
+}
+
+// readFormFile reads a file sent in a form field with its name, rejecting
+// the files larger than maxSize bytes
+func readFormFile(c *gin.Context, field string, maxSize int) ([]byte, string, error) {
+	file, header, err := c.Request.FormFile(field)
+	if err != nil {
+		return nil, "", models.NewBadRequestError("The file must be sent in the "+field+" field", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, int64(maxSize)+1))
+	if err != nil {
+		return nil, "", models.NewBadRequestError("The file could not be read", err)
+	}
+	if len(data) > maxSize {
+		return nil, "", models.NewValidationError("The file is too large", []models.FieldError{{
+			Field:   field,
+			Rule:    "max",
+			Message: fmt.Sprintf("must have at most %d KB", maxSize>>10),
+		}})
+	}
+
+	return data, header.Filename, nil
 }
 
 // UpdateClientAssignments updates client assignments (supervisor, responsible, emisor)
@@ -155,33 +658,74 @@ func (cc *ClientsController) UpdateClientAssignments(c *gin.Context) {
 
 	var assignments models.ClientAssignments
 	if err := c.ShouldBindJSON(&assignments); err != nil {
-		cc.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("Error binding client data")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Error binding client data"})
+		renderBindError(c, cc.logger, err, "Error binding client data")
+		return
+	}
+
+	err := cc.clientsUseCase.UpdateClientAssignments(clientID, assignments, c.GetHeader("If-Match"))
+	if err != nil {
+		renderError(c, cc.logger, err, "error while updating client assignments")
+		return
+	}
+
+	renderMessage(c, http.StatusOK, "Client assignments updated successfully")
+}
+
+// GetAssignmentHistory returns the periods of the assignments of a client
+func (cc *ClientsController) GetAssignmentHistory(c *gin.Context) {
+	clientID := c.Param("id")
+
+	var params models.AssignmentHistoryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		renderBindError(c, cc.logger, err, "Error binding assignment history parameters")
+		return
+	}
+
+	if !cc.setETag(c, clientID) {
+		return
+	}
+
+	periods, err := cc.clientsUseCase.GetAssignmentHistory(clientID, params)
+	if err != nil {
+		renderError(c, cc.logger, err, "Error fetching client assignment history")
+		return
+	}
+
+	c.JSON(http.StatusOK, periods)
+}
+
+// GetAssignmentsAsOf returns the assignments every client had on a day
+func (cc *ClientsController) GetAssignmentsAsOf(c *gin.Context) {
+	var params models.AssignmentsAsOfParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		renderBindError(c, cc.logger, err, "Error binding assignments parameters")
 		return
 	}
 
-	err := cc.clientsUseCase.UpdateClientAssignments(clientID, assignments)
+	periods, err := cc.clientsUseCase.GetAssignmentsAsOf(params)
 	if err != nil {
-		cc.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("UpdateClientAssignments(): error while updating client assignments")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "error while updating client assignments"})
+		renderError(c, cc.logger, err, "Error fetching client assignments")
 		return
 	}
 
-	c.JSON(http.StatusOK, "Client assignments updated successfully")
+	c.JSON(http.StatusOK, periods)
 }
 
-// GetClientsWithPendingPayments returns clients that have pending payments
+// GetClientsWithPendingPayments returns clients that have pending payments as json or as a file
 func (cc *ClientsController) GetClientsWithPendingPayments(c *gin.Context) {
+	e, ok := bindExport(c, cc.logger)
+	if !ok {
+		return
+	}
+
 	clients, err := cc.clientsUseCase.GetClientsWithPendingPayments()
 	if err != nil {
-		cc.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("GetClientsWithPendingPayments(): error while getting clients payments")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "error while getting clients payments"})
+		renderError(c, cc.logger, err, "error while getting clients payments")
+		return
+	}
+
+	if e.format != "" {
+		renderExport(c, cc.logger, e, "pending-payments", "Clients with pending payments", clients)
 		return
 	}
 
@@ -194,36 +738,78 @@ func (cc *ClientsController) UpdateClientPayment(c *gin.Context) {
 
 	var payment models.ClientPayment
 	if err := c.ShouldBindJSON(&payment); err != nil {
-		cc.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("Error binding client data")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Error binding client data"})
+		renderBindError(c, cc.logger, err, "Error binding client data")
 		return
 	}
 
-	err := cc.clientsUseCase.UpdateClientPayment(clientID, payment)
+	err := cc.clientsUseCase.UpdateClientPayment(clientID, payment, c.GetHeader("If-Match"))
 	if err != nil {
-		cc.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("UpdateClientPayment(): error while updating client payment")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		renderError(c, cc.logger, err, "Error updating client payment")
 		return
 	}
 
-	c.JSON(http.StatusOK, "Client payment updated successfully")
+	renderMessage(c, http.StatusOK, "Client payment updated successfully")
 }
 
 // GetClientPayments gets the payments history of a specific client
 func (cc *ClientsController) GetClientPayments(c *gin.Context) {
 	clientID := c.Param("id")
+
+	if !cc.setETag(c, clientID) {
+		return
+	}
+
 	clients, err := cc.clientsUseCase.GetClientPayments(clientID)
 	if err != nil {
-		cc.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("GetClientPayments(): Error fetching clients payments info")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching clients payments info"})
+		renderError(c, cc.logger, err, "Error fetching clients payments info")
 		return
 	}
 
 	c.JSON(http.StatusOK, clients)
 }
+
+// GetClientFees returns the monthly fees of a client with their effective months
+func (cc *ClientsController) GetClientFees(c *gin.Context) {
+	clientID := c.Param("id")
+
+	if !cc.setETag(c, clientID) {
+		return
+	}
+
+	fees, err := cc.clientsUseCase.GetClientFees(clientID)
+	if err != nil {
+		renderError(c, cc.logger, err, "Error fetching client fees")
+		return
+	}
+
+	c.JSON(http.StatusOK, fees)
+}
+
+// SaveClientFee sets the monthly fee of a client from a month
+func (cc *ClientsController) SaveClientFee(c *gin.Context) {
+	clientID := c.Param("id")
+
+	var request models.ClientFeeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		renderBindError(c, cc.logger, err, "Error binding client fee")
+		return
+	}
+
+	fee, err := cc.clientsUseCase.SaveClientFee(clientID, request, c.GetHeader("If-Match"))
+	if err != nil {
+		renderError(c, cc.logger, err, "Error saving client fee")
+		return
+	}
+
+	c.JSON(http.StatusOK, fee)
+}
+
+// DeleteClientFee cancels a scheduled fee of a client
+func (cc *ClientsController) DeleteClientFee(c *gin.Context) {
+	if err := cc.clientsUseCase.DeleteClientFee(c.Param("id"), c.Param("fee_id")); err != nil {
+		renderError(c, cc.logger, err, "Error deleting client fee")
+		return
+	}
+
+	renderMessage(c, http.StatusOK, "Fee deleted successfully")
+}
@@ -3,6 +3,7 @@ package controller
 import (
 	"contabi-be/models"
 
+	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 )
 
@@ -17,6 +18,7 @@ type UsersUseCase interface {
 	GetUserByID(id string) (models.User, error)
 	CreateUser(user models.User) error
 	UpdateUser(user models.User) error
+	PatchUser(userID string, patch models.UserPatch) error
 	UpdateUserRole(user models.User) error
 	PutUserPassword(user models.User) error
 	DeleteUser(id string) error
@@ -24,17 +26,47 @@ type UsersUseCase interface {
 }
 
 type ClientsUsecase interface {
-	GetAllClientsInfo() ([]models.ClientInfo, error)
-	GetActiveClientsInfo() ([]models.ClientInfo, error)
+	GetAllClientsInfo(params models.ClientListParams) (models.ClientInfoPage, error)
+	GetActiveClientsInfo(params models.ClientListParams) (models.ClientInfoPage, error)
+	SearchClients(params models.ClientSearchParams) ([]models.ClientSearchResult, error)
 	GetClientInfo(clientID string) (models.ClientInfo, error)
 	CreateClient(client models.Client, assignments models.ClientAssignments) error
-	UpdateClient(clientID string, client models.Client) error
-	DeactivateClient(clientID string) error
-	ActivateClient(clientID string) error
-	UpdateClientAssignments(clientID string, assignments models.ClientAssignments) error
+	UpdateClient(clientID string, client models.Client, ifMatch string) error
+	PatchClient(clientID string, patch models.ClientPatch, ifMatch string) error
+	DeactivateClient(clientID, ifMatch string) error
+	ActivateClient(clientID, ifMatch string) error
+	ChangeClientState(clientID, userID string, request models.ClientStateRequest, ifMatch string) (models.ClientStateChange, error)
+	GetClientStateHistory(clientID string) ([]models.ClientStateChange, error)
+	OffboardClient(clientID, userID string, request models.OffboardingRequest, ifMatch string) (models.ClientOffboarding, error)
+	GetClientOffboarding(clientID string) (models.ClientOffboarding, error)
+	UpdateClientAssignments(clientID string, assignments models.ClientAssignments, ifMatch string) error
+	GetClientETag(clientID string) (string, error)
+	GetAssignmentHistory(clientID string, params models.AssignmentHistoryParams) ([]models.AssignmentPeriod, error)
+	GetAssignmentsAsOf(params models.AssignmentsAsOfParams) ([]models.AssignmentPeriod, error)
 	GetClientsWithPendingPayments() ([]models.ClientWithPendingPayment, error)
-	UpdateClientPayment(clientID string, payment models.ClientPayment) error
+	UpdateClientPayment(clientID string, payment models.ClientPayment, ifMatch string) error
 	GetClientPayments(clientID string) ([]models.ClientPaymentHistory, error)
+	GetClientFees(clientID string) ([]models.ClientFee, error)
+	SaveClientFee(clientID string, request models.ClientFeeRequest, ifMatch string) (models.ClientFee, error)
+	DeleteClientFee(clientID, feeID string) error
+	GetDuplicateClients() ([]models.DuplicateClientGroup, error)
+	MergeClients(targetID string, request models.MergeClientsRequest) (models.ClientMergeResult, error)
+	ImportClients(rows [][]string, dryRun bool) (models.ClientImportReport, error)
+	ReadCSF(file []byte) (models.CSFDraft, error)
+	AddClientNote(clientID, userID string, request models.ClientNoteRequest) (models.ClientEvent, error)
+	GetClientTimeline(clientID string, params models.TimelineParams) (models.ClientEventPage, error)
+	UploadFielCertificate(clientID string, file []byte) (models.FielCertificate, error)
+	GetFielCertificates(clientID string) ([]models.FielCertificate, error)
+	GetExpiringFiel(params models.ExpiringParams) ([]models.FielExpiration, error)
+	SaveFielRenewal(clientID string, request models.FielRenewalRequest, ifMatch string) (models.FielRenewal, error)
+	UploadCSDCertificate(clientID string, certificate, key []byte, password string) (models.CSDCertificate, error)
+	GetCSDCertificates(clientID string) ([]models.CSDCertificate, error)
+	DeleteCSDCertificate(clientID, csdID string) error
+	GetExpiringCSD(params models.ExpiringParams) ([]models.CSDExpiration, error)
+	GetClientContacts(clientID string) ([]models.ClientContact, error)
+	CreateClientContact(clientID string, request models.ClientContactRequest) (models.ClientContact, error)
+	UpdateClientContact(clientID, contactID string, request models.ClientContactRequest, ifMatch string) (models.ClientContact, error)
+	DeleteClientContact(clientID, contactID string) error
 }
 
 type MenusUseCase interface {
@@ -51,19 +83,38 @@ type NominasUseCase interface {
 	GetClientsWithPendingPaymentsByHREntityID(hrEntityID string) ([]models.ClientWithPendingHRPayment, error)
 	GetClientPendingPaymentsByHREntityIDDetails(clientID, hrEntityID string) ([]models.ClientWithPendingHRPaymentDetails, error)
 	UpdateClientPaymentRecord(clientPaymentRecord models.UpdateClientHRPayment) error
+	PatchClientPaymentRecord(id string, patch models.ClientHRPaymentPatch) error
 	GetClientHRPaymentsHistory(clientID, hrEntityID string) ([]models.ClientHRPayment, error)
 }
 
 type AccountancyUseCase interface {
 	GetClientsBySupervisor(supervisorID string) ([]models.AccountancyClientInfo, error)
 	GetClientAssignmentsMatrix() ([]models.ClientAssignmentMatrixRow, error)
-	UpdateClientAssignments(clientID string, assignments []models.AssignmentSelection) error
+	UpdateClientAssignments(clientID string, assignments []models.AssignmentSelection, ifMatch string) error
 	GetClientsByResonsible(responsibleID string) ([]models.AccountancyClientInfo, error)
 	CreateClientAccountancyStatusWithAssignments(status models.ClientAccountancyStatus, assignments []models.ClientAccountancyAssignment) error
-	UpdateClientAccountancyStatusWithAssignments(statusID int, clientID string, status models.ClientAccountancyStatus, assignments []models.ClientAccountancyAssignment) error
+	UpdateClientAccountancyStatusWithAssignments(statusID int, clientID string, status models.ClientAccountancyStatus, assignments []models.ClientAccountancyAssignment, ifMatch string) error
 	GetClientAccountancyHistory(clientID string) (models.ClientAccountancyHistoryWithAssignments, error)
-	GetAllClients() ([]models.AccountancyClientInfo, error)
-	UpdateClientResponsible(clientID string, responsibleID string) error
+	GetClientAccountancyStatus(clientID string, statusID int) (models.ClientAccountancyHistoryEntry, error)
+	GetAllClients(params models.ClientListParams) (models.AccountancyClientInfoPage, error)
+	UpdateClientResponsible(clientID string, responsibleID string, ifMatch string) error
+	GetClientETag(clientID string) (string, error)
+}
+
+// NotificationsUseCase
+type NotificationsUseCase interface {
+	GetNotifications(userID string, params models.NotificationParams) ([]models.Notification, error)
+	MarkNotificationRead(userID, notificationID string) error
+}
+
+// DocumentsUseCase manages the documents attached to the clients
+type DocumentsUseCase interface {
+	GetDocuments(clientID string, params models.DocumentParams) ([]models.ClientDocument, error)
+	GetDocument(clientID, documentID string) (models.ClientDocument, error)
+	UploadDocument(clientID, userID string, upload models.DocumentUpload, file models.DocumentFile) (models.ClientDocument, error)
+	AddDocumentVersion(clientID, documentID, userID string, upload models.DocumentVersionUpload, file models.DocumentFile) (models.ClientDocument, error)
+	DownloadDocument(clientID, documentID string, params models.DocumentVersionParams) (models.DocumentVersion, []byte, error)
+	DeleteDocument(clientID, documentID string) error
 }
 
 // Controller
@@ -89,3 +140,10 @@ func NewController(lu LoginUseCase, uu UsersUseCase, cu ClientsUsecase, mu Menus
 		logger: logger,
 	}
 }
+
+// currentUser returns the user the authentication middleware logged in
+func currentUser(g *gin.Context) (models.User, bool) {
+	user, _ := g.Get(models.UserContextKey)
+	u, ok := user.(models.User)
+	return u, ok
+}
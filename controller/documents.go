@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"mime"
+	"net/http"
+
+	"contabi-be/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// DocumentsController handles the documents attached to the clients
+type DocumentsController struct {
+	documentsUseCase DocumentsUseCase
+	logger           *logrus.Logger
+}
+
+// NewDocumentsController creates a new instance of DocumentsController
+func NewDocumentsController(documentsUseCase DocumentsUseCase, logger *logrus.Logger) *DocumentsController {
+	return &DocumentsController{
+		documentsUseCase: documentsUseCase,
+		logger:           logger,
+	}
+}
+
+// GetDocuments returns the documents of a client, filtered by category when it is given
+func (dc *DocumentsController) GetDocuments(g *gin.Context) {
+	var params models.DocumentParams
+	if err := g.ShouldBindQuery(&params); err != nil {
+		renderBindError(g, dc.logger, err, "Error binding document parameters")
+		return
+	}
+
+	documents, err := dc.documentsUseCase.GetDocuments(g.Param("id"), params)
+	if err != nil {
+		renderError(g, dc.logger, err, "Error getting documents")
+		return
+	}
+
+	g.JSON(http.StatusOK, documents)
+}
+
+// GetDocument returns a document of a client with its versions
+func (dc *DocumentsController) GetDocument(g *gin.Context) {
+	document, err := dc.documentsUseCase.GetDocument(g.Param("id"), g.Param("document_id"))
+	if err != nil {
+		renderError(g, dc.logger, err, "Error getting document")
+		return
+	}
+
+	g.JSON(http.StatusOK, document)
+}
+
+// UploadDocument stores the file sent in the file field as a new document of a client
+func (dc *DocumentsController) UploadDocument(g *gin.Context) {
+	var upload models.DocumentUpload
+	if err := g.ShouldBind(&upload); err != nil {
+		renderBindError(g, dc.logger, err, "Error binding document data")
+		return
+	}
+
+	data, name, err := readFormFile(g, "file", models.MaxDocumentSize)
+	if err != nil {
+		renderError(g, dc.logger, err, "Error reading document")
+		return
+	}
+
+	user, _ := currentUser(g)
+	document, err := dc.documentsUseCase.UploadDocument(g.Param("id"), user.ID, upload, models.DocumentFile{Name: name, Data: data})
+	if err != nil {
+		renderError(g, dc.logger, err, "Error uploading document")
+		return
+	}
+
+	g.JSON(http.StatusCreated, document)
+}
+
+// AddDocumentVersion stores the file sent in the file field as the new version of a document
+func (dc *DocumentsController) AddDocumentVersion(g *gin.Context) {
+	var upload models.DocumentVersionUpload
+	if err := g.ShouldBind(&upload); err != nil {
+		renderBindError(g, dc.logger, err, "Error binding document version data")
+		return
+	}
+
+	data, name, err := readFormFile(g, "file", models.MaxDocumentSize)
+	if err != nil {
+		renderError(g, dc.logger, err, "Error reading document")
+		return
+	}
+
+	user, _ := currentUser(g)
+	document, err := dc.documentsUseCase.AddDocumentVersion(g.Param("id"), g.Param("document_id"), user.ID, upload, models.DocumentFile{Name: name, Data: data})
+	if err != nil {
+		renderError(g, dc.logger, err, "Error uploading document version")
+		return
+	}
+
+	g.JSON(http.StatusCreated, document)
+}
+
+// DownloadDocument sends the file of a version of a document, the current one
+// by default, with its SHA-256 in the X-Checksum-Sha256 header
+func (dc *DocumentsController) DownloadDocument(g *gin.Context) {
+	var params models.DocumentVersionParams
+	if err := g.ShouldBindQuery(&params); err != nil {
+		renderBindError(g, dc.logger, err, "Error binding document version parameters")
+		return
+	}
+
+	version, data, err := dc.documentsUseCase.DownloadDocument(g.Param("id"), g.Param("document_id"), params)
+	if err != nil {
+		renderError(g, dc.logger, err, "Error downloading document")
+		return
+	}
+
+	g.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": version.FileName}))
+	g.Header("X-Checksum-Sha256", version.Checksum)
+	g.Data(http.StatusOK, version.ContentType, data)
+}
+
+// DeleteDocument removes a document of a client with all its versions
+func (dc *DocumentsController) DeleteDocument(g *gin.Context) {
+	if err := dc.documentsUseCase.DeleteDocument(g.Param("id"), g.Param("document_id")); err != nil {
+		renderError(g, dc.logger, err, "Error deleting document")
+		return
+	}
+
+	renderMessage(g, http.StatusOK, "Document deleted successfully")
+}
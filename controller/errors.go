@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"contabi-be/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// statusByCode maps the domain error codes to HTTP statuses
+var statusByCode = map[models.ErrorCode]int{
+	models.CodeBadRequest:           http.StatusBadRequest,
+	models.CodeValidation:           http.StatusUnprocessableEntity,
+	models.CodeUnauthorized:         http.StatusUnauthorized,
+	models.CodeForbidden:            http.StatusForbidden,
+	models.CodeNotFound:             http.StatusNotFound,
+	models.CodeConflict:             http.StatusConflict,
+	models.CodePreconditionFailed:   http.StatusPreconditionFailed,
+	models.CodePreconditionRequired: http.StatusPreconditionRequired,
+}
+
+// renderError logs the error and writes it with the error envelope.
+// Domain errors keep their own message, any other error is reported as an
+// internal error with the given message so database details never leak.
+func renderError(g *gin.Context, logger *logrus.Logger, err error, message string) {
+	body := models.ErrorResponse{Code: models.CodeInternal, Message: message}
+	status := http.StatusInternalServerError
+
+	var domainErr *models.Error
+	if errors.As(err, &domainErr) {
+		if s, ok := statusByCode[domainErr.Code]; ok {
+			status = s
+			body = models.ErrorResponse{
+				Code:    domainErr.Code,
+				Message: domainErr.Message,
+				Details: domainErr.Details,
+			}
+		}
+	}
+
+	entry := logger.WithFields(logrus.Fields{
+		"error":  err,
+		"method": g.Request.Method,
+		"path":   g.FullPath(),
+		"status": status,
+	})
+	if status >= http.StatusInternalServerError {
+		entry.Error(message)
+	} else {
+		entry.Warn(message)
+	}
+
+	g.JSON(status, body)
+}
+
+// renderBindError writes the error of a request body that could not be read,
+// failed validations are reported field by field
+func renderBindError(g *gin.Context, logger *logrus.Logger, err error, message string) {
+	if details, ok := fieldErrors(err); ok {
+		validationErr := models.NewValidationError("The request has invalid fields", details)
+		validationErr.Err = err
+		renderError(g, logger, validationErr, message)
+		return
+	}
+
+	renderError(g, logger, models.NewBadRequestError(message, err), message)
+}
+
+// renderMessage writes a success response without data
+func renderMessage(g *gin.Context, status int, message string) {
+	g.JSON(status, models.MessageResponse{Message: message})
+}
@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+
+	"contabi-be/models"
+	"contabi-be/spreadsheet"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// export is the file format a listing was requested in, an empty format means json
+type export struct {
+	format      string
+	credentials bool
+}
+
+// bindExport reads the format of a listing from the format parameter or the
+// Accept header, only the administrators can export the credentials of the
+// clients. It returns false when the request was already answered with an error.
+func bindExport(g *gin.Context, logger *logrus.Logger) (export, bool) {
+	var e export
+	var params models.ExportParams
+	if err := g.ShouldBindQuery(&params); err != nil {
+		renderBindError(g, logger, err, "Error binding export parameters")
+		return e, false
+	}
+
+	e.format = params.Format
+	if e.format == "" {
+		accepted := g.NegotiateFormat(gin.MIMEJSON,
+			spreadsheet.ContentTypes[spreadsheet.FormatCSV],
+			spreadsheet.ContentTypes[spreadsheet.FormatXLSX],
+			spreadsheet.ContentTypes[spreadsheet.FormatPDF],
+		)
+		for format, contentType := range spreadsheet.ContentTypes {
+			if contentType == accepted {
+				e.format = format
+			}
+		}
+	}
+	if e.format == "json" {
+		e.format = ""
+	}
+	if e.format == "" {
+		return e, true
+	}
+
+	if params.IncludeCredentials {
+		if u, isUser := currentUser(g); !isUser || u.Role != models.RoleAdmin {
+			renderError(g, logger, models.NewForbiddenError("Only administrators can export the client credentials"), "Error exporting credentials")
+			return e, false
+		}
+		e.credentials = true
+	}
+
+	return e, true
+}
+
+// renderExport writes a listing as a file attachment named after the listing and the day
+func renderExport(g *gin.Context, logger *logrus.Logger, e export, name, title string, items any) {
+	var buf bytes.Buffer
+	if err := spreadsheet.Write(&buf, e.format, spreadsheet.Tabulate(title, items, e.credentials)); err != nil {
+		renderError(g, logger, err, "Error exporting "+name)
+		return
+	}
+
+	filename := fmt.Sprintf("%s-%s.%s", name, time.Now().Format(time.DateOnly), e.format)
+	g.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	g.Data(http.StatusOK, spreadsheet.ContentTypes[e.format], buf.Bytes())
+}
+
+// allPages collects every page of a listing so the exports are not cut at the page size
+func allPages[T any](params models.ClientListParams, list func(models.ClientListParams) ([]T, int, error)) ([]T, error) {
+	params.Page = 1
+	params.PageSize = models.MaxPageSize
+
+	items := []T{}
+	for {
+		page, total, err := list(params)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, page...)
+		if len(page) == 0 || len(items) >= total {
+			return items, nil
+		}
+		params.Page++
+	}
+}
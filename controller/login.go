@@ -3,6 +3,8 @@ package controller
 import (
 	"net/http"
 
+	"contabi-be/models"
+
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 )
@@ -23,17 +25,11 @@ func NewLoginController(loginUseCase LoginUseCase, logger *logrus.Logger) *Login
 
 // Login handles the request to log in a user
 func (lc *LoginController) Login(g *gin.Context) {
-	var credentials struct {
-		Username string `json:"username" binding:"required"`
-		Password string `json:"password" binding:"required"`
-	}
+	var credentials models.LoginRequest
 
 	// Bind JSON to struct
 	if err := g.ShouldBindJSON(&credentials); err != nil {
-		lc.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("Login(): Invalid request format")
-		g.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		renderBindError(g, lc.logger, err, "Invalid request format")
 		return
 	}
 
@@ -43,7 +39,10 @@ func (lc *LoginController) Login(g *gin.Context) {
 		lc.logger.WithFields(logrus.Fields{
 			"username": credentials.Username,
 		}).Error("Login(): Invalid username or password")
-		g.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid username or password"})
+		g.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    models.CodeUnauthorized,
+			Message: "Invalid username or password",
+		})
 		return
 	}
 
@@ -25,10 +25,7 @@ func NewMenusController(menusUseCase MenusUseCase, logger *logrus.Logger) *Menus
 func (mc *MenusController) GetEmisors(g *gin.Context) {
 	emisors, err := mc.menusUseCase.GetEmisors()
 	if err != nil {
-		mc.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("GetEmisors(): Error fetching emisors")
-		g.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching emisors"})
+		renderError(g, mc.logger, err, "Error fetching emisors")
 		return
 	}
 
@@ -39,10 +36,7 @@ func (mc *MenusController) GetEmisors(g *gin.Context) {
 func (mc *MenusController) GetSupervisors(g *gin.Context) {
 	supervisors, err := mc.menusUseCase.GetSupervisors()
 	if err != nil {
-		mc.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("GetSupervisors(): Error fetching supervisors")
-		g.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching supervisors"})
+		renderError(g, mc.logger, err, "Error fetching supervisors")
 		return
 	}
 
@@ -54,10 +48,7 @@ func (mc *MenusController) GetResponsiblesBySupervisor(g *gin.Context) {
 	supervisorID := g.Param("supervisor_id")
 	responsibles, err := mc.menusUseCase.GetResponsiblesBySupervisor(supervisorID)
 	if err != nil {
-		mc.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("GetResponsiblesBySupervisor(): Error fetching responsibles")
-		g.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching responsibles"})
+		renderError(g, mc.logger, err, "Error fetching responsibles")
 		return
 	}
 
@@ -68,10 +59,7 @@ func (mc *MenusController) GetResponsiblesBySupervisor(g *gin.Context) {
 func (mc *MenusController) GetRegimenes(g *gin.Context) {
 	regimenes, err := mc.menusUseCase.GetRegimenes()
 	if err != nil {
-		mc.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("GetRegimenes(): Error fetching regimenes")
-		g.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching regimenes"})
+		renderError(g, mc.logger, err, "Error fetching regimenes")
 		return
 	}
 
@@ -82,10 +70,7 @@ func (mc *MenusController) GetRegimenes(g *gin.Context) {
 func (mc *MenusController) GetAccountancyTypes(g *gin.Context) {
 	accountancyTypes, err := mc.menusUseCase.GetAccountancyTypes()
 	if err != nil {
-		mc.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("GetAccountancyTypes(): Error fetching accountancy types")
-		g.JSON(http.StatusInternalServerError, gin.H{"error": "Error accountancy types"})
+		renderError(g, mc.logger, err, "Error accountancy types")
 		return
 	}
 
@@ -96,10 +81,7 @@ func (mc *MenusController) GetAccountancyTypes(g *gin.Context) {
 func (mc *MenusController) GetAccountancyStatuses(g *gin.Context) {
 	statuses, err := mc.menusUseCase.GetAccountancyStatuses()
 	if err != nil {
-		mc.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("GetAccountancyStatuses(): Error fetching accountancy assignment statuses")
-		g.JSON(http.StatusInternalServerError, gin.H{"error": "Error accountancy assignment statuses"})
+		renderError(g, mc.logger, err, "Error accountancy assignment statuses")
 		return
 	}
 
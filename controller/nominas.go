@@ -25,23 +25,17 @@ func NewNominasController(nominasUsecase NominasUseCase, logger *logrus.Logger)
 func (nc *NominasController) CreateClientPaymentRecord(g *gin.Context) {
 	var clientPaymentRecord models.ClientHRPayment
 	if err := g.ShouldBindJSON(&clientPaymentRecord); err != nil {
-		nc.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("Error binding data")
-		g.JSON(http.StatusBadRequest, gin.H{"error": "Invalid data"})
+		renderBindError(g, nc.logger, err, "Invalid data")
 		return
 	}
 
 	err := nc.nominasUsecase.CreateClientPaymentRecord(clientPaymentRecord)
 	if err != nil {
-		nc.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("CreateClientPaymentRecord(): error while creating client payment record")
-		g.JSON(http.StatusBadRequest, gin.H{"error": "error while creating client payment record"})
+		renderError(g, nc.logger, err, "error while creating client payment record")
 		return
 	}
 
-	g.JSON(http.StatusCreated, "client payment record created successfully")
+	renderMessage(g, http.StatusCreated, "client payment record created successfully")
 }
 
 // GetClientsWithPendingPaymentsByHREntityID gets the list of all clients with pending payments of specific HR entity
@@ -50,10 +44,7 @@ func (nc *NominasController) GetClientsWithPendingPaymentsByHREntityID(g *gin.Co
 
 	clients, err := nc.nominasUsecase.GetClientsWithPendingPaymentsByHREntityID(hrEntityID)
 	if err != nil {
-		nc.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("Error fetching clients with pending payments")
-		g.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching clients with pending payments"})
+		renderError(g, nc.logger, err, "Error fetching clients with pending payments")
 		return
 	}
 
@@ -67,10 +58,7 @@ func (nc *NominasController) GetClientPendingPaymentsByHREntityIDDetails(g *gin.
 
 	payments, err := nc.nominasUsecase.GetClientPendingPaymentsByHREntityIDDetails(clientID, hrEntityID)
 	if err != nil {
-		nc.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("Error fetching pending payments of the client")
-		g.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching pending payments of the client"})
+		renderError(g, nc.logger, err, "Error fetching pending payments of the client")
 		return
 	}
 
@@ -81,23 +69,34 @@ func (nc *NominasController) GetClientPendingPaymentsByHREntityIDDetails(g *gin.
 func (nc *NominasController) UpdateClientPaymentRecord(g *gin.Context) {
 	var clientPaymentRecord models.UpdateClientHRPayment
 	if err := g.ShouldBindJSON(&clientPaymentRecord); err != nil {
-		nc.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("Error binding data")
-		g.JSON(http.StatusBadRequest, gin.H{"error": "Invalid data"})
+		renderBindError(g, nc.logger, err, "Invalid data")
 		return
 	}
 
 	err := nc.nominasUsecase.UpdateClientPaymentRecord(clientPaymentRecord)
 	if err != nil {
-		nc.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("UpdateClientPaymentRecord(): error while updating client payment record")
-		g.JSON(http.StatusBadRequest, gin.H{"error": "error while updating client payment record"})
+		renderError(g, nc.logger, err, "error while updating client payment record")
 		return
 	}
 
-	g.JSON(http.StatusOK, "client payment record updating successfully")
+	renderMessage(g, http.StatusOK, "client payment record updating successfully")
+}
+
+// PatchClientPaymentRecord updates only the fields of a client payment record
+// sent in a JSON merge patch
+func (nc *NominasController) PatchClientPaymentRecord(g *gin.Context) {
+	var patch models.ClientHRPaymentPatch
+	if err := g.ShouldBindJSON(&patch); err != nil {
+		renderBindError(g, nc.logger, err, "Invalid data")
+		return
+	}
+
+	if err := nc.nominasUsecase.PatchClientPaymentRecord(g.Param("id"), patch); err != nil {
+		renderError(g, nc.logger, err, "Error updating client payment record")
+		return
+	}
+
+	renderMessage(g, http.StatusOK, "Client payment record updated successfully")
 }
 
 // GetClientHRPaymentsHistory gets all the payments of a specific client and HR entity
@@ -107,10 +106,7 @@ func (nc *NominasController) GetClientHRPaymentsHistory(g *gin.Context) {
 
 	payments, err := nc.nominasUsecase.GetClientHRPaymentsHistory(clientID, hrEntityID)
 	if err != nil {
-		nc.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("Error fetching history payments of the client")
-		g.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching history payments of the client"})
+		renderError(g, nc.logger, err, "Error fetching history payments of the client")
 		return
 	}
 
@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"net/http"
+
+	"contabi-be/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// NotificationsController handles the notifications of the logged in user
+type NotificationsController struct {
+	notificationsUseCase NotificationsUseCase
+	logger               *logrus.Logger
+}
+
+// NewNotificationsController creates a new instance of NotificationsController
+func NewNotificationsController(notificationsUseCase NotificationsUseCase, logger *logrus.Logger) *NotificationsController {
+	return &NotificationsController{
+		notificationsUseCase: notificationsUseCase,
+		logger:               logger,
+	}
+}
+
+// GetNotifications returns the notifications of the user, only the unread ones when unread is true
+func (nc *NotificationsController) GetNotifications(g *gin.Context) {
+	var params models.NotificationParams
+	if err := g.ShouldBindQuery(&params); err != nil {
+		renderBindError(g, nc.logger, err, "Error binding notification parameters")
+		return
+	}
+
+	user, _ := currentUser(g)
+	notifications, err := nc.notificationsUseCase.GetNotifications(user.ID, params)
+	if err != nil {
+		renderError(g, nc.logger, err, "Error fetching notifications")
+		return
+	}
+
+	g.JSON(http.StatusOK, notifications)
+}
+
+// MarkNotificationRead marks a notification of the user as read
+func (nc *NotificationsController) MarkNotificationRead(g *gin.Context) {
+	user, _ := currentUser(g)
+	if err := nc.notificationsUseCase.MarkNotificationRead(user.ID, g.Param("id")); err != nil {
+		renderError(g, nc.logger, err, "Error marking notification as read")
+		return
+	}
+
+	renderMessage(g, http.StatusOK, "Notification marked as read")
+}
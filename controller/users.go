@@ -27,10 +27,7 @@ func NewUsersController(usersUseCase UsersUseCase, logger *logrus.Logger) *Users
 func (uc *UsersController) GetUsers(g *gin.Context) {
 	users, err := uc.usersUseCase.GetUsers()
 	if err != nil {
-		uc.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("Error fetching users")
-		g.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching users"})
+		renderError(g, uc.logger, err, "Error fetching users")
 		return
 	}
 
@@ -43,10 +40,7 @@ func (uc *UsersController) GetUserByID(g *gin.Context) {
 
 	user, err := uc.usersUseCase.GetUserByID(userID)
 	if err != nil {
-		uc.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("GetUserInfo(): error while fetching user info")
-		g.JSON(http.StatusBadRequest, gin.H{"error": "error while fetching user info"})
+		renderError(g, uc.logger, err, "error while fetching user info")
 		return
 	}
 
@@ -57,92 +51,90 @@ func (uc *UsersController) GetUserByID(g *gin.Context) {
 func (uc *UsersController) CreateUser(g *gin.Context) {
 	var user models.User
 	if err := g.ShouldBindJSON(&user); err != nil {
-		uc.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("Error binding user data")
-		g.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user data"})
+		renderBindError(g, uc.logger, err, "Invalid user data")
 		return
 	}
 
 	err := uc.usersUseCase.CreateUser(user)
 	if err != nil {
-		uc.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("CreateUser(): error while creating user")
-		g.JSON(http.StatusBadRequest, gin.H{"error": "error while creating user"})
+		renderError(g, uc.logger, err, "error while creating user")
 		return
 	}
 
-	g.JSON(http.StatusCreated, "user created successfully")
+	renderMessage(g, http.StatusCreated, "user created successfully")
 }
 
 // UpdateUser updates an user
 func (uc *UsersController) UpdateUser(g *gin.Context) {
-	var user models.User
-	if err := g.ShouldBindJSON(&user); err != nil {
-		uc.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("Error binding user data")
-		g.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user data"})
+	var request models.UpdateUserRequest
+	if err := g.ShouldBindJSON(&request); err != nil {
+		renderBindError(g, uc.logger, err, "Invalid user data")
 		return
 	}
 
+	user := models.User{ID: request.ID, Username: request.Username}
+
 	err := uc.usersUseCase.UpdateUser(user)
 	if err != nil {
-		uc.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("UpdateUser(): error while updating user")
-		g.JSON(http.StatusBadRequest, gin.H{"error": "error while updating user"})
+		renderError(g, uc.logger, err, "error while updating user")
 		return
 	}
 
-	g.JSON(http.StatusOK, "user updated successfully")
+	renderMessage(g, http.StatusOK, "user updated successfully")
+}
+
+// PatchUser updates only the fields of a user sent in a JSON merge patch
+func (uc *UsersController) PatchUser(g *gin.Context) {
+	var patch models.UserPatch
+	if err := g.ShouldBindJSON(&patch); err != nil {
+		renderBindError(g, uc.logger, err, "Invalid user data")
+		return
+	}
+
+	if err := uc.usersUseCase.PatchUser(g.Param("id"), patch); err != nil {
+		renderError(g, uc.logger, err, "error while updating user")
+		return
+	}
+
+	renderMessage(g, http.StatusOK, "user updated successfully")
 }
 
 // UpdateUserRole updates the user role
 func (uc *UsersController) UpdateUserRole(g *gin.Context) {
-	var user models.User
-	if err := g.ShouldBindJSON(&user); err != nil {
-		uc.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("Error binding user data")
-		g.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user data"})
+	var request models.UpdateUserRoleRequest
+	if err := g.ShouldBindJSON(&request); err != nil {
+		renderBindError(g, uc.logger, err, "Invalid user data")
 		return
 	}
 
+	user := models.User{ID: request.ID, Role: request.Role}
+
 	err := uc.usersUseCase.UpdateUserRole(user)
 	if err != nil {
-		uc.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("UpdateUserRole(): error while updating user role")
-		g.JSON(http.StatusBadRequest, gin.H{"error": "error while updating user role"})
+		renderError(g, uc.logger, err, "error while updating user role")
 		return
 	}
 
-	g.JSON(http.StatusOK, "user role updated successfully")
+	renderMessage(g, http.StatusOK, "user role updated successfully")
 }
 
 // PutUserPassword updates the user password
 func (uc *UsersController) PutUserPassword(g *gin.Context) {
-	var user models.User
-	if err := g.ShouldBindJSON(&user); err != nil {
-		uc.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("Error binding user data")
-		g.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user data"})
+	var request models.UpdateUserPasswordRequest
+	if err := g.ShouldBindJSON(&request); err != nil {
+		renderBindError(g, uc.logger, err, "Invalid user data")
 		return
 	}
 
+	user := models.User{ID: request.ID, Password: request.Password}
+
 	err := uc.usersUseCase.PutUserPassword(user)
 	if err != nil {
-		uc.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("PutUserPassword(): error while updating user password")
-		g.JSON(http.StatusBadRequest, gin.H{"error": "error while updating user password"})
+		renderError(g, uc.logger, err, "error while updating user password")
 		return
 	}
 
-	g.JSON(http.StatusOK, "user password updated successfully")
+	renderMessage(g, http.StatusOK, "user password updated successfully")
 }
 
 // DeleteUser deletes an user
@@ -151,24 +143,18 @@ func (uc *UsersController) DeleteUser(g *gin.Context) {
 
 	err := uc.usersUseCase.DeleteUser(userID)
 	if err != nil {
-		uc.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("DeleteUser(): error while deleting user")
-		g.JSON(http.StatusBadRequest, gin.H{"error": "error while deleting user"})
+		renderError(g, uc.logger, err, "error while deleting user")
 		return
 	}
 
-	g.JSON(http.StatusOK, "user deleted successfully")
+	renderMessage(g, http.StatusOK, "user deleted successfully")
 }
 
 // DeleteUser deletes an user
 func (uc *UsersController) GetRoles(g *gin.Context) {
 	roles, err := uc.usersUseCase.GetRoles()
 	if err != nil {
-		uc.logger.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("Error fetching roles")
-		g.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching roles"})
+		renderError(g, uc.logger, err, "Error fetching roles")
 		return
 	}
 
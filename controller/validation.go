@@ -0,0 +1,249 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"contabi-be/models"
+	"contabi-be/sat"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// RegisterValidators adds the custom validation tags used by the models to
+// the validator of gin. The catalog tags check the ids against the menus.
+func RegisterValidators(menus MenusUseCase) error {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return fmt.Errorf("unexpected validator engine %T", binding.Validator.Engine())
+	}
+
+	// reports the fields with their json names, or form names for query strings
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+		if name == "" {
+			name = strings.SplitN(f.Tag.Get("form"), ",", 2)[0]
+		}
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	validators := map[string]validator.Func{
+		"rfc":             validateRFC,
+		"month":           validateMonth,
+		"date":            validateDate,
+		"positive_amount": validatePositiveAmount,
+		"regimen": catalogValidator(func() ([]string, error) {
+			regimenes, err := menus.GetRegimenes()
+			ids := make([]string, 0, len(regimenes))
+			for _, r := range regimenes {
+				ids = append(ids, r.ID)
+			}
+			return ids, err
+		}),
+		"emisor": catalogValidator(func() ([]string, error) {
+			emisors, err := menus.GetEmisors()
+			ids := make([]string, 0, len(emisors))
+			for _, e := range emisors {
+				ids = append(ids, e.ID)
+			}
+			return ids, err
+		}),
+		"supervisor": catalogValidator(func() ([]string, error) {
+			supervisors, err := menus.GetSupervisors()
+			ids := make([]string, 0, len(supervisors))
+			for _, s := range supervisors {
+				ids = append(ids, s.ID)
+			}
+			return ids, err
+		}),
+		"accountancy_type": catalogValidator(func() ([]string, error) {
+			types, err := menus.GetAccountancyTypes()
+			ids := make([]string, 0, len(types))
+			for _, t := range types {
+				ids = append(ids, t.ID)
+			}
+			return ids, err
+		}),
+		"assignment_status": catalogValidator(func() ([]string, error) {
+			statuses, err := menus.GetAccountancyStatuses()
+			ids := make([]string, 0, len(statuses))
+			for _, s := range statuses {
+				ids = append(ids, s.ID)
+			}
+			return ids, err
+		}),
+	}
+
+	// the fields of the merge patches are validated by their value when sent
+	v.RegisterCustomTypeFunc(func(field reflect.Value) any {
+		return field.Interface().(interface{ Validated() any }).Validated()
+	}, models.PatchField[string]{}, models.PatchField[bool]{}, models.PatchField[int]{}, models.PatchField[float64]{})
+
+	for tag, fn := range validators {
+		if err := v.RegisterValidation(tag, fn); err != nil {
+			return fmt.Errorf("registering %s validator: %w", tag, err)
+		}
+	}
+
+	// the responsible must be one of the responsibles of the supervisor
+	v.RegisterStructValidation(func(sl validator.StructLevel) {
+		a := sl.Current().Interface().(models.ClientAssignments)
+		if a.SupervisorID == "" || a.ResponsibleID == "" {
+			return
+		}
+
+		responsibles, err := menus.GetResponsiblesBySupervisor(a.SupervisorID)
+		if err != nil {
+			sl.ReportError(a.ResponsibleID, "responsible_id", "ResponsibleID", "responsible", "")
+			return
+		}
+
+		for _, r := range responsibles {
+			if r.ID == a.ResponsibleID {
+				return
+			}
+		}
+		sl.ReportError(a.ResponsibleID, "responsible_id", "ResponsibleID", "responsible", "")
+	}, models.ClientAssignments{})
+
+	return nil
+}
+
+// validateRFC checks the structure of an RFC, the interactor checks the date and check digit
+func validateRFC(fl validator.FieldLevel) bool {
+	rfc := sat.NormalizeRFC(fl.Field().String())
+	return sat.IsGenericRFC(rfc) || sat.RFCPattern.MatchString(rfc)
+}
+
+// validateMonth checks a month formatted as YYYY-MM or YYYY-MM-DD
+func validateMonth(fl validator.FieldLevel) bool {
+	value := fl.Field().String()
+	if _, err := time.Parse("2006-01", value); err == nil {
+		return true
+	}
+
+	_, err := time.Parse(time.DateOnly, value)
+	return err == nil
+}
+
+// validateDate checks a date formatted as YYYY-MM-DD
+func validateDate(fl validator.FieldLevel) bool {
+	_, err := time.Parse(time.DateOnly, fl.Field().String())
+	return err == nil
+}
+
+// validatePositiveAmount checks an amount sent as text is a decimal number
+// with at most two decimals greater than zero
+func validatePositiveAmount(fl validator.FieldLevel) bool {
+	cents, err := models.ParseAmount(fl.Field().String())
+	return err == nil && cents > 0
+}
+
+// catalogValidator builds a validator that checks the value is one of the ids of a catalog
+func catalogValidator(ids func() ([]string, error)) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		var value string
+		switch fl.Field().Kind() {
+		case reflect.Int, reflect.Int32, reflect.Int64:
+			value = strconv.FormatInt(fl.Field().Int(), 10)
+		default:
+			value = fl.Field().String()
+		}
+
+		catalog, err := ids()
+		if err != nil {
+			return false
+		}
+
+		for _, id := range catalog {
+			if id == value {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// fieldErrors converts the validation errors into the details of the error envelope
+func fieldErrors(err error) ([]models.FieldError, bool) {
+	var sliceErrs binding.SliceValidationError
+	if errors.As(err, &sliceErrs) {
+		var details []models.FieldError
+		for _, e := range sliceErrs {
+			d, ok := fieldErrors(e)
+			if !ok {
+				return nil, false
+			}
+			details = append(details, d...)
+		}
+		return details, true
+	}
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return nil, false
+	}
+
+	details := make([]models.FieldError, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		field := fe.Namespace()
+		if i := strings.Index(field, "."); i >= 0 {
+			field = field[i+1:]
+		}
+
+		details = append(details, models.FieldError{
+			Field:   field,
+			Rule:    fe.Tag(),
+			Message: fieldMessage(fe),
+		})
+	}
+
+	return details, true
+}
+
+// fieldMessage describes a failed validation rule
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required", "required_if":
+		return "is required"
+	case "rfc":
+		return "must be a valid RFC"
+	case "month":
+		return "must be a month formatted as YYYY-MM or YYYY-MM-DD"
+	case "date":
+		return "must be a date formatted as YYYY-MM-DD"
+	case "email":
+		return "must be a valid email address"
+	case "positive_amount":
+		return "must be an amount greater than zero with at most two decimals"
+	case "gt":
+		return "must be an amount greater than zero"
+	case "oneof":
+		return "must be one of: " + fe.Param()
+	case "max":
+		if fe.Kind() == reflect.String {
+			return "must have at most " + fe.Param() + " characters"
+		}
+		return "must be at most " + fe.Param()
+	case "min", "gte":
+		if fe.Kind() == reflect.String {
+			return "must have at least " + fe.Param() + " characters"
+		}
+		return "must be at least " + fe.Param()
+	case "responsible":
+		return "must be one of the responsibles of the supervisor"
+	case "regimen", "emisor", "supervisor", "accountancy_type", "assignment_status":
+		return "must be an existing " + strings.ReplaceAll(fe.Tag(), "_", " ")
+	default:
+		return "is invalid"
+	}
+}
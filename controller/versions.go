@@ -0,0 +1,31 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// setETag sends the ETag of a client, it is read before the client so a
+// change made in between makes the next update fail instead of being lost
+func (cc *ClientsController) setETag(c *gin.Context, clientID string) bool {
+	tag, err := cc.clientsUseCase.GetClientETag(clientID)
+	if err != nil {
+		renderError(c, cc.logger, err, "Error fetching client version")
+		return false
+	}
+
+	c.Header("ETag", tag)
+	return true
+}
+
+// setETag sends the ETag of a client with its accountancy history, the one the
+// changes of its assignments and responsible check
+func (ac *AccountancyController) setETag(g *gin.Context, clientID string) bool {
+	tag, err := ac.accountancyUseCase.GetClientETag(clientID)
+	if err != nil {
+		renderError(g, ac.logger, err, "Error fetching client version")
+		return false
+	}
+
+	g.Header("ETag", tag)
+	return true
+}
@@ -0,0 +1,42 @@
+// Package jobs runs the periodic tasks of the API inside the server process
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Daily runs a job when it starts and then every day at an hour of the local
+// time until the context is done. Running it at start covers the days the
+// server was down, the jobs must be safe to run more than once a day.
+func Daily(ctx context.Context, logger *logrus.Logger, name string, hour int, run func(now time.Time) error) {
+	entry := logger.WithField("job", name)
+
+	for {
+		now := time.Now()
+		if err := run(now); err != nil {
+			entry.WithField("error", err).Error("Error running job")
+		}
+
+		timer := time.NewTimer(time.Until(next(time.Now(), hour)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// next returns the next time it is the hour after now
+func next(now time.Time, hour int) time.Time {
+	y, m, d := now.Date()
+	at := time.Date(y, m, d, hour, 0, 0, 0, now.Location())
+	if !at.After(now) {
+		at = at.AddDate(0, 0, 1)
+	}
+
+	return at
+}
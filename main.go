@@ -1,21 +1,33 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"time"
 
 	"contabi-be/config"
 	"contabi-be/controller"
+	"contabi-be/jobs"
 	"contabi-be/middleware"
+	"contabi-be/openapi"
 	"contabi-be/router"
+	"contabi-be/secret"
 	"contabi-be/service/database"
+	"contabi-be/service/memory"
+	"contabi-be/storage"
 	"contabi-be/usecase"
 
 	"github.com/sirupsen/logrus"
 )
 
 func main() {
+	demo := flag.Bool("demo", false, "serves the API from an in-memory store with seeded data instead of the database")
+	flag.Parse()
+
 	// load env vars
 	cfg, err := config.Load()
 	if err != nil {
@@ -29,27 +41,88 @@ func main() {
 	})
 	logger.SetLevel(logrus.InfoLevel)
 
-	// creates service instances
-	dbs, err := database.NewDatabaseService(cfg)
+	// Creates instances of the services
+	var (
+		ls usecase.LoginService
+		us usecase.UsersService
+		cs usecase.ClientsService
+		ms usecase.MenusService
+		ns usecase.NominasService
+		as usecase.AccountancyService
+		ts usecase.NotificationsService
+		ds usecase.DocumentsService
+		es usecase.TimelineService
+	)
+
+	if *demo {
+		store, err := memory.NewDemoStore()
+		if err != nil {
+			log.Fatalf("Error seeding the demo store: %v", err)
+		}
+		logger.WithField("password", memory.DemoPassword).Info("Demo mode, the users admin, laura, miguel, carlos, sofia and diego share the password")
+
+		ls = memory.NewLoginService(store)
+		us = memory.NewUsersService(store)
+		cs = memory.NewClientsService(store)
+		ms = memory.NewMenusService(store)
+		ns = memory.NewNominasService(store)
+		as = memory.NewAccountancyService(store)
+		ts = memory.NewNotificationsService(store)
+		ds = memory.NewDocumentsService(store)
+		es = memory.NewTimelineService(store)
+	} else {
+		// creates service instances
+		dbs, err := database.NewDatabaseService(cfg)
+		if err != nil {
+			log.Fatalf("Error al crear el servicio de base de datos: %v", err)
+		}
+		if err := dbs.CheckActiveRFCIndex(); err != nil {
+			logger.WithError(err).Warn("The RFCs of the active clients are not unique")
+		}
+
+		ls = database.NewLoginService(dbs.DB)
+		us = database.NewUsersService(dbs.DB)
+		cs = database.NewClientsService(dbs.DB)
+		ms = database.NewMenusService(dbs.DB)
+		ns = database.NewNominasService(dbs.DB)
+		as = database.NewAccountancyService(dbs.DB)
+		ts = database.NewNotificationsService(dbs.DB)
+		ds = database.NewDocumentsService(dbs.DB)
+		es = database.NewTimelineService(dbs.DB)
+	}
+
+	// the certificate files are only kept when an encryption key is configured,
+	// the demo store is lost on exit so it gets a throwaway one
+	if *demo && cfg.EncryptionKey == "" {
+		if cfg.EncryptionKey, err = secret.GenerateKey(); err != nil {
+			log.Fatalf("Error generating the demo encryption key: %v", err)
+		}
+	}
+	box, err := secret.NewBox(cfg.EncryptionKey)
 	if err != nil {
-		log.Fatalf("Error al crear el servicio de base de datos: %v", err)
+		log.Fatalf("Error loading the encryption key: %v", err)
 	}
 
-	// Creates instances of the services
-	ls := database.NewLoginService(dbs.DB)
-	us := database.NewUsersService(dbs.DB)
-	cs := database.NewClientsService(dbs.DB)
-	ms := database.NewMenusService(dbs.DB)
-	ns := database.NewNominasService(dbs.DB)
-	as := database.NewAccountancyService(dbs.DB)
+	// the demo store is lost on exit so its documents go to a throwaway directory
+	if *demo && cfg.StorageDriver == "local" {
+		if cfg.StoragePath, err = os.MkdirTemp("", "contabi-demo-documents-"); err != nil {
+			log.Fatalf("Error creating the demo documents directory: %v", err)
+		}
+	}
+	files, err := newStorage(cfg)
+	if err != nil {
+		log.Fatalf("Error opening the documents storage: %v", err)
+	}
 
 	// creates instances of usecase
 	lu := usecase.NewLoginUseCase(ls)
 	uu := usecase.NewUsersUseCase(us)
-	cu := usecase.NewClientsUseCase(cs)
+	cu := usecase.NewClientsUseCase(cs, ms, ts, es, ds, as, box)
 	mu := usecase.NewMenusUseCase(ms)
-	nu := usecase.NewNominasUseCase(ns)
-	au := usecase.NewAccountancyUseCase(as)
+	nu := usecase.NewNominasUseCase(ns, cs)
+	au := usecase.NewAccountancyUseCase(as, cs, ms)
+	tu := usecase.NewNotificationsUseCase(ts)
+	du := usecase.NewDocumentsUseCase(ds, cs, files)
 
 	// creates instances of controller
 	lc := controller.NewLoginController(lu, logger)
@@ -58,8 +131,15 @@ func main() {
 	mc := controller.NewMenusController(mu, logger)
 	nc := controller.NewNominasController(nu, logger)
 	ac := controller.NewAccountancyController(au, logger)
+	tc := controller.NewNotificationsController(tu, logger)
+	dc := controller.NewDocumentsController(du, logger)
 	mw := middleware.New(lu)
 
+	// registers the custom validation rules of the request models
+	if err := controller.RegisterValidators(mu); err != nil {
+		log.Fatalf("Error registering validators: %v", err)
+	}
+
 	// creates router instance
 	rr := router.NewRouter(
 		lc,
@@ -68,9 +148,46 @@ func main() {
 		mc,
 		nc,
 		ac,
+		tc,
+		dc,
 		mw,
 	)
 
+	// checks that every route is described in the OpenAPI document
+	if err := openapi.Verify(rr.Routes()); err != nil {
+		log.Fatalf("OpenAPI document out of date: %s", err)
+	}
+
+	// notifies the supervisors and responsibles of the FIEL and CSD about to expire
+	thresholds, err := cfg.FielAlertThresholds()
+	if err != nil {
+		log.Fatal(err)
+	}
+	go jobs.Daily(context.Background(), logger, "certificate_expiration_alerts", cfg.JobsHour, func(now time.Time) error {
+		fiel, err := cu.NotifyFielExpirations(now, thresholds)
+		if err != nil {
+			return err
+		}
+		csd, err := cu.NotifyCSDExpirations(now, thresholds)
+		if err != nil {
+			return err
+		}
+
+		logger.WithFields(logrus.Fields{"job": "certificate_expiration_alerts", "fiel": fiel, "csd": csd}).Info("Certificate expiration alerts sent")
+		return nil
+	})
+
+	// sets the fees scheduled for the month as the current fee of the clients
+	go jobs.Daily(context.Background(), logger, "scheduled_fees", cfg.JobsHour, func(now time.Time) error {
+		changed, err := cu.ApplyScheduledFees(now)
+		if err != nil {
+			return err
+		}
+
+		logger.WithFields(logrus.Fields{"job": "scheduled_fees", "clients": changed}).Info("Scheduled fees applied")
+		return nil
+	})
+
 	server := &http.Server{
 		Addr:    fmt.Sprintf(":%s", cfg.Port),
 		Handler: rr,
@@ -80,3 +197,18 @@ func main() {
 		log.Fatalf("Fatal error starting the server: %s", err)
 	}
 }
+
+// newStorage opens the storage of the client documents set by STORAGE_DRIVER
+func newStorage(cfg config.Config) (storage.Storage, error) {
+	if cfg.StorageDriver == "s3" {
+		return storage.NewS3(storage.S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+		})
+	}
+
+	return storage.NewLocal(cfg.StoragePath)
+}
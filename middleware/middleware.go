@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"contabi-be/models"
 	"contabi-be/usecase"
 	"net/http"
 	"time"
@@ -38,8 +39,10 @@ func (m *Middleware) AuthMiddleware() gin.HandlerFunc {
 
 		// Check if both username and password are provided
 		if username == "" || password == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Username and password are required in headers"})
-			c.Abort()
+			c.AbortWithStatusJSON(http.StatusUnauthorized, models.ErrorResponse{
+				Code:    models.CodeUnauthorized,
+				Message: "Username and password are required in headers",
+			})
 			return
 		}
 
@@ -47,11 +50,16 @@ func (m *Middleware) AuthMiddleware() gin.HandlerFunc {
 		user, err := m.UseCase.Login(username, password)
 		if err != nil || user.ID == "" {
 			// If credentials are invalid, return error
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid username or password"})
-			c.Abort()
+			c.AbortWithStatusJSON(http.StatusUnauthorized, models.ErrorResponse{
+				Code:    models.CodeUnauthorized,
+				Message: "Invalid username or password",
+			})
 			return
 		}
 
+		// Keeps the user for the controllers that check its role
+		c.Set(models.UserContextKey, user)
+
 		// Continue with the next middleware or controller
 		c.Next()
 	}
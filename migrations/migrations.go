@@ -0,0 +1,15 @@
+// Package migrations embeds the schema migrations applied at startup
+package migrations
+
+import "embed"
+
+// Postgres holds the migrations applied over the existing Postgres schema
+//
+//go:embed postgres/*.sql
+var Postgres embed.FS
+
+// SQLite holds the migrations of the SQLite backend, it creates the same
+// tables and views the Postgres database provides
+//
+//go:embed sqlite/*.sql
+var SQLite embed.FS
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS clients_rfc_trgm_idx;
+DROP INDEX IF EXISTS clients_name_trgm_idx;
+DROP FUNCTION IF EXISTS f_unaccent(text);
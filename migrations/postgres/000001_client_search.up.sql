@@ -0,0 +1,11 @@
+-- the extensions need a role allowed to create them, usually the database owner
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+-- unaccent is only stable, the immutable wrapper allows using it in indexes
+CREATE OR REPLACE FUNCTION f_unaccent(text) RETURNS text
+    LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT
+    AS $$ SELECT public.unaccent('public.unaccent', $1) $$;
+
+CREATE INDEX IF NOT EXISTS clients_name_trgm_idx ON clients USING gin (lower(f_unaccent(name)) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS clients_rfc_trgm_idx ON clients USING gin (lower(rfc) gin_trgm_ops);
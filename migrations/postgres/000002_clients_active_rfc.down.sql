@@ -0,0 +1 @@
+DROP INDEX IF EXISTS clients_active_rfc_key;
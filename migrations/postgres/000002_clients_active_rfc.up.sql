@@ -0,0 +1,19 @@
+-- the index can not be built while active clients share an RFC, in that case
+-- the API still rejects new duplicates, GET /clients/duplicates lists the
+-- existing ones and the API creates the index at the first start after they
+-- are merged
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT upper(rfc)
+        FROM clients
+        WHERE active = true
+        GROUP BY upper(rfc)
+        HAVING COUNT(*) > 1
+    ) THEN
+        RAISE NOTICE 'active clients share an RFC, clients_active_rfc_key was not created';
+    ELSE
+        CREATE UNIQUE INDEX clients_active_rfc_key ON clients (upper(rfc)) WHERE active = true;
+    END IF;
+END
+$$;
@@ -0,0 +1,2 @@
+-- merged_into points a client absorbed by POST /clients/:id/merge to the survivor
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS merged_into uuid REFERENCES clients (id);
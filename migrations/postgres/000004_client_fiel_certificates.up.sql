@@ -0,0 +1,15 @@
+-- client_fiel_certificates keeps the FIEL certificates uploaded to POST /clients/:id/fiel,
+-- file holds the .cer encrypted and is null when no encryption key is configured
+CREATE TABLE IF NOT EXISTS client_fiel_certificates (
+    id bigserial PRIMARY KEY,
+    client_id uuid NOT NULL REFERENCES clients (id),
+    serial_number text NOT NULL UNIQUE,
+    rfc text NOT NULL,
+    name text NOT NULL,
+    valid_from timestamptz NOT NULL,
+    valid_to timestamptz NOT NULL,
+    file bytea,
+    uploaded_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS client_fiel_certificates_client_id_idx ON client_fiel_certificates (client_id, valid_to);
@@ -0,0 +1,24 @@
+-- client_fiel_renewals tracks the renewal of the FIEL of a client, the row
+-- belongs to the fiel_expiration it was set for
+CREATE TABLE IF NOT EXISTS client_fiel_renewals (
+    client_id uuid PRIMARY KEY REFERENCES clients (id),
+    status text NOT NULL CHECK (status IN ('requested', 'appointment', 'renewed')),
+    appointment_date date,
+    notes text NOT NULL DEFAULT '',
+    fiel_expiration text NOT NULL,
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+-- notifications are the messages of the users, key keeps the daily jobs from
+-- sending the same notification twice
+CREATE TABLE IF NOT EXISTS notifications (
+    id bigserial PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users (id),
+    client_id uuid REFERENCES clients (id),
+    kind text NOT NULL,
+    message text NOT NULL,
+    key text NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    read_at timestamptz,
+    UNIQUE (user_id, key)
+);
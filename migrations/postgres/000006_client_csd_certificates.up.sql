@@ -0,0 +1,19 @@
+-- client_csd_certificates keeps the certificados de sello digital of the clients
+-- uploaded to POST /clients/:id/csd, the private key and its password are
+-- encrypted with the encryption key of the API
+CREATE TABLE IF NOT EXISTS client_csd_certificates (
+    id bigserial PRIMARY KEY,
+    client_id uuid NOT NULL REFERENCES clients (id),
+    serial_number text NOT NULL UNIQUE,
+    rfc text NOT NULL,
+    name text NOT NULL,
+    branch text NOT NULL DEFAULT '',
+    valid_from timestamptz NOT NULL,
+    valid_to timestamptz NOT NULL,
+    certificate bytea NOT NULL,
+    private_key bytea NOT NULL,
+    password bytea NOT NULL,
+    uploaded_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS client_csd_certificates_client_id_idx ON client_csd_certificates (client_id, valid_to);
@@ -0,0 +1,16 @@
+-- client_contacts are the people to reach at a client, the preferred channel
+-- tells the notifications whether to use the email or the phone
+CREATE TABLE IF NOT EXISTS client_contacts (
+    id bigserial PRIMARY KEY,
+    client_id uuid NOT NULL REFERENCES clients (id),
+    name text NOT NULL,
+    role text NOT NULL DEFAULT '',
+    email text NOT NULL DEFAULT '',
+    phone text NOT NULL DEFAULT '',
+    preferred_channel text NOT NULL CHECK (preferred_channel IN ('email', 'phone', 'whatsapp')),
+    is_billing boolean NOT NULL DEFAULT false,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS client_contacts_client_id_idx ON client_contacts (client_id);
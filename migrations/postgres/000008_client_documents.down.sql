@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS client_document_versions;
+DROP TABLE IF EXISTS client_documents;
@@ -0,0 +1,28 @@
+-- client_documents are the files attached to a client, like its constancia de
+-- situación fiscal or its contracts, each upload of a file is a version
+CREATE TABLE IF NOT EXISTS client_documents (
+    id bigserial PRIMARY KEY,
+    client_id uuid NOT NULL REFERENCES clients (id),
+    category text NOT NULL CHECK (category IN ('csf', 'contract', 'acuse', 'identification', 'other')),
+    title text NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS client_documents_client_id_idx ON client_documents (client_id, category);
+
+-- client_document_versions keeps where each version is stored and the SHA-256
+-- of its content, checked when it is downloaded
+CREATE TABLE IF NOT EXISTS client_document_versions (
+    id bigserial PRIMARY KEY,
+    document_id bigint NOT NULL REFERENCES client_documents (id) ON DELETE CASCADE,
+    version integer NOT NULL,
+    file_name text NOT NULL,
+    content_type text NOT NULL,
+    size bigint NOT NULL,
+    checksum text NOT NULL,
+    storage_key text NOT NULL UNIQUE,
+    uploaded_by uuid REFERENCES users (id),
+    uploaded_at timestamptz NOT NULL DEFAULT now(),
+    UNIQUE (document_id, version)
+);
@@ -0,0 +1,12 @@
+-- client_events is the timeline of a client, the notes of the users and the
+-- events recorded when its payments, assignments, accountancy or status change
+CREATE TABLE IF NOT EXISTS client_events (
+    id bigserial PRIMARY KEY,
+    client_id uuid NOT NULL REFERENCES clients (id),
+    type text NOT NULL CHECK (type IN ('note', 'payment', 'assignments', 'accountancy_status', 'activated', 'deactivated')),
+    message text NOT NULL,
+    user_id uuid REFERENCES users (id),
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS client_events_client_id_idx ON client_events (client_id, created_at DESC);
@@ -0,0 +1,26 @@
+-- client_assignment_history keeps the supervisor, responsible and emisor each
+-- client had from valid_from until the day before valid_to, the current ones
+-- have no valid_to. It starts with the current assignments, without valid_from
+-- since it is not known when they began, and copies the column types of
+-- client_assignments.
+CREATE TABLE IF NOT EXISTS client_assignment_history AS
+    SELECT
+        client_id
+        , supervisor_id
+        , responsible_id
+        , emisor_id
+        , NULL::date AS valid_from
+        , NULL::date AS valid_to
+    FROM client_assignments;
+
+ALTER TABLE client_assignment_history ADD COLUMN IF NOT EXISTS id bigserial PRIMARY KEY;
+ALTER TABLE client_assignment_history ALTER COLUMN client_id SET NOT NULL;
+ALTER TABLE client_assignment_history ALTER COLUMN supervisor_id SET NOT NULL;
+ALTER TABLE client_assignment_history ALTER COLUMN responsible_id SET NOT NULL;
+ALTER TABLE client_assignment_history ALTER COLUMN emisor_id SET NOT NULL;
+ALTER TABLE client_assignment_history DROP CONSTRAINT IF EXISTS client_assignment_history_client_id_fkey;
+ALTER TABLE client_assignment_history ADD CONSTRAINT client_assignment_history_client_id_fkey FOREIGN KEY (client_id) REFERENCES clients (id);
+
+-- a client has a single current period
+CREATE UNIQUE INDEX IF NOT EXISTS client_assignment_history_current_idx ON client_assignment_history (client_id) WHERE valid_to IS NULL;
+CREATE INDEX IF NOT EXISTS client_assignment_history_client_id_idx ON client_assignment_history (client_id, valid_from);
@@ -0,0 +1,20 @@
+-- client_fees keeps the monthly fees of each client, a fee applies from its
+-- effective month (YYYY-MM) until the month of the next one and the months
+-- after the current one schedule a change. The first fee of a client has no
+-- effective month, it starts with the current fee of the clients.
+CREATE TABLE IF NOT EXISTS client_fees (
+    id bigserial PRIMARY KEY,
+    client_id uuid NOT NULL REFERENCES clients (id),
+    monthly_fee text NOT NULL,
+    effective_month text CHECK (effective_month ~ '^[0-9]{4}-[0-9]{2}$'),
+    created_at timestamptz NOT NULL DEFAULT now(),
+    UNIQUE (client_id, effective_month)
+);
+
+-- a client has a single first fee
+CREATE UNIQUE INDEX IF NOT EXISTS client_fees_first_idx ON client_fees (client_id) WHERE effective_month IS NULL;
+
+INSERT INTO client_fees (client_id, monthly_fee)
+SELECT id, CAST(monthly_fee AS text) FROM clients
+WHERE COALESCE(CAST(monthly_fee AS text), '') <> ''
+AND NOT EXISTS (SELECT 1 FROM client_fees f WHERE f.client_id = clients.id);
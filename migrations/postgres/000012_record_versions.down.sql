@@ -0,0 +1,3 @@
+ALTER TABLE client_accountancy_status DROP COLUMN IF EXISTS version;
+ALTER TABLE client_assignments DROP COLUMN IF EXISTS version;
+ALTER TABLE clients DROP COLUMN IF EXISTS version;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS client_state_changes;
+ALTER TABLE clients DROP COLUMN IF EXISTS state;
@@ -0,0 +1,23 @@
+-- state is where a client is in its lifecycle, the active flag follows it:
+-- the prospects and terminated clients are not active. The inactive clients
+-- start as terminated.
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS state text NOT NULL DEFAULT 'active'
+    CHECK (state IN ('prospect', 'onboarding', 'active', 'suspended', 'terminated'));
+
+UPDATE clients SET state = 'terminated' WHERE active = false;
+
+-- client_state_changes keeps the changes of state of each client with their
+-- reason and the date they took effect, the user is empty for the changes
+-- recorded by the system
+CREATE TABLE IF NOT EXISTS client_state_changes (
+    id bigserial PRIMARY KEY,
+    client_id uuid NOT NULL REFERENCES clients (id),
+    from_state text NOT NULL,
+    to_state text NOT NULL,
+    reason text NOT NULL,
+    effective_date date NOT NULL,
+    user_id uuid REFERENCES users (id),
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS client_state_changes_client_id_idx ON client_state_changes (client_id, effective_date);
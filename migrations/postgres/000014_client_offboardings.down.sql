@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS client_offboardings;
+ALTER TABLE client_accountancy_status DROP COLUMN IF EXISTS closed_at;
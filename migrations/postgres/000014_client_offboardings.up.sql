@@ -0,0 +1,26 @@
+-- closed_at is set on the monthly accountancy records of a client when it
+-- leaves, a closed month cannot be changed
+ALTER TABLE client_accountancy_status ADD COLUMN IF NOT EXISTS closed_at timestamptz;
+
+-- client_offboardings keeps the clients that left with their last serviced
+-- month (YYYY-MM), the balance they owed up to it, the credentials revoked and
+-- the documents listed to hand over
+CREATE TABLE IF NOT EXISTS client_offboardings (
+    id bigserial PRIMARY KEY,
+    client_id uuid NOT NULL REFERENCES clients (id),
+    reason text NOT NULL,
+    last_serviced_month text NOT NULL CHECK (last_serviced_month ~ '^[0-9]{4}-[0-9]{2}$'),
+    end_date date NOT NULL,
+    months_due integer NOT NULL DEFAULT 0,
+    outstanding_balance text NOT NULL DEFAULT '0',
+    closed_months integer NOT NULL DEFAULT 0,
+    revoked_clave_ciec boolean NOT NULL DEFAULT false,
+    revoked_clave_fiel boolean NOT NULL DEFAULT false,
+    revoked_csd_certificates integer NOT NULL DEFAULT 0,
+    revoked_fiel_files integer NOT NULL DEFAULT 0,
+    handover jsonb NOT NULL DEFAULT '[]',
+    user_id uuid REFERENCES users (id),
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS client_offboardings_client_id_idx ON client_offboardings (client_id, created_at DESC);
@@ -0,0 +1,15 @@
+DROP INDEX IF EXISTS clients_active_rfc_key;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT upper(rfc)
+        FROM clients
+        WHERE active = true
+        GROUP BY upper(rfc)
+        HAVING COUNT(*) > 1
+    ) THEN
+        CREATE UNIQUE INDEX clients_active_rfc_key ON clients (upper(rfc)) WHERE active = true;
+    END IF;
+END
+$$;
@@ -0,0 +1,20 @@
+-- the generic RFCs of the general public and the foreigners are shared by
+-- every client of those kinds. The index can not be built while other active
+-- clients share an RFC, the API creates it at the first start after they are
+-- merged.
+DROP INDEX IF EXISTS clients_active_rfc_key;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT upper(rfc)
+        FROM clients
+        WHERE active = true AND upper(rfc) NOT IN ('XAXX010101000', 'XEXX010101000')
+        GROUP BY upper(rfc)
+        HAVING COUNT(*) > 1
+    ) THEN
+        CREATE UNIQUE INDEX clients_active_rfc_key ON clients (upper(rfc))
+            WHERE active = true AND upper(rfc) NOT IN ('XAXX010101000', 'XEXX010101000');
+    END IF;
+END
+$$;
@@ -0,0 +1,18 @@
+DROP VIEW IF EXISTS clients_with_pending_payments;
+DROP VIEW IF EXISTS active_clients_view;
+DROP VIEW IF EXISTS client_info_view;
+DROP TABLE IF EXISTS client_accountancy_assignments;
+DROP TABLE IF EXISTS client_accountancy_status;
+DROP TABLE IF EXISTS client_assignments_types;
+DROP TABLE IF EXISTS client_hr_payments;
+DROP TABLE IF EXISTS client_payments;
+DROP TABLE IF EXISTS client_assignments;
+DROP TABLE IF EXISTS clients;
+DROP TABLE IF EXISTS assignment_statuses;
+DROP TABLE IF EXISTS accountancy_types;
+DROP TABLE IF EXISTS regimenes;
+DROP TABLE IF EXISTS emisors;
+DROP TABLE IF EXISTS supervisor_responsibles;
+DROP TABLE IF EXISTS user_roles;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS roles;
@@ -0,0 +1,170 @@
+-- gen_random_uuid is not available in SQLite, the uuid ids are built with randomblob
+CREATE TABLE roles (
+    id INTEGER PRIMARY KEY,
+    name TEXT NOT NULL
+);
+
+CREATE TABLE users (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(4)) || '-' || hex(randomblob(2)) || '-4' || substr(hex(randomblob(2)), 2) || '-' || substr('89ab', 1 + abs(random()) % 4, 1) || substr(hex(randomblob(2)), 2) || '-' || hex(randomblob(6)))),
+    username TEXT NOT NULL UNIQUE,
+    password TEXT NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT true
+);
+
+CREATE TABLE user_roles (
+    user_id TEXT PRIMARY KEY REFERENCES users (id),
+    role_id INTEGER NOT NULL REFERENCES roles (id)
+);
+
+CREATE TABLE supervisor_responsibles (
+    supervisor_id TEXT NOT NULL REFERENCES users (id),
+    responsible_id TEXT NOT NULL REFERENCES users (id),
+    PRIMARY KEY (supervisor_id, responsible_id)
+);
+
+CREATE TABLE emisors (
+    id INTEGER PRIMARY KEY,
+    name TEXT NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT true
+);
+
+CREATE TABLE regimenes (
+    id INTEGER PRIMARY KEY,
+    name TEXT NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT true
+);
+
+CREATE TABLE accountancy_types (
+    id INTEGER PRIMARY KEY,
+    name TEXT NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT true
+);
+
+CREATE TABLE assignment_statuses (
+    id INTEGER PRIMARY KEY,
+    name TEXT NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT true
+);
+
+CREATE TABLE clients (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(4)) || '-' || hex(randomblob(2)) || '-4' || substr(hex(randomblob(2)), 2) || '-' || substr('89ab', 1 + abs(random()) % 4, 1) || substr(hex(randomblob(2)), 2) || '-' || hex(randomblob(6)))),
+    name TEXT NOT NULL,
+    rfc TEXT NOT NULL,
+    clave_ciec TEXT NOT NULL DEFAULT '',
+    clave_fiel TEXT NOT NULL DEFAULT '',
+    fiel_expiration TEXT NOT NULL DEFAULT '',
+    monthly_fee TEXT NOT NULL DEFAULT '',
+    regimen_id INTEGER NOT NULL REFERENCES regimenes (id),
+    active BOOLEAN NOT NULL DEFAULT true
+);
+
+CREATE TABLE client_assignments (
+    client_id TEXT PRIMARY KEY REFERENCES clients (id),
+    supervisor_id TEXT NOT NULL REFERENCES users (id),
+    responsible_id TEXT NOT NULL REFERENCES users (id),
+    emisor_id INTEGER NOT NULL REFERENCES emisors (id)
+);
+
+CREATE TABLE client_payments (
+    id INTEGER PRIMARY KEY,
+    client_id TEXT NOT NULL REFERENCES clients (id),
+    last_payment_month TEXT NOT NULL,
+    last_payment_date TEXT NOT NULL,
+    folio_factura TEXT,
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+);
+
+CREATE INDEX client_payments_client_id_idx ON client_payments (client_id, last_payment_month);
+
+CREATE TABLE client_hr_payments (
+    id INTEGER PRIMARY KEY,
+    client_id TEXT NOT NULL REFERENCES clients (id),
+    hr_entity_id TEXT NOT NULL,
+    payment_month TEXT NOT NULL,
+    amount REAL NOT NULL,
+    paid BOOLEAN NOT NULL DEFAULT false,
+    month TEXT NOT NULL
+);
+
+CREATE TABLE client_assignments_types (
+    client_id TEXT NOT NULL REFERENCES clients (id),
+    assignment_type_id INTEGER NOT NULL REFERENCES accountancy_types (id),
+    PRIMARY KEY (client_id, assignment_type_id)
+);
+
+CREATE TABLE client_accountancy_status (
+    id INTEGER PRIMARY KEY,
+    client_id TEXT NOT NULL REFERENCES clients (id),
+    month TEXT NOT NULL,
+    due_date TEXT NOT NULL DEFAULT '',
+    observaciones TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE client_accountancy_assignments (
+    id INTEGER PRIMARY KEY,
+    status_id INTEGER NOT NULL REFERENCES client_accountancy_status (id),
+    assignment_type_id INTEGER NOT NULL REFERENCES accountancy_types (id),
+    assignment_status_id INTEGER NOT NULL REFERENCES assignment_statuses (id),
+    UNIQUE (status_id, assignment_type_id)
+);
+
+-- client_info_view joins a client with its regimen, assignments and last payment
+CREATE VIEW client_info_view AS
+SELECT
+    c.id,
+    c.name,
+    c.rfc,
+    c.clave_ciec,
+    c.clave_fiel,
+    c.fiel_expiration,
+    c.monthly_fee,
+    c.active,
+    c.regimen_id,
+    COALESCE(r.name, '') AS regimen_name,
+    COALESCE(ca.supervisor_id, '') AS supervisor_id,
+    COALESCE(s.username, '') AS supervisor_name,
+    COALESCE(ca.responsible_id, '') AS responsible_id,
+    COALESCE(rs.username, '') AS responsible_name,
+    COALESCE(ca.emisor_id, '') AS emisor_id,
+    COALESCE(e.name, '') AS emisor_name,
+    p.last_payment_month,
+    p.last_payment_date,
+    p.updated_at
+FROM clients c
+LEFT JOIN regimenes r ON r.id = c.regimen_id
+LEFT JOIN client_assignments ca ON ca.client_id = c.id
+LEFT JOIN users s ON s.id = ca.supervisor_id
+LEFT JOIN users rs ON rs.id = ca.responsible_id
+LEFT JOIN emisors e ON e.id = ca.emisor_id
+LEFT JOIN client_payments p ON p.id = (
+    SELECT lp.id
+    FROM client_payments lp
+    WHERE lp.client_id = c.id
+    ORDER BY lp.last_payment_month DESC, lp.id DESC
+    LIMIT 1
+);
+
+CREATE VIEW active_clients_view AS
+SELECT *
+FROM client_info_view
+WHERE active = true;
+
+-- the months are compared by their YYYY-MM prefix, the API accepts both YYYY-MM and YYYY-MM-DD
+CREATE VIEW clients_with_pending_payments AS
+SELECT
+    id,
+    name,
+    rfc,
+    regimen_name,
+    monthly_fee,
+    last_payment_month,
+    last_payment_date,
+    updated_at,
+    supervisor_name,
+    responsible_name,
+    emisor_name,
+    CASE WHEN last_payment_month IS NULL THEN 'never_paid' ELSE 'pending' END AS payment_status
+FROM active_clients_view
+WHERE last_payment_month IS NULL
+   OR substr(last_payment_month, 1, 7) < strftime('%Y-%m', 'now', 'localtime')
+ORDER BY name ASC;
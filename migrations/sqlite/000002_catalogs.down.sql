@@ -0,0 +1,8 @@
+DELETE FROM supervisor_responsibles;
+DELETE FROM user_roles;
+DELETE FROM users;
+DELETE FROM emisors;
+DELETE FROM assignment_statuses;
+DELETE FROM accountancy_types;
+DELETE FROM regimenes;
+DELETE FROM roles;
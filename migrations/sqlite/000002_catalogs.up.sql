@@ -0,0 +1,42 @@
+INSERT INTO roles (id, name) VALUES
+    (1, 'Administrador'),
+    (2, 'Supervisor'),
+    (3, 'Responsable');
+
+INSERT INTO regimenes (id, name) VALUES
+    (601, 'General de Ley Personas Morales'),
+    (603, 'Personas Morales con Fines no Lucrativos'),
+    (605, 'Sueldos y Salarios e Ingresos Asimilados a Salarios'),
+    (606, 'Arrendamiento'),
+    (612, 'Personas Físicas con Actividades Empresariales y Profesionales'),
+    (621, 'Incorporación Fiscal'),
+    (625, 'Régimen de las Actividades Empresariales con ingresos a través de Plataformas Tecnológicas'),
+    (626, 'Régimen Simplificado de Confianza');
+
+INSERT INTO accountancy_types (id, name) VALUES
+    (1, 'Declaración mensual'),
+    (2, 'DIOT'),
+    (3, 'Contabilidad electrónica'),
+    (4, 'Nómina');
+
+INSERT INTO assignment_statuses (id, name) VALUES
+    (1, 'Pendiente'),
+    (2, 'En proceso'),
+    (3, 'Presentada');
+
+INSERT INTO emisors (id, name) VALUES
+    (1, 'Despacho principal');
+
+-- local users, the password of all of them is contabi-local
+INSERT INTO users (id, username, password) VALUES
+    ('00000000-0000-4000-8000-000000000001', 'admin', '$2a$10$4Au3ts3e4Rqdbx/4PKdGnOapVGsM30y6KoFDWZvsulttCWMoMta5a'),
+    ('00000000-0000-4000-8000-000000000002', 'supervisor', '$2a$10$4Au3ts3e4Rqdbx/4PKdGnOapVGsM30y6KoFDWZvsulttCWMoMta5a'),
+    ('00000000-0000-4000-8000-000000000003', 'responsable', '$2a$10$4Au3ts3e4Rqdbx/4PKdGnOapVGsM30y6KoFDWZvsulttCWMoMta5a');
+
+INSERT INTO user_roles (user_id, role_id) VALUES
+    ('00000000-0000-4000-8000-000000000001', 1),
+    ('00000000-0000-4000-8000-000000000002', 2),
+    ('00000000-0000-4000-8000-000000000003', 3);
+
+INSERT INTO supervisor_responsibles (supervisor_id, responsible_id) VALUES
+    ('00000000-0000-4000-8000-000000000002', '00000000-0000-4000-8000-000000000003');
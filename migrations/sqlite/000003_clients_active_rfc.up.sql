@@ -0,0 +1 @@
+CREATE UNIQUE INDEX clients_active_rfc_key ON clients (upper(rfc)) WHERE active = true;
@@ -0,0 +1,15 @@
+-- client_fiel_certificates keeps the FIEL certificates uploaded to POST /clients/:id/fiel,
+-- file holds the .cer encrypted and is null when no encryption key is configured
+CREATE TABLE client_fiel_certificates (
+    id INTEGER PRIMARY KEY,
+    client_id TEXT NOT NULL REFERENCES clients (id),
+    serial_number TEXT NOT NULL UNIQUE,
+    rfc TEXT NOT NULL,
+    name TEXT NOT NULL,
+    valid_from DATETIME NOT NULL,
+    valid_to DATETIME NOT NULL,
+    file BLOB,
+    uploaded_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+);
+
+CREATE INDEX client_fiel_certificates_client_id_idx ON client_fiel_certificates (client_id, valid_to);
@@ -0,0 +1,2 @@
+DROP TABLE notifications;
+DROP TABLE client_fiel_renewals;
@@ -0,0 +1,24 @@
+-- client_fiel_renewals tracks the renewal of the FIEL of a client, the row
+-- belongs to the fiel_expiration it was set for
+CREATE TABLE client_fiel_renewals (
+    client_id TEXT PRIMARY KEY REFERENCES clients (id),
+    status TEXT NOT NULL CHECK (status IN ('requested', 'appointment', 'renewed')),
+    appointment_date DATE,
+    notes TEXT NOT NULL DEFAULT '',
+    fiel_expiration TEXT NOT NULL,
+    updated_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+);
+
+-- notifications are the messages of the users, key keeps the daily jobs from
+-- sending the same notification twice
+CREATE TABLE notifications (
+    id INTEGER PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users (id),
+    client_id TEXT REFERENCES clients (id),
+    kind TEXT NOT NULL,
+    message TEXT NOT NULL,
+    key TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    read_at DATETIME,
+    UNIQUE (user_id, key)
+);
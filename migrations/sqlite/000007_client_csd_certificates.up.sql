@@ -0,0 +1,19 @@
+-- client_csd_certificates keeps the certificados de sello digital of the clients
+-- uploaded to POST /clients/:id/csd, the private key and its password are
+-- encrypted with the encryption key of the API
+CREATE TABLE client_csd_certificates (
+    id INTEGER PRIMARY KEY,
+    client_id TEXT NOT NULL REFERENCES clients (id),
+    serial_number TEXT NOT NULL UNIQUE,
+    rfc TEXT NOT NULL,
+    name TEXT NOT NULL,
+    branch TEXT NOT NULL DEFAULT '',
+    valid_from DATETIME NOT NULL,
+    valid_to DATETIME NOT NULL,
+    certificate BLOB NOT NULL,
+    private_key BLOB NOT NULL,
+    password BLOB NOT NULL,
+    uploaded_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+);
+
+CREATE INDEX client_csd_certificates_client_id_idx ON client_csd_certificates (client_id, valid_to);
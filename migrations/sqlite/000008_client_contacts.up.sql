@@ -0,0 +1,16 @@
+-- client_contacts are the people to reach at a client, the preferred channel
+-- tells the notifications whether to use the email or the phone
+CREATE TABLE client_contacts (
+    id INTEGER PRIMARY KEY,
+    client_id TEXT NOT NULL REFERENCES clients (id),
+    name TEXT NOT NULL,
+    role TEXT NOT NULL DEFAULT '',
+    email TEXT NOT NULL DEFAULT '',
+    phone TEXT NOT NULL DEFAULT '',
+    preferred_channel TEXT NOT NULL CHECK (preferred_channel IN ('email', 'phone', 'whatsapp')),
+    is_billing BOOLEAN NOT NULL DEFAULT false,
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    updated_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+);
+
+CREATE INDEX client_contacts_client_id_idx ON client_contacts (client_id);
@@ -0,0 +1,2 @@
+DROP TABLE client_document_versions;
+DROP TABLE client_documents;
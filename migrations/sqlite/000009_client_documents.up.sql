@@ -0,0 +1,28 @@
+-- client_documents are the files attached to a client, like its constancia de
+-- situación fiscal or its contracts, each upload of a file is a version
+CREATE TABLE client_documents (
+    id INTEGER PRIMARY KEY,
+    client_id TEXT NOT NULL REFERENCES clients (id),
+    category TEXT NOT NULL CHECK (category IN ('csf', 'contract', 'acuse', 'identification', 'other')),
+    title TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    updated_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+);
+
+CREATE INDEX client_documents_client_id_idx ON client_documents (client_id, category);
+
+-- client_document_versions keeps where each version is stored and the SHA-256
+-- of its content, checked when it is downloaded
+CREATE TABLE client_document_versions (
+    id INTEGER PRIMARY KEY,
+    document_id INTEGER NOT NULL REFERENCES client_documents (id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    file_name TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size INTEGER NOT NULL,
+    checksum TEXT NOT NULL,
+    storage_key TEXT NOT NULL UNIQUE,
+    uploaded_by TEXT REFERENCES users (id),
+    uploaded_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    UNIQUE (document_id, version)
+);
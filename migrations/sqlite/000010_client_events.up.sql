@@ -0,0 +1,12 @@
+-- client_events is the timeline of a client, the notes of the users and the
+-- events recorded when its payments, assignments, accountancy or status change
+CREATE TABLE client_events (
+    id INTEGER PRIMARY KEY,
+    client_id TEXT NOT NULL REFERENCES clients (id),
+    type TEXT NOT NULL CHECK (type IN ('note', 'payment', 'assignments', 'accountancy_status', 'activated', 'deactivated')),
+    message TEXT NOT NULL,
+    user_id TEXT REFERENCES users (id),
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+);
+
+CREATE INDEX client_events_client_id_idx ON client_events (client_id, created_at DESC);
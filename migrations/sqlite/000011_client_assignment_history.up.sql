@@ -0,0 +1,20 @@
+-- client_assignment_history keeps the supervisor, responsible and emisor each
+-- client had from valid_from until the day before valid_to, the current ones
+-- have no valid_to. It starts with the current assignments, without valid_from
+-- since it is not known when they began.
+CREATE TABLE client_assignment_history (
+    id INTEGER PRIMARY KEY,
+    client_id TEXT NOT NULL REFERENCES clients (id),
+    supervisor_id TEXT NOT NULL REFERENCES users (id),
+    responsible_id TEXT NOT NULL REFERENCES users (id),
+    emisor_id INTEGER NOT NULL REFERENCES emisors (id),
+    valid_from TEXT,
+    valid_to TEXT
+);
+
+-- a client has a single current period
+CREATE UNIQUE INDEX client_assignment_history_current_idx ON client_assignment_history (client_id) WHERE valid_to IS NULL;
+CREATE INDEX client_assignment_history_client_id_idx ON client_assignment_history (client_id, valid_from);
+
+INSERT INTO client_assignment_history (client_id, supervisor_id, responsible_id, emisor_id)
+SELECT client_id, supervisor_id, responsible_id, emisor_id FROM client_assignments;
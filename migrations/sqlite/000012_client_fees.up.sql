@@ -0,0 +1,18 @@
+-- client_fees keeps the monthly fees of each client, a fee applies from its
+-- effective month (YYYY-MM) until the month of the next one and the months
+-- after the current one schedule a change. The first fee of a client has no
+-- effective month, it starts with the current fee of the clients.
+CREATE TABLE client_fees (
+    id INTEGER PRIMARY KEY,
+    client_id TEXT NOT NULL REFERENCES clients (id),
+    monthly_fee TEXT NOT NULL,
+    effective_month TEXT CHECK (effective_month GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]'),
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    UNIQUE (client_id, effective_month)
+);
+
+-- a client has a single first fee
+CREATE UNIQUE INDEX client_fees_first_idx ON client_fees (client_id) WHERE effective_month IS NULL;
+
+INSERT INTO client_fees (client_id, monthly_fee)
+SELECT id, monthly_fee FROM clients WHERE monthly_fee <> '';
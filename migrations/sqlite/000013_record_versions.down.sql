@@ -0,0 +1,3 @@
+ALTER TABLE client_accountancy_status DROP COLUMN version;
+ALTER TABLE client_assignments DROP COLUMN version;
+ALTER TABLE clients DROP COLUMN version;
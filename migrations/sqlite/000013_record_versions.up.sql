@@ -0,0 +1,6 @@
+-- version counts the changes of a client, its assignments and its monthly
+-- accountancy records, the API sends it as the ETag and a change must carry
+-- the one it read in If-Match
+ALTER TABLE clients ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE client_assignments ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE client_accountancy_status ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
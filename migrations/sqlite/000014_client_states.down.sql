@@ -0,0 +1,2 @@
+DROP TABLE client_state_changes;
+ALTER TABLE clients DROP COLUMN state;
@@ -0,0 +1,23 @@
+-- state is where a client is in its lifecycle, the active flag follows it:
+-- the prospects and terminated clients are not active. The inactive clients
+-- start as terminated.
+ALTER TABLE clients ADD COLUMN state TEXT NOT NULL DEFAULT 'active'
+    CHECK (state IN ('prospect', 'onboarding', 'active', 'suspended', 'terminated'));
+
+UPDATE clients SET state = 'terminated' WHERE active = false;
+
+-- client_state_changes keeps the changes of state of each client with their
+-- reason and the date they took effect, the user is empty for the changes
+-- recorded by the system
+CREATE TABLE client_state_changes (
+    id INTEGER PRIMARY KEY,
+    client_id TEXT NOT NULL REFERENCES clients (id),
+    from_state TEXT NOT NULL,
+    to_state TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    effective_date TEXT NOT NULL,
+    user_id TEXT REFERENCES users (id),
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+);
+
+CREATE INDEX client_state_changes_client_id_idx ON client_state_changes (client_id, effective_date);
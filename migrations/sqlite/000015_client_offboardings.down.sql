@@ -0,0 +1,2 @@
+DROP TABLE client_offboardings;
+ALTER TABLE client_accountancy_status DROP COLUMN closed_at;
@@ -0,0 +1,26 @@
+-- closed_at is set on the monthly accountancy records of a client when it
+-- leaves, a closed month cannot be changed
+ALTER TABLE client_accountancy_status ADD COLUMN closed_at DATETIME;
+
+-- client_offboardings keeps the clients that left with their last serviced
+-- month (YYYY-MM), the balance they owed up to it, the credentials revoked and
+-- the documents listed to hand over
+CREATE TABLE client_offboardings (
+    id INTEGER PRIMARY KEY,
+    client_id TEXT NOT NULL REFERENCES clients (id),
+    reason TEXT NOT NULL,
+    last_serviced_month TEXT NOT NULL CHECK (last_serviced_month GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]'),
+    end_date TEXT NOT NULL,
+    months_due INTEGER NOT NULL DEFAULT 0,
+    outstanding_balance TEXT NOT NULL DEFAULT '0',
+    closed_months INTEGER NOT NULL DEFAULT 0,
+    revoked_clave_ciec BOOLEAN NOT NULL DEFAULT false,
+    revoked_clave_fiel BOOLEAN NOT NULL DEFAULT false,
+    revoked_csd_certificates INTEGER NOT NULL DEFAULT 0,
+    revoked_fiel_files INTEGER NOT NULL DEFAULT 0,
+    handover TEXT NOT NULL DEFAULT '[]',
+    user_id TEXT REFERENCES users (id),
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+);
+
+CREATE INDEX client_offboardings_client_id_idx ON client_offboardings (client_id, created_at DESC);
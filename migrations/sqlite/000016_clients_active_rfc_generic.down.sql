@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS clients_active_rfc_key;
+CREATE UNIQUE INDEX clients_active_rfc_key ON clients (upper(rfc)) WHERE active = true;
@@ -0,0 +1,5 @@
+-- the generic RFCs of the general public and the foreigners are shared by
+-- every client of those kinds
+DROP INDEX IF EXISTS clients_active_rfc_key;
+CREATE UNIQUE INDEX clients_active_rfc_key ON clients (upper(rfc))
+    WHERE active = true AND upper(rfc) NOT IN ('XAXX010101000', 'XEXX010101000');
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// AmountPattern is an amount of money sent as text, pesos of up to ten digits
+// with at most two decimals
+var AmountPattern = regexp.MustCompile(`^[0-9]{1,10}(\.[0-9]{1,2})?$`)
+
+// ParseAmount reads an amount matching AmountPattern in cents, so the amounts
+// are added without the rounding errors of floats
+func ParseAmount(amount string) (int64, error) {
+	amount = strings.TrimSpace(amount)
+	if !AmountPattern.MatchString(amount) {
+		return 0, fmt.Errorf("invalid amount %q", amount)
+	}
+
+	pesos, fraction, _ := strings.Cut(amount, ".")
+	whole, err := strconv.ParseInt(pesos, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q: %w", amount, err)
+	}
+	cents, err := strconv.ParseInt((fraction + "00")[:2], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q: %w", amount, err)
+	}
+
+	return whole*100 + cents, nil
+}
+
+// FormatAmount writes an amount in cents with exactly two decimals
+func FormatAmount(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
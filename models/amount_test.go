@@ -0,0 +1,49 @@
+package models
+
+import "testing"
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		amount string
+		cents  int64
+		valid  bool
+	}{
+		{"1200", 120000, true},
+		{"1234.1", 123410, true},
+		{"1234.10", 123410, true},
+		{" 0.05 ", 5, true},
+		{"9999999999.99", 999999999999, true},
+		{"0", 0, true},
+		{"", 0, false},
+		{"Inf", 0, false},
+		{"NaN", 0, false},
+		{"1e308", 0, false},
+		{"0x1p4", 0, false},
+		{"-500", 0, false},
+		{"1.234", 0, false},
+		{"1,200", 0, false},
+		{"12345678901", 0, false},
+	}
+
+	for _, tt := range tests {
+		cents, err := ParseAmount(tt.amount)
+		if (err == nil) != tt.valid || cents != tt.cents {
+			t.Errorf("ParseAmount(%q) = %d, %v, want %d valid %v", tt.amount, cents, err, tt.cents, tt.valid)
+		}
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	tests := map[int64]string{
+		0:      "0.00",
+		5:      "0.05",
+		370230: "3702.30",
+		-50000: "-500.00",
+	}
+
+	for cents, want := range tests {
+		if got := FormatAmount(cents); got != want {
+			t.Errorf("FormatAmount(%d) = %q, want %q", cents, got, want)
+		}
+	}
+}
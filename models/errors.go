@@ -0,0 +1,117 @@
+package models
+
+import "fmt"
+
+// ErrorCode identifies the kind of a domain error
+type ErrorCode string
+
+const (
+	CodeBadRequest           ErrorCode = "bad_request"
+	CodeValidation           ErrorCode = "validation_error"
+	CodeUnauthorized         ErrorCode = "unauthorized"
+	CodeForbidden            ErrorCode = "forbidden"
+	CodeNotFound             ErrorCode = "not_found"
+	CodeConflict             ErrorCode = "conflict"
+	CodePreconditionFailed   ErrorCode = "precondition_failed"
+	CodePreconditionRequired ErrorCode = "precondition_required"
+	CodeInternal             ErrorCode = "internal_error"
+)
+
+// Error is a domain error, the code tells the API how to render it
+type Error struct {
+	Code    ErrorCode
+	Message string
+	Details any
+	Err     error
+}
+
+// Sentinel errors to compare with errors.Is, only the code is compared
+var (
+	ErrBadRequest   = &Error{Code: CodeBadRequest}
+	ErrValidation   = &Error{Code: CodeValidation}
+	ErrUnauthorized = &Error{Code: CodeUnauthorized}
+	ErrForbidden    = &Error{Code: CodeForbidden}
+	ErrNotFound     = &Error{Code: CodeNotFound}
+	ErrConflict     = &Error{Code: CodeConflict}
+)
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.Err)
+	}
+
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// Unwrap returns the underlying error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the target is a domain error with the same code
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// NewBadRequestError is returned when the request can not be read
+func NewBadRequestError(message string, err error) *Error {
+	return &Error{Code: CodeBadRequest, Message: message, Err: err}
+}
+
+// NewValidationError is returned when the data breaks a business rule
+func NewValidationError(message string, details any) *Error {
+	return &Error{Code: CodeValidation, Message: message, Details: details}
+}
+
+// NewUnauthorizedError is returned when the credentials are missing or invalid
+func NewUnauthorizedError(message string) *Error {
+	return &Error{Code: CodeUnauthorized, Message: message}
+}
+
+// NewForbiddenError is returned when the operation is not allowed
+func NewForbiddenError(message string) *Error {
+	return &Error{Code: CodeForbidden, Message: message}
+}
+
+// NewNotFoundError is returned when a record does not exist
+func NewNotFoundError(message string) *Error {
+	return &Error{Code: CodeNotFound, Message: message}
+}
+
+// NewConflictError is returned when the operation collides with existing data
+func NewConflictError(message string, details any) *Error {
+	return &Error{Code: CodeConflict, Message: message, Details: details}
+}
+
+// NewPreconditionFailedError is returned when the record changed since the
+// version sent in If-Match
+func NewPreconditionFailedError(message string, details any) *Error {
+	return &Error{Code: CodePreconditionFailed, Message: message, Details: details}
+}
+
+// NewPreconditionRequiredError is returned when a change does not send the
+// version it read in If-Match
+func NewPreconditionRequiredError(message string) *Error {
+	return &Error{Code: CodePreconditionRequired, Message: message}
+}
+
+// ErrorResponse is the body returned by the API when a request fails
+type ErrorResponse struct {
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+	Details any       `json:"details,omitempty"`
+}
+
+// FieldError describes a field of the request that failed a validation rule
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// MessageResponse is the body returned by the API when a request succeeds without data
+type MessageResponse struct {
+	Message string `json:"message"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMergeFees(t *testing.T) {
+	tests := []struct {
+		name            string
+		kept, discarded []ClientFee
+		want            FeeMerge
+	}{
+		{
+			name:      "the discarded client has no fees",
+			kept:      []ClientFee{{ID: "1", CreatedAt: "2024-03-10T00:00:00Z"}},
+			discarded: nil,
+			want:      FeeMerge{Month: "2024-03"},
+		},
+		{
+			name:      "the kept client has no fees",
+			kept:      nil,
+			discarded: []ClientFee{{ID: "2", CreatedAt: "2024-03-10T00:00:00Z"}, {ID: "3", EffectiveMonth: "2024-06"}},
+			want:      FeeMerge{Move: []string{"2", "3"}},
+		},
+		{
+			name:      "the fees of the discarded client start later",
+			kept:      []ClientFee{{ID: "1", CreatedAt: "2024-03-10T00:00:00Z"}},
+			discarded: []ClientFee{{ID: "2", CreatedAt: "2024-05-10T00:00:00Z"}, {ID: "3", EffectiveMonth: "2024-03"}},
+			want:      FeeMerge{Discard: []string{"2", "3"}, Month: "2024-03"},
+		},
+		{
+			name:      "the earlier fees of the discarded client date the first of the kept one",
+			kept:      []ClientFee{{ID: "1", CreatedAt: "2024-03-10T00:00:00Z"}, {ID: "4", EffectiveMonth: "2024-08"}},
+			discarded: []ClientFee{{ID: "2", CreatedAt: "2023-11-02T00:00:00Z"}, {ID: "3", EffectiveMonth: "2024-05"}},
+			want:      FeeMerge{Move: []string{"2"}, Discard: []string{"3"}, DateFirst: "1", Month: "2024-03"},
+		},
+		{
+			name:      "the first fee of the kept client is discarded when a dated one has its month",
+			kept:      []ClientFee{{ID: "1", CreatedAt: "2024-05-10T00:00:00Z"}, {ID: "4", EffectiveMonth: "2024-03"}},
+			discarded: []ClientFee{{ID: "2", CreatedAt: "2023-11-02T00:00:00Z"}},
+			want:      FeeMerge{Move: []string{"2"}, Discard: []string{"1"}, Month: "2024-03"},
+		},
+	}
+
+	for _, tt := range tests {
+		got := MergeFees(tt.kept, tt.discarded)
+		if !slices.Equal(got.Move, tt.want.Move) || !slices.Equal(got.Discard, tt.want.Discard) ||
+			got.DateFirst != tt.want.DateFirst || got.Month != tt.want.Month {
+			t.Errorf("%s: MergeFees = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -2,12 +2,45 @@ package models
 
 type User struct {
 	ID       string `json:"id"`
-	Username string `json:"username"`
-	Password string `json:"password,omitempty"`
+	Username string `json:"username" binding:"required,max=100"`
+	Password string `json:"password,omitempty" binding:"required,min=8"`
 	Active   bool   `json:"active"`
-	Role     int    `json:"role"`
+	Role     int    `json:"role" binding:"required,gt=0"`
 }
 
+// UpdateUserRequest is the body expected when renaming a user
+type UpdateUserRequest struct {
+	ID       string `json:"id" binding:"required"`
+	Username string `json:"username" binding:"required,max=100"`
+}
+
+// UpdateUserRoleRequest is the body expected when changing the role of a user
+type UpdateUserRoleRequest struct {
+	ID   string `json:"id" binding:"required"`
+	Role int    `json:"role" binding:"required,gt=0"`
+}
+
+// UpdateUserPasswordRequest is the body expected when changing the password of a user
+type UpdateUserPasswordRequest struct {
+	ID       string `json:"id" binding:"required"`
+	Password string `json:"password" binding:"required,min=8"`
+}
+
+// LoginRequest is the body expected by the login endpoint
+type LoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// Ids of the roles in the roles catalog
+const (
+	RoleAdmin      = 1
+	RoleSupervisor = 2
+)
+
+// UserContextKey is the key of the logged user in the request context
+const UserContextKey = "user"
+
 type Role struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
@@ -36,32 +69,41 @@ type Responsible struct {
 
 type Client struct {
 	ID             string `json:"id"`
-	Name           string `json:"name"`
-	RegimenID      string `json:"regimen_id"`
-	RFC            string `json:"rfc"`
-	ClaveCIEC      string `json:"clave_ciec"`
-	ClaveFiel      string `json:"clave_fiel"`
-	FielExpiration string `json:"fiel_expiration"`
-	MonthlyFee     string `json:"monthly_fee"`
-	Active         string `json:"active"`
+	Name           string `json:"name" binding:"required,max=255"`
+	RegimenID      string `json:"regimen_id" binding:"required,regimen"`
+	RFC            string `json:"rfc" binding:"required,rfc"`
+	ClaveCIEC      string `json:"clave_ciec" binding:"max=100"`
+	ClaveFiel      string `json:"clave_fiel" binding:"max=100"`
+	FielExpiration string `json:"fiel_expiration" binding:"omitempty,date"`
+	MonthlyFee     string `json:"monthly_fee" binding:"omitempty,positive_amount"`
+	Active         string `json:"active" binding:"omitempty,oneof=true false"`
+	// State is the one a new client starts in, active when it is not sent, the
+	// updates change it with PUT /clients/:id/state instead
+	State string `json:"state,omitempty" binding:"omitempty,oneof=prospect onboarding active"`
 }
 
 type ClientAssignments struct {
 	ClientID        string `json:"client_id"`
-	SupervisorID    string `json:"supervisor_id"`
+	SupervisorID    string `json:"supervisor_id" binding:"required,supervisor"`
 	SupervisorName  string `json:"supervisor_name"`
-	ResponsibleID   string `json:"responsible_id"`
+	ResponsibleID   string `json:"responsible_id" binding:"required"`
 	ResponsibleName string `json:"responsible_name"`
-	EmisorID        string `json:"emisor_id"`
+	EmisorID        string `json:"emisor_id" binding:"required,emisor"`
 	EmisorName      string `json:"emisor_name"`
 }
 
+// CreateClientRequest is the body expected when creating a client with its assignments
+type CreateClientRequest struct {
+	Client      Client            `json:"client"`
+	Assignments ClientAssignments `json:"assignments"`
+}
+
 type ClientPayment struct {
 	ClientID         string `json:"client_id"`
 	ClientName       string `json:"client_name"`
-	LastPaymentMonth string `json:"last_payment_month"`
-	LastPaymentDate  string `json:"last_payment_date"`
-	FolioFactura     string `json:"folio_factura"`
+	LastPaymentMonth string `json:"last_payment_month" binding:"required,month"`
+	LastPaymentDate  string `json:"last_payment_date" binding:"required,date"`
+	FolioFactura     string `json:"folio_factura" binding:"max=100"`
 	UpdatedAt        string `json:"updated_at"`
 }
 
@@ -71,23 +113,550 @@ type ClientInfo struct {
 	Name             string `json:"name"`
 	RFC              string `json:"rfc"`
 	RegimenName      string `json:"regimen_name"`
-	RegimenID        string `json:"regimen_id"`
-	ClaveCIEC        string `json:"clave_ciec"`
-	ClaveFiel        string `json:"clave_fiel"`
+	RegimenID        string `json:"regimen_id" export:"-"`
+	ClaveCIEC        string `json:"clave_ciec" export:"credential"`
+	ClaveFiel        string `json:"clave_fiel" export:"credential"`
 	FielExpiration   string `json:"fiel_expiration"`
 	MonthlyFee       string `json:"monthly_fee"`
 	Active           bool   `json:"active"`
-	SupervisorID     string `json:"supervisor_id,omitempty"`
+	State            string `json:"state"`
+	SupervisorID     string `json:"supervisor_id,omitempty" export:"-"`
 	SupervisorName   string `json:"supervisor_name,omitempty"`
-	ResponsibleID    string `json:"responsible_id,omitempty"`
+	ResponsibleID    string `json:"responsible_id,omitempty" export:"-"`
 	ResponsibleName  string `json:"responsible_name,omitempty"`
-	EmisorID         string `json:"emisor_id,omitempty"`
+	EmisorID         string `json:"emisor_id,omitempty" export:"-"`
 	EmisorName       string `json:"emisor_name,omitempty"`
 	LastPaymentMonth string `json:"last_payment_month,omitempty"`
 	LastPaymentDate  string `json:"last_payment_date,omitempty"`
 	UpdatedAt        string `json:"updated_at,omitempty"`
+	// Contacts and CSDCertificates are only included in the response of a single client
+	Contacts        []ClientContact  `json:"contacts,omitempty" export:"-"`
+	CSDCertificates []CSDCertificate `json:"csd_certificates,omitempty" export:"-"`
+}
+
+// Page sizes of the client listings
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+)
+
+// ClientListParams are the pagination, sorting and filters of the client listings
+type ClientListParams struct {
+	Page          int      `form:"page" binding:"omitempty,min=1"`
+	PageSize      int      `form:"page_size" binding:"omitempty,min=1,max=200"`
+	Sort          string   `form:"sort" binding:"omitempty,oneof=name rfc fiel_expiration monthly_fee"`
+	Order         string   `form:"order" binding:"omitempty,oneof=asc desc"`
+	RegimenID     string   `form:"regimen_id"`
+	SupervisorID  string   `form:"supervisor_id"`
+	ResponsibleID string   `form:"responsible_id"`
+	EmisorID      string   `form:"emisor_id"`
+	Active        *bool    `form:"active"`
+	State         string   `form:"state" binding:"omitempty,oneof=prospect onboarding active suspended terminated"`
+	MinFee        *float64 `form:"min_fee" binding:"omitempty,gte=0"`
+	MaxFee        *float64 `form:"max_fee" binding:"omitempty,gte=0"`
+}
+
+// ClientInfoPage is a page of the client listings with the total of matching clients
+type ClientInfoPage struct {
+	Items    []ClientInfo `json:"items"`
+	Total    int          `json:"total"`
+	Page     int          `json:"page"`
+	PageSize int          `json:"page_size"`
+}
+
+// Result limits of the client search
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 50
+)
+
+// ClientSearchParams are the query and options of the client search
+type ClientSearchParams struct {
+	Q      string `form:"q" binding:"required,min=2,max=100"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=50"`
+	Active *bool  `form:"active"`
+}
+
+// ClientSearchResult is a client found by the search, ranked by score from 0 to 1.
+// The highlights are HTML escaped with the matching parts inside <mark> tags.
+type ClientSearchResult struct {
+	ID            string  `json:"id"`
+	Name          string  `json:"name"`
+	RFC           string  `json:"rfc"`
+	Active        bool    `json:"active"`
+	Score         float64 `json:"score"`
+	NameHighlight string  `json:"name_highlight"`
+	RFCHighlight  string  `json:"rfc_highlight"`
+}
+
+// ClientSummary identifies a client in reports, merged clients point to the client that absorbed them
+type ClientSummary struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	RFC        string `json:"rfc"`
+	Active     bool   `json:"active"`
+	State      string `json:"state"`
+	MergedInto string `json:"merged_into,omitempty"`
+}
+
+// ClientConflict is the detail of the conflict returned when an active client already has the RFC
+type ClientConflict struct {
+	ExistingClientID string `json:"existing_client_id"`
+	RFC              string `json:"rfc"`
+}
+
+// Reasons a group of clients is suspected to be duplicated
+const (
+	DuplicateReasonRFC  = "rfc"
+	DuplicateReasonName = "name"
+)
+
+// DuplicateClientGroup is a set of clients suspected to be the same one, the
+// key is the RFC or the normalized name they share
+type DuplicateClientGroup struct {
+	Reason  string          `json:"reason"`
+	Key     string          `json:"key"`
+	Clients []ClientSummary `json:"clients"`
+}
+
+// Rules of a merge for the months both clients have records of, the records
+// of the preferred client are kept and the ones of the other are discarded
+const (
+	MergeKeepTarget = "target"
+	MergeKeepSource = "source"
+)
+
+// MergeClientsRequest is the body of a merge, the client of the path is the
+// target that survives and the source is deactivated
+type MergeClientsRequest struct {
+	SourceID  string `json:"source_id" binding:"required"`
+	Conflicts string `json:"conflicts" binding:"omitempty,oneof=target source"`
+}
+
+// MergeCount counts the records of a table moved to the target and the ones
+// discarded because the other client had a record of the same month
+type MergeCount struct {
+	Moved     int `json:"moved"`
+	Discarded int `json:"discarded"`
+}
+
+// ClientMergeResult reports the records moved by a merge
+type ClientMergeResult struct {
+	SourceID            string     `json:"source_id"`
+	TargetID            string     `json:"target_id"`
+	Conflicts           string     `json:"conflicts"`
+	Payments            MergeCount `json:"payments"`
+	HRPayments          MergeCount `json:"hr_payments"`
+	AccountancyStatuses MergeCount `json:"accountancy_statuses"`
+	AssignmentTypes     MergeCount `json:"assignment_types"`
+	Contacts            MergeCount `json:"contacts"`
+	Documents           MergeCount `json:"documents"`
+	Events              MergeCount `json:"events"`
+	Fees                MergeCount `json:"fees"`
+	AssignmentHistory   MergeCount `json:"assignment_history"`
+	StateChanges        MergeCount `json:"state_changes"`
+	FielCertificates    MergeCount `json:"fiel_certificates"`
+	CSDCertificates     MergeCount `json:"csd_certificates"`
+	FielRenewals        MergeCount `json:"fiel_renewals"`
+}
+
+// ExportParams select the file format of a listing, the credentials are only
+// exported for administrators that ask for them
+type ExportParams struct {
+	Format             string `form:"format" binding:"omitempty,oneof=json csv xlsx pdf"`
+	IncludeCredentials bool   `form:"include_credentials"`
+}
+
+// MaxImportRows limits the clients of an import file
+const MaxImportRows = 1000
+
+// MaxImportSize limits the import files, in bytes
+const MaxImportSize = 10 << 20
+
+// ClientImportParams are the query parameters of an import, it is a dry run
+// unless dry_run is false
+type ClientImportParams struct {
+	DryRun *bool `form:"dry_run"`
+}
+
+// ClientImportRow is the validation result of a row of an import file, the
+// rows are numbered like the spreadsheet so the header is row 1
+type ClientImportRow struct {
+	Row    int          `json:"row"`
+	Name   string       `json:"name"`
+	RFC    string       `json:"rfc"`
+	Valid  bool         `json:"valid"`
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+// ClientImportReport reports the rows of an import file, on a dry run the
+// valid rows are only validated and nothing is imported
+type ClientImportReport struct {
+	DryRun   bool              `json:"dry_run"`
+	Total    int               `json:"total"`
+	Valid    int               `json:"valid"`
+	Invalid  int               `json:"invalid"`
+	Imported int               `json:"imported"`
+	Rows     []ClientImportRow `json:"rows"`
+}
+
+// MaxCertificateSize limits the .cer files, the SAT certificates are about 2 KB
+const MaxCertificateSize = 64 << 10
+
+// FielCertificate is the data of an uploaded FIEL certificate (.cer) of a
+// client, the dates are RFC 3339 timestamps in UTC
+type FielCertificate struct {
+	ID           string `json:"id"`
+	ClientID     string `json:"client_id"`
+	SerialNumber string `json:"serial_number"`
+	RFC          string `json:"rfc"`
+	Name         string `json:"name"`
+	ValidFrom    string `json:"valid_from"`
+	ValidTo      string `json:"valid_to"`
+	FileStored   bool   `json:"file_stored"`
+	UploadedAt   string `json:"uploaded_at"`
+}
+
+// CSDCertificate is the data of a certificado de sello digital (CSD) of a
+// client, its private key and password are kept encrypted and never returned
+type CSDCertificate struct {
+	ID           string `json:"id"`
+	ClientID     string `json:"client_id"`
+	SerialNumber string `json:"serial_number"`
+	RFC          string `json:"rfc"`
+	Name         string `json:"name"`
+	Branch       string `json:"branch,omitempty"`
+	ValidFrom    string `json:"valid_from"`
+	ValidTo      string `json:"valid_to"`
+	UploadedAt   string `json:"uploaded_at"`
+}
+
+// CSDFiles are the files of a CSD as they are stored, the key and its
+// password are sealed with the encryption key
+type CSDFiles struct {
+	Certificate []byte
+	Key         []byte
+	Password    []byte
+}
+
+// CSDExpiration is a CSD that expires soon or already expired and has not
+// been replaced by a newer one of the client, the days left are negative for
+// the expired ones
+type CSDExpiration struct {
+	CSDID           string `json:"csd_id"`
+	SerialNumber    string `json:"serial_number"`
+	ValidTo         string `json:"valid_to"`
+	DaysLeft        int    `json:"days_left"`
+	ClientID        string `json:"client_id"`
+	Name            string `json:"name"`
+	RFC             string `json:"rfc"`
+	SupervisorID    string `json:"supervisor_id"`
+	SupervisorName  string `json:"supervisor_name"`
+	ResponsibleID   string `json:"responsible_id"`
+	ResponsibleName string `json:"responsible_name"`
+}
+
+// Renewal statuses of the FIEL of a client
+const (
+	FielRenewalRequested   = "requested"
+	FielRenewalAppointment = "appointment"
+	FielRenewalRenewed     = "renewed"
+)
+
+// DefaultExpiringDays is the window of the listings of expiring certificates
+const DefaultExpiringDays = 30
+
+// ExpiringParams are the query parameters of the listings of expiring certificates
+type ExpiringParams struct {
+	Days int `form:"days" binding:"omitempty,min=1,max=365"`
 }
 
+// Channels to reach the contacts of a client
+const (
+	ContactChannelEmail    = "email"
+	ContactChannelPhone    = "phone"
+	ContactChannelWhatsApp = "whatsapp"
+)
+
+// ClientContactRequest creates or replaces a contact of a client, the
+// preferred channel defaults to the email when it is given and needs the
+// email or the phone it uses
+type ClientContactRequest struct {
+	Name             string `json:"name" binding:"required,max=200"`
+	Role             string `json:"role,omitempty" binding:"max=100"`
+	Email            string `json:"email,omitempty" binding:"omitempty,email,max=254"`
+	Phone            string `json:"phone,omitempty" binding:"max=30"`
+	PreferredChannel string `json:"preferred_channel,omitempty" binding:"omitempty,oneof=email phone whatsapp"`
+	IsBilling        bool   `json:"is_billing"`
+}
+
+// ClientContact is a person to reach at a client, the billing contacts get the
+// invoices and the payment reminders
+type ClientContact struct {
+	ID               string `json:"id"`
+	ClientID         string `json:"client_id"`
+	Name             string `json:"name"`
+	Role             string `json:"role,omitempty"`
+	Email            string `json:"email,omitempty"`
+	Phone            string `json:"phone,omitempty"`
+	PreferredChannel string `json:"preferred_channel"`
+	IsBilling        bool   `json:"is_billing"`
+	CreatedAt        string `json:"created_at"`
+	UpdatedAt        string `json:"updated_at"`
+}
+
+// Address returns the email or the phone the contact is reached at by its
+// preferred channel
+func (c ClientContact) Address() string {
+	if c.PreferredChannel == ContactChannelEmail {
+		return c.Email
+	}
+
+	return c.Phone
+}
+
+// Categories of the client documents
+const (
+	DocumentCSF            = "csf" // constancia de situación fiscal
+	DocumentContract       = "contract"
+	DocumentAcuse          = "acuse"
+	DocumentIdentification = "identification"
+	DocumentOther          = "other"
+)
+
+// MaxDocumentSize is the largest client document accepted, in bytes
+const MaxDocumentSize = 20 << 20
+
+// DocumentParams filter the documents of a client by category
+type DocumentParams struct {
+	Category string `form:"category" binding:"omitempty,oneof=csf contract acuse identification other"`
+}
+
+// DocumentUpload are the form fields sent with the file of a new document, the
+// title defaults to the file name. The checksum is the SHA-256 of the file in
+// hex, when it is sent the upload is rejected if the received file differs.
+type DocumentUpload struct {
+	Category string `form:"category" binding:"required,oneof=csf contract acuse identification other"`
+	Title    string `form:"title" binding:"max=200"`
+	Checksum string `form:"checksum" binding:"omitempty,len=64,hexadecimal"`
+}
+
+// DocumentVersionUpload are the form fields sent with a new version of a document
+type DocumentVersionUpload struct {
+	Checksum string `form:"checksum" binding:"omitempty,len=64,hexadecimal"`
+}
+
+// DocumentFile is an uploaded file before it is stored
+type DocumentFile struct {
+	Name string
+	Data []byte
+}
+
+// ClientDocument is a file attached to a client with its current version, the
+// previous versions are only included in the response of a single document
+type ClientDocument struct {
+	ID        string            `json:"id"`
+	ClientID  string            `json:"client_id"`
+	Category  string            `json:"category"`
+	Title     string            `json:"title"`
+	Current   DocumentVersion   `json:"current"`
+	Versions  []DocumentVersion `json:"versions,omitempty"`
+	CreatedAt string            `json:"created_at"`
+	UpdatedAt string            `json:"updated_at"`
+}
+
+// DocumentVersion is an upload of the file of a document, the checksum is the
+// SHA-256 of its content in hex
+type DocumentVersion struct {
+	Version        int    `json:"version"`
+	FileName       string `json:"file_name"`
+	ContentType    string `json:"content_type"`
+	Size           int64  `json:"size"`
+	Checksum       string `json:"checksum"`
+	StorageKey     string `json:"-"`
+	UploadedBy     string `json:"uploaded_by,omitempty"`
+	UploadedByName string `json:"uploaded_by_name,omitempty"`
+	UploadedAt     string `json:"uploaded_at"`
+}
+
+// DocumentVersionParams select a version of a document, the current one by default
+type DocumentVersionParams struct {
+	Version int `form:"version" binding:"omitempty,min=1"`
+}
+
+// CSFDraft is the data read from a constancia de situación fiscal. The client
+// is a draft for POST /clients with the first current regimen found in the
+// catalog, the warnings tell what should be reviewed before creating it.
+type CSFDraft struct {
+	Client           Client          `json:"client"`
+	CURP             string          `json:"curp,omitempty"`
+	IDCIF            string          `json:"id_cif,omitempty"`
+	PostalCode       string          `json:"postal_code"`
+	Status           string          `json:"status"`
+	Regimenes        []CSFRegimen    `json:"regimenes"`
+	Obligations      []CSFObligation `json:"obligations"`
+	ExistingClientID string          `json:"existing_client_id,omitempty"`
+	Warnings         []string        `json:"warnings"`
+}
+
+// CSFRegimen is a regimen of a constancia, the regimen id is the matching
+// regimen of the catalog and the end date is empty while the regimen applies
+type CSFRegimen struct {
+	Name      string `json:"name"`
+	RegimenID string `json:"regimen_id,omitempty"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date,omitempty"`
+}
+
+// CSFObligation is a tax obligation listed in a constancia
+type CSFObligation struct {
+	Description string `json:"description"`
+	StartDate   string `json:"start_date"`
+	EndDate     string `json:"end_date,omitempty"`
+}
+
+// FielRenewalRequest sets the renewal status of the FIEL of a client, the
+// appointment date is required for the appointment status
+type FielRenewalRequest struct {
+	Status          string `json:"status" binding:"required,oneof=requested appointment renewed"`
+	AppointmentDate string `json:"appointment_date,omitempty" binding:"required_if=Status appointment,omitempty,date"` // YYYY-MM-DD
+	Notes           string `json:"notes,omitempty" binding:"max=1000"`
+}
+
+// FielRenewal is the renewal status of the FIEL of a client, it belongs to the
+// expiration it was set for and is cleared when a new certificate is uploaded
+type FielRenewal struct {
+	Status          string `json:"status"`
+	AppointmentDate string `json:"appointment_date,omitempty"`
+	Notes           string `json:"notes,omitempty"`
+	FielExpiration  string `json:"fiel_expiration"`
+	UpdatedAt       string `json:"updated_at"`
+}
+
+// FielExpiration is a client whose FIEL expires soon or already expired, the
+// days left are negative for the expired ones
+type FielExpiration struct {
+	ClientID        string       `json:"client_id"`
+	Name            string       `json:"name"`
+	RFC             string       `json:"rfc"`
+	FielExpiration  string       `json:"fiel_expiration"`
+	DaysLeft        int          `json:"days_left"`
+	SupervisorID    string       `json:"supervisor_id"`
+	SupervisorName  string       `json:"supervisor_name"`
+	ResponsibleID   string       `json:"responsible_id"`
+	ResponsibleName string       `json:"responsible_name"`
+	Renewal         *FielRenewal `json:"renewal"`
+}
+
+// Types of the events of the timeline of a client, the notes are written by
+// the users and the other events are recorded when the client changes
+const (
+	EventNote              = "note"
+	EventPayment           = "payment"
+	EventAssignments       = "assignments"
+	EventAccountancyStatus = "accountancy_status"
+	EventActivated         = "activated"
+	EventDeactivated       = "deactivated"
+)
+
+// ClientNoteRequest is the body of a note added to the timeline of a client
+type ClientNoteRequest struct {
+	Text string `json:"text" binding:"required,max=5000"`
+}
+
+// TimelineParams paginate the timeline of a client and filter it by event type
+type TimelineParams struct {
+	Page     int    `form:"page" binding:"omitempty,min=1"`
+	PageSize int    `form:"page_size" binding:"omitempty,min=1,max=200"`
+	Type     string `form:"type" binding:"omitempty,oneof=note payment assignments accountancy_status activated deactivated"`
+}
+
+// ClientEvent is an entry of the timeline of a client, the user is the author
+// of the notes and is empty for the recorded events
+type ClientEvent struct {
+	ID        string `json:"id"`
+	ClientID  string `json:"client_id"`
+	Type      string `json:"type"`
+	Message   string `json:"message"`
+	UserID    string `json:"user_id,omitempty"`
+	UserName  string `json:"user_name,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
+
+// ClientEventPage is a page of the timeline of a client, the newest events first
+type ClientEventPage struct {
+	Items    []ClientEvent `json:"items"`
+	Total    int           `json:"total"`
+	Page     int           `json:"page"`
+	PageSize int           `json:"page_size"`
+}
+
+// AssignmentPeriod are the supervisor, responsible and emisor a client had
+// from a day until the day before valid_to. The period without valid_from
+// started before the history was kept and the one without valid_to is current.
+type AssignmentPeriod struct {
+	ClientID        string `json:"client_id"`
+	ClientName      string `json:"client_name"`
+	SupervisorID    string `json:"supervisor_id"`
+	SupervisorName  string `json:"supervisor_name"`
+	ResponsibleID   string `json:"responsible_id"`
+	ResponsibleName string `json:"responsible_name"`
+	EmisorID        string `json:"emisor_id"`
+	EmisorName      string `json:"emisor_name"`
+	ValidFrom       string `json:"valid_from,omitempty"`
+	ValidTo         string `json:"valid_to,omitempty"`
+}
+
+// Covers tells whether the assignments of the period were in effect on a day
+// formatted as YYYY-MM-DD
+func (p AssignmentPeriod) Covers(day string) bool {
+	return (p.ValidFrom == "" || p.ValidFrom <= day) && (p.ValidTo == "" || p.ValidTo > day)
+}
+
+// AssignmentHistoryParams narrow the assignment history of a client to the
+// period in effect on a day
+type AssignmentHistoryParams struct {
+	AsOf string `form:"as_of" binding:"omitempty,date"`
+}
+
+// AssignmentsAsOfParams are the query parameters of the assignments every
+// client had on a day
+type AssignmentsAsOfParams struct {
+	AsOf          string `form:"as_of" binding:"required,date"`
+	SupervisorID  string `form:"supervisor_id"`
+	ResponsibleID string `form:"responsible_id"`
+}
+
+// Kinds of the notifications sent to the users
+const (
+	NotificationFielExpiration = "fiel_expiration"
+	NotificationCSDExpiration  = "csd_expiration"
+)
+
+// Notification is a message for a user about one of its clients, the key
+// keeps a notification from being sent twice
+type Notification struct {
+	ID        string `json:"id"`
+	UserID    string `json:"user_id"`
+	ClientID  string `json:"client_id,omitempty"`
+	Kind      string `json:"kind"`
+	Message   string `json:"message"`
+	Key       string `json:"-"`
+	CreatedAt string `json:"created_at"`
+	ReadAt    string `json:"read_at,omitempty"`
+}
+
+// NotificationParams are the query parameters of the notifications listing
+type NotificationParams struct {
+	Unread bool `form:"unread"`
+}
+
+// Payment statuses reported for clients with pending payments
+const (
+	PaymentStatusPending   = "pending"
+	PaymentStatusNeverPaid = "never_paid"
+)
+
 // ClientWithPendingPayment used for clients with pending payments
 type ClientWithPendingPayment struct {
 	ID               string `json:"id"`
@@ -95,6 +664,7 @@ type ClientWithPendingPayment struct {
 	RFC              string `json:"rfc"`
 	RegimenName      string `json:"regimen_name"`
 	MonthlyFee       string `json:"monthly_fee"`
+	State            string `json:"state"`
 	LastPaymentMonth string `json:"last_payment_month,omitempty"`
 	LastPaymentDate  string `json:"last_payment_date,omitempty"`
 	UpdatedAt        string `json:"updated_at,omitempty"`
@@ -102,6 +672,29 @@ type ClientWithPendingPayment struct {
 	ResponsibleName  string `json:"responsible_name,omitempty"`
 	EmisorName       string `json:"emisor_name,omitempty"`
 	PaymentStatus    string `json:"payment_status"`
+	// MonthsDue are the months after the last paid one up to the current, the
+	// current only for the clients that never paid, the months the client was
+	// not active are not charged
+	MonthsDue int `json:"months_due"`
+	// AmountDue adds the fee in effect on each of the months due
+	AmountDue string `json:"amount_due"`
+}
+
+// ClientFee is the monthly fee of a client from the effective month until the
+// month of the next one, the first fee of a client has no effective month
+type ClientFee struct {
+	ID             string `json:"id"`
+	ClientID       string `json:"client_id"`
+	MonthlyFee     string `json:"monthly_fee"`
+	EffectiveMonth string `json:"effective_month,omitempty"`
+	CreatedAt      string `json:"created_at"`
+}
+
+// ClientFeeRequest sets the monthly fee of a client from a month, a future
+// month schedules the change
+type ClientFeeRequest struct {
+	MonthlyFee     string `json:"monthly_fee" binding:"required,positive_amount"`
+	EffectiveMonth string `json:"effective_month" binding:"required,month"`
 }
 
 type ClientPaymentHistory struct {
@@ -115,12 +708,12 @@ type ClientPaymentHistory struct {
 
 type ClientHRPayment struct {
 	ID           string  `json:"id"`
-	ClientID     string  `json:"client_id"`
-	HREntityID   string  `json:"hr_entity_id"`
-	PaymentMonth string  `json:"payment_month"`
-	Amount       float64 `json:"amount"`
+	ClientID     string  `json:"client_id" binding:"required"`
+	HREntityID   string  `json:"hr_entity_id" binding:"required"`
+	PaymentMonth string  `json:"payment_month" binding:"required,month"`
+	Amount       float64 `json:"amount" binding:"gt=0"`
 	Paid         bool    `json:"paid"`
-	Month        string  `json:"month"`
+	Month        string  `json:"month" binding:"required"`
 }
 
 type ClientWithPendingHRPaymentDetails struct {
@@ -141,11 +734,11 @@ type ClientWithPendingHRPayment struct {
 }
 
 type UpdateClientHRPayment struct {
-	ID           string  `json:"id"`
-	PaymentMonth string  `json:"payment_month"`
-	Amount       float64 `json:"amount"`
-	Month        string  `json:"month"`
-	Paid         string  `json:"paid"`
+	ID           string  `json:"id" binding:"required"`
+	PaymentMonth string  `json:"payment_month" binding:"required,month"`
+	Amount       float64 `json:"amount" binding:"gt=0"`
+	Month        string  `json:"month" binding:"required"`
+	Paid         string  `json:"paid" binding:"required,oneof=true false"`
 }
 
 // ACCOUNTANCY
@@ -166,18 +759,26 @@ type AccountancyClientInfo struct {
 	Name            string `json:"name"`
 	RFC             string `json:"rfc"`
 	RegimenName     string `json:"regimen_name"`
-	RegimenID       string `json:"regimen_id"`
-	ClaveCIEC       string `json:"clave_ciec"`
-	ClaveFiel       string `json:"clave_fiel"`
+	RegimenID       string `json:"regimen_id" export:"-"`
+	ClaveCIEC       string `json:"clave_ciec" export:"credential"`
+	ClaveFiel       string `json:"clave_fiel" export:"credential"`
 	FielExpiration  string `json:"fiel_expiration"`
-	SupervisorID    string `json:"supervisor_id,omitempty"`
+	SupervisorID    string `json:"supervisor_id,omitempty" export:"-"`
 	SupervisorName  string `json:"supervisor_name,omitempty"`
-	ResponsibleID   string `json:"responsible_id,omitempty"`
+	ResponsibleID   string `json:"responsible_id,omitempty" export:"-"`
 	ResponsibleName string `json:"responsible_name,omitempty"`
-	EmisorID        string `json:"emisor_id,omitempty"`
+	EmisorID        string `json:"emisor_id,omitempty" export:"-"`
 	EmisorName      string `json:"emisor_name,omitempty"`
 }
 
+// AccountancyClientInfoPage is a page of the accountancy client listing with the total of matching clients
+type AccountancyClientInfoPage struct {
+	Items    []AccountancyClientInfo `json:"items"`
+	Total    int                     `json:"total"`
+	Page     int                     `json:"page"`
+	PageSize int                     `json:"page_size"`
+}
+
 // ClientAssignmentMatrixRow represents a row in the client-assignment matrix for the frontend
 // Each row is a client-assignment pair with a boolean indicating if the client has that assignment type
 // Useful for building a dynamic table in the frontend
@@ -190,7 +791,7 @@ type ClientAssignmentMatrixRow struct {
 }
 
 type AssignmentSelection struct {
-	AssignmentTypeID int  `json:"assignment_type_id"`
+	AssignmentTypeID int  `json:"assignment_type_id" binding:"required,accountancy_type"`
 	Selected         bool `json:"selected"`
 }
 
@@ -198,24 +799,30 @@ type AssignmentSelection struct {
 
 type ClientAccountancyStatus struct {
 	ID          int    `json:"id"`
-	ClientID    string `json:"client_id"`
-	Month       string `json:"month"`              // YYYY-MM-DD
-	DueDate     string `json:"due_date,omitempty"` // YYYY-MM-DD
-	Observacion string `json:"observaciones,omitempty"`
+	ClientID    string `json:"client_id" binding:"required"`
+	Month       string `json:"month" binding:"required,month"`              // YYYY-MM-DD
+	DueDate     string `json:"due_date,omitempty" binding:"omitempty,date"` // YYYY-MM-DD
+	Observacion string `json:"observaciones,omitempty" binding:"max=1000"`
+	Version     int    `json:"version"` // sent quoted in If-Match to update the record
+	// ClosedAt is set when the client left, a closed month cannot be changed
+	ClosedAt string `json:"closed_at,omitempty"`
 }
 
 type ClientAccountancyAssignment struct {
 	ID                   int    `json:"id"`
 	StatusID             int    `json:"status_id"`
-	AssignmentTypeID     int    `json:"assignment_type_id"`
+	AssignmentTypeID     int    `json:"assignment_type_id" binding:"required,accountancy_type"`
 	AssignmentTypeName   string `json:"assignment_type_name"`
-	AssignmentStatusID   int    `json:"assignment_status_id"`
+	AssignmentStatusID   int    `json:"assignment_status_id" binding:"required,assignment_status"`
 	AssignmentStatusName string `json:"assignment_status_name"`
 }
 
 type ClientAccountancyHistoryEntry struct {
 	Status      ClientAccountancyStatus       `json:"status"`
-	Assignments []ClientAccountancyAssignment `json:"assignments"`
+	Assignments []ClientAccountancyAssignment `json:"assignments" binding:"dive"`
+	// AssignedTo are the supervisor, responsible and emisor of the client at
+	// the end of the month, empty when it was not a client then
+	AssignedTo *AssignmentPeriod `json:"assigned_to,omitempty"`
 }
 
 // Estructura para devolver el historial y los tipos de asignaciones activas
@@ -229,3 +836,63 @@ type UpdateClientResponsible struct {
 	ResponsibleID string `json:"responsible_id"`
 	ClientID      string `json:"client_id"`
 }
+
+// FeeStart is the month a fee takes effect from, the first fee of a client
+// starts the month it was recorded
+func FeeStart(fee ClientFee) string {
+	if fee.EffectiveMonth != "" {
+		return fee.EffectiveMonth
+	}
+	if len(fee.CreatedAt) < 7 {
+		return ""
+	}
+
+	return fee.CreatedAt[:7]
+}
+
+// FeeMerge tells what a merge does with the fees of two clients. The fees of
+// the kept client apply from the month the earliest of them starts, the fees
+// of the other client that start before it are moved and the rest discarded.
+// When fees are moved the first fee of the kept client takes effect on that
+// month, or is discarded when it already has a fee for the month.
+type FeeMerge struct {
+	Move, Discard []string
+	// DateFirst is the first fee of the kept client to date with Month
+	DateFirst, Month string
+}
+
+// MergeFees plans the merge of the fees of the client kept with the ones of
+// the client discarded
+func MergeFees(kept, discarded []ClientFee) FeeMerge {
+	var merge FeeMerge
+	first := ""
+	dated := map[string]bool{}
+	for _, f := range kept {
+		if start := FeeStart(f); merge.Month == "" || start < merge.Month {
+			merge.Month = start
+		}
+		if f.EffectiveMonth == "" {
+			first = f.ID
+		} else {
+			dated[f.EffectiveMonth] = true
+		}
+	}
+
+	for _, f := range discarded {
+		if len(kept) == 0 || FeeStart(f) < merge.Month {
+			merge.Move = append(merge.Move, f.ID)
+		} else {
+			merge.Discard = append(merge.Discard, f.ID)
+		}
+	}
+
+	if len(merge.Move) > 0 && first != "" {
+		if dated[merge.Month] {
+			merge.Discard = append(merge.Discard, first)
+		} else {
+			merge.DateFirst = first
+		}
+	}
+
+	return merge
+}
@@ -0,0 +1,60 @@
+package models
+
+// Types of the items of the handover list of a client that leaves
+const (
+	HandoverDocument = "document"
+	HandoverFiel     = "fiel_certificate"
+	HandoverCSD      = "csd_certificate"
+)
+
+// OffboardingRequest is the body of the offboarding of a client, the end date
+// is today when it is not sent and cannot be in the future. The last serviced
+// month (YYYY-MM) cannot be after the month of the end date.
+type OffboardingRequest struct {
+	Reason            string `json:"reason" binding:"required,max=1000"`
+	LastServicedMonth string `json:"last_serviced_month" binding:"required,month"`
+	EndDate           string `json:"end_date" binding:"omitempty,date"`
+}
+
+// ClientOffboarding is the record of a client that left, with the balance it
+// owed up to its end date, the accountancy months closed, the credentials
+// revoked and the documents to hand over. While the client is terminated its
+// data does not change and no records are accepted for the months after the
+// one of the end date.
+type ClientOffboarding struct {
+	ID                string `json:"id"`
+	ClientID          string `json:"client_id"`
+	Reason            string `json:"reason"`
+	LastServicedMonth string `json:"last_serviced_month"`
+	EndDate           string `json:"end_date"`
+	// MonthsDue are the charged months after the last paid one, or from the
+	// first one when it never paid, up to the month of the end date.
+	// OutstandingBalance adds the fee in effect on each of them
+	MonthsDue          int                `json:"months_due"`
+	OutstandingBalance string             `json:"outstanding_balance"`
+	ClosedMonths       int                `json:"closed_months"`
+	Revoked            RevokedCredentials `json:"revoked"`
+	Handover           []HandoverItem     `json:"handover"`
+	UserID             string             `json:"user_id,omitempty"`
+	CreatedAt          string             `json:"created_at"`
+}
+
+// RevokedCredentials tells which credentials of a client were removed when it
+// left, the CSD are deleted with their keys and the FIEL files are cleared
+type RevokedCredentials struct {
+	ClaveCIEC       bool `json:"clave_ciec"`
+	ClaveFiel       bool `json:"clave_fiel"`
+	CSDCertificates int  `json:"csd_certificates"`
+	FielFiles       int  `json:"fiel_files"`
+}
+
+// HandoverItem is a document or certificate of a client listed to be handed
+// over when it leaves. Revoked marks the certificates deleted or whose file was
+// cleared by the offboarding, only their data is left to hand over.
+type HandoverItem struct {
+	Type    string `json:"type"`
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Detail  string `json:"detail,omitempty"`
+	Revoked bool   `json:"revoked"`
+}
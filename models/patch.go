@@ -0,0 +1,72 @@
+package models
+
+import (
+	"encoding/json"
+)
+
+// PatchField is a field of a JSON merge patch (RFC 7396), Set tells the field
+// was sent and Null that it was sent as null to clear it. The validator sees
+// the value only when it was sent and is not null, so omitempty skips the rest.
+type PatchField[T any] struct {
+	Value T
+	Set   bool
+	Null  bool
+}
+
+// UnmarshalJSON marks the field as sent, json calls it for null too
+func (f *PatchField[T]) UnmarshalJSON(data []byte) error {
+	f.Set = true
+	if string(data) == "null" {
+		f.Null = true
+		return nil
+	}
+
+	return json.Unmarshal(data, &f.Value)
+}
+
+// MarshalJSON writes the value, or null when it was not sent or was null
+func (f PatchField[T]) MarshalJSON() ([]byte, error) {
+	if !f.Set || f.Null {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(f.Value)
+}
+
+// Validated returns the value to validate, nil when it was not sent or was null
+func (f PatchField[T]) Validated() any {
+	if !f.Set || f.Null {
+		return nil
+	}
+
+	return f.Value
+}
+
+// ClientPatch changes only the fields of a client that are sent, the keys
+// and the FIEL expiration are cleared with null
+type ClientPatch struct {
+	Name           PatchField[string] `json:"name" binding:"omitempty,max=255"`
+	RegimenID      PatchField[string] `json:"regimen_id" binding:"omitempty,regimen"`
+	RFC            PatchField[string] `json:"rfc" binding:"omitempty,rfc"`
+	ClaveCIEC      PatchField[string] `json:"clave_ciec" binding:"omitempty,max=100"`
+	ClaveFiel      PatchField[string] `json:"clave_fiel" binding:"omitempty,max=100"`
+	FielExpiration PatchField[string] `json:"fiel_expiration" binding:"omitempty,date"`
+	MonthlyFee     PatchField[string] `json:"monthly_fee" binding:"omitempty,positive_amount"`
+	Active         PatchField[bool]   `json:"active"`
+}
+
+// UserPatch changes only the fields of a user that are sent
+type UserPatch struct {
+	Username PatchField[string] `json:"username" binding:"omitempty,max=100"`
+	Password PatchField[string] `json:"password" binding:"omitempty,min=8"`
+	Role     PatchField[int]    `json:"role" binding:"omitempty,gt=0"`
+}
+
+// ClientHRPaymentPatch changes only the fields of a nomina payment record
+// that are sent
+type ClientHRPaymentPatch struct {
+	PaymentMonth PatchField[string]  `json:"payment_month" binding:"omitempty,month"`
+	Amount       PatchField[float64] `json:"amount" binding:"omitempty,gt=0"`
+	Paid         PatchField[bool]    `json:"paid"`
+	Month        PatchField[string]  `json:"month"`
+}
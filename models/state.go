@@ -0,0 +1,84 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Lifecycle states of a client, a prospect is not a client yet, onboarding
+// ones are being set up, the suspended ones stopped paying and the terminated
+// ones left
+const (
+	ClientProspect   = "prospect"
+	ClientOnboarding = "onboarding"
+	ClientActive     = "active"
+	ClientSuspended  = "suspended"
+	ClientTerminated = "terminated"
+)
+
+// clientTransitions are the states each state can change to, a terminated
+// client comes back through onboarding or straight to active
+var clientTransitions = map[string][]string{
+	ClientProspect:   {ClientOnboarding, ClientTerminated},
+	ClientOnboarding: {ClientActive, ClientTerminated},
+	ClientActive:     {ClientSuspended, ClientTerminated},
+	ClientSuspended:  {ClientActive, ClientTerminated},
+	ClientTerminated: {ClientOnboarding, ClientActive},
+}
+
+// ClientTransitions returns the states a client in a state can change to
+func ClientTransitions(state string) []string {
+	return clientTransitions[state]
+}
+
+// CanChangeClientState tells whether a client can change between two states
+func CanChangeClientState(from, to string) bool {
+	return slices.Contains(clientTransitions[from], to)
+}
+
+// IsActiveState tells whether the clients in a state are active, the ones
+// being set up, serviced or suspended, the active flag of a client follows it
+func IsActiveState(state string) bool {
+	return state == ClientOnboarding || state == ClientActive || state == ClientSuspended
+}
+
+// IsChargedState tells whether the months a client spends in a state are
+// charged, only the active ones pay their fee
+func IsChargedState(state string) bool {
+	return state == ClientActive
+}
+
+// ClientStateRequest is the body of a change of the state of a client, the
+// effective date is today when it is not sent and cannot be in the future
+type ClientStateRequest struct {
+	State         string `json:"state" binding:"required,oneof=prospect onboarding active suspended terminated"`
+	Reason        string `json:"reason" binding:"required,max=1000"`
+	EffectiveDate string `json:"effective_date" binding:"omitempty,date"`
+}
+
+// ClientStateChange is a change of the state of a client, the user is who
+// made it and is empty for the changes recorded by the system
+type ClientStateChange struct {
+	ID            string `json:"id"`
+	ClientID      string `json:"client_id"`
+	FromState     string `json:"from_state"`
+	ToState       string `json:"to_state"`
+	Reason        string `json:"reason"`
+	EffectiveDate string `json:"effective_date"`
+	UserID        string `json:"user_id,omitempty"`
+	CreatedAt     string `json:"created_at"`
+}
+
+// Event is the event of the timeline of the client that records the change
+func (c ClientStateChange) Event() ClientEvent {
+	eventType := EventDeactivated
+	if c.ToState == ClientOnboarding || c.ToState == ClientActive {
+		eventType = EventActivated
+	}
+
+	return ClientEvent{
+		ClientID: c.ClientID,
+		Type:     eventType,
+		Message:  fmt.Sprintf("Client changed from %s to %s on %s: %s", c.FromState, c.ToState, c.EffectiveDate, c.Reason),
+	}
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AssignmentChanges describes the assignments of a client that differ between
+// two reads of it, it is empty when none changed
+func AssignmentChanges(before, after ClientInfo) string {
+	var changes []string
+	change := func(role, from, to, fromName, toName string) {
+		if from != to {
+			changes = append(changes, fmt.Sprintf("%s changed from %s to %s", role, orNone(fromName), orNone(toName)))
+		}
+	}
+	change("supervisor", before.SupervisorID, after.SupervisorID, before.SupervisorName, after.SupervisorName)
+	change("responsible", before.ResponsibleID, after.ResponsibleID, before.ResponsibleName, after.ResponsibleName)
+	change("emisor", before.EmisorID, after.EmisorID, before.EmisorName, after.EmisorName)
+
+	if len(changes) == 0 {
+		return ""
+	}
+	changes[0] = strings.ToUpper(changes[0][:1]) + changes[0][1:]
+
+	return strings.Join(changes, ", ")
+}
+
+// orNone names a missing assignment
+func orNone(name string) string {
+	if name == "" {
+		return "none"
+	}
+
+	return name
+}
+
+// AccountancyAssignmentChanges describes the accountancy types added to and
+// removed from a client between two reads of its active ones, it is empty
+// when none changed
+func AccountancyAssignmentChanges(before, after []AccountancyType) string {
+	var changes []string
+	if added := missingTypes(after, before); len(added) > 0 {
+		changes = append(changes, "added "+strings.Join(added, ", "))
+	}
+	if removed := missingTypes(before, after); len(removed) > 0 {
+		changes = append(changes, "removed "+strings.Join(removed, ", "))
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+
+	return "Accountancy assignments changed: " + strings.Join(changes, "; ")
+}
+
+// missingTypes returns the names of the types of a that are not in b
+func missingTypes(a, b []AccountancyType) []string {
+	var names []string
+	for _, t := range a {
+		found := false
+		for _, o := range b {
+			if o.ID == t.ID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			names = append(names, t.Name)
+		}
+	}
+
+	return names
+}
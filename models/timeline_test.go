@@ -0,0 +1,65 @@
+package models
+
+import "testing"
+
+func TestAssignmentChanges(t *testing.T) {
+	before := ClientInfo{SupervisorID: "1", SupervisorName: "laura", ResponsibleID: "2", ResponsibleName: "pedro", EmisorID: "1", EmisorName: "Despacho"}
+
+	tests := []struct {
+		name  string
+		after ClientInfo
+		want  string
+	}{
+		{"nothing changed", before, ""},
+		{
+			name:  "the responsible changed",
+			after: ClientInfo{SupervisorID: "1", SupervisorName: "laura", ResponsibleID: "3", ResponsibleName: "ana", EmisorID: "1", EmisorName: "Despacho"},
+			want:  "Responsible changed from pedro to ana",
+		},
+		{
+			name:  "the supervisor and the emisor changed",
+			after: ClientInfo{SupervisorID: "4", SupervisorName: "marta", ResponsibleID: "2", ResponsibleName: "pedro"},
+			want:  "Supervisor changed from laura to marta, emisor changed from Despacho to none",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := AssignmentChanges(before, tt.after); got != tt.want {
+			t.Errorf("%s: AssignmentChanges = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAccountancyAssignmentChanges(t *testing.T) {
+	before := []AccountancyType{{ID: "1", Name: "DIOT"}, {ID: "2", Name: "Nómina"}}
+
+	tests := []struct {
+		name  string
+		after []AccountancyType
+		want  string
+	}{
+		{"nothing changed", before, ""},
+		{"one added", append(before, AccountancyType{ID: "3", Name: "IVA"}), "Accountancy assignments changed: added IVA"},
+		{"added and removed", []AccountancyType{{ID: "2", Name: "Nómina"}, {ID: "3", Name: "IVA"}}, "Accountancy assignments changed: added IVA; removed DIOT"},
+		{"all removed", nil, "Accountancy assignments changed: removed DIOT, Nómina"},
+	}
+
+	for _, tt := range tests {
+		if got := AccountancyAssignmentChanges(before, tt.after); got != tt.want {
+			t.Errorf("%s: AccountancyAssignmentChanges = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStateChangeEvent(t *testing.T) {
+	change := ClientStateChange{ClientID: "7", FromState: ClientTerminated, ToState: ClientOnboarding, EffectiveDate: "2024-05-02", Reason: "Came back"}
+	want := ClientEvent{ClientID: "7", Type: EventActivated, Message: "Client changed from terminated to onboarding on 2024-05-02: Came back"}
+	if got := change.Event(); got != want {
+		t.Errorf("Event = %+v, want %+v", got, want)
+	}
+
+	change.FromState, change.ToState = ClientActive, ClientSuspended
+	if got := change.Event(); got.Type != EventDeactivated {
+		t.Errorf("suspending the event is %s, want %s", got.Type, EventDeactivated)
+	}
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ClientVersion counts the changes of a client and of its assignments, the
+// ETag of a client is made of both
+type ClientVersion struct {
+	Client      int
+	Assignments int
+}
+
+// ETag returns the version as the value of an ETag header
+func (v ClientVersion) ETag() string {
+	return fmt.Sprintf(`"%d.%d"`, v.Client, v.Assignments)
+}
+
+// ParseClientETag reads the version of an ETag made by ClientVersion.ETag
+func ParseClientETag(tag string) (ClientVersion, bool) {
+	client, assignments, ok := strings.Cut(strings.Trim(tag, `"`), ".")
+	if !ok {
+		return ClientVersion{}, false
+	}
+
+	var v ClientVersion
+	var errClient, errAssignments error
+	v.Client, errClient = strconv.Atoi(client)
+	v.Assignments, errAssignments = strconv.Atoi(assignments)
+
+	return v, errClient == nil && errAssignments == nil
+}
+
+// StatusETag returns the version of a monthly accountancy record as the value
+// of an ETag header
+func StatusETag(version int) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// ParseStatusETag reads the version of an ETag made by StatusETag
+func ParseStatusETag(tag string) (int, bool) {
+	version, err := strconv.Atoi(strings.Trim(tag, `"`))
+	return version, err == nil
+}
@@ -0,0 +1,421 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"contabi-be/models"
+	"contabi-be/spreadsheet"
+)
+
+// Document is the root object of an OpenAPI 3 document
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components Components            `json:"components"`
+	Security   []map[string][]string `json:"security,omitempty"`
+}
+
+// Info holds the API metadata
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// PathItem maps a lower case HTTP method to its operation
+type PathItem map[string]*Operation
+
+// Operation describes a single API operation on a path
+type Operation struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	OperationID string                `json:"operationId"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security"`
+}
+
+// Parameter describes a path, query or header parameter
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody describes the body of a request
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response describes a single response of an operation
+type Response struct {
+	Description string               `json:"description"`
+	Headers     map[string]Header    `json:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// Header describes a header of a response
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// MediaType holds the schema of a given content type
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the reusable schemas and security schemes
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+// SecurityScheme describes an authentication mechanism
+type SecurityScheme struct {
+	Type        string `json:"type"`
+	In          string `json:"in"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// Schema is the subset of the JSON schema used by the API models
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+}
+
+// patchField is implemented by the fields of the JSON merge patches, they are
+// documented as their value that may be null
+type patchField interface {
+	Validated() any
+}
+
+var patchFieldType = reflect.TypeOf((*patchField)(nil)).Elem()
+
+var (
+	once     sync.Once
+	document Document
+)
+
+// Spec returns the OpenAPI document of the API, it is built only once
+func Spec() Document {
+	once.Do(func() {
+		document = build()
+	})
+
+	return document
+}
+
+// build generates the document from the operations table
+func build() Document {
+	doc := Document{
+		OpenAPI: "3.0.3",
+		Info: Info{
+			Title:       "Contabi API",
+			Description: "Backend API for the Contabi accounting office management system",
+			Version:     "1.0.0",
+		},
+		Paths: map[string]PathItem{},
+		Components: Components{
+			Schemas: map[string]*Schema{},
+			SecuritySchemes: map[string]SecurityScheme{
+				"username": {
+					Type:        "apiKey",
+					In:          "header",
+					Name:        "X-Username",
+					Description: "Username of the logged user",
+				},
+				"password": {
+					Type:        "apiKey",
+					In:          "header",
+					Name:        "X-UserPassword",
+					Description: "Password of the logged user",
+				},
+			},
+		},
+		Security: []map[string][]string{{"username": {}, "password": {}}},
+	}
+
+	g := &generator{schemas: doc.Components.Schemas}
+	for _, op := range operations {
+		path := toOpenAPIPath(op.Path)
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = PathItem{}
+			doc.Paths[path] = item
+		}
+		item[strings.ToLower(op.Method)] = g.operation(op)
+	}
+
+	return doc
+}
+
+// exportQuery are the parameters of the listings that can be downloaded as files,
+// the format can also be chosen with the Accept header
+var exportQuery = []query{
+	{Name: "format", Type: "string", Description: "json, csv, xlsx or pdf, the files hold every matching client instead of a page"},
+	{Name: "include_credentials", Type: "boolean", Description: "Exports the CIEC and FIEL passwords, only for administrators"},
+}
+
+var pathParam = regexp.MustCompile(`[:*]([A-Za-z0-9_]+)`)
+
+// toOpenAPIPath converts a gin path (/clients/:id) into an OpenAPI path (/clients/{id})
+func toOpenAPIPath(path string) string {
+	return pathParam.ReplaceAllString(path, "{$1}")
+}
+
+// generator builds the schemas of the operations, registering named types as components
+type generator struct {
+	schemas map[string]*Schema
+}
+
+// operation builds the OpenAPI operation of a table entry
+func (g *generator) operation(op operation) *Operation {
+	o := &Operation{
+		Tags:        []string{op.Tag},
+		Summary:     op.Summary,
+		OperationID: op.ID,
+		Responses:   map[string]Response{},
+	}
+
+	if op.Public {
+		o.Security = []map[string][]string{}
+	}
+
+	for _, m := range pathParam.FindAllStringSubmatch(op.Path, -1) {
+		o.Parameters = append(o.Parameters, Parameter{
+			Name:     m[1],
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		})
+	}
+
+	query := op.Query
+	if op.Export {
+		query = append(query, exportQuery...)
+	}
+
+	for _, q := range query {
+		o.Parameters = append(o.Parameters, Parameter{
+			Name:        q.Name,
+			In:          "query",
+			Description: q.Description,
+			Required:    q.Required,
+			Schema:      &Schema{Type: q.Type},
+		})
+	}
+
+	if op.IfMatch != "" {
+		o.Parameters = append(o.Parameters, Parameter{
+			Name:        "If-Match",
+			In:          "header",
+			Description: op.IfMatch,
+			Required:    true,
+			Schema:      &Schema{Type: "string"},
+		})
+	}
+
+	if op.Request != nil {
+		o.RequestBody = &RequestBody{
+			Required: true,
+			Content: map[string]MediaType{
+				"application/json": {Schema: g.schema(reflect.TypeOf(op.Request))},
+			},
+		}
+	}
+
+	if len(op.Upload) > 0 {
+		form := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		for _, field := range op.Upload {
+			form.Properties[field] = &Schema{Type: "string", Format: "binary"}
+			form.Required = append(form.Required, field)
+		}
+		for _, field := range op.Form {
+			form.Properties[field.Name] = &Schema{Type: field.Type, Description: field.Description}
+			if field.Required {
+				form.Required = append(form.Required, field.Name)
+			}
+		}
+
+		o.RequestBody = &RequestBody{
+			Required: true,
+			Content: map[string]MediaType{
+				"multipart/form-data": {Schema: form},
+			},
+		}
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	success := Response{Description: http.StatusText(status)}
+	if op.Response != nil {
+		success.Content = map[string]MediaType{
+			"application/json": {Schema: g.schema(reflect.TypeOf(op.Response))},
+		}
+	}
+	if op.Export {
+		for _, format := range []string{spreadsheet.FormatCSV, spreadsheet.FormatXLSX, spreadsheet.FormatPDF} {
+			success.Content[spreadsheet.ContentTypes[format]] = MediaType{Schema: &Schema{Type: "string", Format: "binary"}}
+		}
+	}
+	if op.File {
+		success.Content = map[string]MediaType{
+			"application/octet-stream": {Schema: &Schema{Type: "string", Format: "binary"}},
+		}
+	}
+	if op.ETag != "" {
+		success.Headers = map[string]Header{
+			"ETag": {Description: op.ETag, Schema: &Schema{Type: "string"}},
+		}
+	}
+	o.Responses[statusKey(status)] = success
+
+	errs := op.Errors
+	if op.IfMatch != "" {
+		errs = slices.Concat(errs, []int{http.StatusPreconditionFailed, http.StatusPreconditionRequired})
+	}
+	if !op.Public {
+		errs = append([]int{http.StatusUnauthorized}, errs...)
+	}
+
+	for _, s := range errs {
+		o.Responses[statusKey(s)] = Response{
+			Description: http.StatusText(s),
+			Content: map[string]MediaType{
+				"application/json": {Schema: g.schema(reflect.TypeOf(models.ErrorResponse{}))},
+			},
+		}
+	}
+
+	return o
+}
+
+// schema returns the schema of a Go type, named structs are referenced from the components
+func (g *generator) schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Implements(patchFieldType) {
+			s := *g.schema(t.Field(0).Type)
+			s.Nullable = true
+			return &s
+		}
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		name := t.Name()
+		if _, ok := g.schemas[name]; !ok {
+			// registers a placeholder first so recursive types do not loop forever
+			g.schemas[name] = &Schema{}
+			*g.schemas[name] = *g.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	default:
+		return &Schema{}
+	}
+}
+
+// structSchema builds the object schema of a struct using its json tags
+func (g *generator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name, omitempty := jsonName(f)
+		if name == "-" {
+			continue
+		}
+
+		if f.Anonymous && name == "" {
+			embedded := g.structSchema(f.Type)
+			for k, v := range embedded.Properties {
+				s.Properties[k] = v
+			}
+			s.Required = append(s.Required, embedded.Required...)
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		s.Properties[name] = g.schema(f.Type)
+		if !omitempty && f.Type.Kind() != reflect.Pointer && !f.Type.Implements(patchFieldType) {
+			s.Required = append(s.Required, name)
+		}
+	}
+	sort.Strings(s.Required)
+
+	return s
+}
+
+// jsonName returns the json name of a field and whether it is omitted when empty
+func jsonName(f reflect.StructField) (string, bool) {
+	tag := f.Tag.Get("json")
+	if tag == "" {
+		return "", false
+	}
+
+	parts := strings.Split(tag, ",")
+	omitempty := false
+	for _, p := range parts[1:] {
+		if p == "omitempty" {
+			omitempty = true
+		}
+	}
+
+	return parts[0], omitempty
+}
+
+// statusKey formats an HTTP status as a responses key
+func statusKey(status int) string {
+	return strconv.Itoa(status)
+}
@@ -0,0 +1,291 @@
+package openapi
+
+import (
+	"net/http"
+
+	"contabi-be/models"
+)
+
+// operation describes a route of the router package and its payloads
+type operation struct {
+	ID       string
+	Method   string
+	Path     string
+	Tag      string
+	Summary  string
+	Public   bool
+	Query    []query
+	Request  any
+	Upload   []string // form fields of the uploaded files, the body is multipart instead of json
+	Form     []query  // text fields sent along the uploaded files
+	Export   bool     // the listing can also be downloaded as csv, xlsx or pdf
+	File     bool     // the response is a file whose content type varies
+	Response any
+	Status   int
+	Errors   []int
+	ETag     string // the ETag the response sends, empty when it sends none
+	IfMatch  string // the ETag a change must send in If-Match, empty when it needs none
+}
+
+// query describes a query string parameter or a form field of an operation
+type query struct {
+	Name        string
+	Type        string
+	Description string
+	Required    bool
+}
+
+// Error statuses shared by the operations
+var (
+	listErrors   = []int{http.StatusInternalServerError}
+	pageErrors   = []int{http.StatusBadRequest, http.StatusUnprocessableEntity, http.StatusInternalServerError}
+	itemErrors   = []int{http.StatusNotFound, http.StatusInternalServerError}
+	createErrors = []int{http.StatusBadRequest, http.StatusConflict, http.StatusUnprocessableEntity, http.StatusInternalServerError}
+	updateErrors = []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity, http.StatusInternalServerError}
+	actionErrors = []int{http.StatusNotFound, http.StatusInternalServerError}
+	stateErrors  = []int{http.StatusNotFound, http.StatusConflict, http.StatusInternalServerError}
+	exportErrors = []int{http.StatusBadRequest, http.StatusForbidden, http.StatusUnprocessableEntity, http.StatusInternalServerError}
+)
+
+// ETags the changes send in If-Match, * or a list of them, and the reads send
+const (
+	clientETag    = "ETag of the client sent by GET /clients/:id"
+	statusETag    = "ETag of the monthly record sent by GET /accountancy/client/:client_id/status/:status_id"
+	clientVersion = "Version of the client, send it in If-Match to change it"
+	statusVersion = "Version of the monthly record, send it in If-Match to change it"
+)
+
+// clientListQuery are the pagination, sorting and filters of the client listings
+var clientListQuery = []query{
+	{Name: "page", Type: "integer", Description: "Page number, starts at 1"},
+	{Name: "page_size", Type: "integer", Description: "Clients per page, 50 by default and 200 at most"},
+	{Name: "sort", Type: "string", Description: "name, rfc, fiel_expiration or monthly_fee"},
+	{Name: "order", Type: "string", Description: "asc or desc"},
+	{Name: "regimen_id", Type: "string", Description: "Only clients of the regimen"},
+	{Name: "supervisor_id", Type: "string", Description: "Only clients of the supervisor"},
+	{Name: "responsible_id", Type: "string", Description: "Only clients of the responsible"},
+	{Name: "emisor_id", Type: "string", Description: "Only clients of the emisor"},
+	{Name: "active", Type: "boolean", Description: "Only active or inactive clients"},
+	{Name: "state", Type: "string", Description: "Only clients in the state: prospect, onboarding, active, suspended or terminated"},
+	{Name: "min_fee", Type: "number", Description: "Minimum monthly fee"},
+	{Name: "max_fee", Type: "number", Description: "Maximum monthly fee"},
+}
+
+// operations lists every route registered by the router package, keep it in sync with the router
+var operations = []operation{
+	// Docs
+	{ID: "getOpenAPI", Method: http.MethodGet, Path: "/openapi.json", Tag: "docs", Summary: "Gets this OpenAPI document", Public: true},
+
+	// Login
+	{ID: "login", Method: http.MethodPost, Path: "/login", Tag: "login", Summary: "Validates the credentials of a user", Public: true,
+		Request: models.LoginRequest{}, Response: struct {
+			User models.User `json:"user"`
+		}{}, Errors: []int{http.StatusBadRequest, http.StatusUnauthorized}},
+
+	// Users
+	{ID: "getUsers", Method: http.MethodGet, Path: "/users", Tag: "users", Summary: "Gets all the users",
+		Response: []models.User{}, Errors: listErrors},
+	{ID: "getUserByID", Method: http.MethodGet, Path: "/user/:id", Tag: "users", Summary: "Gets a user by id",
+		Response: models.User{}, Errors: itemErrors},
+	{ID: "createUser", Method: http.MethodPost, Path: "/user", Tag: "users", Summary: "Creates a user",
+		Request: models.User{}, Response: models.MessageResponse{}, Status: http.StatusCreated, Errors: createErrors},
+	{ID: "updateUser", Method: http.MethodPut, Path: "/user", Tag: "users", Summary: "Updates the username of a user",
+		Request: models.UpdateUserRequest{}, Response: models.MessageResponse{}, Errors: updateErrors},
+	{ID: "updateUserRole", Method: http.MethodPut, Path: "/user/role", Tag: "users", Summary: "Updates the role of a user",
+		Request: models.UpdateUserRoleRequest{}, Response: models.MessageResponse{}, Errors: updateErrors},
+	{ID: "putUserPassword", Method: http.MethodPut, Path: "/user/pass", Tag: "users", Summary: "Updates the password of a user",
+		Request: models.UpdateUserPasswordRequest{}, Response: models.MessageResponse{}, Errors: updateErrors},
+	{ID: "patchUser", Method: http.MethodPatch, Path: "/user/:id", Tag: "users", Summary: "Updates only the username, role or password of a user sent in a JSON merge patch",
+		Request: models.UserPatch{}, Response: models.MessageResponse{}, Errors: updateErrors},
+	{ID: "deleteUser", Method: http.MethodDelete, Path: "/user/:id", Tag: "users", Summary: "Deactivates a user",
+		Response: models.MessageResponse{}, Errors: actionErrors},
+	{ID: "getRoles", Method: http.MethodGet, Path: "/roles", Tag: "users", Summary: "Gets all the roles",
+		Response: []models.Role{}, Errors: listErrors},
+
+	// Clients
+	{ID: "getClientsInfo", Method: http.MethodGet, Path: "/clients", Tag: "clients", Summary: "Gets a page of the clients with full info",
+		Query: clientListQuery, Response: models.ClientInfoPage{}, Export: true, Errors: exportErrors},
+	{ID: "getActiveClientsInfo", Method: http.MethodGet, Path: "/clients/active", Tag: "clients", Summary: "Gets a page of the active clients with full info",
+		Query: clientListQuery, Response: models.ClientInfoPage{}, Export: true, Errors: exportErrors},
+	{ID: "searchClients", Method: http.MethodGet, Path: "/clients/search", Tag: "clients", Summary: "Searches clients by name or RFC ignoring accents and typos",
+		Query: []query{
+			{Name: "q", Type: "string", Description: "Text to look for, at least 2 characters"},
+			{Name: "limit", Type: "integer", Description: "Maximum results, 20 by default and 50 at most"},
+			{Name: "active", Type: "boolean", Description: "Only active or inactive clients"},
+		}, Response: []models.ClientSearchResult{}, Errors: pageErrors},
+	{ID: "getDuplicateClients", Method: http.MethodGet, Path: "/clients/duplicates", Tag: "clients", Summary: "Lists the groups of clients suspected to be duplicates",
+		Response: []models.DuplicateClientGroup{}, Errors: listErrors},
+	{ID: "getClientInfo", Method: http.MethodGet, Path: "/clients/:id", Tag: "clients", Summary: "Gets full info of a specific client",
+		Response: models.ClientInfo{}, Errors: itemErrors, ETag: clientVersion},
+	{ID: "createClient", Method: http.MethodPost, Path: "/clients", Tag: "clients", Summary: "Creates a new client with assignments",
+		Request: models.CreateClientRequest{}, Response: models.MessageResponse{}, Status: http.StatusCreated, Errors: createErrors},
+	{ID: "importClients", Method: http.MethodPost, Path: "/clients/import", Tag: "clients", Summary: "Validates a CSV or XLSX file of up to 10 MB and 1000 clients and imports the valid rows",
+		Query: []query{
+			{Name: "dry_run", Type: "boolean", Description: "Only validates the rows when true, the default, set it to false to import them"},
+		}, Upload: []string{"file"}, Response: models.ClientImportReport{}, Errors: []int{http.StatusBadRequest, http.StatusConflict, http.StatusUnprocessableEntity, http.StatusInternalServerError}},
+	{ID: "readCSF", Method: http.MethodPost, Path: "/clients/csf", Tag: "clients", Summary: "Reads the data of a new client from the PDF of its constancia de situación fiscal, the draft is sent to POST /clients",
+		Upload: []string{"file"}, Response: models.CSFDraft{}, Errors: pageErrors},
+	{ID: "updateClient", Method: http.MethodPut, Path: "/clients/:id", Tag: "clients", Summary: "Updates the basic info of a client",
+		Request: models.Client{}, Response: models.MessageResponse{}, Errors: updateErrors, IfMatch: clientETag},
+	{ID: "patchClient", Method: http.MethodPatch, Path: "/clients/:id", Tag: "clients", Summary: "Updates only the fields of a client sent in a JSON merge patch, null clears the keys and the FIEL expiration",
+		Request: models.ClientPatch{}, Response: models.MessageResponse{}, Errors: updateErrors, IfMatch: clientETag},
+	{ID: "deactivateClient", Method: http.MethodDelete, Path: "/clients/:id", Tag: "clients", Summary: "Terminates a client (soft delete)",
+		Response: models.MessageResponse{}, Errors: stateErrors, IfMatch: clientETag},
+	{ID: "activateClient", Method: http.MethodPut, Path: "/clients/:id/activate", Tag: "clients", Summary: "Makes a client active",
+		Response: models.MessageResponse{}, Errors: stateErrors, IfMatch: clientETag},
+	{ID: "changeClientState", Method: http.MethodPut, Path: "/clients/:id/state", Tag: "clients", Summary: "Moves a client to another state of its lifecycle with the reason and the date it took effect, today by default",
+		Request: models.ClientStateRequest{}, Response: models.ClientStateChange{}, Errors: updateErrors, IfMatch: clientETag},
+	{ID: "getClientStateHistory", Method: http.MethodGet, Path: "/clients/:id/state/history", Tag: "clients", Summary: "Gets the changes of state of a client, the latest effective date first",
+		Response: []models.ClientStateChange{}, Errors: itemErrors, ETag: clientVersion},
+	{ID: "offboardClient", Method: http.MethodPost, Path: "/clients/:id/offboarding", Tag: "clients", Summary: "Terminates a client that leaves: computes what it owes up to its end date, closes its accountancy months, revokes its credentials and lists its documents to hand over",
+		Request: models.OffboardingRequest{}, Response: models.ClientOffboarding{}, Status: http.StatusCreated, Errors: updateErrors, IfMatch: clientETag},
+	{ID: "getClientOffboarding", Method: http.MethodGet, Path: "/clients/:id/offboarding", Tag: "clients", Summary: "Gets the latest offboarding of a client",
+		Response: models.ClientOffboarding{}, Errors: itemErrors},
+	{ID: "mergeClients", Method: http.MethodPost, Path: "/clients/:id/merge", Tag: "clients", Summary: "Merges another client into this one moving its history",
+		Request: models.MergeClientsRequest{}, Response: models.ClientMergeResult{}, Errors: updateErrors},
+	{ID: "uploadFielCertificate", Method: http.MethodPost, Path: "/clients/:id/fiel", Tag: "clients", Summary: "Reads the FIEL certificate (.cer) of a client and takes its expiration from it",
+		Upload: []string{"file"}, Response: models.FielCertificate{}, Status: http.StatusCreated, Errors: updateErrors},
+	{ID: "getFielCertificates", Method: http.MethodGet, Path: "/clients/:id/fiel", Tag: "clients", Summary: "Gets the FIEL certificates uploaded for a client",
+		Response: []models.FielCertificate{}, Errors: itemErrors, ETag: clientVersion},
+	{ID: "getExpiringFiel", Method: http.MethodGet, Path: "/clients/fiel/expiring", Tag: "clients", Summary: "Lists the active clients whose FIEL expires in the next days or already expired",
+		Query: []query{
+			{Name: "days", Type: "integer", Description: "Days ahead to look for expirations, 30 by default and 365 at most"},
+		}, Response: []models.FielExpiration{}, Errors: pageErrors},
+	{ID: "saveFielRenewal", Method: http.MethodPut, Path: "/clients/:id/fiel/renewal", Tag: "clients", Summary: "Sets the renewal status of the FIEL of a client",
+		Request: models.FielRenewalRequest{}, Response: models.FielRenewal{}, Errors: updateErrors, IfMatch: clientETag},
+	{ID: "uploadCSDCertificate", Method: http.MethodPost, Path: "/clients/:id/csd", Tag: "clients", Summary: "Stores the certificate (.cer) and the private key (.key) of a CSD of a client, the key and its password are encrypted",
+		Upload: []string{"certificate", "key"}, Form: []query{{Name: "password", Type: "string", Description: "Password of the private key", Required: true}}, Response: models.CSDCertificate{}, Status: http.StatusCreated, Errors: updateErrors},
+	{ID: "getCSDCertificates", Method: http.MethodGet, Path: "/clients/:id/csd", Tag: "clients", Summary: "Gets the CSD uploaded for a client",
+		Response: []models.CSDCertificate{}, Errors: itemErrors},
+	{ID: "deleteCSDCertificate", Method: http.MethodDelete, Path: "/clients/:id/csd/:csd_id", Tag: "clients", Summary: "Removes a CSD of a client with its key",
+		Response: models.MessageResponse{}, Errors: actionErrors},
+	{ID: "getExpiringCSD", Method: http.MethodGet, Path: "/clients/csd/expiring", Tag: "clients", Summary: "Lists the CSD of the active clients that expire in the next days or already expired",
+		Query: []query{
+			{Name: "days", Type: "integer", Description: "Days ahead to look for expirations, 30 by default and 365 at most"},
+		}, Response: []models.CSDExpiration{}, Errors: pageErrors},
+	{ID: "getClientContacts", Method: http.MethodGet, Path: "/clients/:id/contacts", Tag: "clients", Summary: "Gets the contacts of a client, the billing ones first",
+		Response: []models.ClientContact{}, Errors: itemErrors, ETag: clientVersion},
+	{ID: "createClientContact", Method: http.MethodPost, Path: "/clients/:id/contacts", Tag: "clients", Summary: "Adds a contact to a client",
+		Request: models.ClientContactRequest{}, Response: models.ClientContact{}, Status: http.StatusCreated, Errors: updateErrors},
+	{ID: "updateClientContact", Method: http.MethodPut, Path: "/clients/:id/contacts/:contact_id", Tag: "clients", Summary: "Replaces the data of a contact of a client",
+		Request: models.ClientContactRequest{}, Response: models.ClientContact{}, Errors: updateErrors, IfMatch: clientETag},
+	{ID: "deleteClientContact", Method: http.MethodDelete, Path: "/clients/:id/contacts/:contact_id", Tag: "clients", Summary: "Removes a contact of a client",
+		Response: models.MessageResponse{}, Errors: actionErrors},
+	{ID: "addClientNote", Method: http.MethodPost, Path: "/clients/:id/notes", Tag: "clients", Summary: "Adds a note of the logged in user to the timeline of a client",
+		Request: models.ClientNoteRequest{}, Response: models.ClientEvent{}, Status: http.StatusCreated, Errors: updateErrors},
+	{ID: "getClientTimeline", Method: http.MethodGet, Path: "/clients/:id/timeline", Tag: "clients", Summary: "Gets the notes of the users and the recorded payments, assignment, accountancy and status changes of a client, the newest first",
+		Query: []query{
+			{Name: "page", Type: "integer", Description: "Page number, starts at 1"},
+			{Name: "page_size", Type: "integer", Description: "Events per page, 50 by default and 200 at most"},
+			{Name: "type", Type: "string", Description: "Only the events of a type: note, payment, assignments, accountancy_status, activated or deactivated"},
+		}, Response: models.ClientEventPage{}, Errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusInternalServerError}},
+	{ID: "updateClientAssignments", Method: http.MethodPut, Path: "/clients/:id/assignments", Tag: "clients", Summary: "Updates the supervisor, responsible and emisor of a client",
+		Request: models.ClientAssignments{}, Response: models.MessageResponse{}, Errors: updateErrors, IfMatch: clientETag},
+	{ID: "getAssignmentHistory", Method: http.MethodGet, Path: "/clients/:id/assignments/history", Tag: "clients", Summary: "Gets the periods of the supervisor, responsible and emisor of a client, the newest first, valid_to is the day after a period ended",
+		Query: []query{
+			{Name: "as_of", Type: "string", Description: "Only the period in effect on a day, YYYY-MM-DD"},
+		}, Response: []models.AssignmentPeriod{}, Errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusInternalServerError}, ETag: clientVersion},
+	{ID: "getAssignmentsAsOf", Method: http.MethodGet, Path: "/clients/assignments", Tag: "clients", Summary: "Gets the supervisor, responsible and emisor every client had on a day",
+		Query: []query{
+			{Name: "as_of", Type: "string", Description: "Day of the assignments, YYYY-MM-DD", Required: true},
+			{Name: "supervisor_id", Type: "string", Description: "Only the clients of a supervisor"},
+			{Name: "responsible_id", Type: "string", Description: "Only the clients of a responsible"},
+		}, Response: []models.AssignmentPeriod{}, Errors: pageErrors},
+	{ID: "getClientsWithPendingPayments", Method: http.MethodGet, Path: "/clients/pending-payments", Tag: "clients", Summary: "Gets clients with pending payments",
+		Response: []models.ClientWithPendingPayment{}, Export: true, Errors: exportErrors},
+	{ID: "updateClientPayment", Method: http.MethodPut, Path: "/clients/:id/payment", Tag: "clients", Summary: "Registers a payment of a client",
+		Request: models.ClientPayment{}, Response: models.MessageResponse{}, Errors: updateErrors, IfMatch: clientETag},
+	{ID: "getClientPayments", Method: http.MethodGet, Path: "/clients/:id/payment", Tag: "clients", Summary: "Gets the payments history of a client",
+		Response: []models.ClientPaymentHistory{}, Errors: itemErrors, ETag: clientVersion},
+	{ID: "getClientFees", Method: http.MethodGet, Path: "/clients/:id/fees", Tag: "clients", Summary: "Gets the monthly fees of a client, the latest effective month first, the first fee has no effective month",
+		Response: []models.ClientFee{}, Errors: itemErrors, ETag: clientVersion},
+	{ID: "saveClientFee", Method: http.MethodPut, Path: "/clients/:id/fees", Tag: "clients", Summary: "Sets the monthly fee of a client from a month replacing the one of that month, a future month schedules the change",
+		Request: models.ClientFeeRequest{}, Response: models.ClientFee{}, Errors: updateErrors, IfMatch: clientETag},
+	{ID: "deleteClientFee", Method: http.MethodDelete, Path: "/clients/:id/fees/:fee_id", Tag: "clients", Summary: "Cancels a fee scheduled for a future month",
+		Response: models.MessageResponse{}, Errors: stateErrors},
+
+	// Menus
+	{ID: "getEmisors", Method: http.MethodGet, Path: "/menu/emisors", Tag: "menus", Summary: "Gets the active emisors",
+		Response: []models.Emisor{}, Errors: listErrors},
+	{ID: "getSupervisors", Method: http.MethodGet, Path: "/menu/supervisors", Tag: "menus", Summary: "Gets the active supervisors",
+		Response: []models.Supervisor{}, Errors: listErrors},
+	{ID: "getResponsiblesBySupervisor", Method: http.MethodGet, Path: "/menu/responsibles/:supervisor_id", Tag: "menus", Summary: "Gets the responsibles of a supervisor",
+		Response: []models.Responsible{}, Errors: listErrors},
+	{ID: "getRegimenes", Method: http.MethodGet, Path: "/menu/regimenes", Tag: "menus", Summary: "Gets the active regimenes",
+		Response: []models.Regimen{}, Errors: listErrors},
+	{ID: "getAccountancyTypes", Method: http.MethodGet, Path: "/menu/accountancy/types", Tag: "menus", Summary: "Gets the accountancy assignment types",
+		Response: []models.AccountancyType{}, Errors: listErrors},
+	{ID: "getAccountancyStatuses", Method: http.MethodGet, Path: "/menu/accountancy/status", Tag: "menus", Summary: "Gets the accountancy assignment statuses",
+		Response: []models.AccountancyAssignmentStatus{}, Errors: listErrors},
+
+	// Nominas
+	{ID: "createClientPaymentRecord", Method: http.MethodPost, Path: "/client/hrpayment", Tag: "nominas", Summary: "Creates a HR payment record of a client",
+		Request: models.ClientHRPayment{}, Response: models.MessageResponse{}, Status: http.StatusCreated, Errors: createErrors},
+	{ID: "getClientsWithPendingPaymentsByHREntityID", Method: http.MethodGet, Path: "/clients/hrpayment/:hr_entity_id", Tag: "nominas", Summary: "Gets the clients with pending payments of a HR entity",
+		Response: []models.ClientWithPendingHRPayment{}, Errors: listErrors},
+	{ID: "getClientPendingPaymentsByHREntityIDDetails", Method: http.MethodGet, Path: "/client/:client_id/hrpayments/:hr_entity_id", Tag: "nominas", Summary: "Gets the pending payments of a client and HR entity",
+		Response: []models.ClientWithPendingHRPaymentDetails{}, Errors: listErrors},
+	{ID: "updateClientPaymentRecord", Method: http.MethodPut, Path: "/client/hrpayment", Tag: "nominas", Summary: "Updates a HR payment record of a client",
+		Request: models.UpdateClientHRPayment{}, Response: models.MessageResponse{}, Errors: updateErrors},
+	{ID: "patchClientPaymentRecord", Method: http.MethodPatch, Path: "/client/hrpayment/:id", Tag: "nominas", Summary: "Updates only the fields of a HR payment record of a client sent in a JSON merge patch",
+		Request: models.ClientHRPaymentPatch{}, Response: models.MessageResponse{}, Errors: updateErrors},
+	{ID: "getClientHRPaymentsHistory", Method: http.MethodGet, Path: "/client/:client_id/hrpayments/:hr_entity_id/history", Tag: "nominas", Summary: "Gets the HR payments history of a client and HR entity",
+		Response: []models.ClientHRPayment{}, Errors: listErrors},
+
+	// Accountancy
+	{ID: "getClientsBySupervisor", Method: http.MethodGet, Path: "/accountancy/clients/supervisor/:supervisor_id", Tag: "accountancy", Summary: "Gets the onboarding and active clients of a supervisor",
+		Response: []models.AccountancyClientInfo{}, Export: true, Errors: exportErrors},
+	{ID: "getClientAssignmentsMatrix", Method: http.MethodGet, Path: "/accountancy/clients/assignments/:supervisor_id", Tag: "accountancy", Summary: "Gets the client and assignment type matrix",
+		Response: []models.ClientAssignmentMatrixRow{}, Errors: listErrors},
+	{ID: "updateClientAccountancyAssignments", Method: http.MethodPut, Path: "/accountancy/client/:client_id/assignments", Tag: "accountancy", Summary: "Selects or unselects the assignment types of a client",
+		Request: []models.AssignmentSelection{}, Response: models.MessageResponse{}, Errors: updateErrors, IfMatch: clientETag},
+	{ID: "getClientsByResponsible", Method: http.MethodGet, Path: "/accountancy/clients/responsible/:responsible_id", Tag: "accountancy", Summary: "Gets the onboarding and active clients of a responsible",
+		Response: []models.AccountancyClientInfo{}, Export: true, Errors: exportErrors},
+	{ID: "createClientAccountancyStatus", Method: http.MethodPost, Path: "/accountancy/clients/history/record", Tag: "accountancy", Summary: "Creates the monthly accountancy record of a client",
+		Request: models.ClientAccountancyHistoryEntry{}, Response: models.MessageResponse{}, Status: http.StatusCreated, Errors: createErrors},
+	{ID: "getClientAccountancyStatus", Method: http.MethodGet, Path: "/accountancy/client/:client_id/status/:status_id", Tag: "accountancy", Summary: "Gets a monthly accountancy record of a client",
+		Response: models.ClientAccountancyHistoryEntry{}, Errors: append([]int{http.StatusBadRequest}, itemErrors...), ETag: statusVersion},
+	{ID: "updateClientAccountancyStatus", Method: http.MethodPut, Path: "/accountancy/client/:client_id/status/:status_id", Tag: "accountancy", Summary: "Updates a monthly accountancy record of a client",
+		Request: models.ClientAccountancyHistoryEntry{}, Response: models.MessageResponse{}, Errors: append([]int{http.StatusForbidden}, updateErrors...), IfMatch: statusETag},
+	{ID: "getClientAccountancyHistory", Method: http.MethodGet, Path: "/accountancy/client/:client_id/history", Tag: "accountancy", Summary: "Gets the accountancy history of a client",
+		Response: models.ClientAccountancyHistoryWithAssignments{}, Errors: itemErrors, ETag: clientVersion},
+	{ID: "getAllAccountancyClients", Method: http.MethodGet, Path: "/accountancy/clients/all", Tag: "accountancy", Summary: "Gets a page of the onboarding and active clients",
+		Query: clientListQuery, Response: models.AccountancyClientInfoPage{}, Export: true, Errors: exportErrors},
+	{ID: "updateClientResponsible", Method: http.MethodPut, Path: "/accountancy/client/:client_id/responsible/:responsible_id", Tag: "accountancy", Summary: "Updates the responsible of a client",
+		Response: models.MessageResponse{}, Errors: actionErrors, IfMatch: clientETag},
+
+	// Notifications
+	{ID: "getNotifications", Method: http.MethodGet, Path: "/notifications", Tag: "notifications", Summary: "Gets the notifications of the logged in user, the newest first",
+		Query: []query{
+			{Name: "unread", Type: "boolean", Description: "Only the notifications that were not read"},
+		}, Response: []models.Notification{}, Errors: pageErrors},
+	{ID: "markNotificationRead", Method: http.MethodPut, Path: "/notifications/:id/read", Tag: "notifications", Summary: "Marks a notification of the logged in user as read",
+		Response: models.MessageResponse{}, Errors: actionErrors},
+
+	// Documents
+	{ID: "getDocuments", Method: http.MethodGet, Path: "/clients/:id/documents", Tag: "documents", Summary: "Gets the documents of a client with their current version, the last updated first",
+		Query: []query{
+			{Name: "category", Type: "string", Description: "csf, contract, acuse, identification or other"},
+		}, Response: []models.ClientDocument{}, Errors: []int{http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusInternalServerError}},
+	{ID: "uploadDocument", Method: http.MethodPost, Path: "/clients/:id/documents", Tag: "documents", Summary: "Uploads a PDF, JPEG, PNG, XML, DOCX or XLSX file of up to 20 MB as a new document of a client",
+		Upload: []string{"file"}, Form: documentForm(
+			query{Name: "category", Type: "string", Description: "csf, contract, acuse, identification or other", Required: true},
+			query{Name: "title", Type: "string", Description: "Title of the document, the file name by default"},
+		), Response: models.ClientDocument{}, Status: http.StatusCreated, Errors: updateErrors},
+	{ID: "getDocument", Method: http.MethodGet, Path: "/clients/:id/documents/:document_id", Tag: "documents", Summary: "Gets a document of a client with all its versions, the newest first",
+		Response: models.ClientDocument{}, Errors: itemErrors},
+	{ID: "addDocumentVersion", Method: http.MethodPost, Path: "/clients/:id/documents/:document_id/versions", Tag: "documents", Summary: "Uploads a new version of a document, it becomes the current one",
+		Upload: []string{"file"}, Form: documentForm(), Response: models.ClientDocument{}, Status: http.StatusCreated, Errors: updateErrors},
+	{ID: "downloadDocument", Method: http.MethodGet, Path: "/clients/:id/documents/:document_id/file", Tag: "documents", Summary: "Downloads the file of a version of a document, its SHA-256 is sent in the X-Checksum-Sha256 header",
+		Query: []query{
+			{Name: "version", Type: "integer", Description: "Version to download, the current one by default"},
+		}, File: true, Errors: []int{http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusInternalServerError}},
+	{ID: "deleteDocument", Method: http.MethodDelete, Path: "/clients/:id/documents/:document_id", Tag: "documents", Summary: "Removes a document with all its versions",
+		Response: models.MessageResponse{}, Errors: actionErrors},
+}
+
+// documentForm returns the form fields of a document upload, the checksum is
+// accepted by every upload
+func documentForm(fields ...query) []query {
+	return append(fields, query{Name: "checksum", Type: "string", Description: "SHA-256 of the file in hex, the upload is rejected when the received file differs"})
+}
@@ -0,0 +1,56 @@
+package openapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DocsPath is the route that serves the Swagger UI, it is not part of the API
+const DocsPath = "/docs/*any"
+
+// Verify checks that every registered route is documented and that the
+// document does not describe routes that are no longer registered
+func Verify(routes gin.RoutesInfo) error {
+	documented := map[string]bool{}
+	for _, op := range operations {
+		documented[op.Method+" "+op.Path] = true
+	}
+
+	var missing []string
+	for _, r := range routes {
+		if r.Path == DocsPath {
+			continue
+		}
+
+		key := r.Method + " " + r.Path
+		if !documented[key] {
+			missing = append(missing, key)
+		}
+		delete(documented, key)
+	}
+
+	var stale []string
+	for key := range documented {
+		stale = append(stale, key)
+	}
+
+	if len(missing) == 0 && len(stale) == 0 {
+		return nil
+	}
+
+	sort.Strings(missing)
+	sort.Strings(stale)
+
+	var problems []string
+	if len(missing) > 0 {
+		problems = append(problems, fmt.Sprintf("routes missing from the OpenAPI document: %s", strings.Join(missing, ", ")))
+	}
+	if len(stale) > 0 {
+		problems = append(problems, fmt.Sprintf("documented routes not registered: %s", strings.Join(stale, ", ")))
+	}
+
+	return fmt.Errorf("%s", strings.Join(problems, "; "))
+}
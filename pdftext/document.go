@@ -0,0 +1,244 @@
+package pdftext
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+)
+
+// stream is a stream object, its data is decoded when read
+type stream struct {
+	dict dict
+	raw  []byte
+}
+
+// maxDecoded limits the bytes the streams of a file decode to, counting the
+// output of every filter, so a small compressed file can not fill the memory
+const maxDecoded = 64 << 20
+
+// document keeps the objects of a PDF file by their number
+type document struct {
+	objects map[int]any
+	// decoded are the bytes decoded from the streams so far
+	decoded int
+	// err is ErrTooLarge once the streams decoded more than maxDecoded bytes
+	err error
+}
+
+var objectHeader = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+
+// open reads the objects of a PDF file. The cross reference table is not
+// used, the file is scanned for its objects so damaged or truncated files
+// still give the text of their pages, and a later definition of an object
+// replaces the previous one like an incremental update does.
+func open(data []byte) (*document, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, " \r\n\t"), []byte("%PDF-")) {
+		return nil, ErrNotPDF
+	}
+
+	doc := &document{objects: map[int]any{}}
+	var objStreams []stream
+	for pos := 0; pos < len(data); {
+		loc := objectHeader.FindSubmatchIndex(data[pos:])
+		if loc == nil {
+			break
+		}
+		num, _ := strconv.Atoi(string(data[pos+loc[2] : pos+loc[3]]))
+		l := &lexer{data: data, pos: pos + loc[1]}
+		pos += loc[1]
+
+		obj, err := l.object()
+		if err != nil {
+			continue
+		}
+		if d, ok := obj.(dict); ok {
+			if next, err := l.token(); err == nil && next == keyword("stream") {
+				s := doc.readStream(d, data, l)
+				pos = l.pos
+				obj = s
+				if d["Type"] == name("ObjStm") {
+					objStreams = append(objStreams, s)
+				}
+			}
+		}
+		doc.objects[num] = obj
+	}
+
+	// the objects compressed in object streams only fill the numbers that were
+	// not defined outside of them
+	for _, s := range objStreams {
+		doc.readObjectStream(s)
+	}
+
+	if len(doc.objects) == 0 {
+		return nil, ErrNotPDF
+	}
+
+	return doc, nil
+}
+
+// readStream reads the data of a stream that starts after the stream keyword,
+// its length is searched when the Length entry does not match the file
+func (doc *document) readStream(d dict, data []byte, l *lexer) stream {
+	start := l.pos
+	if start < len(data) && data[start] == '\r' {
+		start++
+	}
+	if start < len(data) && data[start] == '\n' {
+		start++
+	}
+
+	if n, ok := doc.resolve(d["Length"]).(float64); ok {
+		end := start + int(n)
+		if end <= len(data) && end >= start {
+			rest := bytes.TrimLeft(data[end:], " \r\n\t")
+			if bytes.HasPrefix(rest, []byte("endstream")) {
+				l.pos = len(data) - len(rest) + len("endstream")
+				return stream{dict: d, raw: data[start:end]}
+			}
+		}
+	}
+
+	end := bytes.Index(data[start:], []byte("endstream"))
+	if end < 0 {
+		l.pos = len(data)
+		return stream{dict: d, raw: data[start:]}
+	}
+	l.pos = start + end + len("endstream")
+
+	return stream{dict: d, raw: bytes.TrimRight(data[start:start+end], "\r\n")}
+}
+
+// readObjectStream adds the objects compressed in an object stream
+func (doc *document) readObjectStream(s stream) {
+	data, err := doc.decode(s)
+	if err != nil {
+		return
+	}
+	n, _ := doc.resolve(s.dict["N"]).(float64)
+	first, _ := doc.resolve(s.dict["First"]).(float64)
+	if int(first) > len(data) {
+		return
+	}
+
+	header := &lexer{data: data[:int(first)]}
+	for i := 0; i < int(n); i++ {
+		num, err1 := header.token()
+		offset, err2 := header.token()
+		if err1 != nil || err2 != nil {
+			return
+		}
+		number, ok1 := num.(float64)
+		at, ok2 := offset.(float64)
+		if !ok1 || !ok2 || int(first)+int(at) > len(data) {
+			return
+		}
+		if _, ok := doc.objects[int(number)]; ok {
+			continue
+		}
+
+		l := &lexer{data: data, pos: int(first) + int(at)}
+		if obj, err := l.object(); err == nil {
+			doc.objects[int(number)] = obj
+		}
+	}
+}
+
+// resolve follows the references, a missing object is nil
+func (doc *document) resolve(obj any) any {
+	for i := 0; i < 32; i++ {
+		r, ok := obj.(ref)
+		if !ok {
+			return obj
+		}
+		obj = doc.objects[r.num]
+	}
+
+	return nil
+}
+
+// dict resolves an object that should be a dictionary, a stream gives its dictionary
+func (doc *document) dict(obj any) dict {
+	switch v := doc.resolve(obj).(type) {
+	case dict:
+		return v
+	case stream:
+		return v.dict
+	}
+
+	return nil
+}
+
+// array resolves an object that should be an array
+func (doc *document) array(obj any) []any {
+	a, _ := doc.resolve(obj).([]any)
+	return a
+}
+
+// decode applies the filters of a stream to its data, it fails with
+// ErrTooLarge once the streams of the document decoded more than maxDecoded
+func (doc *document) decode(s stream) ([]byte, error) {
+	if doc.err != nil {
+		return nil, doc.err
+	}
+
+	var filters []any
+	switch f := doc.resolve(s.dict["Filter"]).(type) {
+	case name:
+		filters = []any{f}
+	case []any:
+		filters = f
+	}
+
+	data := s.raw
+	for _, f := range filters {
+		var err error
+		switch doc.resolve(f) {
+		case name("FlateDecode"), name("Fl"):
+			data, err = inflate(data, maxDecoded-doc.decoded)
+		case name("ASCIIHexDecode"), name("AHx"):
+			data, err = (&lexer{data: append(append([]byte("<"), data...), '>')}).hexString()
+		default:
+			return nil, fmt.Errorf("unsupported filter %v", f)
+		}
+		if err == nil && len(data) > maxDecoded-doc.decoded {
+			err = ErrTooLarge
+		}
+		if errors.Is(err, ErrTooLarge) {
+			doc.err = err
+		}
+		if err != nil {
+			return nil, err
+		}
+		doc.decoded += len(data)
+	}
+
+	return data, nil
+}
+
+// inflate decompresses a zlib stream of up to limit bytes, the data read
+// before a corruption is kept
+func inflate(data []byte, limit int) ([]byte, error) {
+	var r io.ReadCloser
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		// some writers leave out the zlib header
+		r = flate.NewReader(bytes.NewReader(data))
+	}
+	defer r.Close()
+
+	out, err := io.ReadAll(io.LimitReader(r, int64(limit)+1))
+	if len(out) > limit {
+		return nil, ErrTooLarge
+	}
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && len(out) == 0 {
+		return nil, err
+	}
+
+	return out, nil
+}
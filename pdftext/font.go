@@ -0,0 +1,357 @@
+package pdftext
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// font turns the bytes of the strings shown with a font into text
+type font struct {
+	// codeLengths are the byte lengths of the codes, from the codespace ranges
+	// of the ToUnicode map, the simple fonts use one byte codes
+	codeLengths []int
+	unicode     map[string]string
+	// simple maps the one byte codes of the fonts without ToUnicode map
+	simple *[256]rune
+	// cid fonts without a ToUnicode map can not be read
+	cid bool
+	// widths are the advances of the codes in thousandths of the font size
+	widths       map[uint32]float64
+	defaultWidth float64
+}
+
+// glyph is a character code of a shown string
+type glyph struct {
+	text  string
+	width float64
+	// space is the one byte code 32 that gets the word spacing
+	space bool
+}
+
+// loadFont reads how to decode the text of a font dictionary
+func (doc *document) loadFont(obj any) *font {
+	d := doc.dict(obj)
+	f := &font{codeLengths: []int{1}, widths: map[uint32]float64{}, defaultWidth: 500}
+	if d == nil {
+		f.simple = encodingTable(nil)
+		return f
+	}
+	doc.loadWidths(f, d)
+
+	if s, ok := doc.resolve(d["ToUnicode"]).(stream); ok {
+		if data, err := doc.decode(s); err == nil {
+			f.parseCMap(data)
+		}
+	}
+	if f.unicode != nil {
+		return f
+	}
+
+	if d["Subtype"] == name("Type0") {
+		f.cid = true
+		return f
+	}
+
+	f.simple = encodingTable(nil)
+	switch e := doc.resolve(d["Encoding"]).(type) {
+	case name:
+		f.simple = encodingTable(e)
+	case dict:
+		f.simple = encodingTable(doc.resolve(e["BaseEncoding"]))
+		code := 0
+		for _, item := range doc.array(e["Differences"]) {
+			switch v := doc.resolve(item).(type) {
+			case float64:
+				code = int(v)
+			case name:
+				if r, ok := glyphRune(string(v)); ok && code >= 0 && code < 256 {
+					f.simple[code] = r
+				}
+				code++
+			}
+		}
+	}
+
+	return f
+}
+
+// encodingTable returns a copy of the table of a base encoding, the fonts
+// without encoding are read as WinAnsi which covers the standard one for text
+func encodingTable(encoding any) *[256]rune {
+	cm := charmap.Windows1252
+	if encoding == name("MacRomanEncoding") {
+		cm = charmap.Macintosh
+	}
+
+	var table [256]rune
+	for i := range table {
+		table[i] = cm.DecodeByte(byte(i))
+	}
+
+	return &table
+}
+
+// glyphNames are the names of the glyphs of the Spanish texts that are not a
+// single letter or an uniXXXX name
+var glyphNames = map[string]rune{
+	"space": ' ', "exclam": '!', "quotedbl": '"', "numbersign": '#', "dollar": '$', "percent": '%',
+	"ampersand": '&', "quotesingle": '\'', "parenleft": '(', "parenright": ')', "asterisk": '*',
+	"plus": '+', "comma": ',', "hyphen": '-', "period": '.', "slash": '/', "colon": ':',
+	"semicolon": ';', "less": '<', "equal": '=', "greater": '>', "question": '?', "at": '@',
+	"bracketleft": '[', "backslash": '\\', "bracketright": ']', "underscore": '_', "quoteright": '’',
+	"zero": '0', "one": '1', "two": '2', "three": '3', "four": '4', "five": '5', "six": '6',
+	"seven": '7', "eight": '8', "nine": '9', "exclamdown": '¡', "questiondown": '¿', "degree": '°',
+	"ordfeminine": 'ª', "ordmasculine": 'º', "endash": '–', "emdash": '—', "bullet": '•',
+	"aacute": 'á', "eacute": 'é', "iacute": 'í', "oacute": 'ó', "uacute": 'ú', "ntilde": 'ñ',
+	"udieresis": 'ü', "Aacute": 'Á', "Eacute": 'É', "Iacute": 'Í', "Oacute": 'Ó', "Uacute": 'Ú',
+	"Ntilde": 'Ñ', "Udieresis": 'Ü', "quotedblleft": '“', "quotedblright": '”', "quoteleft": '‘',
+}
+
+// glyphRune returns the character of a glyph name
+func glyphRune(glyph string) (rune, bool) {
+	if r, ok := glyphNames[glyph]; ok {
+		return r, true
+	}
+	if len(glyph) == 1 {
+		return rune(glyph[0]), true
+	}
+	if hex, ok := strings.CutPrefix(glyph, "uni"); ok && len(hex) == 4 {
+		if v, err := strconv.ParseUint(hex, 16, 16); err == nil {
+			return rune(v), true
+		}
+	}
+
+	return 0, false
+}
+
+// parseCMap reads the codespace ranges and the bfchar and bfrange mappings of
+// a ToUnicode map
+func (f *font) parseCMap(data []byte) {
+	l := &lexer{data: data}
+	var operands []any
+	lengths := map[int]bool{}
+	unicode := map[string]string{}
+
+	for {
+		tok, err := l.object()
+		if err != nil {
+			break
+		}
+		op, ok := tok.(keyword)
+		if !ok {
+			operands = append(operands, tok)
+			continue
+		}
+
+		switch op {
+		case "endcodespacerange":
+			for i := 0; i+1 < len(operands); i += 2 {
+				if lo, ok := operands[i].([]byte); ok && len(lo) > 0 {
+					lengths[len(lo)] = true
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok1 := operands[i].([]byte)
+				dst, ok2 := operands[i+1].([]byte)
+				if ok1 && ok2 {
+					unicode[string(src)] = utf16String(dst)
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				lo, ok1 := operands[i].([]byte)
+				hi, ok2 := operands[i+1].([]byte)
+				if !ok1 || !ok2 || len(lo) != len(hi) || len(lo) == 0 || len(lo) > 4 {
+					continue
+				}
+				start, end := codeValue(lo), codeValue(hi)
+				if end < start || end-start > 0xFFFF {
+					continue
+				}
+				switch dst := operands[i+2].(type) {
+				case []byte:
+					for code := start; code <= end; code++ {
+						unicode[string(codeBytes(code, len(lo)))] = utf16String(addToLast(dst, code-start))
+					}
+				case []any:
+					for j, item := range dst {
+						if b, ok := item.([]byte); ok && start+uint32(j) <= end {
+							unicode[string(codeBytes(start+uint32(j), len(lo)))] = utf16String(b)
+						}
+					}
+				}
+			}
+		}
+
+		// the operands of the other operators, like begincodespacerange, are
+		// counts or names that are not needed
+		operands = nil
+	}
+
+	if len(unicode) == 0 {
+		return
+	}
+	f.unicode = unicode
+	f.codeLengths = nil
+	for n := 1; n <= 4; n++ {
+		if lengths[n] {
+			f.codeLengths = append(f.codeLengths, n)
+		}
+	}
+	if len(f.codeLengths) == 0 {
+		for src := range unicode {
+			f.codeLengths = []int{len(src)}
+			break
+		}
+	}
+}
+
+// codeValue reads a big endian character code
+func codeValue(b []byte) uint32 {
+	var v uint32
+	for _, c := range b {
+		v = v<<8 | uint32(c)
+	}
+
+	return v
+}
+
+// codeBytes writes a character code with the given number of bytes
+func codeBytes(v uint32, n int) []byte {
+	b := make([]byte, n)
+	for i := n - 1; i >= 0; i-- {
+		b[i] = byte(v)
+		v >>= 8
+	}
+
+	return b
+}
+
+// addToLast adds an offset to the last UTF-16 unit of a bfrange destination
+func addToLast(dst []byte, offset uint32) []byte {
+	out := append([]byte(nil), dst...)
+	if len(out) >= 2 {
+		v := uint32(out[len(out)-2])<<8 | uint32(out[len(out)-1])
+		v += offset
+		out[len(out)-2], out[len(out)-1] = byte(v>>8), byte(v)
+	}
+
+	return out
+}
+
+// utf16String decodes the big endian UTF-16 text of a ToUnicode map
+func utf16String(b []byte) string {
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+
+	return string(utf16.Decode(units))
+}
+
+// loadWidths reads the glyph widths of a simple font or of the descendant
+// font of a composite one, the standard fonts without widths use an average
+func (doc *document) loadWidths(f *font, d dict) {
+	if d["Subtype"] != name("Type0") {
+		first, _ := doc.resolve(d["FirstChar"]).(float64)
+		for i, w := range doc.array(d["Widths"]) {
+			if v, ok := doc.resolve(w).(float64); ok {
+				f.widths[uint32(int(first)+i)] = v
+			}
+		}
+		return
+	}
+
+	descendants := doc.array(d["DescendantFonts"])
+	if len(descendants) == 0 {
+		return
+	}
+	cid := doc.dict(descendants[0])
+	f.defaultWidth = 1000
+	if dw, ok := doc.resolve(cid["DW"]).(float64); ok {
+		f.defaultWidth = dw
+	}
+
+	// the W array has "c [w1 w2 ...]" and "cfirst clast w" entries
+	w := doc.array(cid["W"])
+	for i := 0; i+1 < len(w); {
+		start, ok := doc.resolve(w[i]).(float64)
+		if !ok {
+			return
+		}
+		if list, ok := doc.resolve(w[i+1]).([]any); ok {
+			for j, item := range list {
+				if v, ok := doc.resolve(item).(float64); ok {
+					f.widths[uint32(int(start)+j)] = v
+				}
+			}
+			i += 2
+			continue
+		}
+		if i+2 >= len(w) {
+			return
+		}
+		end, ok1 := doc.resolve(w[i+1]).(float64)
+		v, ok2 := doc.resolve(w[i+2]).(float64)
+		if ok1 && ok2 && end >= start && end-start <= 0xFFFF {
+			for c := start; c <= end; c++ {
+				f.widths[uint32(c)] = v
+			}
+		}
+		i += 3
+	}
+}
+
+// width returns the advance of a code in thousandths of the font size
+func (f *font) width(code []byte) float64 {
+	if w, ok := f.widths[codeValue(code)]; ok {
+		return w
+	}
+
+	return f.defaultWidth
+}
+
+// glyphs splits the bytes of a shown string in its codes
+func (f *font) glyphs(s []byte) []glyph {
+	var glyphs []glyph
+	if f.simple != nil {
+		for i, c := range s {
+			g := glyph{width: f.width(s[i : i+1]), space: c == ' '}
+			if r := f.simple[c]; r != 0 && r != '\uFFFD' {
+				g.text = string(r)
+			}
+			glyphs = append(glyphs, g)
+		}
+		return glyphs
+	}
+
+	lengths := f.codeLengths
+	if f.cid {
+		lengths = []int{2}
+	}
+	for i := 0; i < len(s); {
+		n := lengths[0]
+		for _, l := range lengths {
+			if i+l > len(s) {
+				break
+			}
+			if _, ok := f.unicode[string(s[i:i+l])]; ok {
+				n = l
+				break
+			}
+		}
+		if i+n > len(s) {
+			n = len(s) - i
+		}
+
+		code := s[i : i+n]
+		glyphs = append(glyphs, glyph{text: f.unicode[string(code)], width: f.width(code), space: n == 1 && code[0] == ' '})
+		i += n
+	}
+
+	return glyphs
+}
@@ -0,0 +1,258 @@
+package pdftext
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+)
+
+// The objects of a PDF file are read into these types, numbers are float64
+// and the other types are kept as Go values
+type (
+	name    string
+	keyword string
+	dict    map[name]any
+	ref     struct{ num, gen int }
+)
+
+var errSyntax = errors.New("pdf syntax error")
+
+// lexer reads the tokens and objects of a PDF file or content stream
+type lexer struct {
+	data []byte
+	pos  int
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == '\f' || c == 0
+}
+
+func isDelimiter(c byte) bool {
+	return bytes.IndexByte([]byte("()<>[]{}/%"), c) >= 0
+}
+
+// skipSpace skips the whitespace and the comments
+func (l *lexer) skipSpace() {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		switch {
+		case isSpace(c):
+			l.pos++
+		case c == '%':
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// token reads the next token, the arrays and dictionaries are returned as
+// their delimiters
+func (l *lexer) token() (any, error) {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return nil, errSyntax
+	}
+
+	c := l.data[l.pos]
+	switch {
+	case c == '(':
+		return l.literalString()
+	case c == '<' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '<':
+		l.pos += 2
+		return keyword("<<"), nil
+	case c == '>' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '>':
+		l.pos += 2
+		return keyword(">>"), nil
+	case c == '<':
+		return l.hexString()
+	case c == '[' || c == ']' || c == '{' || c == '}':
+		l.pos++
+		return keyword(c), nil
+	case c == '/':
+		l.pos++
+		return name(l.regular()), nil
+	}
+
+	word := l.regular()
+	if word == "" {
+		// a stray delimiter, like a lone ) or >
+		l.pos++
+		return keyword(c), nil
+	}
+	if n, err := strconv.ParseFloat(word, 64); err == nil && (word[0] == '-' || word[0] == '+' || word[0] == '.' || (word[0] >= '0' && word[0] <= '9')) {
+		return n, nil
+	}
+
+	return keyword(word), nil
+}
+
+// regular reads a run of regular characters, decoding the #xx escapes of names
+func (l *lexer) regular() string {
+	var b []byte
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if isSpace(c) || isDelimiter(c) {
+			break
+		}
+		if c == '#' && l.pos+2 < len(l.data) {
+			if v, err := strconv.ParseUint(string(l.data[l.pos+1:l.pos+3]), 16, 8); err == nil {
+				b = append(b, byte(v))
+				l.pos += 3
+				continue
+			}
+		}
+		b = append(b, c)
+		l.pos++
+	}
+
+	return string(b)
+}
+
+// literalString reads a (string) with its escapes and balanced parentheses
+func (l *lexer) literalString() ([]byte, error) {
+	l.pos++
+	var b []byte
+	depth := 1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return b, nil
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				return b, nil
+			}
+			e := l.data[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						v = v*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					c = byte(v)
+				} else {
+					c = e
+				}
+			}
+		}
+		b = append(b, c)
+	}
+
+	return b, nil
+}
+
+// hexString reads a <hex string>, an odd last digit is followed by a 0
+func (l *lexer) hexString() ([]byte, error) {
+	l.pos++
+	var digits []byte
+	for l.pos < len(l.data) && l.data[l.pos] != '>' {
+		c := l.data[l.pos]
+		l.pos++
+		if !isSpace(c) {
+			digits = append(digits, c)
+		}
+	}
+	l.pos++
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+
+	b := make([]byte, len(digits)/2)
+	for i := range b {
+		v, err := strconv.ParseUint(string(digits[2*i:2*i+2]), 16, 8)
+		if err != nil {
+			return nil, errSyntax
+		}
+		b[i] = byte(v)
+	}
+
+	return b, nil
+}
+
+// object reads a complete object, resolving the arrays, the dictionaries and
+// the indirect references "num gen R"
+func (l *lexer) object() (any, error) {
+	tok, err := l.token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok {
+	case keyword("<<"):
+		d := dict{}
+		for {
+			key, err := l.object()
+			if err != nil {
+				return nil, err
+			}
+			if key == keyword(">>") {
+				return d, nil
+			}
+			k, ok := key.(name)
+			if !ok {
+				return nil, errSyntax
+			}
+			value, err := l.object()
+			if err != nil {
+				return nil, err
+			}
+			d[k] = value
+		}
+	case keyword("["):
+		var a []any
+		for {
+			item, err := l.object()
+			if err != nil {
+				return nil, err
+			}
+			if item == keyword("]") {
+				return a, nil
+			}
+			a = append(a, item)
+		}
+	}
+
+	// a non negative integer may start a reference
+	if n, ok := tok.(float64); ok && n >= 0 && n == float64(int(n)) {
+		save := l.pos
+		if gen, err := l.token(); err == nil {
+			if g, ok := gen.(float64); ok && g >= 0 && g == float64(int(g)) {
+				if r, err := l.token(); err == nil && r == keyword("R") {
+					return ref{int(n), int(g)}, nil
+				}
+			}
+		}
+		l.pos = save
+	}
+
+	return tok, nil
+}
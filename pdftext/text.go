@@ -0,0 +1,434 @@
+// Package pdftext extracts the text layer of PDF files, like the constancias
+// the SAT issues. It reads the pages in order and rebuilds their lines from
+// the position of the text, the layout and the images are ignored.
+package pdftext
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrNotPDF is returned when the data is not a PDF file
+	ErrNotPDF = errors.New("the file is not a PDF")
+	// ErrEncrypted is returned for the encrypted PDF files, their text can not be read
+	ErrEncrypted = errors.New("the PDF file is encrypted")
+	// ErrTooLarge is returned when the streams of a file decompress to more
+	// than 64 MB
+	ErrTooLarge = errors.New("the streams of the PDF file are too large")
+)
+
+// maxDepth limits the nesting of the page tree and of the form XObjects
+const maxDepth = 32
+
+// matrix is an affine transformation [a b c d e f]
+type matrix [6]float64
+
+var identity = matrix{1, 0, 0, 1, 0, 0}
+
+// mul returns the transformation m followed by n
+func (m matrix) mul(n matrix) matrix {
+	return matrix{
+		m[0]*n[0] + m[1]*n[2],
+		m[0]*n[1] + m[1]*n[3],
+		m[2]*n[0] + m[3]*n[2],
+		m[2]*n[1] + m[3]*n[3],
+		m[4]*n[0] + m[5]*n[2] + n[4],
+		m[4]*n[1] + m[5]*n[3] + n[5],
+	}
+}
+
+// run is a string shown on a page, with its start and end on the page
+type run struct {
+	x, y, end, size float64
+	text            string
+}
+
+// Extract returns the text of a PDF file, a line per line of text of its
+// pages and an empty line between the pages
+func Extract(data []byte) (string, error) {
+	doc, err := open(data)
+	if err != nil {
+		return "", err
+	}
+	if doc.err != nil {
+		return "", doc.err
+	}
+	for _, obj := range doc.objects {
+		if d := doc.dict(obj); d != nil && d["Filter"] == name("Standard") && d["O"] != nil {
+			return "", ErrEncrypted
+		}
+	}
+
+	var pages []string
+	for _, page := range doc.pages() {
+		p := &painter{doc: doc, fonts: map[any]*font{}}
+		for _, content := range doc.contents(page["Contents"]) {
+			p.paint(content, doc.dict(page["Resources"]), identity, 0)
+		}
+		pages = append(pages, layout(p.runs))
+	}
+	if doc.err != nil {
+		return "", doc.err
+	}
+
+	return strings.Join(pages, "\n\n"), nil
+}
+
+// pages returns the pages of the document in order with their inherited
+// resources, the page tree is walked from the catalog when there is one
+func (doc *document) pages() []dict {
+	var root any
+	var numbers []int
+	for num, obj := range doc.objects {
+		if d := doc.dict(obj); d != nil && d["Type"] == name("Catalog") {
+			root = d["Pages"]
+		}
+		numbers = append(numbers, num)
+	}
+
+	var pages []dict
+	var walk func(node any, resources any, depth int)
+	walk = func(node any, resources any, depth int) {
+		d := doc.dict(node)
+		if d == nil || depth > maxDepth {
+			return
+		}
+		if r, ok := d["Resources"]; ok {
+			resources = r
+		}
+		if d["Type"] == name("Page") || (d["Kids"] == nil && d["Contents"] != nil) {
+			page := dict{}
+			for k, v := range d {
+				page[k] = v
+			}
+			page["Resources"] = resources
+			pages = append(pages, page)
+			return
+		}
+		for _, kid := range doc.array(d["Kids"]) {
+			walk(kid, resources, depth+1)
+		}
+	}
+	walk(root, nil, 0)
+	if len(pages) > 0 {
+		return pages
+	}
+
+	// without a catalog the pages are taken in the order of their numbers
+	sort.Ints(numbers)
+	for _, num := range numbers {
+		if d := doc.dict(doc.objects[num]); d != nil && d["Type"] == name("Page") {
+			walk(d, doc.resolve(doc.dict(d["Parent"])["Resources"]), 0)
+		}
+	}
+
+	return pages
+}
+
+// contents returns the decoded content streams of a page
+func (doc *document) contents(obj any) [][]byte {
+	var streams []any
+	switch v := doc.resolve(obj).(type) {
+	case stream:
+		streams = []any{v}
+	case []any:
+		streams = v
+	}
+
+	var contents [][]byte
+	for _, s := range streams {
+		if st, ok := doc.resolve(s).(stream); ok {
+			if data, err := doc.decode(st); err == nil {
+				contents = append(contents, data)
+			}
+		}
+	}
+
+	return contents
+}
+
+// textState are the text parameters of the graphics state
+type textState struct {
+	ctm                        matrix
+	font                       *font
+	size, charSpace, wordSpace float64
+	scale, leading, rise       float64
+}
+
+// painter interprets the content streams of a page and collects the shown strings
+type painter struct {
+	doc   *document
+	fonts map[any]*font
+	runs  []run
+}
+
+// paint interprets a content stream with its resources
+func (p *painter) paint(content []byte, resources dict, ctm matrix, depth int) {
+	if depth > maxDepth {
+		return
+	}
+
+	state := textState{ctm: ctm, scale: 1}
+	var stack []textState
+	var tm, tlm matrix
+	var operands []any
+	l := &lexer{data: content}
+
+	for {
+		tok, err := l.object()
+		if err != nil {
+			return
+		}
+		op, ok := tok.(keyword)
+		if !ok {
+			operands = append(operands, tok)
+			continue
+		}
+
+		nums := numbers(operands)
+		switch op {
+		case "q":
+			stack = append(stack, state)
+		case "Q":
+			if len(stack) > 0 {
+				state = stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+			}
+		case "cm":
+			if len(nums) == 6 {
+				state.ctm = matrix(nums).mul(state.ctm)
+			}
+		case "BT":
+			tm, tlm = identity, identity
+		case "Tf":
+			if len(operands) == 2 {
+				key, _ := operands[0].(name)
+				state.font = p.font(resources, key)
+				state.size, _ = operands[1].(float64)
+			}
+		case "Tc":
+			if len(nums) == 1 {
+				state.charSpace = nums[0]
+			}
+		case "Tw":
+			if len(nums) == 1 {
+				state.wordSpace = nums[0]
+			}
+		case "Tz":
+			if len(nums) == 1 {
+				state.scale = nums[0] / 100
+			}
+		case "TL":
+			if len(nums) == 1 {
+				state.leading = nums[0]
+			}
+		case "Ts":
+			if len(nums) == 1 {
+				state.rise = nums[0]
+			}
+		case "Td", "TD":
+			if len(nums) == 2 {
+				if op == "TD" {
+					state.leading = -nums[1]
+				}
+				tlm = matrix{1, 0, 0, 1, nums[0], nums[1]}.mul(tlm)
+				tm = tlm
+			}
+		case "Tm":
+			if len(nums) == 6 {
+				tlm = matrix(nums)
+				tm = tlm
+			}
+		case "T*":
+			tlm = matrix{1, 0, 0, 1, 0, -state.leading}.mul(tlm)
+			tm = tlm
+		case "Tj", "'", "\"":
+			if op != "Tj" {
+				if op == "\"" && len(nums) >= 2 {
+					state.wordSpace, state.charSpace = nums[0], nums[1]
+				}
+				tlm = matrix{1, 0, 0, 1, 0, -state.leading}.mul(tlm)
+				tm = tlm
+			}
+			if len(operands) > 0 {
+				if s, ok := operands[len(operands)-1].([]byte); ok {
+					tm = p.show(s, state, tm)
+				}
+			}
+		case "TJ":
+			if len(operands) == 1 {
+				items, _ := operands[0].([]any)
+				for _, item := range items {
+					switch v := item.(type) {
+					case []byte:
+						tm = p.show(v, state, tm)
+					case float64:
+						tm = matrix{1, 0, 0, 1, -v / 1000 * state.size * state.scale, 0}.mul(tm)
+					}
+				}
+			}
+		case "Do":
+			if len(operands) == 1 {
+				key, _ := operands[0].(name)
+				p.form(resources, key, state.ctm, depth)
+			}
+		case "BI":
+			skipInlineImage(l)
+		}
+		operands = nil
+	}
+}
+
+// numbers returns the operands when all of them are numbers
+func numbers(operands []any) []float64 {
+	nums := make([]float64, 0, len(operands))
+	for _, o := range operands {
+		n, ok := o.(float64)
+		if !ok {
+			return nil
+		}
+		nums = append(nums, n)
+	}
+
+	return nums
+}
+
+// font returns the decoder of a font of the resources, loaded once per page
+func (p *painter) font(resources dict, key name) *font {
+	obj := p.doc.dict(resources["Font"])[key]
+	cacheKey := any(obj)
+	if _, ok := obj.(ref); !ok {
+		cacheKey = key
+	}
+	if f, ok := p.fonts[cacheKey]; ok {
+		return f
+	}
+
+	f := p.doc.loadFont(obj)
+	p.fonts[cacheKey] = f
+	return f
+}
+
+// form paints a form XObject of the resources, the images are ignored
+func (p *painter) form(resources dict, key name, ctm matrix, depth int) {
+	s, ok := p.doc.resolve(p.doc.dict(resources["XObject"])[key]).(stream)
+	if !ok || s.dict["Subtype"] != name("Form") {
+		return
+	}
+	data, err := p.doc.decode(s)
+	if err != nil {
+		return
+	}
+
+	if m := numbers(p.doc.array(s.dict["Matrix"])); len(m) == 6 {
+		ctm = matrix(m).mul(ctm)
+	}
+	formResources := p.doc.dict(s.dict["Resources"])
+	if formResources == nil {
+		formResources = resources
+	}
+
+	p.paint(data, formResources, ctm, depth+1)
+}
+
+// show adds a shown string to the runs and returns the text matrix moved
+// past it
+func (p *painter) show(s []byte, state textState, tm matrix) matrix {
+	if state.font == nil {
+		state.font = p.doc.loadFont(nil)
+	}
+
+	start := matrix{1, 0, 0, 1, 0, state.rise}.mul(tm).mul(state.ctm)
+	var text strings.Builder
+	for _, g := range state.font.glyphs(s) {
+		advance := g.width/1000*state.size + state.charSpace
+		if g.space {
+			advance += state.wordSpace
+		}
+		text.WriteString(g.text)
+		tm = matrix{1, 0, 0, 1, advance * state.scale, 0}.mul(tm)
+	}
+	end := matrix{1, 0, 0, 1, 0, state.rise}.mul(tm).mul(state.ctm)
+
+	// the size on the page is the size of the font scaled by the matrices
+	scaled := matrix{0, 0, 0, state.size, 0, 0}.mul(start)
+	size := math.Hypot(scaled[2], scaled[3])
+	if text.Len() > 0 {
+		p.runs = append(p.runs, run{x: start[4], y: start[5], end: end[4], size: size, text: text.String()})
+	}
+
+	return tm
+}
+
+// skipInlineImage moves the lexer past the data of an inline image, which
+// ends with the EI operator after a whitespace
+func skipInlineImage(l *lexer) {
+	id := bytes.Index(l.data[l.pos:], []byte("ID"))
+	if id < 0 {
+		l.pos = len(l.data)
+		return
+	}
+	pos := l.pos + id + 3
+	for pos < len(l.data) {
+		ei := bytes.Index(l.data[pos:], []byte("EI"))
+		if ei < 0 {
+			break
+		}
+		at := pos + ei
+		if isSpace(l.data[at-1]) && (at+2 == len(l.data) || isSpace(l.data[at+2])) {
+			l.pos = at + 2
+			return
+		}
+		pos = at + 2
+	}
+	l.pos = len(l.data)
+}
+
+// layout groups the runs of a page in lines from the top, the runs of a line
+// are sorted from the left and a space is added between separated runs
+func layout(runs []run) string {
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].y > runs[j].y
+	})
+
+	var lines [][]run
+	for _, r := range runs {
+		if n := len(lines); n > 0 {
+			last := lines[n-1]
+			tolerance := math.Min(r.size, last[0].size) / 2
+			if math.Abs(last[0].y-r.y) <= tolerance {
+				lines[n-1] = append(last, r)
+				continue
+			}
+		}
+		lines = append(lines, []run{r})
+	}
+
+	text := make([]string, 0, len(lines))
+	for _, line := range lines {
+		sort.SliceStable(line, func(i, j int) bool {
+			return line[i].x < line[j].x
+		})
+
+		var b strings.Builder
+		for i, r := range line {
+			if i > 0 {
+				gap := r.x - line[i-1].end
+				joined := b.String()
+				if gap > r.size*0.15 && !strings.HasSuffix(joined, " ") && !strings.HasPrefix(r.text, " ") {
+					b.WriteByte(' ')
+				}
+			}
+			b.WriteString(r.text)
+		}
+		if s := strings.Join(strings.Fields(b.String()), " "); s != "" {
+			text = append(text, s)
+		}
+	}
+
+	return strings.Join(text, "\n")
+}
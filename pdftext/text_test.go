@@ -0,0 +1,153 @@
+package pdftext
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+// content is a content stream of a PDF file with its filters
+type content struct {
+	filter string
+	data   []byte
+}
+
+// testPDF builds a file of one page painted by the content streams, with
+// Helvetica as F1
+func testPDF(contents ...content) []byte {
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	b.WriteString("1 0 obj <</Type/Catalog/Pages 2 0 R>> endobj\n")
+	b.WriteString("2 0 obj <</Type/Pages/Kids[3 0 R]/Count 1>> endobj\n")
+
+	var refs []string
+	for i := range contents {
+		refs = append(refs, fmt.Sprintf("%d 0 R", i+5))
+	}
+	fmt.Fprintf(&b, "3 0 obj <</Type/Page/Parent 2 0 R/Resources<</Font<</F1 4 0 R>>>>/Contents[%s]>> endobj\n", strings.Join(refs, " "))
+	b.WriteString("4 0 obj <</Type/Font/Subtype/Type1/BaseFont/Helvetica>> endobj\n")
+
+	for i, c := range contents {
+		fmt.Fprintf(&b, "%d 0 obj <</Length %d%s>> stream\n", i+5, len(c.data), c.filter)
+		b.Write(c.data)
+		b.WriteString("\nendstream endobj\n")
+	}
+	b.WriteString("%%EOF\n")
+
+	return b.Bytes()
+}
+
+// deflate compresses data with zlib, flushing it after every part
+func deflate(parts ...[]byte) []byte {
+	var b bytes.Buffer
+	w := zlib.NewWriter(&b)
+	for _, p := range parts {
+		w.Write(p)
+		w.Flush()
+	}
+	w.Close()
+
+	return b.Bytes()
+}
+
+func TestExtract(t *testing.T) {
+	hola := []byte("BT /F1 12 Tf 72 720 Td (Hola) Tj ET\n")
+	mundo := []byte("BT /F1 12 Tf 72 700 Td (mundo) Tj ET\n")
+	blank := bytes.Repeat([]byte(" "), maxDecoded)
+
+	flushed := deflate(hola, mundo)
+	truncated := flushed[:len(deflate(hola))+4]
+
+	fisica, err := os.ReadFile("testdata/csf_fisica.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	moral, err := os.ReadFile("testdata/csf_moral.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		file []byte
+		text []string
+		err  error
+	}{
+		{
+			name: "the constancia of a persona física",
+			file: fisica,
+			text: []string{"CONSTANCIA DE SITUACIÓN FISCAL", "RFC: MUGA800101ABA", "Primer Apellido: MUÑOZ", "Cadena Original Sello:"},
+		},
+		{
+			name: "the constancia of a persona moral",
+			file: moral,
+			text: []string{"RFC: CBA050315QW2", "Denominación/Razón Social: COMERCIALIZADORA BAJÍO", "Código Postal: 06600 Tipo de Vialidad: CALLE"},
+		},
+		{
+			name: "a plain and a compressed stream",
+			file: testPDF(content{data: hola}, content{filter: "/Filter/FlateDecode", data: deflate(mundo)}),
+			text: []string{"Hola\nmundo"},
+		},
+		{
+			name: "chained filters",
+			file: testPDF(content{filter: "/Filter[/AHx/Fl]", data: []byte(hex.EncodeToString(deflate(hola)))}),
+			text: []string{"Hola"},
+		},
+		{
+			name: "a truncated stream keeps the text before the cut",
+			file: testPDF(content{filter: "/Filter/FlateDecode", data: truncated}),
+			text: []string{"Hola"},
+		},
+		{
+			name: "an unknown filter skips the stream",
+			file: testPDF(content{filter: "/Filter/LZWDecode", data: []byte("garbage")}, content{data: mundo}),
+			text: []string{"mundo"},
+		},
+		{
+			name: "a stream inflating past the limit",
+			file: testPDF(content{filter: "/Filter/FlateDecode", data: deflate(blank, hola)}),
+			err:  ErrTooLarge,
+		},
+		{
+			name: "streams inflating past the limit together",
+			file: testPDF(content{filter: "/Filter/Fl", data: deflate(blank[:maxDecoded/2], hola)}, content{filter: "/Filter/Fl", data: deflate(blank[:maxDecoded/2], mundo)}),
+			err:  ErrTooLarge,
+		},
+		{
+			name: "chained filters past the limit",
+			file: testPDF(content{filter: "/Filter[/AHx/Fl]", data: []byte(hex.EncodeToString(deflate(blank[:maxDecoded-20], hola)))}),
+			err:  ErrTooLarge,
+		},
+		{
+			name: "not a PDF",
+			file: []byte("nombre,rfc\n"),
+			err:  ErrNotPDF,
+		},
+		{
+			name: "an encrypted file",
+			file: append(testPDF(content{data: hola}), "6 0 obj <</Filter/Standard/O(x)/U(y)>> endobj\n"...),
+			err:  ErrEncrypted,
+		},
+	}
+
+	for _, tt := range tests {
+		text, err := Extract(tt.file)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: error %v, want %v", tt.name, err, tt.err)
+			continue
+		}
+		for _, want := range tt.text {
+			if !strings.Contains(text, want) {
+				t.Errorf("%s: text %q, want it to contain %q", tt.name, text, want)
+			}
+		}
+		if tt.name == "an unknown filter skips the stream" && strings.Contains(text, "garbage") {
+			t.Errorf("%s: text %q has the data of the unknown filter", tt.name, text)
+		}
+	}
+}
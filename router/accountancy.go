@@ -10,6 +10,7 @@ func accountancyRoutes(r *gin.Engine, accountancyController AccountancyControlle
 	r.PUT("/accountancy/client/:client_id/assignments", accountancyController.UpdateClientAssignments)
 	r.GET("/accountancy/clients/responsible/:responsible_id", accountancyController.GetClientsByResonsible)
 	r.POST("/accountancy/clients/history/record", accountancyController.CreateClientAccountancyStatusWithAssignments)
+	r.GET("/accountancy/client/:client_id/status/:status_id", accountancyController.GetClientAccountancyStatus)
 	r.PUT("/accountancy/client/:client_id/status/:status_id", accountancyController.UpdateClientAccountancyStatusWithAssignments)
 	r.GET("/accountancy/client/:client_id/history", accountancyController.GetClientAccountancyHistory)
 	r.GET("/accountancy/clients/all", accountancyController.GetAllClients)
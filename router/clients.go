@@ -11,24 +11,102 @@ func clientsRoutes(r *gin.Engine, clientsController ClientsController) {
 	// Gets only active clients with full info
 	r.GET("/clients/active", clientsController.GetActiveClientsInfo)
 
+	// Searches clients by name or RFC ignoring accents and typos
+	r.GET("/clients/search", clientsController.SearchClients)
+
+	// Lists the clients that share an RFC or a similar name
+	r.GET("/clients/duplicates", clientsController.GetDuplicateClients)
+
 	// Gets full info of a specific client
 	r.GET("/clients/:id", clientsController.GetClientInfo)
 
 	// Creates a new client with assignments
 	r.POST("/clients", clientsController.CreateClient)
 
+	// Validates a CSV or XLSX file of clients and imports the valid rows
+	r.POST("/clients/import", clientsController.ImportClients)
+
+	// Reads the data of a new client from its constancia de situación fiscal
+	r.POST("/clients/csf", clientsController.ReadCSF)
+
 	// Updates the basic info of a client
 	r.PUT("/clients/:id", clientsController.UpdateClient)
 
-	// Deactivates a client (soft delete)
+	// Updates only the fields of a client sent in a JSON merge patch
+	r.PATCH("/clients/:id", clientsController.PatchClient)
+
+	// Terminates a client (soft delete)
 	r.DELETE("/clients/:id", clientsController.DeactivateClient)
 
-	// Activates a client
+	// Makes a client active
 	r.PUT("/clients/:id/activate", clientsController.ActivateClient)
 
+	// Moves a client to another state of its lifecycle
+	r.PUT("/clients/:id/state", clientsController.ChangeClientState)
+
+	// Gets the changes of state of a client
+	r.GET("/clients/:id/state/history", clientsController.GetClientStateHistory)
+
+	// Terminates a client that leaves closing its accountancy and revoking its credentials
+	r.POST("/clients/:id/offboarding", clientsController.OffboardClient)
+
+	// Gets the latest offboarding of a client
+	r.GET("/clients/:id/offboarding", clientsController.GetClientOffboarding)
+
+	// Merges another client into this one moving its history
+	r.POST("/clients/:id/merge", clientsController.MergeClients)
+
+	// Reads the FIEL certificate of a client and takes its expiration from it
+	r.POST("/clients/:id/fiel", clientsController.UploadFielCertificate)
+
+	// Gets the FIEL certificates uploaded for a client
+	r.GET("/clients/:id/fiel", clientsController.GetFielCertificates)
+
+	// Lists the clients whose FIEL expires in the next days or already expired
+	r.GET("/clients/fiel/expiring", clientsController.GetExpiringFiel)
+
+	// Sets the renewal status of the FIEL of a client
+	r.PUT("/clients/:id/fiel/renewal", clientsController.SaveFielRenewal)
+
+	// Stores the certificate and the encrypted key of a CSD of a client
+	r.POST("/clients/:id/csd", clientsController.UploadCSDCertificate)
+
+	// Gets the CSD uploaded for a client
+	r.GET("/clients/:id/csd", clientsController.GetCSDCertificates)
+
+	// Removes a CSD of a client with its key
+	r.DELETE("/clients/:id/csd/:csd_id", clientsController.DeleteCSDCertificate)
+
+	// Lists the CSD of the active clients that expire in the next days or already expired
+	r.GET("/clients/csd/expiring", clientsController.GetExpiringCSD)
+
+	// Gets the contacts of a client
+	r.GET("/clients/:id/contacts", clientsController.GetClientContacts)
+
+	// Adds a contact to a client
+	r.POST("/clients/:id/contacts", clientsController.CreateClientContact)
+
+	// Replaces the data of a contact of a client
+	r.PUT("/clients/:id/contacts/:contact_id", clientsController.UpdateClientContact)
+
+	// Removes a contact of a client
+	r.DELETE("/clients/:id/contacts/:contact_id", clientsController.DeleteClientContact)
+
+	// Adds a note of the logged in user to the timeline of a client
+	r.POST("/clients/:id/notes", clientsController.AddClientNote)
+
+	// Gets the notes and recorded events of a client, the newest first
+	r.GET("/clients/:id/timeline", clientsController.GetClientTimeline)
+
 	// Updates the assignments of a specific client (supervisor, responsible, emisor)
 	r.PUT("/clients/:id/assignments", clientsController.UpdateClientAssignments)
 
+	// Gets the supervisors, responsibles and emisors a client had and since when
+	r.GET("/clients/:id/assignments/history", clientsController.GetAssignmentHistory)
+
+	// Gets the assignments every client had on a day
+	r.GET("/clients/assignments", clientsController.GetAssignmentsAsOf)
+
 	// Get clients with pending payments
 	r.GET("/clients/pending-payments", clientsController.GetClientsWithPendingPayments)
 
@@ -37,4 +115,13 @@ func clientsRoutes(r *gin.Engine, clientsController ClientsController) {
 
 	// Gets the payments history of a specific client
 	r.GET("/clients/:id/payment", clientsController.GetClientPayments)
+
+	// Gets the monthly fees of a client with their effective months
+	r.GET("/clients/:id/fees", clientsController.GetClientFees)
+
+	// Sets the monthly fee of a client from a month, a future month schedules it
+	r.PUT("/clients/:id/fees", clientsController.SaveClientFee)
+
+	// Cancels a fee scheduled for a future month
+	r.DELETE("/clients/:id/fees/:fee_id", clientsController.DeleteClientFee)
 }
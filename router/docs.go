@@ -0,0 +1,20 @@
+package router
+
+import (
+	"net/http"
+
+	"contabi-be/openapi"
+
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+)
+
+// docsRoutes sets the routes for the OpenAPI document and the Swagger UI
+func docsRoutes(r *gin.Engine) {
+	r.GET("/openapi.json", func(g *gin.Context) {
+		g.JSON(http.StatusOK, openapi.Spec())
+	})
+
+	r.GET(openapi.DocsPath, ginSwagger.WrapHandler(swaggerFiles.Handler, ginSwagger.URL("/openapi.json")))
+}
@@ -0,0 +1,95 @@
+package router
+
+import (
+	"io"
+	"sync"
+	"testing"
+
+	"contabi-be/controller"
+	"contabi-be/middleware"
+	"contabi-be/openapi"
+	"contabi-be/secret"
+	"contabi-be/service/memory"
+	"contabi-be/storage"
+	"contabi-be/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// testMenus are the catalogs the validators check, the validator caches the
+// functions of a struct the first time it validates it so they are registered
+// once and read the catalogs of the store of the test running
+var (
+	testMenus     = &struct{ controller.MenusUseCase }{}
+	registerTests sync.Once
+	registerErr   error
+)
+
+// newTestRouter builds the router of the API on a fresh demo store, the same
+// way main does in demo mode
+func newTestRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	store, err := memory.NewDemoStore()
+	if err != nil {
+		t.Fatalf("seeding the demo store: %v", err)
+	}
+
+	key, err := secret.GenerateKey()
+	if err != nil {
+		t.Fatalf("generating the encryption key: %v", err)
+	}
+	box, err := secret.NewBox(key)
+	if err != nil {
+		t.Fatalf("loading the encryption key: %v", err)
+	}
+	files, err := storage.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("opening the documents storage: %v", err)
+	}
+
+	ls := memory.NewLoginService(store)
+	us := memory.NewUsersService(store)
+	cs := memory.NewClientsService(store)
+	ms := memory.NewMenusService(store)
+	ns := memory.NewNominasService(store)
+	as := memory.NewAccountancyService(store)
+	ts := memory.NewNotificationsService(store)
+	ds := memory.NewDocumentsService(store)
+	es := memory.NewTimelineService(store)
+
+	lu := usecase.NewLoginUseCase(ls)
+	mu := usecase.NewMenusUseCase(ms)
+	cu := usecase.NewClientsUseCase(cs, ms, ts, es, ds, as, box)
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	testMenus.MenusUseCase = mu
+	registerTests.Do(func() { registerErr = controller.RegisterValidators(testMenus) })
+	if registerErr != nil {
+		t.Fatalf("registering validators: %v", registerErr)
+	}
+
+	return NewRouter(
+		controller.NewLoginController(lu, logger),
+		controller.NewUsersController(usecase.NewUsersUseCase(us), logger),
+		controller.NewClientsController(cu, logger),
+		controller.NewMenusController(mu, logger),
+		controller.NewNominasController(usecase.NewNominasUseCase(ns, cs), logger),
+		controller.NewAccountancyController(usecase.NewAccountancyUseCase(as, cs, ms), logger),
+		controller.NewNotificationsController(usecase.NewNotificationsUseCase(ts), logger),
+		controller.NewDocumentsController(usecase.NewDocumentsUseCase(ds, cs, files), logger),
+		middleware.New(lu),
+	)
+}
+
+func TestOpenAPIDocumentsEveryRoute(t *testing.T) {
+	r := newTestRouter(t)
+
+	if err := openapi.Verify(r.Routes()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,25 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func documentsRoutes(r *gin.Engine, documentsController DocumentsController) {
+	// Gets the documents of a client with their current version
+	r.GET("/clients/:id/documents", documentsController.GetDocuments)
+
+	// Uploads a file as a new document of a client
+	r.POST("/clients/:id/documents", documentsController.UploadDocument)
+
+	// Gets a document of a client with all its versions
+	r.GET("/clients/:id/documents/:document_id", documentsController.GetDocument)
+
+	// Uploads a new version of a document
+	r.POST("/clients/:id/documents/:document_id/versions", documentsController.AddDocumentVersion)
+
+	// Downloads the file of a version of a document, the current one by default
+	r.GET("/clients/:id/documents/:document_id/file", documentsController.DownloadDocument)
+
+	// Removes a document with all its versions
+	r.DELETE("/clients/:id/documents/:document_id", documentsController.DeleteDocument)
+}
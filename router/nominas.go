@@ -7,5 +7,6 @@ func nominasRouter(r *gin.Engine, nominasController NominasController) {
 	r.GET("/clients/hrpayment/:hr_entity_id", nominasController.GetClientsWithPendingPaymentsByHREntityID)
 	r.GET("/client/:client_id/hrpayments/:hr_entity_id", nominasController.GetClientPendingPaymentsByHREntityIDDetails)
 	r.PUT("/client/hrpayment", nominasController.UpdateClientPaymentRecord)
+	r.PATCH("/client/hrpayment/:id", nominasController.PatchClientPaymentRecord)
 	r.GET("/client/:client_id/hrpayments/:hr_entity_id/history", nominasController.GetClientHRPaymentsHistory)
 }
@@ -0,0 +1,13 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func notificationsRoutes(r *gin.Engine, notificationsController NotificationsController) {
+	// Gets the notifications of the logged in user
+	r.GET("/notifications", notificationsController.GetNotifications)
+
+	// Marks a notification of the logged in user as read
+	r.PUT("/notifications/:id/read", notificationsController.MarkNotificationRead)
+}
@@ -15,6 +15,7 @@ type UsersController interface {
 	GetUserByID(g *gin.Context)
 	CreateUser(g *gin.Context)
 	UpdateUser(g *gin.Context)
+	PatchUser(g *gin.Context)
 	UpdateUserRole(g *gin.Context)
 	PutUserPassword(g *gin.Context)
 	DeleteUser(g *gin.Context)
@@ -25,15 +26,44 @@ type UsersController interface {
 type ClientsController interface {
 	GetClientsInfo(c *gin.Context)
 	GetActiveClientsInfo(c *gin.Context)
+	SearchClients(c *gin.Context)
+	GetDuplicateClients(c *gin.Context)
 	GetClientInfo(c *gin.Context)
 	CreateClient(c *gin.Context)
+	ImportClients(c *gin.Context)
+	ReadCSF(c *gin.Context)
 	UpdateClient(c *gin.Context)
+	PatchClient(c *gin.Context)
 	DeactivateClient(c *gin.Context)
 	ActivateClient(c *gin.Context)
+	ChangeClientState(c *gin.Context)
+	GetClientStateHistory(c *gin.Context)
+	OffboardClient(c *gin.Context)
+	GetClientOffboarding(c *gin.Context)
+	MergeClients(c *gin.Context)
+	UploadFielCertificate(c *gin.Context)
+	GetFielCertificates(c *gin.Context)
+	GetExpiringFiel(c *gin.Context)
+	SaveFielRenewal(c *gin.Context)
+	UploadCSDCertificate(c *gin.Context)
+	GetCSDCertificates(c *gin.Context)
+	DeleteCSDCertificate(c *gin.Context)
+	GetExpiringCSD(c *gin.Context)
+	GetClientContacts(c *gin.Context)
+	CreateClientContact(c *gin.Context)
+	UpdateClientContact(c *gin.Context)
+	DeleteClientContact(c *gin.Context)
+	AddClientNote(c *gin.Context)
+	GetClientTimeline(c *gin.Context)
 	UpdateClientAssignments(c *gin.Context)
+	GetAssignmentHistory(c *gin.Context)
+	GetAssignmentsAsOf(c *gin.Context)
 	GetClientsWithPendingPayments(c *gin.Context)
 	UpdateClientPayment(c *gin.Context)
 	GetClientPayments(c *gin.Context)
+	GetClientFees(c *gin.Context)
+	SaveClientFee(c *gin.Context)
+	DeleteClientFee(c *gin.Context)
 }
 
 type MenusController interface {
@@ -50,6 +80,7 @@ type NominasController interface {
 	GetClientsWithPendingPaymentsByHREntityID(g *gin.Context)
 	GetClientPendingPaymentsByHREntityIDDetails(g *gin.Context)
 	UpdateClientPaymentRecord(g *gin.Context)
+	PatchClientPaymentRecord(g *gin.Context)
 	GetClientHRPaymentsHistory(g *gin.Context)
 }
 
@@ -61,10 +92,27 @@ type AccountancyController interface {
 	CreateClientAccountancyStatusWithAssignments(g *gin.Context)
 	UpdateClientAccountancyStatusWithAssignments(g *gin.Context)
 	GetClientAccountancyHistory(g *gin.Context)
+	GetClientAccountancyStatus(g *gin.Context)
 	GetAllClients(g *gin.Context)
 	UpdateClientResponsible(g *gin.Context)
 }
 
+// NotificationsController handles the notifications of the logged in user
+type NotificationsController interface {
+	GetNotifications(g *gin.Context)
+	MarkNotificationRead(g *gin.Context)
+}
+
+// DocumentsController handles the documents attached to the clients
+type DocumentsController interface {
+	GetDocuments(g *gin.Context)
+	GetDocument(g *gin.Context)
+	UploadDocument(g *gin.Context)
+	AddDocumentVersion(g *gin.Context)
+	DownloadDocument(g *gin.Context)
+	DeleteDocument(g *gin.Context)
+}
+
 // NewRouter set the API routes and applies the middleware
 func NewRouter(
 	loginController LoginController,
@@ -73,6 +121,8 @@ func NewRouter(
 	menusController MenusController,
 	nominasController NominasController,
 	accountancyController AccountancyController,
+	notificationsController NotificationsController,
+	documentsController DocumentsController,
 	mw *middleware.Middleware,
 ) *gin.Engine {
 	// Creates a new instance of Gin router
@@ -81,6 +131,9 @@ func NewRouter(
 	// Adds the CORS middleware to all routes
 	r.Use(mw.CORS())
 
+	// Routes for the OpenAPI document and Swagger UI
+	docsRoutes(r)
+
 	// Routes for Login
 	loginRoutes(r, loginController)
 
@@ -97,5 +150,9 @@ func NewRouter(
 
 	accountancyRoutes(r, accountancyController)
 
+	notificationsRoutes(r, notificationsController)
+
+	documentsRoutes(r, documentsController)
+
 	return r
 }
@@ -0,0 +1,589 @@
+package router
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"contabi-be/models"
+	"contabi-be/service/memory"
+
+	"github.com/gin-gonic/gin"
+)
+
+// request sends a request as the demo admin, or without credentials when
+// anonymous, with the body encoded as JSON when it is not nil
+func request(t *testing.T, r *gin.Engine, method, path string, body any, header map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			t.Fatalf("encoding the body: %v", err)
+		}
+	}
+
+	req := httptest.NewRequest(method, path, &payload)
+	req.Header.Set("Content-Type", "application/json")
+	if header["anonymous"] == "" {
+		req.Header.Set("X-Username", "admin")
+		req.Header.Set("X-UserPassword", memory.DemoPassword)
+	}
+	for k, v := range header {
+		if k != "anonymous" {
+			req.Header.Set(k, v)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	return w
+}
+
+// upload sends a file in the file field of a form as the demo admin
+func upload(t *testing.T, r *gin.Engine, path, filename string, data []byte) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatalf("creating the form: %v", err)
+	}
+	part.Write(data)
+	form.Close()
+
+	req := httptest.NewRequest(http.MethodPost, path, &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("X-Username", "admin")
+	req.Header.Set("X-UserPassword", memory.DemoPassword)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	return w
+}
+
+// decode reads the JSON body of a response
+func decode[T any](t *testing.T, w *httptest.ResponseRecorder) T {
+	t.Helper()
+
+	var v T
+	if err := json.Unmarshal(w.Body.Bytes(), &v); err != nil {
+		t.Fatalf("decoding %q: %v", w.Body.String(), err)
+	}
+
+	return v
+}
+
+// expectStatus fails the test when the response has another status
+func expectStatus(t *testing.T, w *httptest.ResponseRecorder, status int) {
+	t.Helper()
+
+	if w.Code != status {
+		t.Fatalf("status %d, want %d: %s", w.Code, status, w.Body.String())
+	}
+}
+
+// expectError fails the test when the response is not an error with the
+// status and code given
+func expectError(t *testing.T, w *httptest.ResponseRecorder, status int, code models.ErrorCode) models.ErrorResponse {
+	t.Helper()
+
+	expectStatus(t, w, status)
+	body := decode[models.ErrorResponse](t, w)
+	if body.Code != code || body.Message == "" {
+		t.Fatalf("error %+v, want code %s with a message", body, code)
+	}
+
+	return body
+}
+
+// clientByName finds a client of the listing by its name
+func clientByName(t *testing.T, r *gin.Engine, name string) models.ClientInfo {
+	t.Helper()
+
+	w := request(t, r, http.MethodGet, "/clients?page_size=200", nil, nil)
+	expectStatus(t, w, http.StatusOK)
+	for _, c := range decode[models.ClientInfoPage](t, w).Items {
+		if c.Name == name {
+			return c
+		}
+	}
+
+	t.Fatalf("client %q not found", name)
+	return models.ClientInfo{}
+}
+
+func TestLogin(t *testing.T) {
+	r := newTestRouter(t)
+	anonymous := map[string]string{"anonymous": "true"}
+
+	w := request(t, r, http.MethodPost, "/login", models.LoginRequest{Username: "laura", Password: memory.DemoPassword}, anonymous)
+	expectStatus(t, w, http.StatusOK)
+	if user := decode[struct{ User models.User }](t, w).User; user.Username != "laura" || user.ID == "" {
+		t.Fatalf("logged in as %+v, want laura", user)
+	}
+
+	w = request(t, r, http.MethodPost, "/login", models.LoginRequest{Username: "laura", Password: "wrong"}, anonymous)
+	expectError(t, w, http.StatusUnauthorized, models.CodeUnauthorized)
+
+	w = request(t, r, http.MethodGet, "/clients", nil, anonymous)
+	expectError(t, w, http.StatusUnauthorized, models.CodeUnauthorized)
+
+	w = request(t, r, http.MethodGet, "/clients", nil, map[string]string{"anonymous": "true", "X-Username": "admin", "X-UserPassword": "wrong"})
+	expectError(t, w, http.StatusUnauthorized, models.CodeUnauthorized)
+}
+
+func TestClientCRUD(t *testing.T) {
+	r := newTestRouter(t)
+
+	w := request(t, r, http.MethodGet, "/menu/supervisors", nil, nil)
+	expectStatus(t, w, http.StatusOK)
+	supervisor := decode[[]models.Supervisor](t, w)[0]
+
+	w = request(t, r, http.MethodGet, "/menu/responsibles/"+supervisor.ID, nil, nil)
+	expectStatus(t, w, http.StatusOK)
+	responsible := decode[[]models.Responsible](t, w)[0]
+
+	create := models.CreateClientRequest{
+		Client: models.Client{Name: "Papelería Torres", RegimenID: "612", RFC: "TOPA850203KJA", MonthlyFee: "1200"},
+		Assignments: models.ClientAssignments{
+			SupervisorID:  supervisor.ID,
+			ResponsibleID: responsible.ID,
+			EmisorID:      "1",
+		},
+	}
+	w = request(t, r, http.MethodPost, "/clients", create, nil)
+	expectStatus(t, w, http.StatusCreated)
+
+	w = request(t, r, http.MethodPost, "/clients", create, nil)
+	expectError(t, w, http.StatusConflict, models.CodeConflict)
+
+	created := clientByName(t, r, "Papelería Torres")
+	if created.RFC != "TOPA850203KJA" || created.MonthlyFee != "1200" || created.State != models.ClientActive {
+		t.Fatalf("created %+v", created)
+	}
+
+	w = request(t, r, http.MethodGet, "/clients/"+created.ID, nil, nil)
+	expectStatus(t, w, http.StatusOK)
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("no ETag on the client")
+	}
+
+	update := create.Client
+	update.Name = "Papelería Torres Hermanos"
+	update.MonthlyFee = "1300"
+
+	w = request(t, r, http.MethodPut, "/clients/"+created.ID, update, nil)
+	expectError(t, w, http.StatusPreconditionRequired, models.CodePreconditionRequired)
+
+	w = request(t, r, http.MethodPut, "/clients/"+created.ID, update, map[string]string{"If-Match": etag})
+	expectStatus(t, w, http.StatusOK)
+
+	w = request(t, r, http.MethodPut, "/clients/"+created.ID, update, map[string]string{"If-Match": etag})
+	expectError(t, w, http.StatusPreconditionFailed, models.CodePreconditionFailed)
+
+	w = request(t, r, http.MethodGet, "/clients/"+created.ID, nil, nil)
+	expectStatus(t, w, http.StatusOK)
+	if info := decode[models.ClientInfo](t, w); info.Name != "Papelería Torres Hermanos" || info.MonthlyFee != "1300" {
+		t.Fatalf("updated %+v", info)
+	}
+
+	w = request(t, r, http.MethodDelete, "/clients/"+created.ID, nil, map[string]string{"If-Match": w.Header().Get("ETag")})
+	expectStatus(t, w, http.StatusOK)
+
+	w = request(t, r, http.MethodGet, "/clients/"+created.ID, nil, nil)
+	expectStatus(t, w, http.StatusOK)
+	if info := decode[models.ClientInfo](t, w); info.Active || info.State != models.ClientTerminated {
+		t.Fatalf("deactivated %+v", info)
+	}
+}
+
+func TestClientPayments(t *testing.T) {
+	r := newTestRouter(t)
+	client := clientByName(t, r, "Transportes Núñez")
+
+	w := request(t, r, http.MethodGet, "/clients/pending-payments", nil, nil)
+	expectStatus(t, w, http.StatusOK)
+	if !bytes.Contains(w.Body.Bytes(), []byte(client.ID)) {
+		t.Fatalf("a client that never paid is not pending: %s", w.Body.String())
+	}
+
+	w = request(t, r, http.MethodGet, "/clients/"+client.ID+"/payment", nil, nil)
+	expectStatus(t, w, http.StatusOK)
+	if payments := decode[[]models.ClientPayment](t, w); len(payments) != 0 {
+		t.Fatalf("payments %+v of a client that never paid", payments)
+	}
+
+	payment := models.ClientPayment{LastPaymentMonth: "2024-05", LastPaymentDate: "2024-06-03", FolioFactura: "F-1001"}
+	w = request(t, r, http.MethodPut, "/clients/"+client.ID+"/payment", payment, map[string]string{"If-Match": w.Header().Get("ETag")})
+	expectStatus(t, w, http.StatusOK)
+
+	w = request(t, r, http.MethodGet, "/clients/"+client.ID+"/payment", nil, nil)
+	expectStatus(t, w, http.StatusOK)
+	payments := decode[[]models.ClientPayment](t, w)
+	if len(payments) != 1 || payments[0].FolioFactura != "F-1001" || payments[0].LastPaymentMonth[:7] != "2024-05" {
+		t.Fatalf("payments %+v", payments)
+	}
+
+	invalid := models.ClientPayment{LastPaymentMonth: "2024-13", LastPaymentDate: "2024-06-03"}
+	w = request(t, r, http.MethodPut, "/clients/"+client.ID+"/payment", invalid, map[string]string{"If-Match": w.Header().Get("ETag")})
+	expectError(t, w, http.StatusUnprocessableEntity, models.CodeValidation)
+}
+
+func TestErrorShapes(t *testing.T) {
+	r := newTestRouter(t)
+
+	w := request(t, r, http.MethodGet, "/clients/999999", nil, nil)
+	expectError(t, w, http.StatusNotFound, models.CodeNotFound)
+
+	w = request(t, r, http.MethodGet, "/clients?page_size=500", nil, nil)
+	body := expectError(t, w, http.StatusUnprocessableEntity, models.CodeValidation)
+	details, _ := json.Marshal(body.Details)
+	var fields []models.FieldError
+	if err := json.Unmarshal(details, &fields); err != nil || len(fields) != 1 || fields[0].Field != "page_size" || fields[0].Rule != "max" {
+		t.Fatalf("details %s, want the page_size field failing max", details)
+	}
+
+	w = request(t, r, http.MethodPost, "/clients", models.CreateClientRequest{
+		Client: models.Client{Name: "Sin RFC", RegimenID: "999", RFC: "NOT-AN-RFC"},
+	}, nil)
+	body = expectError(t, w, http.StatusUnprocessableEntity, models.CodeValidation)
+	details, _ = json.Marshal(body.Details)
+	for _, field := range []string{"client.regimen_id", "client.rfc", "assignments.supervisor_id"} {
+		if !bytes.Contains(details, []byte(`"`+field+`"`)) {
+			t.Errorf("details %s do not report %s", details, field)
+		}
+	}
+
+	w = request(t, r, http.MethodPost, "/clients", nil, map[string]string{"Content-Type": "application/json"})
+	expectError(t, w, http.StatusBadRequest, models.CodeBadRequest)
+}
+
+func TestUserPatchChangesAllOrNothing(t *testing.T) {
+	r := newTestRouter(t)
+
+	w := request(t, r, http.MethodGet, "/users", nil, nil)
+	expectStatus(t, w, http.StatusOK)
+	var laura models.User
+	for _, u := range decode[[]models.User](t, w) {
+		if u.Username == "laura" {
+			laura = u
+		}
+	}
+	role := 1
+	if laura.Role == role {
+		role = 2
+	}
+
+	w = request(t, r, http.MethodPatch, "/user/"+laura.ID, map[string]any{"username": "admin", "role": role, "password": "another-password"}, nil)
+	expectError(t, w, http.StatusConflict, models.CodeConflict)
+
+	w = request(t, r, http.MethodGet, "/user/"+laura.ID, nil, nil)
+	expectStatus(t, w, http.StatusOK)
+	if user := decode[models.User](t, w); user.Username != "laura" || user.Role != laura.Role {
+		t.Fatalf("a rejected patch changed the user to %+v", user)
+	}
+	w = request(t, r, http.MethodPost, "/login", models.LoginRequest{Username: "laura", Password: memory.DemoPassword}, map[string]string{"anonymous": "true"})
+	expectStatus(t, w, http.StatusOK)
+
+	w = request(t, r, http.MethodPatch, "/user/"+laura.ID, map[string]any{"role": role}, nil)
+	expectStatus(t, w, http.StatusOK)
+	w = request(t, r, http.MethodGet, "/user/"+laura.ID, nil, nil)
+	if user := decode[models.User](t, w); user.Username != "laura" || user.Role != role {
+		t.Fatalf("patched %+v, want role %d", user, role)
+	}
+}
+
+func TestClientStateFollowsItsHistory(t *testing.T) {
+	r := newTestRouter(t)
+	client := clientByName(t, r, "Transportes Núñez")
+
+	etag := func() string {
+		w := request(t, r, http.MethodGet, "/clients/"+client.ID, nil, nil)
+		expectStatus(t, w, http.StatusOK)
+		return w.Header().Get("ETag")
+	}
+
+	suspend := models.ClientStateRequest{State: models.ClientSuspended, Reason: "Stopped paying", EffectiveDate: "2024-06-10"}
+	w := request(t, r, http.MethodPut, "/clients/"+client.ID+"/state", suspend, map[string]string{"If-Match": etag()})
+	expectStatus(t, w, http.StatusOK)
+
+	back := models.ClientStateRequest{State: models.ClientActive, Reason: "Paid", EffectiveDate: "2024-06-01"}
+	w = request(t, r, http.MethodPut, "/clients/"+client.ID+"/state", back, map[string]string{"If-Match": etag()})
+	body := expectError(t, w, http.StatusUnprocessableEntity, models.CodeValidation)
+	if details, _ := json.Marshal(body.Details); !bytes.Contains(details, []byte(`"after_last_change"`)) {
+		t.Fatalf("details %s, want the effective date failing after_last_change", details)
+	}
+
+	w = request(t, r, http.MethodPatch, "/clients/"+client.ID, map[string]any{"active": false}, map[string]string{"If-Match": etag()})
+	expectStatus(t, w, http.StatusOK)
+
+	w = request(t, r, http.MethodGet, "/clients/"+client.ID+"/state/history", nil, nil)
+	expectStatus(t, w, http.StatusOK)
+	history := decode[[]models.ClientStateChange](t, w)
+	if len(history) < 2 || history[0].FromState != models.ClientSuspended || history[0].ToState != models.ClientTerminated {
+		t.Fatalf("history %+v, want the deactivation of the suspended client first", history)
+	}
+}
+
+func TestVersionMovesOncePerChange(t *testing.T) {
+	r := newTestRouter(t)
+	client := clientByName(t, r, "Transportes Núñez")
+	other := clientByName(t, r, "Fundación Peña Azul")
+
+	version := func() models.ClientVersion {
+		w := request(t, r, http.MethodGet, "/clients/"+client.ID, nil, nil)
+		expectStatus(t, w, http.StatusOK)
+		v, ok := models.ParseClientETag(w.Header().Get("ETag"))
+		if !ok {
+			t.Fatalf("ETag %q", w.Header().Get("ETag"))
+		}
+		return v
+	}
+
+	before := version()
+	update := models.Client{Name: client.Name, RegimenID: client.RegimenID, RFC: other.RFC, MonthlyFee: client.MonthlyFee}
+	w := request(t, r, http.MethodPut, "/clients/"+client.ID, update, map[string]string{"If-Match": before.ETag()})
+	expectError(t, w, http.StatusConflict, models.CodeConflict)
+	if after := version(); after != before {
+		t.Fatalf("a rejected update moved the version from %s to %s", before.ETag(), after.ETag())
+	}
+
+	update.RFC = client.RFC
+	w = request(t, r, http.MethodPut, "/clients/"+client.ID, update, map[string]string{"If-Match": before.ETag()})
+	expectStatus(t, w, http.StatusOK)
+	if after := version(); after != (models.ClientVersion{Client: before.Client + 1, Assignments: before.Assignments}) {
+		t.Fatalf("an update moved the version from %s to %s", before.ETag(), after.ETag())
+	}
+
+	w = request(t, r, http.MethodGet, "/clients/"+client.ID, nil, nil)
+	expectStatus(t, w, http.StatusOK)
+	info := decode[models.ClientInfo](t, w)
+	before = version()
+	assignments := models.ClientAssignments{SupervisorID: info.SupervisorID, ResponsibleID: info.ResponsibleID, EmisorID: info.EmisorID}
+	w = request(t, r, http.MethodPut, "/clients/"+client.ID+"/assignments", assignments, map[string]string{"If-Match": before.ETag()})
+	expectStatus(t, w, http.StatusOK)
+	if after := version(); after != (models.ClientVersion{Client: before.Client, Assignments: before.Assignments + 1}) {
+		t.Fatalf("an assignments update moved the version from %s to %s", before.ETag(), after.ETag())
+	}
+
+	w = request(t, r, http.MethodPut, "/clients/"+client.ID+"/assignments", assignments, map[string]string{"If-Match": before.ETag()})
+	expectError(t, w, http.StatusPreconditionFailed, models.CodePreconditionFailed)
+}
+
+func TestStatusETag(t *testing.T) {
+	r := newTestRouter(t)
+	client := clientByName(t, r, "Abarrotes Muñoz")
+
+	w := request(t, r, http.MethodGet, "/accountancy/client/"+client.ID+"/history", nil, nil)
+	expectStatus(t, w, http.StatusOK)
+	clientTag := w.Header().Get("ETag")
+	history := decode[models.ClientAccountancyHistoryWithAssignments](t, w)
+	if len(history.History) == 0 {
+		t.Fatal("the demo client has no accountancy months")
+	}
+	status := history.History[0].Status
+	path := fmt.Sprintf("/accountancy/client/%s/status/%d", client.ID, status.ID)
+
+	w = request(t, r, http.MethodGet, path, nil, nil)
+	expectStatus(t, w, http.StatusOK)
+	etag := w.Header().Get("ETag")
+	if etag != models.StatusETag(status.Version) {
+		t.Fatalf("ETag %s, want the version of the record %s", etag, models.StatusETag(status.Version))
+	}
+	entry := decode[models.ClientAccountancyHistoryEntry](t, w)
+	entry.Status.Observacion = "Revisado"
+
+	for _, ifMatch := range []string{clientTag, "W/" + etag} {
+		w = request(t, r, http.MethodPut, path, entry, map[string]string{"If-Match": ifMatch})
+		expectError(t, w, http.StatusPreconditionFailed, models.CodePreconditionFailed)
+	}
+
+	w = request(t, r, http.MethodPut, path, entry, map[string]string{"If-Match": clientTag + `, ` + etag})
+	expectStatus(t, w, http.StatusOK)
+	w = request(t, r, http.MethodPut, path, entry, map[string]string{"If-Match": etag})
+	expectError(t, w, http.StatusPreconditionFailed, models.CodePreconditionFailed)
+	w = request(t, r, http.MethodPut, path, entry, map[string]string{"If-Match": "*"})
+	expectStatus(t, w, http.StatusOK)
+
+	w = request(t, r, http.MethodDelete, "/clients/"+client.ID, nil, nil)
+	expectError(t, w, http.StatusPreconditionRequired, models.CodePreconditionRequired)
+}
+
+func TestOffboardingBlocksTheClient(t *testing.T) {
+	r := newTestRouter(t)
+	client := clientByName(t, r, "Transportes Núñez")
+
+	etag := func() string {
+		w := request(t, r, http.MethodGet, "/clients/"+client.ID, nil, nil)
+		expectStatus(t, w, http.StatusOK)
+		return w.Header().Get("ETag")
+	}
+
+	// the demo client never paid and has an accountancy month since last month
+	offboard := models.OffboardingRequest{Reason: "Moved to another firm", LastServicedMonth: time.Now().Format("2006-01")}
+	w := request(t, r, http.MethodPost, "/clients/"+client.ID+"/offboarding", offboard, map[string]string{"If-Match": etag()})
+	expectStatus(t, w, http.StatusCreated)
+	offboarding := decode[models.ClientOffboarding](t, w)
+	if offboarding.MonthsDue != 2 || offboarding.OutstandingBalance != "7000.00" {
+		t.Fatalf("offboarding owes %d months, %s, want 2 months, 7000.00", offboarding.MonthsDue, offboarding.OutstandingBalance)
+	}
+
+	w = request(t, r, http.MethodGet, "/clients/"+client.ID+"/timeline?type="+models.EventDeactivated, nil, nil)
+	expectStatus(t, w, http.StatusOK)
+	if events := decode[models.ClientEventPage](t, w); events.Total != 1 {
+		t.Fatalf("timeline %+v, want the offboarding event", events)
+	}
+
+	update := models.Client{Name: client.Name, RegimenID: client.RegimenID, RFC: client.RFC, ClaveCIEC: "newsecret", Active: "false"}
+	w = request(t, r, http.MethodPut, "/clients/"+client.ID, update, map[string]string{"If-Match": etag()})
+	expectError(t, w, http.StatusConflict, models.CodeConflict)
+
+	w = request(t, r, http.MethodPatch, "/clients/"+client.ID, map[string]any{"clave_ciec": "newsecret"}, map[string]string{"If-Match": etag()})
+	expectError(t, w, http.StatusConflict, models.CodeConflict)
+
+	contact := models.ClientContactRequest{Name: "Ana Núñez", Email: "ana@example.com"}
+	w = request(t, r, http.MethodPost, "/clients/"+client.ID+"/contacts", contact, nil)
+	expectError(t, w, http.StatusConflict, models.CodeConflict)
+
+	w = request(t, r, http.MethodPatch, "/clients/"+client.ID, map[string]any{"active": true}, map[string]string{"If-Match": etag()})
+	expectStatus(t, w, http.StatusOK)
+	w = request(t, r, http.MethodPost, "/clients/"+client.ID+"/contacts", contact, nil)
+	expectStatus(t, w, http.StatusCreated)
+}
+
+func TestOffboardingATerminatedClient(t *testing.T) {
+	r := newTestRouter(t)
+	client := clientByName(t, r, "Transportes Núñez")
+
+	etag := func() string {
+		w := request(t, r, http.MethodGet, "/clients/"+client.ID, nil, nil)
+		expectStatus(t, w, http.StatusOK)
+		return w.Header().Get("ETag")
+	}
+
+	terminatedOn := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+	terminate := models.ClientStateRequest{State: models.ClientTerminated, Reason: "Stopped paying", EffectiveDate: terminatedOn}
+	w := request(t, r, http.MethodPut, "/clients/"+client.ID+"/state", terminate, map[string]string{"If-Match": etag()})
+	expectStatus(t, w, http.StatusOK)
+
+	offboard := models.OffboardingRequest{Reason: "Moved to another firm", LastServicedMonth: time.Now().Format("2006-01"), EndDate: time.Now().Format("2006-01-02")}
+	w = request(t, r, http.MethodPost, "/clients/"+client.ID+"/offboarding", offboard, map[string]string{"If-Match": etag()})
+	expectError(t, w, http.StatusUnprocessableEntity, models.CodeValidation)
+
+	offboard.EndDate = ""
+	w = request(t, r, http.MethodPost, "/clients/"+client.ID+"/offboarding", offboard, map[string]string{"If-Match": etag()})
+	expectStatus(t, w, http.StatusCreated)
+	if offboarding := decode[models.ClientOffboarding](t, w); offboarding.EndDate != terminatedOn {
+		t.Fatalf("offboarding ends on %s, want the date the client was terminated, %s", offboarding.EndDate, terminatedOn)
+	}
+
+	w = request(t, r, http.MethodGet, "/clients/"+client.ID+"/state/history", nil, nil)
+	expectStatus(t, w, http.StatusOK)
+	if changes := decode[[]models.ClientStateChange](t, w); len(changes) != 1 {
+		t.Fatalf("state history %+v, want only the termination", changes)
+	}
+
+	w = request(t, r, http.MethodPost, "/clients/"+client.ID+"/offboarding", offboard, map[string]string{"If-Match": etag()})
+	expectError(t, w, http.StatusConflict, models.CodeConflict)
+}
+
+func TestGenericRFCsAreNotDuplicates(t *testing.T) {
+	r := newTestRouter(t)
+	assigned := clientByName(t, r, "Transportes Núñez")
+	w := request(t, r, http.MethodGet, "/clients/"+assigned.ID, nil, nil)
+	expectStatus(t, w, http.StatusOK)
+	info := decode[models.ClientInfo](t, w)
+	assignments := models.ClientAssignments{SupervisorID: info.SupervisorID, ResponsibleID: info.ResponsibleID, EmisorID: info.EmisorID}
+
+	for _, name := range []string{"Mostrador Norte", "Mostrador Sur"} {
+		create := models.CreateClientRequest{
+			Client:      models.Client{Name: name, RegimenID: "612", RFC: "xaxx-010101-000"},
+			Assignments: assignments,
+		}
+		w := request(t, r, http.MethodPost, "/clients", create, nil)
+		expectStatus(t, w, http.StatusCreated)
+	}
+
+	w = request(t, r, http.MethodGet, "/clients/duplicates", nil, nil)
+	expectStatus(t, w, http.StatusOK)
+	for _, g := range decode[[]models.DuplicateClientGroup](t, w) {
+		if g.Key == "XAXX010101000" {
+			t.Fatalf("the clients of the general public are reported as duplicates: %+v", g)
+		}
+	}
+
+	moral := models.CreateClientRequest{
+		Client:      models.Client{Name: "Mostrador Moral", RegimenID: "601", RFC: "XAXX010101000"},
+		Assignments: assignments,
+	}
+	w = request(t, r, http.MethodPost, "/clients", moral, nil)
+	expectError(t, w, http.StatusUnprocessableEntity, models.CodeValidation)
+}
+
+func TestImportStopsAtTheLimit(t *testing.T) {
+	r := newTestRouter(t)
+
+	upload := func(file string) *httptest.ResponseRecorder {
+		return upload(t, r, "/clients/import", "clientes.csv", []byte(file))
+	}
+
+	header := "nombre,rfc,regimen,honorarios,supervisor,responsable,emisor\n"
+	w := upload(header + "Papelería Torres,TOPA850203KJA,612,1234.567,laura,laura,1\n")
+	expectStatus(t, w, http.StatusOK)
+	report := decode[models.ClientImportReport](t, w)
+	if len(report.Rows) != 1 || report.Rows[0].Valid || !slices.ContainsFunc(report.Rows[0].Errors, func(e models.FieldError) bool {
+		return e.Field == "monthly_fee" && e.Rule == "positive_amount"
+	}) {
+		t.Fatalf("import %+v, want the fee with three decimals rejected", report)
+	}
+
+	// the blank lines do not count, the file has one client past the limit
+	file := header + strings.Repeat(",,,,,,\n", 50)
+	for i := 0; i <= models.MaxImportRows; i++ {
+		file += fmt.Sprintf("Cliente %d,XAXX010101000,612,1000,laura,laura,1\n", i)
+	}
+	w = upload(file)
+	if body := expectError(t, w, http.StatusUnprocessableEntity, models.CodeValidation); !strings.Contains(body.Message, "more than") {
+		t.Fatalf("error %+v, want the file rejected for its rows", body)
+	}
+}
+
+func TestReadCSF(t *testing.T) {
+	r := newTestRouter(t)
+
+	constancia, err := os.ReadFile("../pdftext/testdata/csf_fisica.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := upload(t, r, "/clients/csf", "constancia.pdf", constancia)
+	expectStatus(t, w, http.StatusOK)
+	if draft := decode[models.CSFDraft](t, w); draft.Client.RFC != "MUGA800101ABA" || draft.Client.Name == "" {
+		t.Fatalf("draft %+v, want the client of the constancia", draft)
+	}
+
+	// a page whose content inflates past the limit of the decoded streams
+	var content bytes.Buffer
+	z := zlib.NewWriter(&content)
+	z.Write(bytes.Repeat([]byte(" "), 65<<20))
+	z.Close()
+	bomb := fmt.Sprintf("%%PDF-1.4\n1 0 obj <</Type/Page/Contents 2 0 R>> endobj\n2 0 obj <</Length %d/Filter/FlateDecode>> stream\n%s\nendstream endobj\n", content.Len(), content.Bytes())
+	w = upload(t, r, "/clients/csf", "constancia.pdf", []byte(bomb))
+	expectError(t, w, http.StatusUnprocessableEntity, models.CodeValidation)
+	if !strings.Contains(w.Body.String(), `"rule":"max"`) {
+		t.Fatalf("error %s, want the file rejected for its size", w.Body.String())
+	}
+}
@@ -9,6 +9,7 @@ func usersRoutes(r *gin.Engine, usersController UsersController) {
 	r.GET("/user/:id", usersController.GetUserByID)
 	r.POST("/user", usersController.CreateUser)
 	r.PUT("/user", usersController.UpdateUser)
+	r.PATCH("/user/:id", usersController.PatchUser)
 	r.PUT("/user/role", usersController.UpdateUserRole)
 	r.PUT("/user/pass", usersController.PutUserPassword)
 	r.DELETE("/user/:id", usersController.DeleteUser)
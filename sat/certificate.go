@@ -0,0 +1,133 @@
+package sat
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Attributes of the subject of the certificates issued by the SAT
+var (
+	// oidUniqueIdentifier holds the RFC of the taxpayer, followed by the RFC of
+	// its legal representative for personas morales
+	oidUniqueIdentifier = asn1.ObjectIdentifier{2, 5, 4, 45}
+	// oidName holds the name of the taxpayer
+	oidName = asn1.ObjectIdentifier{2, 5, 4, 41}
+)
+
+// ErrCertificateRFC is returned for certificates whose subject has no RFC
+var ErrCertificateRFC = errors.New("the certificate does not name an RFC")
+
+// CertificateType tells a FIEL certificate from a CSD one
+type CertificateType string
+
+const (
+	// CertificateFiel is the e.firma, it signs and encrypts documents
+	CertificateFiel CertificateType = "fiel"
+	// CertificateCSD is the certificado de sello digital, it only seals invoices
+	CertificateCSD CertificateType = "csd"
+	// CertificateUnknown is a certificate without key usages
+	CertificateUnknown CertificateType = ""
+)
+
+// Certificate is the data of a certificate issued by the SAT, a FIEL or a CSD
+type Certificate struct {
+	// SerialNumber is the certificate number the SAT shows, 20 digits
+	SerialNumber string
+	RFC          string
+	Name         string
+	// Branch is the branch a CSD was requested for, it may be empty
+	Branch    string
+	Type      CertificateType
+	ValidFrom time.Time
+	ValidTo   time.Time
+	PublicKey crypto.PublicKey
+}
+
+// ParseCertificate reads a .cer file, the SAT issues them in DER but PEM
+// copies are accepted too
+func ParseCertificate(data []byte) (Certificate, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		data = block.Bytes
+	}
+
+	cert, err := x509.ParseCertificate(data)
+	if err != nil {
+		return Certificate{}, fmt.Errorf("parsing certificate: %w", err)
+	}
+
+	c := Certificate{
+		SerialNumber: serialNumber(cert),
+		Name:         cert.Subject.CommonName,
+		Type:         certificateType(cert.KeyUsage),
+		ValidFrom:    cert.NotBefore.UTC(),
+		ValidTo:      cert.NotAfter.UTC(),
+		PublicKey:    cert.PublicKey,
+	}
+	if len(cert.Subject.OrganizationalUnit) > 0 {
+		c.Branch = strings.TrimSpace(cert.Subject.OrganizationalUnit[0])
+	}
+
+	for _, attr := range cert.Subject.Names {
+		value, ok := attr.Value.(string)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case attr.Type.Equal(oidUniqueIdentifier):
+			rfc, _, _ := strings.Cut(value, "/")
+			c.RFC = NormalizeRFC(rfc)
+		case attr.Type.Equal(oidName) && c.Name == "":
+			c.Name = strings.TrimSpace(value)
+		}
+	}
+
+	if c.RFC == "" {
+		return Certificate{}, ErrCertificateRFC
+	}
+
+	return c, nil
+}
+
+// serialNumber returns the certificate number, the SAT encodes its digits as
+// the ASCII bytes of the serial
+func serialNumber(cert *x509.Certificate) string {
+	b := cert.SerialNumber.Bytes()
+	for _, d := range b {
+		if d < '0' || d > '9' {
+			return cert.SerialNumber.String()
+		}
+	}
+
+	return string(b)
+}
+
+// certificateType tells the type of a certificate by its key usages, the FIEL
+// can also encipher data while the CSD only signs
+func certificateType(usage x509.KeyUsage) CertificateType {
+	switch {
+	case usage&(x509.KeyUsageDataEncipherment|x509.KeyUsageKeyAgreement) != 0:
+		return CertificateFiel
+	case usage&(x509.KeyUsageDigitalSignature|x509.KeyUsageContentCommitment) != 0:
+		return CertificateCSD
+	default:
+		return CertificateUnknown
+	}
+}
+
+// MatchesKey reports whether a private key belongs to the certificate
+func (c Certificate) MatchesKey(key crypto.Signer) bool {
+	public, ok := key.Public().(interface{ Equal(crypto.PublicKey) bool })
+	return ok && public.Equal(c.PublicKey)
+}
+
+// Expired reports whether the certificate is no longer valid at a time
+func (c Certificate) Expired(at time.Time) bool {
+	return !at.Before(c.ValidTo)
+}
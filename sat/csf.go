@@ -0,0 +1,251 @@
+package sat
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ErrNotCSF is returned by ParseCSF for texts without the data of a constancia
+var ErrNotCSF = errors.New("the text is not a constancia de situación fiscal")
+
+// CSF is the data of a constancia de situación fiscal (CSF), the document the
+// SAT issues with the registration of a taxpayer. The personas morales have a
+// legal name and the personas físicas their names and surnames.
+type CSF struct {
+	RFC           string
+	CURP          string
+	IDCIF         string
+	LegalName     string
+	GivenNames    string
+	FirstSurname  string
+	SecondSurname string
+	PostalCode    string
+	// Status is the estatus en el padrón, like ACTIVO or SUSPENDIDO
+	Status      string
+	Regimenes   []CSFRegimen
+	Obligations []CSFObligation
+}
+
+// CSFRegimen is a regimen of a taxpayer, the dates are YYYY-MM-DD and the end
+// date is empty while the regimen applies
+type CSFRegimen struct {
+	Name      string
+	StartDate string
+	EndDate   string
+}
+
+// CSFObligation is an obligation of a taxpayer, its description includes the
+// due date the SAT gives for it
+type CSFObligation struct {
+	Description string
+	StartDate   string
+	EndDate     string
+}
+
+// csfLabels match the labels of the data of a constancia, the ones that are
+// not read still end the value of the label before them on the same line
+var csfLabels = []struct {
+	key     string
+	pattern string
+}{
+	{"rfc", `RFC:`},
+	{"curp", `CURP:`},
+	{"idcif", `idCIF:`},
+	{"given_names", `Nombre\s*\(s\):`},
+	{"first_surname", `Primer Apellido:`},
+	{"second_surname", `Segundo Apellido:`},
+	{"legal_name", `Denominaci[oó]n\s*/\s*Raz[oó]n Social:`},
+	{"postal_code", `C[oó]digo Postal:`},
+	{"status", `Estatus en el padr[oó]n:`},
+	{"", `R[eé]gimen Capital:`},
+	{"", `Nombre Comercial:`},
+	{"", `Fecha inicio de operaciones:`},
+	{"", `Fecha de [uú]ltimo cambio de estado:`},
+	{"", `Tipo de Vialidad:`},
+	{"", `Nombre de Vialidad:`},
+	{"", `N[uú]mero Exterior:`},
+	{"", `N[uú]mero Interior:`},
+	{"", `Nombre de la Colonia:`},
+	{"", `Nombre de la Localidad:`},
+	{"", `Nombre del Municipio o Demarcaci[oó]n Territorial:`},
+	{"", `Nombre de la Entidad Federativa:`},
+	{"", `Entre Calle:`},
+	{"", `Y Calle:`},
+	{"", `Correo Electr[oó]nico:`},
+	{"", `Tel\. Fijo Lada:`},
+	{"", `Tel\. M[oó]vil Lada:`},
+	{"", `N[uú]mero:`},
+}
+
+var (
+	csfLabelPattern = func() *regexp.Regexp {
+		patterns := make([]string, len(csfLabels))
+		for i, l := range csfLabels {
+			patterns[i] = "(" + l.pattern + ")"
+		}
+		return regexp.MustCompile(strings.Join(patterns, "|"))
+	}()
+	// csfRowDates match the start and end dates that close a row of the tables
+	csfRowDates = regexp.MustCompile(`\s*(\d{2}/\d{2}/\d{4})(?:\s+(\d{2}/\d{2}/\d{4}))?$`)
+	// csfSection matches the titles of the sections, like "Obligaciones:"
+	csfSection = regexp.MustCompile(`^[\pL ]+:$`)
+	// csfBoilerplate matches the lines repeated on every page
+	csfBoilerplate = regexp.MustCompile(`(?i)^p[aá]gina\b|constancia de situaci[oó]n fiscal`)
+	// csfTableEnd matches the texts after the last table
+	csfTableEnd = regexp.MustCompile(`^(Sus datos personales|Cadena Original|Sello Digital)`)
+)
+
+// ParseCSF reads the data of a constancia from its text, as extracted from
+// the PDF the SAT issues with a line per line of the document
+func ParseCSF(text string) (CSF, error) {
+	lines := strings.Split(text, "\n")
+	for i := range lines {
+		lines[i] = strings.Join(strings.Fields(lines[i]), " ")
+	}
+
+	values := map[string]string{}
+	for _, line := range lines {
+		for key, value := range csfValues(line) {
+			// the first page header may repeat the data on the next pages
+			if _, ok := values[key]; !ok && value != "" {
+				values[key] = value
+			}
+		}
+	}
+
+	csf := CSF{
+		RFC:           NormalizeRFC(firstField(values["rfc"])),
+		CURP:          strings.ToUpper(firstField(values["curp"])),
+		IDCIF:         firstField(values["idcif"]),
+		LegalName:     values["legal_name"],
+		GivenNames:    values["given_names"],
+		FirstSurname:  values["first_surname"],
+		SecondSurname: values["second_surname"],
+		PostalCode:    firstField(values["postal_code"]),
+		Status:        values["status"],
+	}
+	if !RFCPattern.MatchString(csf.RFC) {
+		return CSF{}, ErrNotCSF
+	}
+
+	for _, row := range csfTable(lines, "Regímenes:") {
+		csf.Regimenes = append(csf.Regimenes, CSFRegimen{
+			Name:      strings.TrimSuffix(row.text, "."),
+			StartDate: row.start,
+			EndDate:   row.end,
+		})
+	}
+	for _, row := range csfTable(lines, "Obligaciones:") {
+		csf.Obligations = append(csf.Obligations, CSFObligation{
+			Description: row.text,
+			StartDate:   row.start,
+			EndDate:     row.end,
+		})
+	}
+
+	return csf, nil
+}
+
+// csfValues returns the values of the labels of a line, each value runs until
+// the next label or the end of the line
+func csfValues(line string) map[string]string {
+	matches := csfLabelPattern.FindAllStringSubmatchIndex(line, -1)
+	values := map[string]string{}
+	for i, m := range matches {
+		end := len(line)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+
+		// the submatch that is set tells the label
+		for j, l := range csfLabels {
+			if m[2+2*j] >= 0 {
+				if l.key != "" {
+					values[l.key] = strings.TrimSpace(line[m[1]:end])
+				}
+				break
+			}
+		}
+	}
+
+	return values
+}
+
+// firstField returns the first word of a value, for the codes that the
+// extraction may join with the text next to them
+func firstField(value string) string {
+	if fields := strings.Fields(value); len(fields) > 0 {
+		return fields[0]
+	}
+
+	return ""
+}
+
+// csfRow is a row of the tables of regimenes and obligations
+type csfRow struct {
+	text, start, end string
+}
+
+// csfTable reads the rows of the table that follows a section title. A row
+// starts on the line with its dates and the lines after it without dates
+// continue the texts that did not fit in its cells.
+func csfTable(lines []string, title string) []csfRow {
+	start := -1
+	for i, line := range lines {
+		if strings.EqualFold(line, title) {
+			start = i + 1
+			break
+		}
+	}
+	if start < 0 {
+		return nil
+	}
+
+	var rows []csfRow
+	var pending []string
+	for _, line := range lines[start:] {
+		if csfSection.MatchString(line) || csfTableEnd.MatchString(line) {
+			break
+		}
+		// the column titles and the page headers
+		if line == "" || csfBoilerplate.MatchString(line) || (strings.Contains(line, "Fecha Inicio") && !csfRowDates.MatchString(line)) {
+			continue
+		}
+
+		m := csfRowDates.FindStringSubmatchIndex(line)
+		if m == nil {
+			if len(rows) == 0 {
+				pending = append(pending, line)
+			} else {
+				rows[len(rows)-1].text += " " + line
+			}
+			continue
+		}
+
+		row := csfRow{text: strings.Join(append(pending, line[:m[0]]), " "), start: csfDate(line[m[2]:m[3]])}
+		if m[4] >= 0 {
+			row.end = csfDate(line[m[4]:m[5]])
+		}
+		pending = nil
+		rows = append(rows, row)
+	}
+
+	for i := range rows {
+		rows[i].text = strings.TrimSpace(rows[i].text)
+	}
+
+	return rows
+}
+
+// csfDate converts a DD/MM/YYYY date of a constancia to YYYY-MM-DD, the
+// invalid dates are left empty
+func csfDate(value string) string {
+	t, err := time.Parse("02/01/2006", value)
+	if err != nil {
+		return ""
+	}
+
+	return t.Format(time.DateOnly)
+}
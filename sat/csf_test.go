@@ -0,0 +1,93 @@
+package sat
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testCSF reads the text of a constancia of the testdata directory
+func testCSF(t *testing.T, name string) string {
+	t.Helper()
+
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(data)
+}
+
+func TestParseCSF(t *testing.T) {
+	fisica := testCSF(t, "csf_fisica.txt")
+	moral := testCSF(t, "csf_moral.txt")
+
+	tests := []struct {
+		name string
+		text string
+		want CSF
+		err  error
+	}{
+		{
+			name: "a persona física",
+			text: fisica,
+			want: CSF{
+				RFC: "MUGA800101ABA", CURP: "MUGA800101HDFXXX09", IDCIF: "12345678901",
+				GivenNames: "ANDRÉS", FirstSurname: "MUÑOZ", SecondSurname: "GARCIA",
+				PostalCode: "06600", Status: "ACTIVO",
+				Regimenes: []CSFRegimen{
+					{"Régimen de Incorporación Fiscal", "2014-01-01", "2021-12-31"},
+					{"Régimen de las Personas Físicas con Actividades Empresariales y Profesionales", "2010-01-01", ""},
+					{"Régimen de Sueldos y Salarios e Ingresos Asimilados a Salarios", "2022-01-01", ""},
+				},
+				Obligations: []CSFObligation{
+					{"Pago definitivo mensual de IVA. A más tardar el día 17 del mes inmediato posterior al periodo que corresponda.", "2022-01-01", ""},
+					{"Declaración anual de ISR del ejercicio A más tardar el 30 de abril del ejercicio Personas físicas. siguiente.", "2010-01-01", ""},
+				},
+			},
+		},
+		{
+			name: "a persona moral",
+			text: moral,
+			want: CSF{
+				RFC: "CBA050315QW2", IDCIF: "17060131234", LegalName: "COMERCIALIZADORA BAJÍO",
+				PostalCode: "06600", Status: "ACTIVO",
+				Regimenes: []CSFRegimen{{"Régimen General de Ley Personas Morales", "2005-03-15", ""}},
+				Obligations: []CSFObligation{
+					{"Pago definitivo mensual de IVA. A más tardar el día 17 del mes inmediato posterior al periodo que corresponda.", "2022-01-01", ""},
+					{"Declaración anual de ISR del ejercicio A más tardar el 30 de abril del ejercicio Personas físicas. siguiente.", "2010-01-01", ""},
+				},
+			},
+		},
+		{
+			name: "a constancia cut before its tables",
+			text: fisica[:strings.Index(fisica, "Datos del domicilio")],
+			want: CSF{
+				RFC: "MUGA800101ABA", CURP: "MUGA800101HDFXXX09", IDCIF: "12345678901",
+				GivenNames: "ANDRÉS", FirstSurname: "MUÑOZ", SecondSurname: "GARCIA", Status: "ACTIVO",
+			},
+		},
+		{
+			name: "a text without an RFC",
+			text: strings.ReplaceAll(fisica, "MUGA800101ABA", "MUGA8001"),
+			err:  ErrNotCSF,
+		},
+		{
+			name: "an empty text",
+			err:  ErrNotCSF,
+		},
+	}
+
+	for _, tt := range tests {
+		csf, err := ParseCSF(tt.text)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: error %v, want %v", tt.name, err, tt.err)
+			continue
+		}
+		if !reflect.DeepEqual(csf, tt.want) {
+			t.Errorf("%s: ParseCSF = %+v, want %+v", tt.name, csf, tt.want)
+		}
+	}
+}
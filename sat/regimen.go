@@ -0,0 +1,97 @@
+package sat
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// regimen is a regimen of the SAT catalog c_RegimenFiscal with the persona
+// types it accepts
+type regimen struct {
+	name     string
+	personas []PersonaType
+}
+
+// regimenes are the regimenes of the SAT catalog c_RegimenFiscal, keyed by
+// their code
+var regimenes = map[string]regimen{
+	"601": {"General de Ley Personas Morales", []PersonaType{PersonaMoral}},
+	"603": {"Personas Morales con Fines no Lucrativos", []PersonaType{PersonaMoral}},
+	"605": {"Sueldos y Salarios e Ingresos Asimilados a Salarios", []PersonaType{PersonaFisica}},
+	"606": {"Arrendamiento", []PersonaType{PersonaFisica}},
+	"607": {"Régimen de Enajenación o Adquisición de Bienes", []PersonaType{PersonaFisica}},
+	"608": {"Demás ingresos", []PersonaType{PersonaFisica}},
+	"609": {"Consolidación", []PersonaType{PersonaMoral}},
+	"610": {"Residentes en el Extranjero sin Establecimiento Permanente en México", []PersonaType{PersonaFisica, PersonaMoral}},
+	"611": {"Ingresos por Dividendos (socios y accionistas)", []PersonaType{PersonaFisica}},
+	"612": {"Personas Físicas con Actividades Empresariales y Profesionales", []PersonaType{PersonaFisica}},
+	"614": {"Ingresos por intereses", []PersonaType{PersonaFisica}},
+	"615": {"Régimen de los ingresos por obtención de premios", []PersonaType{PersonaFisica}},
+	"616": {"Sin obligaciones fiscales", []PersonaType{PersonaFisica}},
+	"620": {"Sociedades Cooperativas de Producción que optan por diferir sus ingresos", []PersonaType{PersonaMoral}},
+	"621": {"Incorporación Fiscal", []PersonaType{PersonaFisica}},
+	"622": {"Actividades Agrícolas, Ganaderas, Silvícolas y Pesqueras", []PersonaType{PersonaMoral}},
+	"623": {"Opcional para Grupos de Sociedades", []PersonaType{PersonaMoral}},
+	"624": {"Coordinados", []PersonaType{PersonaMoral}},
+	"625": {"Régimen de las Actividades Empresariales con ingresos a través de Plataformas Tecnológicas", []PersonaType{PersonaFisica}},
+	"626": {"Régimen Simplificado de Confianza", []PersonaType{PersonaFisica, PersonaMoral}},
+	"628": {"Hidrocarburos", []PersonaType{PersonaMoral}},
+	"629": {"De los Regímenes Fiscales Preferentes y de las Empresas Multinacionales", []PersonaType{PersonaFisica}},
+	"630": {"Enajenación de acciones en bolsa de valores", []PersonaType{PersonaFisica}},
+}
+
+// regimenCodePrefix matches a regimen named with its code first, like
+// "601 - General de Ley Personas Morales"
+var regimenCodePrefix = regexp.MustCompile(`^(\d{3})\b`)
+
+// RegimenCode finds the code of the SAT catalog of a regimen of the catalog of
+// the database: its id when it is a code of the SAT, else the code its name
+// starts with or the one of the regimen of the SAT with its name
+func RegimenCode(id, name string) (string, bool) {
+	if _, ok := regimenes[id]; ok {
+		return id, true
+	}
+
+	if m := regimenCodePrefix.FindStringSubmatch(strings.TrimSpace(name)); m != nil {
+		if _, ok := regimenes[m[1]]; ok {
+			return m[1], true
+		}
+	}
+
+	folded := foldName(name)
+	for code, r := range regimenes {
+		if folded != "" && foldName(r.name) == folded {
+			return code, true
+		}
+	}
+
+	return "", false
+}
+
+// RegimenAllows reports whether a regimen of the SAT accepts a persona type,
+// an unknown code accepts none
+func RegimenAllows(code string, persona PersonaType) bool {
+	for _, p := range regimenes[code].personas {
+		if p == persona {
+			return true
+		}
+	}
+
+	return false
+}
+
+// foldName lowercases a name and removes its accents and repeated spaces to
+// compare it with the names of the SAT catalog
+func foldName(name string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(name) {
+		if !unicode.Is(unicode.Mn, r) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+
+	return strings.Join(strings.Fields(b.String()), " ")
+}
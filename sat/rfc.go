@@ -0,0 +1,118 @@
+// Package sat implements the rules of the Mexican tax administration (SAT)
+// the API needs, starting with the RFC
+package sat
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// PersonaType tells whether a taxpayer is an individual or a company
+type PersonaType string
+
+const (
+	PersonaFisica PersonaType = "fisica"
+	PersonaMoral  PersonaType = "moral"
+)
+
+// RFCPattern matches the structure of an RFC: 4 letters for personas físicas or
+// 3 for personas morales, the date of birth or incorporation, the homoclave and
+// the check digit
+var RFCPattern = regexp.MustCompile(`^([A-ZÑ&]{3,4})([0-9]{6})([1-9A-NP-Z]{2})([0-9A])$`)
+
+// Generic RFCs used by the SAT for the general public and foreigners, their
+// homoclave 00 does not match RFCPattern
+const (
+	RFCPublicoGeneral = "XAXX010101000"
+	RFCExtranjero     = "XEXX010101000"
+)
+
+// IsGenericRFC tells whether a normalized RFC is one of the generic RFCs
+func IsGenericRFC(rfc string) bool {
+	return rfc == RFCPublicoGeneral || rfc == RFCExtranjero
+}
+
+// Errors returned by ParseRFC
+var (
+	ErrRFCFormat     = errors.New("rfc must have 3 or 4 letters, a YYMMDD date, a 2 character homoclave and a check digit")
+	ErrRFCDate       = errors.New("rfc has an invalid date")
+	ErrRFCCheckDigit = errors.New("rfc has an invalid check digit")
+)
+
+// checkDigitValues are the values of the characters in the check digit algorithm
+var checkDigitValues = func() map[rune]int {
+	values := map[rune]int{}
+	for i, r := range "0123456789ABCDEFGHIJKLMN&OPQRSTUVWXYZ Ñ" {
+		values[r] = i
+	}
+	return values
+}()
+
+// RFC is a parsed RFC
+type RFC struct {
+	Value   string
+	Persona PersonaType
+	Date    time.Time
+}
+
+// NormalizeRFC uppercases an RFC and removes the spaces and dashes people type in it
+func NormalizeRFC(rfc string) string {
+	return strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(rfc)))
+}
+
+// ParseRFC validates the structure, date and check digit of a normalized RFC
+// and detects its persona type from its length
+func ParseRFC(rfc string) (RFC, error) {
+	if IsGenericRFC(rfc) {
+		return RFC{Value: rfc, Persona: PersonaFisica, Date: time.Date(2001, time.January, 1, 0, 0, 0, 0, time.UTC)}, nil
+	}
+
+	parts := RFCPattern.FindStringSubmatch(rfc)
+	if parts == nil {
+		return RFC{}, ErrRFCFormat
+	}
+
+	persona := PersonaMoral
+	if len([]rune(parts[1])) == 4 {
+		persona = PersonaFisica
+	}
+
+	date, err := time.Parse("060102", parts[2])
+	if err != nil {
+		return RFC{}, ErrRFCDate
+	}
+
+	if CheckDigit(rfc) != []rune(rfc)[len([]rune(rfc))-1] {
+		return RFC{}, ErrRFCCheckDigit
+	}
+
+	return RFC{Value: rfc, Persona: persona, Date: date}, nil
+}
+
+// CheckDigit computes the check digit of a complete RFC, its last character is
+// ignored and the RFCs of personas morales are padded with a leading space
+func CheckDigit(rfc string) rune {
+	runes := []rune(rfc)
+	if len(runes) == 12 {
+		runes = append([]rune{' '}, runes...)
+	}
+	if len(runes) < 12 {
+		return 0
+	}
+
+	sum := 0
+	for i, r := range runes[:12] {
+		sum += checkDigitValues[r] * (13 - i)
+	}
+
+	switch digit := 11 - sum%11; digit {
+	case 11:
+		return '0'
+	case 10:
+		return 'A'
+	default:
+		return rune('0' + digit)
+	}
+}
@@ -0,0 +1,112 @@
+package sat
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseRFC(t *testing.T) {
+	tests := []struct {
+		rfc     string
+		persona PersonaType
+		date    string
+		err     error
+	}{
+		{"TOPA850203KJA", PersonaFisica, "1985-02-03", nil},
+		{"MUGA800101ABA", PersonaFisica, "1980-01-01", nil},
+		{"FPA101010HJ9", PersonaMoral, "2010-10-10", nil},
+		{"TNU120601RT4", PersonaMoral, "2012-06-01", nil},
+		{RFCPublicoGeneral, PersonaFisica, "2001-01-01", nil},
+		{RFCExtranjero, PersonaFisica, "2001-01-01", nil},
+		{"TOPA850203KJ1", "", "", ErrRFCCheckDigit},
+		{"FPA101010HJ8", "", "", ErrRFCCheckDigit},
+		{"TOPA851303KJA", "", "", ErrRFCDate},
+		{"TOPA850230KJA", "", "", ErrRFCDate},
+		{"TOPA8502030JA", "", "", ErrRFCFormat},
+		{"TOPA850203KJ", "", "", ErrRFCFormat},
+		{"T0PA850203KJA", "", "", ErrRFCFormat},
+		{"XAXX010101001", "", "", ErrRFCFormat},
+		{"", "", "", ErrRFCFormat},
+	}
+
+	for _, tt := range tests {
+		rfc, err := ParseRFC(tt.rfc)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("ParseRFC(%q) error %v, want %v", tt.rfc, err, tt.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if rfc.Persona != tt.persona || rfc.Date.Format("2006-01-02") != tt.date {
+			t.Errorf("ParseRFC(%q) = %s born %s, want %s born %s", tt.rfc, rfc.Persona, rfc.Date.Format("2006-01-02"), tt.persona, tt.date)
+		}
+	}
+}
+
+func TestCheckDigit(t *testing.T) {
+	tests := map[string]rune{
+		"TOPA850203KJA": 'A',
+		"FPA101010HJ9":  '9',
+		"CBA050315QW2":  '2',
+		"RAOL900712PL7": '7',
+		"TOPA85":        0,
+	}
+
+	for rfc, want := range tests {
+		if got := CheckDigit(rfc); got != want {
+			t.Errorf("CheckDigit(%q) = %q, want %q", rfc, got, want)
+		}
+	}
+}
+
+func TestNormalizeRFC(t *testing.T) {
+	if got := NormalizeRFC(" topa-850203 kja "); got != "TOPA850203KJA" {
+		t.Errorf("NormalizeRFC = %q, want TOPA850203KJA", got)
+	}
+}
+
+func TestRegimenCode(t *testing.T) {
+	tests := []struct {
+		id, name string
+		code     string
+		ok       bool
+	}{
+		{"601", "", "601", true},
+		{"1", "601 - General de Ley Personas Morales", "601", true},
+		{"7", "Regimen Simplificado de Confianza", "626", true},
+		{"8", "  personas  físicas con actividades empresariales y profesionales", "612", true},
+		{"999", "", "", false},
+		{"9", "Régimen inventado", "", false},
+		{"10", "", "", false},
+	}
+
+	for _, tt := range tests {
+		code, ok := RegimenCode(tt.id, tt.name)
+		if code != tt.code || ok != tt.ok {
+			t.Errorf("RegimenCode(%q, %q) = %q, %v, want %q, %v", tt.id, tt.name, code, ok, tt.code, tt.ok)
+		}
+	}
+}
+
+func TestRegimenAllows(t *testing.T) {
+	tests := []struct {
+		code    string
+		persona PersonaType
+		allows  bool
+	}{
+		{"601", PersonaMoral, true},
+		{"601", PersonaFisica, false},
+		{"612", PersonaFisica, true},
+		{"612", PersonaMoral, false},
+		{"626", PersonaFisica, true},
+		{"626", PersonaMoral, true},
+		{"999", PersonaFisica, false},
+	}
+
+	for _, tt := range tests {
+		if got := RegimenAllows(tt.code, tt.persona); got != tt.allows {
+			t.Errorf("RegimenAllows(%q, %s) = %v, want %v", tt.code, tt.persona, got, tt.allows)
+		}
+	}
+}
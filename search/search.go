@@ -0,0 +1,232 @@
+// Package search ranks and highlights texts for the fuzzy client search. It
+// follows pg_trgm so the backends without the extension rank like Postgres.
+package search
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Threshold is the minimum score of a match, the default similarity threshold of pg_trgm
+const Threshold = 0.3
+
+// Normalize lowercases a text and removes its accents, "Muñoz" becomes "munoz"
+func Normalize(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		b.WriteString(normalizeRune(r))
+	}
+
+	return b.String()
+}
+
+// normalizeRune lowercases a rune and drops its combining marks
+func normalizeRune(r rune) string {
+	var b strings.Builder
+	for _, d := range norm.NFD.String(string(r)) {
+		if unicode.Is(unicode.Mn, d) {
+			continue
+		}
+		b.WriteRune(unicode.ToLower(d))
+	}
+
+	return b.String()
+}
+
+// isWordRune reports whether a rune is part of a word
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// words splits a normalized text in its alphanumeric words
+func words(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !isWordRune(r)
+	})
+}
+
+// trigrams returns the trigrams of the words of a text, each word is padded
+// with two spaces before and one after like pg_trgm does
+func trigrams(ws []string) map[string]bool {
+	set := map[string]bool{}
+	for _, w := range ws {
+		padded := []rune("  " + w + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = true
+		}
+	}
+
+	return set
+}
+
+// similarity is the share of trigrams two sets of words have in common
+func similarity(a, b []string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+
+	common := 0
+	for t := range ta {
+		if tb[t] {
+			common++
+		}
+	}
+
+	return float64(common) / float64(len(ta)+len(tb)-common)
+}
+
+// Similarity returns the trigram similarity of two texts, from 0 to 1
+func Similarity(a, b string) float64 {
+	return similarity(words(Normalize(a)), words(Normalize(b)))
+}
+
+// Score ranks how well a text matches a query, from 0 to 1. The query is
+// compared with every run of as many consecutive words of the text, like the
+// word_similarity of pg_trgm, and a text that contains the query scores 1.
+func Score(query, text string) float64 {
+	q, t := Normalize(query), Normalize(text)
+	if strings.TrimSpace(q) == "" {
+		return 0
+	}
+	if strings.Contains(t, strings.TrimSpace(q)) {
+		return 1
+	}
+
+	qw, tw := words(q), words(t)
+	if len(qw) == 0 || len(tw) == 0 {
+		return 0
+	}
+
+	n := len(qw)
+	if n > len(tw) {
+		n = len(tw)
+	}
+
+	best := 0.0
+	for i := 0; i+n <= len(tw); i++ {
+		if s := similarity(qw, tw[i:i+n]); s > best {
+			best = s
+		}
+	}
+
+	return best
+}
+
+// Highlight escapes a text for HTML and wraps the parts that contain a word of
+// the query in <mark> tags, accents and case are ignored
+func Highlight(text, query string) string {
+	original := []rune(text)
+
+	// normalized text with the position of each of its runes in the original
+	var normalized []rune
+	var positions []int
+	for i, r := range original {
+		for _, n := range normalizeRune(r) {
+			normalized = append(normalized, n)
+			positions = append(positions, i)
+		}
+	}
+
+	// single letters would mark most of the text
+	marked := make([]bool, len(original))
+	for _, w := range words(Normalize(query)) {
+		needle := []rune(w)
+		if len(needle) < 2 {
+			continue
+		}
+		found := false
+		for i := 0; i+len(needle) <= len(normalized); i++ {
+			if string(normalized[i:i+len(needle)]) != w {
+				continue
+			}
+			found = true
+			for j := i; j < i+len(needle); j++ {
+				marked[positions[j]] = true
+			}
+		}
+
+		// misspelled words mark the whole words of the text they resemble
+		for start := 0; !found && start < len(normalized); {
+			if !isWordRune(normalized[start]) {
+				start++
+				continue
+			}
+			end := start
+			for end < len(normalized) && isWordRune(normalized[end]) {
+				end++
+			}
+			if similarity([]string{w}, []string{string(normalized[start:end])}) >= Threshold {
+				for j := start; j < end; j++ {
+					marked[positions[j]] = true
+				}
+			}
+			start = end
+		}
+	}
+
+	var b strings.Builder
+	for i, r := range original {
+		if marked[i] && (i == 0 || !marked[i-1]) {
+			b.WriteString("<mark>")
+		}
+		b.WriteString(html.EscapeString(string(r)))
+		if marked[i] && (i == len(original)-1 || !marked[i+1]) {
+			b.WriteString("</mark>")
+		}
+	}
+
+	return b.String()
+}
+
+// Match is a ranked search candidate
+type Match struct {
+	Index int
+	Score float64
+}
+
+// Rank scores the candidates against the query and returns the ones above the
+// threshold, best first, keeping the order of the candidates on ties
+func Rank(query string, candidates [][]string) []Match {
+	var matches []Match
+	for i, fields := range candidates {
+		best := 0.0
+		for _, f := range fields {
+			if s := Score(query, f); s > best {
+				best = s
+			}
+		}
+		if best >= Threshold {
+			matches = append(matches, Match{Index: i, Score: best})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+
+	return matches
+}
+
+// legalSuffixes are the company type abbreviations ignored when comparing names
+var legalSuffixes = []string{
+	"s de rl de cv", "sapi de cv", "sab de cv", "sa de cv", "s en c", "s de rl", "sapi", "sas", "sa", "sc", "ac", "de cv",
+}
+
+// NameKey reduces a client name to compare it with others, ignoring accents,
+// punctuation and the company type, "Muñoz, S.A. de C.V." becomes "munoz"
+func NameKey(name string) string {
+	key := strings.Join(words(strings.ReplaceAll(Normalize(name), ".", "")), " ")
+	for _, suffix := range legalSuffixes {
+		if trimmed := strings.TrimSuffix(key, " "+suffix); trimmed != key {
+			key = trimmed
+			break
+		}
+	}
+
+	return key
+}
@@ -0,0 +1,85 @@
+// Package secret encrypts the files of the clients the API keeps, like the
+// certificates of their FIEL, with AES-256-GCM
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// KeySize is the length of the key in bytes, it is configured base64 encoded
+const KeySize = 32
+
+// ErrSealed is returned for data that was not sealed with the key
+var ErrSealed = errors.New("the data was not sealed with the key")
+
+// Box seals and opens data with a key
+type Box struct {
+	aead cipher.AEAD
+}
+
+// NewBox creates a box from a base64 encoded key, an empty key returns a nil
+// box since encrypting the files is optional
+func NewBox(key string) (*Box, error) {
+	if key == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("decoding encryption key: %w", err)
+	}
+	if len(raw) != KeySize {
+		return nil, fmt.Errorf("the encryption key must have %d bytes, it has %d", KeySize, len(raw))
+	}
+
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Box{aead: aead}, nil
+}
+
+// GenerateKey returns a random key encoded like ENCRYPTION_KEY expects it
+func GenerateKey() (string, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// Seal encrypts data, the random nonce is prepended to the result
+func (b *Box) Seal(data []byte) ([]byte, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return b.aead.Seal(nonce, nonce, data, nil), nil
+}
+
+// Open decrypts data returned by Seal
+func (b *Box) Open(sealed []byte) ([]byte, error) {
+	n := b.aead.NonceSize()
+	if len(sealed) < n {
+		return nil, ErrSealed
+	}
+
+	data, err := b.aead.Open(nil, sealed[:n], sealed[n:], nil)
+	if err != nil {
+		return nil, ErrSealed
+	}
+
+	return data, nil
+}
@@ -3,9 +3,7 @@ package database
 import (
 	"contabi-be/models"
 	"database/sql"
-	"fmt"
-
-	"github.com/lib/pq"
+	"time"
 )
 
 // AccountancyService
@@ -36,7 +34,7 @@ func (as *AccountancyService) GetClientsBySupervisor(supervisorID string) ([]mod
 			emisor_name
 		FROM client_info_view
 		WHERE supervisor_id = $1
-		AND active = true
+		AND ` + servicedClients + `
 	`
 	rows, err := as.db.Query(q, supervisorID)
 	if err != nil {
@@ -70,7 +68,7 @@ func (as *AccountancyService) GetClientsBySupervisor(supervisorID string) ([]mod
 	return clients, nil
 }
 
-// GetClientAssignmentsMatrix retrieves, for all active clients, the list of assignment types and whether each client has each assignment
+// GetClientAssignmentsMatrix retrieves, for all onboarding and active clients, the list of assignment types and whether each client has each assignment
 func (as *AccountancyService) GetClientAssignmentsMatrix() ([]models.ClientAssignmentMatrixRow, error) {
 	q := `
 		SELECT
@@ -83,7 +81,7 @@ func (as *AccountancyService) GetClientAssignmentsMatrix() ([]models.ClientAssig
 		CROSS JOIN accountancy_types at
 		LEFT JOIN client_assignments_types cat
 		  ON c.id = cat.client_id AND at.id = cat.assignment_type_id
-		WHERE c.active = TRUE
+		WHERE c.state IN ('onboarding', 'active')
 		ORDER BY c.name, at.name;
 	`
 	rows, err := as.db.Query(q)
@@ -109,8 +107,25 @@ func (as *AccountancyService) GetClientAssignmentsMatrix() ([]models.ClientAssig
 	return result, nil
 }
 
-// UpdateClientAssignments updates assignments of a client according to the state
-func (as *AccountancyService) UpdateClientAssignments(clientID string, assignments []models.AssignmentSelection) error {
+// UpdateClientAssignments updates assignments of a client according to the
+// state and records the added and removed ones in the timeline, it fails when
+// the client is no longer in the version given
+func (as *AccountancyService) UpdateClientAssignments(clientID string, assignments []models.AssignmentSelection, version models.ClientVersion) error {
+	tx, err := as.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := claimAssignmentsVersion(tx, clientID, version); err != nil {
+		return err
+	}
+
+	before, err := activeAssignments(tx, clientID)
+	if err != nil {
+		return err
+	}
+
 	var toDelete []int
 	var toAdd []int
 	for _, a := range assignments {
@@ -121,27 +136,66 @@ func (as *AccountancyService) UpdateClientAssignments(clientID string, assignmen
 		}
 	}
 
-	if len(toDelete) > 0 {
-		_, err := as.db.Exec(`
+	for _, assignmentTypeID := range toDelete {
+		_, err := tx.Exec(`
 			DELETE FROM client_assignments_types
-			WHERE client_id = $1 AND assignment_type_id = ANY($2)
-		`, clientID, pq.Array(toDelete))
+			WHERE client_id = $1 AND assignment_type_id = $2
+		`, clientID, assignmentTypeID)
 		if err != nil {
-			return err
+			return mapError(err, "client assignment type")
 		}
 	}
 
 	for _, assignmentTypeID := range toAdd {
-		_, err := as.db.Exec(`
+		_, err := tx.Exec(`
 			INSERT INTO client_assignments_types (client_id, assignment_type_id)
 			VALUES ($1, $2)
 			ON CONFLICT DO NOTHING
 		`, clientID, assignmentTypeID)
 		if err != nil {
+			return mapError(err, "client assignment type")
+		}
+	}
+
+	after, err := activeAssignments(tx, clientID)
+	if err != nil {
+		return err
+	}
+	if changes := models.AccountancyAssignmentChanges(before, after); changes != "" {
+		event := models.ClientEvent{ClientID: clientID, Type: models.EventAssignments, Message: changes}
+		if _, err := createClientEvent(tx, event); err != nil {
 			return err
 		}
 	}
-	return nil
+
+	return tx.Commit()
+}
+
+// activeAssignments reads the accountancy types assigned to a client
+func activeAssignments(tx *sql.Tx, clientID string) ([]models.AccountancyType, error) {
+	q := `
+		SELECT at.id, at.name
+		FROM client_assignments_types cat
+		JOIN accountancy_types at ON cat.assignment_type_id = at.id
+		WHERE cat.client_id = $1
+		ORDER BY at.name ASC
+	`
+	rows, err := tx.Query(q, clientID)
+	if err != nil {
+		return nil, mapError(err, "client assignment type")
+	}
+	defer rows.Close()
+
+	var types []models.AccountancyType
+	for rows.Next() {
+		var at models.AccountancyType
+		if err := rows.Scan(&at.ID, &at.Name); err != nil {
+			return nil, err
+		}
+		types = append(types, at)
+	}
+
+	return types, rows.Err()
 }
 
 // GetClientsByResonsible retrieves all the clients of a specific responsible
@@ -162,7 +216,7 @@ func (as *AccountancyService) GetClientsByResonsible(responsibleID string) ([]mo
 			emisor_name
 		FROM client_info_view
 		WHERE responsible_id = $1
-		AND active = true
+		AND ` + servicedClients + `
 	`
 	rows, err := as.db.Query(q, responsibleID)
 	if err != nil {
@@ -196,8 +250,9 @@ func (as *AccountancyService) GetClientsByResonsible(responsibleID string) ([]mo
 	return clients, nil
 }
 
-// CreateClientAccountancyStatusWithAssignments creates a new monthly record for a client
-func (as *AccountancyService) CreateClientAccountancyStatusWithAssignments(status models.ClientAccountancyStatus, assignments []models.ClientAccountancyAssignment) error {
+// CreateClientAccountancyStatusWithAssignments creates a new monthly record
+// for a client and records the event of it in the timeline
+func (as *AccountancyService) CreateClientAccountancyStatusWithAssignments(status models.ClientAccountancyStatus, assignments []models.ClientAccountancyAssignment, event models.ClientEvent) error {
 	tx, err := as.db.Begin()
 	if err != nil {
 		return err
@@ -217,7 +272,7 @@ func (as *AccountancyService) CreateClientAccountancyStatusWithAssignments(statu
 	).Scan(&statusID)
 	if err != nil {
 		tx.Rollback()
-		return err
+		return mapError(err, "accountancy status")
 	}
 
 	for _, a := range assignments {
@@ -229,15 +284,22 @@ func (as *AccountancyService) CreateClientAccountancyStatusWithAssignments(statu
 		)
 		if err != nil {
 			tx.Rollback()
-			return err
+			return mapError(err, "accountancy assignment")
 		}
 	}
 
+	if _, err = createClientEvent(tx, event); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	return tx.Commit()
 }
 
-// UpdateClientAccountancyStatusWithAssignments updates an existing monthly record for a client
-func (as *AccountancyService) UpdateClientAccountancyStatusWithAssignments(statusID int, clientID string, status models.ClientAccountancyStatus, assignments []models.ClientAccountancyAssignment) error {
+// UpdateClientAccountancyStatusWithAssignments updates an existing monthly
+// record for a client when it is still in the version given and records the
+// event of it in the timeline
+func (as *AccountancyService) UpdateClientAccountancyStatusWithAssignments(statusID int, clientID string, status models.ClientAccountancyStatus, assignments []models.ClientAccountancyAssignment, version int, event models.ClientEvent) error {
 	tx, err := as.db.Begin()
 	if err != nil {
 		return err
@@ -251,13 +313,13 @@ func (as *AccountancyService) UpdateClientAccountancyStatusWithAssignments(statu
 	// Update main status, validating that it belongs to the client
 	result, err := tx.Exec(
 		`UPDATE client_accountancy_status 
-		 SET due_date = $1, observaciones = $2
-		 WHERE id = $3 AND client_id = $4`,
-		status.DueDate, status.Observacion, statusID, clientID,
+		 SET due_date = $1, observaciones = $2, version = version + 1
+		 WHERE id = $3 AND client_id = $4 AND closed_at IS NULL AND version = $5`,
+		status.DueDate, status.Observacion, statusID, clientID, version,
 	)
 	if err != nil {
 		tx.Rollback()
-		return err
+		return mapError(err, "accountancy status")
 	}
 
 	// Verify that at least one row was affected
@@ -268,7 +330,16 @@ func (as *AccountancyService) UpdateClientAccountancyStatusWithAssignments(statu
 	}
 	if rowsAffected == 0 {
 		tx.Rollback()
-		return fmt.Errorf("status not found or does not belong to the specified client")
+		var closed bool
+		var current int
+		err = as.db.QueryRow(`SELECT closed_at IS NOT NULL, version FROM client_accountancy_status WHERE id = $1 AND client_id = $2`, statusID, clientID).Scan(&closed, &current)
+		if err == nil && closed {
+			return models.NewConflictError("The accountancy month was closed when the client left", nil)
+		}
+		if err == nil {
+			return models.NewPreconditionFailedError("The accountancy status changed since it was read", map[string]string{"etag": models.StatusETag(current)})
+		}
+		return models.NewNotFoundError("accountancy status not found or does not belong to the specified client")
 	}
 
 	// Actualizar las asignaciones
@@ -282,10 +353,15 @@ func (as *AccountancyService) UpdateClientAccountancyStatusWithAssignments(statu
 		)
 		if err != nil {
 			tx.Rollback()
-			return err
+			return mapError(err, "accountancy assignment")
 		}
 	}
 
+	if _, err = createClientEvent(tx, event); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	return tx.Commit()
 }
 
@@ -319,7 +395,9 @@ func (as *AccountancyService) GetClientAccountancyHistory(clientID string) (mode
 			, client_id
 			, month
 			, due_date
-			, observaciones 
+			, observaciones
+			, version
+			, closed_at
 		FROM client_accountancy_status 
 		WHERE client_id = $1 
 		ORDER BY month DESC
@@ -332,9 +410,13 @@ func (as *AccountancyService) GetClientAccountancyHistory(clientID string) (mode
 
 	for rows.Next() {
 		var status models.ClientAccountancyStatus
-		if err := rows.Scan(&status.ID, &status.ClientID, &status.Month, &status.DueDate, &status.Observacion); err != nil {
+		var closedAt sql.NullTime
+		if err := rows.Scan(&status.ID, &status.ClientID, &status.Month, &status.DueDate, &status.Observacion, &status.Version, &closedAt); err != nil {
 			return result, err
 		}
+		if closedAt.Valid {
+			status.ClosedAt = closedAt.Time.UTC().Format(time.RFC3339)
+		}
 
 		// Gets assignmentsfor this status, only the actives for the client
 		qAssign := `
@@ -376,10 +458,23 @@ func (as *AccountancyService) GetClientAccountancyHistory(clientID string) (mode
 	return result, nil
 }
 
-// GetAllClients retrieves all the clients
-func (as *AccountancyService) GetAllClients() ([]models.AccountancyClientInfo, error) {
+// GetAllClients retrieves a page of the clients whose accountancy is worked on
+func (as *AccountancyService) GetAllClients(params models.ClientListParams) (models.AccountancyClientInfoPage, error) {
+	page := models.AccountancyClientInfoPage{Page: params.Page, PageSize: params.PageSize, Items: []models.AccountancyClientInfo{}}
+
+	where, args := clientListFilters(params)
+	if where == "" {
+		where = " WHERE " + servicedClients
+	} else {
+		where += " AND " + servicedClients
+	}
+
+	if err := as.db.QueryRow(`SELECT COUNT(*) FROM client_info_view`+where, args...).Scan(&page.Total); err != nil {
+		return page, mapError(err, "clients")
+	}
+
 	q := `
-		SELECT DISTINCT
+		SELECT
 			id,
 			name,
 			rfc,
@@ -394,16 +489,14 @@ func (as *AccountancyService) GetAllClients() ([]models.AccountancyClientInfo, e
 			responsible_name,
 			emisor_id,
 			emisor_name
-		FROM client_info_view
-		WHERE active = true
-	`
-	rows, err := as.db.Query(q)
+		FROM client_info_view` + where + clientListOrder(params, len(args))
+
+	rows, err := as.db.Query(q, append(args, clientListPage(params)...)...)
 	if err != nil {
-		return nil, err
+		return page, mapError(err, "clients")
 	}
 	defer rows.Close()
 
-	var clients []models.AccountancyClientInfo
 	for rows.Next() {
 		var client models.AccountancyClientInfo
 		if err := rows.Scan(
@@ -422,25 +515,53 @@ func (as *AccountancyService) GetAllClients() ([]models.AccountancyClientInfo, e
 			&client.EmisorID,
 			&client.EmisorName,
 		); err != nil {
-			return clients, err
+			return page, err
 		}
 
-		clients = append(clients, client)
+		page.Items = append(page.Items, client)
 	}
 
-	return clients, nil
+	return page, rows.Err()
 }
 
-// GetAllClients retrieves all the clients
-func (as *AccountancyService) UpdateClientResponsible(clientID string, responsibleID string) error {
+// UpdateClientResponsible updates the responsible of a client, keeps the
+// previous one in the assignment history and records the change in the
+// timeline, it fails when the client is no longer in the version given
+func (as *AccountancyService) UpdateClientResponsible(clientID string, responsibleID string, version models.ClientVersion) error {
+	tx, err := as.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := claimAssignmentsVersion(tx, clientID, version); err != nil {
+		return err
+	}
+
+	before, err := assignmentNames(tx, clientID)
+	if err != nil {
+		return err
+	}
+
 	q := `
 		UPDATE client_assignments
 		SET responsible_id = $1
 		WHERE client_id = $2
 	`
-	_, err := as.db.Exec(q, responsibleID, clientID)
+	result, err := tx.Exec(q, responsibleID, clientID)
 	if err != nil {
+		return mapError(err, "client assignments")
+	}
+	if err := checkAffected(result, "client assignments"); err != nil {
 		return err
 	}
-	return nil
+
+	if err := saveAssignmentPeriod(tx, clientID); err != nil {
+		return err
+	}
+	if err := recordAssignmentChanges(tx, clientID, before); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
@@ -0,0 +1,141 @@
+package database
+
+import (
+	"contabi-be/models"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// assignmentPeriodsQuery selects the assignment periods with the names of the
+// client, the users and the emisor
+const assignmentPeriodsQuery = `
+	SELECT
+		h.client_id
+		, c.name
+		, h.supervisor_id
+		, COALESCE(s.username, '')
+		, h.responsible_id
+		, COALESCE(r.username, '')
+		, h.emisor_id
+		, COALESCE(e.name, '')
+		, CAST(h.valid_from AS TEXT)
+		, CAST(h.valid_to AS TEXT)
+	FROM client_assignment_history h
+	JOIN clients c ON c.id = h.client_id
+	LEFT JOIN users s ON s.id = h.supervisor_id
+	LEFT JOIN users r ON r.id = h.responsible_id
+	LEFT JOIN emisors e ON e.id = h.emisor_id
+`
+
+// GetAssignmentHistory retrieves the assignment periods of a client, the
+// newest first
+func (cs *ClientsService) GetAssignmentHistory(clientID string) ([]models.AssignmentPeriod, error) {
+	q := assignmentPeriodsQuery + `
+		WHERE h.client_id = $1
+		ORDER BY h.valid_to IS NULL DESC, h.valid_to DESC, h.id DESC
+	`
+
+	return cs.queryAssignmentPeriods(q, clientID)
+}
+
+// GetAssignmentsAsOf retrieves the assignments every client had on a day,
+// of a supervisor or a responsible when the params have them
+func (cs *ClientsService) GetAssignmentsAsOf(params models.AssignmentsAsOfParams) ([]models.AssignmentPeriod, error) {
+	q := assignmentPeriodsQuery + `
+		WHERE (h.valid_from IS NULL OR h.valid_from <= $1)
+		AND (h.valid_to IS NULL OR h.valid_to > $1)
+		AND ($2 = '' OR h.supervisor_id = $2)
+		AND ($3 = '' OR h.responsible_id = $3)
+		ORDER BY LOWER(c.name) ASC
+	`
+
+	return cs.queryAssignmentPeriods(q, params.AsOf, params.SupervisorID, params.ResponsibleID)
+}
+
+// queryAssignmentPeriods runs a query of assignmentPeriodsQuery
+func (cs *ClientsService) queryAssignmentPeriods(q string, args ...any) ([]models.AssignmentPeriod, error) {
+	rows, err := cs.db.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	periods := []models.AssignmentPeriod{}
+	for rows.Next() {
+		var p models.AssignmentPeriod
+		var validFrom, validTo sql.NullString
+		if err := rows.Scan(
+			&p.ClientID,
+			&p.ClientName,
+			&p.SupervisorID,
+			&p.SupervisorName,
+			&p.ResponsibleID,
+			&p.ResponsibleName,
+			&p.EmisorID,
+			&p.EmisorName,
+			&validFrom,
+			&validTo,
+		); err != nil {
+			return periods, err
+		}
+		p.ValidFrom = validFrom.String
+		p.ValidTo = validTo.String
+
+		periods = append(periods, p)
+	}
+
+	return periods, rows.Err()
+}
+
+// saveAssignmentPeriod opens a period with the assignments stored in
+// client_assignments when they differ from the current period, which is
+// closed the day before. A period opened the same day is replaced instead.
+func saveAssignmentPeriod(tx *sql.Tx, clientID string) error {
+	day := time.Now().Format(time.DateOnly)
+
+	q := `
+		SELECT
+			h.id
+			, CAST(h.valid_from AS TEXT)
+			, h.supervisor_id = a.supervisor_id AND h.responsible_id = a.responsible_id AND h.emisor_id = a.emisor_id
+		FROM client_assignment_history h
+		JOIN client_assignments a ON a.client_id = h.client_id
+		WHERE h.client_id = $1 AND h.valid_to IS NULL
+	`
+
+	var id int64
+	var validFrom sql.NullString
+	var unchanged bool
+	err := tx.QueryRow(q, clientID).Scan(&id, &validFrom, &unchanged)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return err
+	case unchanged:
+		return nil
+	case validFrom.String == day:
+		replace := `
+			UPDATE client_assignment_history
+			SET supervisor_id = a.supervisor_id, responsible_id = a.responsible_id, emisor_id = a.emisor_id
+			FROM client_assignments a
+			WHERE a.client_id = client_assignment_history.client_id AND client_assignment_history.id = $1
+		`
+		_, err := tx.Exec(replace, id)
+		return mapError(err, "client assignment history")
+	default:
+		if _, err := tx.Exec(`UPDATE client_assignment_history SET valid_to = $1 WHERE id = $2`, day, id); err != nil {
+			return mapError(err, "client assignment history")
+		}
+	}
+
+	insert := `
+		INSERT INTO client_assignment_history (client_id, supervisor_id, responsible_id, emisor_id, valid_from)
+		SELECT client_id, supervisor_id, responsible_id, emisor_id, $2
+		FROM client_assignments
+		WHERE client_id = $1
+	`
+	_, err = tx.Exec(insert, clientID, day)
+
+	return mapError(err, "client assignment history")
+}
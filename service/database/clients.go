@@ -2,6 +2,7 @@ package database
 
 import (
 	"contabi-be/models"
+	"contabi-be/search"
 	"database/sql"
 )
 
@@ -58,6 +59,7 @@ func (cs *ClientsService) GetClientInfo(clientID string) (models.ClientInfo, err
 			fiel_expiration,
 			monthly_fee,
 			active,
+			` + clientState("client_info_view") + `,
 			regimen_id,
 			regimen_name,
 			supervisor_id,
@@ -73,6 +75,9 @@ func (cs *ClientsService) GetClientInfo(clientID string) (models.ClientInfo, err
 		WHERE id = $1
 	`
 
+	var lpm sql.NullString
+	var lpd sql.NullString
+	var ua sql.NullString
 	row := cs.db.QueryRow(q, clientID)
 	if err := row.Scan(
 		&client.ID,
@@ -83,6 +88,7 @@ func (cs *ClientsService) GetClientInfo(clientID string) (models.ClientInfo, err
 		&client.FielExpiration,
 		&client.MonthlyFee,
 		&client.Active,
+		&client.State,
 		&client.RegimenID,
 		&client.RegimenName,
 		&client.SupervisorID,
@@ -91,11 +97,22 @@ func (cs *ClientsService) GetClientInfo(clientID string) (models.ClientInfo, err
 		&client.ResponsibleName,
 		&client.EmisorID,
 		&client.EmisorName,
-		&client.LastPaymentMonth,
-		&client.LastPaymentDate,
-		&client.UpdatedAt,
+		&lpm,
+		&lpd,
+		&ua,
 	); err != nil {
-		return client, err
+		return client, mapError(err, "client")
+	}
+
+	if lpm.Valid {
+		client.LastPaymentMonth = lpm.String
+	}
+
+	if lpd.Valid {
+		client.LastPaymentDate = lpd.String
+	}
+	if ua.Valid {
+		client.UpdatedAt = ua.String
 	}
 
 	return client, nil
@@ -103,12 +120,33 @@ func (cs *ClientsService) GetClientInfo(clientID string) (models.ClientInfo, err
 
 // CreateClient creates a new client
 func (cs *ClientsService) CreateClient(client models.Client, assignments models.ClientAssignments) error {
+	return cs.CreateClients([]models.CreateClientRequest{{Client: client, Assignments: assignments}})
+}
+
+// CreateClients creates several clients with their assignments in a single
+// transaction, none of them is created when one fails
+func (cs *ClientsService) CreateClients(requests []models.CreateClientRequest) error {
 	tx, err := cs.db.Begin()
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
+	for _, r := range requests {
+		if err := insertClient(tx, r.Client, r.Assignments); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// insertClient inserts a client and its assignments in a transaction
+func insertClient(tx *sql.Tx, client models.Client, assignments models.ClientAssignments) error {
 	q := `
 		INSERT INTO clients (
 			name
@@ -118,18 +156,26 @@ func (cs *ClientsService) CreateClient(client models.Client, assignments models.
 			, fiel_expiration
 			, monthly_fee
 			, regimen_id
+			, state
+			, active
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7
+			$1, $2, $3, $4, $5, $6, $7, $8, $9
 		)
 		RETURNING id
 	`
 
+	state := client.State
+	if state == "" {
+		state = models.ClientActive
+	}
+
 	row := tx.QueryRow(
 		q, client.Name, client.RFC, client.ClaveCIEC, client.ClaveFiel,
 		client.FielExpiration, client.MonthlyFee, client.RegimenID,
+		state, models.IsActiveState(state),
 	)
 	if err := row.Scan(&assignments.ClientID); err != nil {
-		return err
+		return mapError(err, "client")
 	}
 
 	queryAssignments := `
@@ -138,7 +184,7 @@ func (cs *ClientsService) CreateClient(client models.Client, assignments models.
 		VALUES ($1, $2, $3, $4)
 	`
 
-	_, err = tx.Exec(
+	_, err := tx.Exec(
 		queryAssignments,
 		assignments.ClientID,
 		assignments.SupervisorID,
@@ -146,98 +192,37 @@ func (cs *ClientsService) CreateClient(client models.Client, assignments models.
 		assignments.EmisorID,
 	)
 	if err != nil {
-		return err
+		return mapError(err, "client assignments")
 	}
 
-	if err := tx.Commit(); err != nil {
+	if err := saveCurrentFee(tx, assignments.ClientID, client.MonthlyFee); err != nil {
 		return err
 	}
 
-	return nil
+	return saveAssignmentPeriod(tx, assignments.ClientID)
 }
 
-// GetAllClientsInfo retrieves all clients with complete information using the view
-func (cs *ClientsService) GetAllClientsInfo() ([]models.ClientInfo, error) {
-	q := `
-		SELECT 
-			id,
-			name,
-			rfc,
-			clave_ciec,
-			clave_fiel,
-			fiel_expiration,
-			monthly_fee,
-			active,
-			regimen_id,
-			regimen_name,
-			supervisor_id,
-			supervisor_name,
-			responsible_id,
-			responsible_name,
-			emisor_id,
-			emisor_name,
-			last_payment_month,
-			last_payment_date,
-			updated_at
-		FROM client_info_view
-		ORDER BY name ASC
-	`
-
-	rows, err := cs.db.Query(q)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var lpm sql.NullString
-	var lpd sql.NullString
-	var ua sql.NullString
-	var clients []models.ClientInfo
-	for rows.Next() {
-		var client models.ClientInfo
-		if err := rows.Scan(
-			&client.ID,
-			&client.Name,
-			&client.RFC,
-			&client.ClaveCIEC,
-			&client.ClaveFiel,
-			&client.FielExpiration,
-			&client.MonthlyFee,
-			&client.Active,
-			&client.RegimenID,
-			&client.RegimenName,
-			&client.SupervisorID,
-			&client.SupervisorName,
-			&client.ResponsibleID,
-			&client.ResponsibleName,
-			&client.EmisorID,
-			&client.EmisorName,
-			&lpm,
-			&lpd,
-			&ua,
-		); err != nil {
-			return nil, err
-		}
+// GetAllClientsInfo retrieves a page of the clients with complete information using the view
+func (cs *ClientsService) GetAllClientsInfo(params models.ClientListParams) (models.ClientInfoPage, error) {
+	return cs.listClientsInfo(params)
+}
 
-		if lpm.Valid {
-			client.LastPaymentMonth = lpm.String
-		}
+// GetActiveClientsInfo retrieves a page of the active clients with complete information
+func (cs *ClientsService) GetActiveClientsInfo(params models.ClientListParams) (models.ClientInfoPage, error) {
+	active := true
+	params.Active = &active
+	return cs.listClientsInfo(params)
+}
 
-		if lpd.Valid {
-			client.LastPaymentDate = lpd.String
-		}
-		if ua.Valid {
-			client.UpdatedAt = ua.String
-		}
+// listClientsInfo counts the clients that match the filters and retrieves the requested page
+func (cs *ClientsService) listClientsInfo(params models.ClientListParams) (models.ClientInfoPage, error) {
+	page := models.ClientInfoPage{Page: params.Page, PageSize: params.PageSize, Items: []models.ClientInfo{}}
+	where, args := clientListFilters(params)
 
-		clients = append(clients, client)
+	if err := cs.db.QueryRow(`SELECT COUNT(*) FROM client_info_view`+where, args...).Scan(&page.Total); err != nil {
+		return page, mapError(err, "clients")
 	}
 
-	return clients, nil
-}
-
-// GetActiveClientsInfo retrieves only active clients with complete information
-func (cs *ClientsService) GetActiveClientsInfo() ([]models.ClientInfo, error) {
 	q := `
 		SELECT 
 			id,
@@ -248,6 +233,7 @@ func (cs *ClientsService) GetActiveClientsInfo() ([]models.ClientInfo, error) {
 			fiel_expiration,
 			monthly_fee,
 			active,
+			` + clientState("client_info_view") + `,
 			regimen_id,
 			regimen_name,
 			supervisor_id,
@@ -259,20 +245,17 @@ func (cs *ClientsService) GetActiveClientsInfo() ([]models.ClientInfo, error) {
 			last_payment_month,
 			last_payment_date,
 			updated_at
-		FROM active_clients_view
-		ORDER BY name ASC
-	`
+		FROM client_info_view` + where + clientListOrder(params, len(args))
 
-	rows, err := cs.db.Query(q)
+	rows, err := cs.db.Query(q, append(args, clientListPage(params)...)...)
 	if err != nil {
-		return nil, err
+		return page, mapError(err, "clients")
 	}
 	defer rows.Close()
 
 	var lpm sql.NullString
 	var lpd sql.NullString
 	var ua sql.NullString
-	var clients []models.ClientInfo
 	for rows.Next() {
 		var client models.ClientInfo
 		if err := rows.Scan(
@@ -284,6 +267,7 @@ func (cs *ClientsService) GetActiveClientsInfo() ([]models.ClientInfo, error) {
 			&client.FielExpiration,
 			&client.MonthlyFee,
 			&client.Active,
+			&client.State,
 			&client.RegimenID,
 			&client.RegimenName,
 			&client.SupervisorID,
@@ -296,7 +280,7 @@ func (cs *ClientsService) GetActiveClientsInfo() ([]models.ClientInfo, error) {
 			&lpd,
 			&ua,
 		); err != nil {
-			return nil, err
+			return page, err
 		}
 
 		if lpm.Valid {
@@ -310,10 +294,10 @@ func (cs *ClientsService) GetActiveClientsInfo() ([]models.ClientInfo, error) {
 			client.UpdatedAt = ua.String
 		}
 
-		clients = append(clients, client)
+		page.Items = append(page.Items, client)
 	}
 
-	return clients, nil
+	return page, rows.Err()
 }
 
 // GetClientsWithPendingPayments retrieves clients with pending payments
@@ -331,7 +315,8 @@ func (cs *ClientsService) GetClientsWithPendingPayments() ([]models.ClientWithPe
 			supervisor_name,
 			responsible_name,
 			emisor_name,
-			payment_status
+			payment_status,
+			` + clientState("clients_with_pending_payments") + `
 		FROM clients_with_pending_payments
 	`
 
@@ -360,6 +345,7 @@ func (cs *ClientsService) GetClientsWithPendingPayments() ([]models.ClientWithPe
 			&client.ResponsibleName,
 			&client.EmisorName,
 			&client.PaymentStatus,
+			&client.State,
 		); err != nil {
 			return nil, err
 		}
@@ -381,63 +367,157 @@ func (cs *ClientsService) GetClientsWithPendingPayments() ([]models.ClientWithPe
 	return clients, nil
 }
 
-// UpdateClient updates client information
-func (cs *ClientsService) UpdateClient(clientID string, client models.Client) error {
+// UpdateClient updates client information, a new monthly fee is recorded as
+// the one in effect from this month. The active flag follows the state of the
+// client, it changes with the change of state given if any, which is recorded
+// in the timeline. It fails when the client is no longer in the version given.
+func (cs *ClientsService) UpdateClient(clientID string, client models.Client, change *models.ClientStateChange, version models.ClientVersion) error {
+	tx, err := cs.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := claimClientVersion(tx, clientID, version); err != nil {
+		return err
+	}
+
 	q := `
 		UPDATE clients 
 		SET name = $1, rfc = $2, clave_ciec = $3, clave_fiel = $4, 
-		    fiel_expiration = $5, monthly_fee = $6, regimen_id = $7, active = $8
-		WHERE id = $9
+		    fiel_expiration = $5, monthly_fee = $6, regimen_id = $7
+		WHERE id = $8
 	`
 
-	_, err := cs.db.Exec(q, client.Name, client.RFC, client.ClaveCIEC, client.ClaveFiel,
-		client.FielExpiration, client.MonthlyFee, client.RegimenID, client.Active, clientID)
+	result, err := tx.Exec(q, client.Name, client.RFC, client.ClaveCIEC, client.ClaveFiel,
+		client.FielExpiration, client.MonthlyFee, client.RegimenID, clientID)
+	if err != nil {
+		return mapError(err, "client")
+	}
+	if err := checkAffected(result, "client"); err != nil {
+		return err
+	}
+
+	if err := saveCurrentFee(tx, clientID, client.MonthlyFee); err != nil {
+		return err
+	}
 
-	return err
+	if change != nil {
+		if _, err := changeClientState(tx, *change); err != nil {
+			return err
+		}
+		if _, err := createClientEvent(tx, change.Event()); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
 
-// UpdateClientAssignments updates client assignments (supervisor, responsible, emisor)
-func (cs *ClientsService) UpdateClientAssignments(clientID string, assignments models.ClientAssignments) error {
+// UpdateClientAssignments updates client assignments (supervisor, responsible,
+// emisor), keeps the previous ones in their history and records the ones that
+// changed in the timeline, it fails when the client is no longer in the
+// version given
+func (cs *ClientsService) UpdateClientAssignments(clientID string, assignments models.ClientAssignments, version models.ClientVersion) error {
+	tx, err := cs.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := claimAssignmentsVersion(tx, clientID, version); err != nil {
+		return err
+	}
+
+	before, err := assignmentNames(tx, clientID)
+	if err != nil {
+		return err
+	}
+
 	q := `
 		UPDATE client_assignments 
 		SET supervisor_id = $1, responsible_id = $2, emisor_id = $3
 		WHERE client_id = $4
 	`
 
-	_, err := cs.db.Exec(q, assignments.SupervisorID, assignments.ResponsibleID,
+	result, err := tx.Exec(q, assignments.SupervisorID, assignments.ResponsibleID,
 		assignments.EmisorID, clientID)
+	if err != nil {
+		return mapError(err, "client assignments")
+	}
+	if err := checkAffected(result, "client assignments"); err != nil {
+		return err
+	}
 
-	return err
+	if err := saveAssignmentPeriod(tx, clientID); err != nil {
+		return err
+	}
+	if err := recordAssignmentChanges(tx, clientID, before); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-// UpdateClientPayment updates client payment information
-func (cs *ClientsService) UpdateClientPayment(clientID string, payment models.ClientPayment) error {
+// assignmentNames reads the assignments of a client with their names
+func assignmentNames(tx *sql.Tx, clientID string) (models.ClientInfo, error) {
 	q := `
-		INSERT INTO client_payments (client_id, last_payment_month, last_payment_date, folio_factura)
-		VALUES ($1, $2, $3, $4)
+		SELECT supervisor_id, supervisor_name, responsible_id, responsible_name, emisor_id, emisor_name
+		FROM client_info_view
+		WHERE id = $1
 	`
 
-	_, err := cs.db.Exec(q, clientID, payment.LastPaymentMonth, payment.LastPaymentDate, payment.FolioFactura)
+	var info models.ClientInfo
+	err := tx.QueryRow(q, clientID).Scan(&info.SupervisorID, &info.SupervisorName,
+		&info.ResponsibleID, &info.ResponsibleName, &info.EmisorID, &info.EmisorName)
+	if err != nil {
+		return info, mapError(err, "client")
+	}
 
-	return err
+	return info, nil
 }
 
-// DeactivateClient sets a client as inactive (soft delete)
-func (cs *ClientsService) DeactivateClient(clientID string) error {
-	q := `UPDATE clients SET active = false WHERE id = $1`
+// recordAssignmentChanges adds to the timeline of a client the assignments
+// that differ from the ones it had before
+func recordAssignmentChanges(tx *sql.Tx, clientID string, before models.ClientInfo) error {
+	after, err := assignmentNames(tx, clientID)
+	if err != nil {
+		return err
+	}
 
-	_, err := cs.db.Exec(q, clientID)
+	if changes := models.AssignmentChanges(before, after); changes != "" {
+		_, err = createClientEvent(tx, models.ClientEvent{ClientID: clientID, Type: models.EventAssignments, Message: changes})
+	}
 
 	return err
 }
 
-// ActivateClient sets a client as active
-func (cs *ClientsService) ActivateClient(clientID string) error {
-	q := `UPDATE clients SET active = true WHERE id = $1`
+// UpdateClientPayment updates client payment information and records it in
+// the timeline, it fails when the client is no longer in the version given
+func (cs *ClientsService) UpdateClientPayment(clientID string, payment models.ClientPayment, version models.ClientVersion, event models.ClientEvent) error {
+	tx, err := cs.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := claimClientVersion(tx, clientID, version); err != nil {
+		return err
+	}
 
-	_, err := cs.db.Exec(q, clientID)
+	q := `
+		INSERT INTO client_payments (client_id, last_payment_month, last_payment_date, folio_factura)
+		VALUES ($1, $2, $3, $4)
+	`
 
-	return err
+	if _, err := tx.Exec(q, clientID, payment.LastPaymentMonth, payment.LastPaymentDate, payment.FolioFactura); err != nil {
+		return mapError(err, "client payment")
+	}
+	if _, err := createClientEvent(tx, event); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 // GetClientPayments gets the payments history of a specific client
@@ -476,3 +556,162 @@ func (cs *ClientsService) GetClientPayments(clientID string) ([]models.ClientPay
 	}
 	return payments, nil
 }
+
+// SearchClients finds the clients whose name or RFC resemble the query, with
+// pg_trgm and unaccent on Postgres and ranked in Go on SQLite
+func (cs *ClientsService) SearchClients(params models.ClientSearchParams) ([]models.ClientSearchResult, error) {
+	if isSQLite(cs.db) {
+		return cs.searchClientsInGo(params)
+	}
+
+	args := []any{params.Q, likePattern(params.Q), params.Limit}
+	active := ""
+	if params.Active != nil {
+		args = append(args, *params.Active)
+		active = "AND active = $4"
+	}
+
+	q := `
+		SELECT
+			id,
+			name,
+			rfc,
+			active,
+			GREATEST(
+				word_similarity(lower(f_unaccent($1)), lower(f_unaccent(name))),
+				word_similarity(lower($1), lower(rfc)),
+				CASE WHEN lower(f_unaccent(name)) LIKE lower(f_unaccent($2)) OR lower(rfc) LIKE lower($2) THEN 1 ELSE 0 END
+			) AS score
+		FROM clients
+		WHERE (
+			lower(f_unaccent($1)) <% lower(f_unaccent(name))
+			OR lower($1) <% lower(rfc)
+			OR lower(f_unaccent(name)) LIKE lower(f_unaccent($2))
+			OR lower(rfc) LIKE lower($2)
+		) ` + active + `
+		ORDER BY score DESC, name ASC
+		LIMIT $3
+	`
+
+	rows, err := cs.db.Query(q, args...)
+	if err != nil {
+		return nil, mapError(err, "clients")
+	}
+	defer rows.Close()
+
+	results := []models.ClientSearchResult{}
+	for rows.Next() {
+		var r models.ClientSearchResult
+		if err := rows.Scan(&r.ID, &r.Name, &r.RFC, &r.Active, &r.Score); err != nil {
+			return nil, err
+		}
+
+		results = append(results, r)
+	}
+
+	return results, rows.Err()
+}
+
+// searchClientsInGo ranks every client with the search package, SQLite has no trigram extension
+func (cs *ClientsService) searchClientsInGo(params models.ClientSearchParams) ([]models.ClientSearchResult, error) {
+	q := `SELECT id, name, rfc, active FROM clients ORDER BY name ASC`
+
+	rows, err := cs.db.Query(q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var clients []models.ClientSearchResult
+	var candidates [][]string
+	for rows.Next() {
+		var r models.ClientSearchResult
+		if err := rows.Scan(&r.ID, &r.Name, &r.RFC, &r.Active); err != nil {
+			return nil, err
+		}
+		if params.Active != nil && r.Active != *params.Active {
+			continue
+		}
+
+		clients = append(clients, r)
+		candidates = append(candidates, []string{r.Name, r.RFC})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	results := []models.ClientSearchResult{}
+	for _, m := range search.Rank(params.Q, candidates) {
+		if len(results) == params.Limit {
+			break
+		}
+		r := clients[m.Index]
+		r.Score = m.Score
+		results = append(results, r)
+	}
+
+	return results, nil
+}
+
+// GetClientSummary retrieves the id, name, RFC and merge pointer of a client
+func (cs *ClientsService) GetClientSummary(clientID string) (models.ClientSummary, error) {
+	q := `
+		SELECT id, name, rfc, active, state, merged_into
+		FROM clients
+		WHERE id = $1
+	`
+
+	return scanClientSummary(cs.db.QueryRow(q, clientID))
+}
+
+// GetActiveClientByRFC retrieves the active client with an RFC, the comparison ignores case
+func (cs *ClientsService) GetActiveClientByRFC(rfc string) (models.ClientSummary, error) {
+	q := `
+		SELECT id, name, rfc, active, state, merged_into
+		FROM clients
+		WHERE upper(rfc) = upper($1) AND active = true
+		LIMIT 1
+	`
+
+	return scanClientSummary(cs.db.QueryRow(q, rfc))
+}
+
+// GetClientSummaries retrieves the id, name and RFC of every client that was not merged
+func (cs *ClientsService) GetClientSummaries() ([]models.ClientSummary, error) {
+	q := `
+		SELECT id, name, rfc, active, state, merged_into
+		FROM clients
+		WHERE merged_into IS NULL
+		ORDER BY name ASC, id ASC
+	`
+
+	rows, err := cs.db.Query(q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	clients := []models.ClientSummary{}
+	for rows.Next() {
+		c, err := scanClientSummary(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		clients = append(clients, c)
+	}
+
+	return clients, rows.Err()
+}
+
+// scanClientSummary reads a client summary from a row
+func scanClientSummary(row interface{ Scan(dest ...any) error }) (models.ClientSummary, error) {
+	var c models.ClientSummary
+	var mergedInto sql.NullString
+	if err := row.Scan(&c.ID, &c.Name, &c.RFC, &c.Active, &c.State, &mergedInto); err != nil {
+		return c, mapError(err, "client")
+	}
+	c.MergedInto = mergedInto.String
+
+	return c, nil
+}
@@ -0,0 +1,104 @@
+package database
+
+import (
+	"contabi-be/models"
+	"time"
+)
+
+const contactColumns = `id, client_id, name, role, email, phone, preferred_channel, is_billing, created_at, updated_at`
+
+// scanContact reads a contact selected with contactColumns
+func scanContact(row interface{ Scan(dest ...any) error }) (models.ClientContact, error) {
+	var c models.ClientContact
+	var createdAt, updatedAt time.Time
+	if err := row.Scan(&c.ID, &c.ClientID, &c.Name, &c.Role, &c.Email, &c.Phone, &c.PreferredChannel, &c.IsBilling, &createdAt, &updatedAt); err != nil {
+		return c, err
+	}
+	c.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+	c.UpdatedAt = updatedAt.UTC().Format(time.RFC3339)
+
+	return c, nil
+}
+
+// GetClientContacts retrieves the contacts of a client, the billing ones first
+func (cs *ClientsService) GetClientContacts(clientID string) ([]models.ClientContact, error) {
+	q := `SELECT ` + contactColumns + `
+		FROM client_contacts
+		WHERE client_id = $1
+		ORDER BY is_billing DESC, name ASC, id ASC
+	`
+
+	rows, err := cs.db.Query(q, clientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	contacts := []models.ClientContact{}
+	for rows.Next() {
+		c, err := scanContact(rows)
+		if err != nil {
+			return nil, err
+		}
+		contacts = append(contacts, c)
+	}
+
+	return contacts, rows.Err()
+}
+
+// CreateClientContact adds a contact to a client
+func (cs *ClientsService) CreateClientContact(contact models.ClientContact) (models.ClientContact, error) {
+	q := `
+		INSERT INTO client_contacts (client_id, name, role, email, phone, preferred_channel, is_billing)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + contactColumns
+
+	row := cs.db.QueryRow(q, contact.ClientID, contact.Name, contact.Role, contact.Email, contact.Phone, contact.PreferredChannel, contact.IsBilling)
+	created, err := scanContact(row)
+	if err != nil {
+		return contact, mapError(err, "contact")
+	}
+
+	return created, nil
+}
+
+// UpdateClientContact replaces the data of a contact of a client, it fails
+// when the client is no longer in the version given
+func (cs *ClientsService) UpdateClientContact(contact models.ClientContact, version models.ClientVersion) (models.ClientContact, error) {
+	tx, err := cs.db.Begin()
+	if err != nil {
+		return contact, err
+	}
+	defer tx.Rollback()
+
+	if err := claimClientVersion(tx, contact.ClientID, version); err != nil {
+		return contact, err
+	}
+
+	q := `
+		UPDATE client_contacts
+		SET name = $3, role = $4, email = $5, phone = $6, preferred_channel = $7, is_billing = $8, updated_at = $9
+		WHERE id = $1 AND client_id = $2
+		RETURNING ` + contactColumns
+
+	row := tx.QueryRow(q, contact.ID, contact.ClientID, contact.Name, contact.Role, contact.Email, contact.Phone,
+		contact.PreferredChannel, contact.IsBilling, time.Now().UTC().Format(time.RFC3339))
+	updated, err := scanContact(row)
+	if err != nil {
+		return contact, mapError(err, "contact")
+	}
+
+	return updated, tx.Commit()
+}
+
+// DeleteClientContact removes a contact of a client
+func (cs *ClientsService) DeleteClientContact(clientID, contactID string) error {
+	q := `DELETE FROM client_contacts WHERE id = $1 AND client_id = $2`
+
+	result, err := cs.db.Exec(q, contactID, clientID)
+	if err != nil {
+		return mapError(err, "contact")
+	}
+
+	return checkAffected(result, "contact")
+}
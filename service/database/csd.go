@@ -0,0 +1,125 @@
+package database
+
+import (
+	"contabi-be/models"
+	"time"
+)
+
+// SaveCSDCertificate stores the data of a CSD of a client with its files
+func (cs *ClientsService) SaveCSDCertificate(cert models.CSDCertificate, files models.CSDFiles) (models.CSDCertificate, error) {
+	q := `
+		INSERT INTO client_csd_certificates (
+			client_id, serial_number, rfc, name, branch, valid_from, valid_to, certificate, private_key, password)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, uploaded_at
+	`
+
+	var uploadedAt time.Time
+	row := cs.db.QueryRow(q, cert.ClientID, cert.SerialNumber, cert.RFC, cert.Name, cert.Branch,
+		cert.ValidFrom, cert.ValidTo, files.Certificate, files.Key, files.Password)
+	if err := row.Scan(&cert.ID, &uploadedAt); err != nil {
+		return cert, mapError(err, "csd certificate")
+	}
+	cert.UploadedAt = uploadedAt.UTC().Format(time.RFC3339)
+
+	return cert, nil
+}
+
+// GetCSDCertificates retrieves the CSD of a client, the one that expires last first
+func (cs *ClientsService) GetCSDCertificates(clientID string) ([]models.CSDCertificate, error) {
+	q := `
+		SELECT id, client_id, serial_number, rfc, name, branch, valid_from, valid_to, uploaded_at
+		FROM client_csd_certificates
+		WHERE client_id = $1
+		ORDER BY valid_to DESC, id DESC
+	`
+
+	rows, err := cs.db.Query(q, clientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	certs := []models.CSDCertificate{}
+	for rows.Next() {
+		var c models.CSDCertificate
+		var validFrom, validTo, uploadedAt time.Time
+		if err := rows.Scan(&c.ID, &c.ClientID, &c.SerialNumber, &c.RFC, &c.Name, &c.Branch, &validFrom, &validTo, &uploadedAt); err != nil {
+			return nil, err
+		}
+		c.ValidFrom = validFrom.UTC().Format(time.RFC3339)
+		c.ValidTo = validTo.UTC().Format(time.RFC3339)
+		c.UploadedAt = uploadedAt.UTC().Format(time.RFC3339)
+
+		certs = append(certs, c)
+	}
+
+	return certs, rows.Err()
+}
+
+// DeleteCSDCertificate removes a CSD of a client with its files
+func (cs *ClientsService) DeleteCSDCertificate(clientID, csdID string) error {
+	q := `DELETE FROM client_csd_certificates WHERE id = $1 AND client_id = $2`
+
+	result, err := cs.db.Exec(q, csdID, clientID)
+	if err != nil {
+		return mapError(err, "csd certificate")
+	}
+
+	return checkAffected(result, "csd certificate")
+}
+
+// GetExpiringCSD retrieves the CSD of the active clients that expire before a
+// time, including the expired ones, leaving out the ones replaced by a CSD
+// that expires later
+func (cs *ClientsService) GetExpiringCSD(before string) ([]models.CSDExpiration, error) {
+	q := `
+		SELECT
+			d.id,
+			d.serial_number,
+			d.valid_to,
+			v.id,
+			v.name,
+			v.rfc,
+			v.supervisor_id,
+			v.supervisor_name,
+			v.responsible_id,
+			v.responsible_name
+		FROM client_csd_certificates d
+		JOIN client_info_view v ON v.id = d.client_id
+		JOIN clients c ON c.id = d.client_id
+		WHERE v.active = true
+			AND c.merged_into IS NULL
+			AND d.valid_to < $1
+			AND NOT EXISTS (
+				SELECT 1
+				FROM client_csd_certificates newer
+				WHERE newer.client_id = d.client_id
+					AND newer.valid_to > d.valid_to
+			)
+		ORDER BY d.valid_to ASC, v.name ASC
+	`
+
+	rows, err := cs.db.Query(q, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	expirations := []models.CSDExpiration{}
+	for rows.Next() {
+		var e models.CSDExpiration
+		var validTo time.Time
+		if err := rows.Scan(
+			&e.CSDID, &e.SerialNumber, &validTo, &e.ClientID, &e.Name, &e.RFC,
+			&e.SupervisorID, &e.SupervisorName, &e.ResponsibleID, &e.ResponsibleName,
+		); err != nil {
+			return nil, err
+		}
+		e.ValidTo = validTo.UTC().Format(time.RFC3339)
+
+		expirations = append(expirations, e)
+	}
+
+	return expirations, rows.Err()
+}
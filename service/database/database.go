@@ -2,11 +2,26 @@ package database
 
 import (
 	"contabi-be/config"
+	"contabi-be/migrations"
 	"database/sql"
+	"errors"
 	"fmt"
+	"io/fs"
 
+	"github.com/golang-migrate/migrate/v4"
+	migratedb "github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	sqlitemigrate "github.com/golang-migrate/migrate/v4/database/sqlite"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
 	_ "github.com/lib/pq"
+	"modernc.org/sqlite"
+)
+
+// Supported values of DB_DRIVER
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
 )
 
 // DataBaseService contains the database connection
@@ -17,6 +32,28 @@ type DataBaseService struct {
 
 // NewDatabaseService creates a new database service
 func NewDatabaseService(cfg config.Config) (*DataBaseService, error) {
+	var db *sql.DB
+	var err error
+	switch cfg.DBDriver {
+	case "", DriverPostgres:
+		db, err = openPostgres(cfg)
+	case DriverSQLite:
+		db, err = openSQLite(cfg)
+	default:
+		return nil, fmt.Errorf("unsupported DB_DRIVER %q", cfg.DBDriver)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &DataBaseService{
+		cfg: cfg,
+		DB:  db,
+	}, nil
+}
+
+// openPostgres connects to the Postgres database
+func openPostgres(cfg config.Config) (*sql.DB, error) {
 	con := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable", cfg.DBUser, cfg.DBPassword, cfg.DBHost, cfg.DBPort, cfg.DBName)
 	db, err := sql.Open("postgres", con)
 	if err != nil {
@@ -28,8 +65,102 @@ func NewDatabaseService(cfg config.Config) (*DataBaseService, error) {
 		return nil, err
 	}
 
-	return &DataBaseService{
-		cfg: cfg,
-		DB:  db,
-	}, nil
+	// a table of its own keeps the versions apart from other migration tools
+	driver, err := postgres.WithInstance(db, &postgres.Config{MigrationsTable: "api_schema_migrations"})
+	if err != nil {
+		return nil, fmt.Errorf("preparing the Postgres migrations: %w", err)
+	}
+
+	if err := migrateUp(migrations.Postgres, "postgres", driver); err != nil {
+		return nil, fmt.Errorf("migrating the Postgres database: %w", err)
+	}
+
+	return db, nil
+}
+
+// openSQLite opens the database file named by DB_NAME and migrates it to the
+// latest schema, the file is created when it does not exist
+func openSQLite(cfg config.Config) (*sql.DB, error) {
+	if cfg.DBName == "" {
+		return nil, errors.New("DB_NAME must be the path of the SQLite database file")
+	}
+
+	con := fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)", cfg.DBName)
+	db, err := sql.Open("sqlite", con)
+	if err != nil {
+		return nil, err
+	}
+
+	driver, err := sqlitemigrate.WithInstance(db, &sqlitemigrate.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("preparing the SQLite migrations: %w", err)
+	}
+
+	if err := migrateUp(migrations.SQLite, "sqlite", driver); err != nil {
+		return nil, fmt.Errorf("migrating the SQLite database: %w", err)
+	}
+
+	return db, nil
+}
+
+// migrateUp applies the pending migrations of a directory of the embedded files
+func migrateUp(files fs.FS, dir string, driver migratedb.Driver) error {
+	source, err := iofs.New(files, dir)
+	if err != nil {
+		return err
+	}
+
+	m, err := migrate.NewWithInstance("iofs", source, dir, driver)
+	if err != nil {
+		return err
+	}
+
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+
+	return nil
+}
+
+// activeRFCIndex keeps a single active client with each RFC, but for the
+// generic RFCs of the general public and the foreigners
+const activeRFCIndex = `CREATE UNIQUE INDEX IF NOT EXISTS clients_active_rfc_key ON clients (upper(rfc))
+	WHERE active = true AND upper(rfc) NOT IN ('XAXX010101000', 'XEXX010101000')`
+
+// CheckActiveRFCIndex creates the unique index of the RFCs of the active
+// clients when it is missing. It can not be built while active clients share
+// an RFC, then it returns an error with how many RFCs they share so it is
+// reported at every start until they are merged.
+func (dbs *DataBaseService) CheckActiveRFCIndex() error {
+	q := `
+		SELECT COUNT(*)
+		FROM (
+			SELECT upper(rfc)
+			FROM clients
+			WHERE active = true AND upper(rfc) NOT IN ('XAXX010101000', 'XEXX010101000')
+			GROUP BY upper(rfc)
+			HAVING COUNT(*) > 1
+		) duplicates
+	`
+
+	var shared int
+	if err := dbs.DB.QueryRow(q).Scan(&shared); err != nil {
+		return fmt.Errorf("counting the RFCs shared by active clients: %w", err)
+	}
+	if shared > 0 {
+		return fmt.Errorf("%d RFCs are shared by active clients, clients_active_rfc_key is created at the first start after they are merged, GET /clients/duplicates lists them", shared)
+	}
+
+	if _, err := dbs.DB.Exec(activeRFCIndex); err != nil {
+		return fmt.Errorf("creating clients_active_rfc_key: %w", err)
+	}
+
+	return nil
+}
+
+// isSQLite reports whether a connection uses the SQLite driver, for the few
+// queries that need Postgres extensions
+func isSQLite(db *sql.DB) bool {
+	_, ok := db.Driver().(*sqlite.Driver)
+	return ok
 }
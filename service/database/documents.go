@@ -0,0 +1,269 @@
+package database
+
+import (
+	"contabi-be/models"
+	"database/sql"
+	"time"
+)
+
+// DocumentsService keeps the documents of the clients, their files are kept
+// by the storage and the tables only know their keys
+type DocumentsService struct {
+	db *sql.DB
+}
+
+func NewDocumentsService(db *sql.DB) *DocumentsService {
+	return &DocumentsService{db: db}
+}
+
+const documentVersionColumns = `
+	v.version, v.file_name, v.content_type, v.size, v.checksum, v.storage_key,
+	v.uploaded_by, COALESCE(u.username, ''), v.uploaded_at
+`
+
+// scanDocumentVersion reads a version selected with documentVersionColumns
+// after the columns of dest
+func scanDocumentVersion(row interface{ Scan(dest ...any) error }, dest ...any) (models.DocumentVersion, error) {
+	var v models.DocumentVersion
+	var uploadedBy sql.NullString
+	var uploadedAt time.Time
+	dest = append(dest, &v.Version, &v.FileName, &v.ContentType, &v.Size, &v.Checksum, &v.StorageKey, &uploadedBy, &v.UploadedByName, &uploadedAt)
+	if err := row.Scan(dest...); err != nil {
+		return v, err
+	}
+	v.UploadedBy = uploadedBy.String
+	v.UploadedAt = uploadedAt.UTC().Format(time.RFC3339)
+
+	return v, nil
+}
+
+// GetDocuments retrieves the documents of a client with their current
+// version, the last updated first
+func (ds *DocumentsService) GetDocuments(clientID string, params models.DocumentParams) ([]models.ClientDocument, error) {
+	q := `
+		SELECT d.id, d.client_id, d.category, d.title, d.created_at, d.updated_at, ` + documentVersionColumns + `
+		FROM client_documents d
+		JOIN client_document_versions v
+			ON v.document_id = d.id
+			AND v.version = (SELECT MAX(version) FROM client_document_versions WHERE document_id = d.id)
+		LEFT JOIN users u ON u.id = v.uploaded_by
+		WHERE d.client_id = $1
+			AND ($2 = '' OR d.category = $2)
+		ORDER BY d.updated_at DESC, d.id DESC
+	`
+
+	rows, err := ds.db.Query(q, clientID, params.Category)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	documents := []models.ClientDocument{}
+	for rows.Next() {
+		var d models.ClientDocument
+		var createdAt, updatedAt time.Time
+		if d.Current, err = scanDocumentVersion(rows, &d.ID, &d.ClientID, &d.Category, &d.Title, &createdAt, &updatedAt); err != nil {
+			return nil, err
+		}
+		d.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+		d.UpdatedAt = updatedAt.UTC().Format(time.RFC3339)
+
+		documents = append(documents, d)
+	}
+
+	return documents, rows.Err()
+}
+
+// GetDocument retrieves a document of a client with all its versions, the
+// newest first
+func (ds *DocumentsService) GetDocument(clientID, documentID string) (models.ClientDocument, error) {
+	d := models.ClientDocument{ID: documentID, ClientID: clientID}
+
+	var createdAt, updatedAt time.Time
+	row := ds.db.QueryRow(`SELECT category, title, created_at, updated_at FROM client_documents WHERE id = $1 AND client_id = $2`, documentID, clientID)
+	if err := row.Scan(&d.Category, &d.Title, &createdAt, &updatedAt); err != nil {
+		return d, mapError(err, "document")
+	}
+	d.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+	d.UpdatedAt = updatedAt.UTC().Format(time.RFC3339)
+
+	q := `
+		SELECT ` + documentVersionColumns + `
+		FROM client_document_versions v
+		LEFT JOIN users u ON u.id = v.uploaded_by
+		WHERE v.document_id = $1
+		ORDER BY v.version DESC
+	`
+
+	rows, err := ds.db.Query(q, documentID)
+	if err != nil {
+		return d, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		v, err := scanDocumentVersion(rows)
+		if err != nil {
+			return d, err
+		}
+		d.Versions = append(d.Versions, v)
+	}
+	if err := rows.Err(); err != nil {
+		return d, err
+	}
+	if len(d.Versions) > 0 {
+		d.Current = d.Versions[0]
+	}
+
+	return d, nil
+}
+
+// CreateDocument stores a document with its first version
+func (ds *DocumentsService) CreateDocument(document models.ClientDocument) (models.ClientDocument, error) {
+	tx, err := ds.db.Begin()
+	if err != nil {
+		return document, err
+	}
+	defer tx.Rollback()
+
+	q := `
+		INSERT INTO client_documents (client_id, category, title)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`
+	if err := tx.QueryRow(q, document.ClientID, document.Category, document.Title).Scan(&document.ID); err != nil {
+		return document, mapError(err, "document")
+	}
+
+	document.Current.Version = 1
+	if err := insertDocumentVersion(tx, document.ID, &document.Current); err != nil {
+		return document, err
+	}
+	document.CreatedAt = document.Current.UploadedAt
+	document.UpdatedAt = document.Current.UploadedAt
+
+	return document, tx.Commit()
+}
+
+// AddDocumentVersion stores a new version of a document of a client, it gets
+// the number that follows the last version
+func (ds *DocumentsService) AddDocumentVersion(clientID, documentID string, version models.DocumentVersion) (models.DocumentVersion, error) {
+	tx, err := ds.db.Begin()
+	if err != nil {
+		return version, err
+	}
+	defer tx.Rollback()
+
+	// touching the document first locks it, two uploads can not take the same number
+	now := time.Now().UTC().Format(time.RFC3339)
+	result, err := tx.Exec(`UPDATE client_documents SET updated_at = $3 WHERE id = $1 AND client_id = $2`, documentID, clientID, now)
+	if err != nil {
+		return version, mapError(err, "document")
+	}
+	if err := checkAffected(result, "document"); err != nil {
+		return version, err
+	}
+
+	q := `SELECT COALESCE(MAX(version), 0) + 1 FROM client_document_versions WHERE document_id = $1`
+	if err := tx.QueryRow(q, documentID).Scan(&version.Version); err != nil {
+		return version, err
+	}
+	if err := insertDocumentVersion(tx, documentID, &version); err != nil {
+		return version, err
+	}
+
+	return version, tx.Commit()
+}
+
+// insertDocumentVersion stores a version of a document and sets its upload time
+func insertDocumentVersion(tx *sql.Tx, documentID string, version *models.DocumentVersion) error {
+	q := `
+		INSERT INTO client_document_versions (
+			document_id, version, file_name, content_type, size, checksum, storage_key, uploaded_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING uploaded_at
+	`
+
+	var uploadedAt time.Time
+	uploadedBy := sql.NullString{String: version.UploadedBy, Valid: version.UploadedBy != ""}
+	row := tx.QueryRow(q, documentID, version.Version, version.FileName, version.ContentType, version.Size,
+		version.Checksum, version.StorageKey, uploadedBy)
+	if err := row.Scan(&uploadedAt); err != nil {
+		return mapError(err, "document version")
+	}
+	version.UploadedAt = uploadedAt.UTC().Format(time.RFC3339)
+
+	return nil
+}
+
+// GetDocumentVersion retrieves a version of a document of a client, the
+// current one when the version is 0
+func (ds *DocumentsService) GetDocumentVersion(clientID, documentID string, version int) (models.DocumentVersion, error) {
+	q := `
+		SELECT ` + documentVersionColumns + `
+		FROM client_document_versions v
+		JOIN client_documents d ON d.id = v.document_id
+		LEFT JOIN users u ON u.id = v.uploaded_by
+		WHERE d.id = $1
+			AND d.client_id = $2
+			AND ($3 = 0 OR v.version = $3)
+		ORDER BY v.version DESC
+		LIMIT 1
+	`
+
+	v, err := scanDocumentVersion(ds.db.QueryRow(q, documentID, clientID, version))
+	if err != nil {
+		return v, mapError(err, "document version")
+	}
+
+	return v, nil
+}
+
+// DeleteDocument removes a document of a client with its versions and
+// returns the storage keys of their files
+func (ds *DocumentsService) DeleteDocument(clientID, documentID string) ([]string, error) {
+	tx, err := ds.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	q := `
+		SELECT v.storage_key
+		FROM client_document_versions v
+		JOIN client_documents d ON d.id = v.document_id
+		WHERE d.id = $1 AND d.client_id = $2
+	`
+
+	rows, err := tx.Query(q, documentID, clientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if _, err := tx.Exec(`DELETE FROM client_document_versions WHERE document_id = $1`, documentID); err != nil {
+		return nil, mapError(err, "document")
+	}
+	result, err := tx.Exec(`DELETE FROM client_documents WHERE id = $1 AND client_id = $2`, documentID, clientID)
+	if err != nil {
+		return nil, mapError(err, "document")
+	}
+	if err := checkAffected(result, "document"); err != nil {
+		return nil, err
+	}
+
+	return keys, tx.Commit()
+}
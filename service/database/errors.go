@@ -0,0 +1,128 @@
+package database
+
+import (
+	"contabi-be/models"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// Postgres error codes mapped to domain errors
+const (
+	pqUniqueViolation     = "23505"
+	pqForeignKeyViolation = "23503"
+	pqNotNullViolation    = "23502"
+	pqCheckViolation      = "23514"
+	pqInvalidText         = "22P02"
+	pqInvalidDatetime     = "22007"
+	pqDatetimeOverflow    = "22008"
+)
+
+// mapError translates database errors into domain errors, the entity names the record in the messages
+func mapError(err error, entity string) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.NewNotFoundError(entity + " not found")
+	}
+
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		return mapSQLiteError(sqliteErr, entity)
+	}
+
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	details := map[string]string{}
+	if pqErr.Constraint != "" {
+		details["constraint"] = pqErr.Constraint
+	}
+	if pqErr.Column != "" {
+		details["column"] = pqErr.Column
+	}
+
+	var domainErr *models.Error
+	switch string(pqErr.Code) {
+	case pqUniqueViolation:
+		domainErr = models.NewConflictError(entity+" already exists", details)
+	case pqForeignKeyViolation:
+		if strings.Contains(pqErr.Detail, "still referenced") {
+			domainErr = models.NewConflictError(entity+" is still referenced by other records", details)
+		} else {
+			domainErr = models.NewValidationError(entity+" references a record that does not exist", details)
+		}
+	case pqNotNullViolation:
+		domainErr = models.NewValidationError(entity+" is missing a required value", details)
+	case pqCheckViolation:
+		domainErr = models.NewValidationError(entity+" has an invalid value", details)
+	case pqInvalidText, pqInvalidDatetime, pqDatetimeOverflow:
+		domainErr = models.NewValidationError(entity+" has a value with an invalid format", nil)
+	default:
+		return err
+	}
+	domainErr.Err = err
+
+	return domainErr
+}
+
+// mapSQLiteError translates the constraint errors of SQLite, it does not tell a
+// missing reference from a referenced record so foreign keys are reported as validation errors
+func mapSQLiteError(err *sqlite.Error, entity string) error {
+	details := map[string]string{}
+	message := strings.TrimSuffix(err.Error(), fmt.Sprintf(" (%d)", err.Code()))
+	if i := strings.LastIndex(message, "constraint failed: "); i >= 0 {
+		details["constraint"] = message[i+len("constraint failed: "):]
+	}
+
+	var domainErr *models.Error
+	switch err.Code() {
+	case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+		domainErr = models.NewConflictError(entity+" already exists", details)
+	case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+		domainErr = models.NewValidationError(entity+" references a record that does not exist", nil)
+	case sqlite3.SQLITE_CONSTRAINT_NOTNULL:
+		domainErr = models.NewValidationError(entity+" is missing a required value", details)
+	case sqlite3.SQLITE_CONSTRAINT_CHECK:
+		domainErr = models.NewValidationError(entity+" has an invalid value", details)
+	default:
+		return err
+	}
+	domainErr.Err = err
+
+	return domainErr
+}
+
+// checkAffected returns a not found error when the statement did not touch any row
+func checkAffected(result sql.Result, entity string) error {
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return models.NewNotFoundError(entity + " not found")
+	}
+
+	return nil
+}
+
+// parseBool reads the flags the models carry as text, both drivers receive a real boolean
+func parseBool(value, field string) (bool, error) {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, models.NewValidationError(field+" must be true or false", nil)
+	}
+
+	return b, nil
+}
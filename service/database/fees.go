@@ -0,0 +1,180 @@
+package database
+
+import (
+	"contabi-be/models"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+const feeColumns = `id, client_id, monthly_fee, effective_month, created_at`
+
+// scanFee reads a fee selected with feeColumns
+func scanFee(row interface{ Scan(dest ...any) error }) (models.ClientFee, error) {
+	var f models.ClientFee
+	var effectiveMonth sql.NullString
+	var createdAt time.Time
+	if err := row.Scan(&f.ID, &f.ClientID, &f.MonthlyFee, &effectiveMonth, &createdAt); err != nil {
+		return f, err
+	}
+	f.EffectiveMonth = effectiveMonth.String
+	f.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+
+	return f, nil
+}
+
+// GetClientFees retrieves the monthly fees of a client, the latest effective
+// month first
+func (cs *ClientsService) GetClientFees(clientID string) ([]models.ClientFee, error) {
+	return cs.queryFees(`SELECT `+feeColumns+`
+		FROM client_fees
+		WHERE client_id = $1
+		ORDER BY effective_month IS NULL, effective_month DESC
+	`, clientID)
+}
+
+// GetAllClientFees retrieves the monthly fees of every client
+func (cs *ClientsService) GetAllClientFees() ([]models.ClientFee, error) {
+	return cs.queryFees(`SELECT ` + feeColumns + `
+		FROM client_fees
+		ORDER BY client_id, effective_month IS NULL, effective_month DESC
+	`)
+}
+
+// queryFees runs a query of the fees selected with feeColumns
+func (cs *ClientsService) queryFees(q string, args ...any) ([]models.ClientFee, error) {
+	rows, err := cs.db.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fees := []models.ClientFee{}
+	for rows.Next() {
+		f, err := scanFee(rows)
+		if err != nil {
+			return nil, err
+		}
+		fees = append(fees, f)
+	}
+
+	return fees, rows.Err()
+}
+
+// SaveClientFee sets the monthly fee of a client from a month, replacing the
+// one of the same month, and updates the current fee of the client. It fails
+// when the client is no longer in the version given.
+func (cs *ClientsService) SaveClientFee(fee models.ClientFee, version models.ClientVersion) (models.ClientFee, error) {
+	tx, err := cs.db.Begin()
+	if err != nil {
+		return fee, err
+	}
+	defer tx.Rollback()
+
+	if err := claimClientVersion(tx, fee.ClientID, version); err != nil {
+		return fee, err
+	}
+
+	q := `
+		INSERT INTO client_fees (client_id, monthly_fee, effective_month)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (client_id, effective_month) DO UPDATE SET monthly_fee = EXCLUDED.monthly_fee
+		RETURNING ` + feeColumns
+
+	saved, err := scanFee(tx.QueryRow(q, fee.ClientID, fee.MonthlyFee, fee.EffectiveMonth))
+	if err != nil {
+		return fee, mapError(err, "client fee")
+	}
+
+	if err := applyCurrentFee(tx, fee.ClientID); err != nil {
+		return saved, err
+	}
+
+	return saved, tx.Commit()
+}
+
+// DeleteClientFee removes a fee of a client
+func (cs *ClientsService) DeleteClientFee(clientID, feeID string) error {
+	result, err := cs.db.Exec(`DELETE FROM client_fees WHERE id = $1 AND client_id = $2`, feeID, clientID)
+	if err != nil {
+		return mapError(err, "client fee")
+	}
+
+	return checkAffected(result, "client fee")
+}
+
+// ApplyClientFees sets the current fee of the clients that have a fee
+// effective on a month, it returns the clients whose fee changed
+func (cs *ClientsService) ApplyClientFees(month string) (int, error) {
+	q := `
+		UPDATE clients
+		SET monthly_fee = f.monthly_fee, version = clients.version + 1
+		FROM client_fees f
+		WHERE f.client_id = clients.id AND f.effective_month = $1 AND clients.monthly_fee <> f.monthly_fee
+	`
+
+	result, err := cs.db.Exec(q, month)
+	if err != nil {
+		return 0, mapError(err, "client fees")
+	}
+
+	return rowsAffected(result)
+}
+
+// feeInEffect returns the fee of a client in effect on a month, false when the
+// client has none
+func feeInEffect(tx *sql.Tx, clientID, month string) (string, bool, error) {
+	q := `
+		SELECT monthly_fee
+		FROM client_fees
+		WHERE client_id = $1 AND (effective_month IS NULL OR effective_month <= $2)
+		ORDER BY effective_month IS NULL, effective_month DESC
+		LIMIT 1
+	`
+
+	var fee string
+	err := tx.QueryRow(q, clientID, month).Scan(&fee)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+
+	return fee, err == nil, err
+}
+
+// applyCurrentFee sets the fee of a client to the one in effect this month,
+// the version of the client was claimed in the same transaction
+func applyCurrentFee(tx *sql.Tx, clientID string) error {
+	fee, ok, err := feeInEffect(tx, clientID, time.Now().Format("2006-01"))
+	if err != nil || !ok {
+		return err
+	}
+
+	_, err = tx.Exec(`UPDATE clients SET monthly_fee = $1 WHERE id = $2 AND monthly_fee <> $1`, fee, clientID)
+
+	return mapError(err, "client")
+}
+
+// saveCurrentFee records the fee of a client set on its data as the one in
+// effect from this month, or as its first fee when it had none
+func saveCurrentFee(tx *sql.Tx, clientID, fee string) error {
+	if fee == "" {
+		return nil
+	}
+
+	month := time.Now().Format("2006-01")
+	current, ok, err := feeInEffect(tx, clientID, month)
+	switch {
+	case err != nil:
+		return err
+	case !ok:
+		_, err = tx.Exec(`INSERT INTO client_fees (client_id, monthly_fee) VALUES ($1, $2)`, clientID, fee)
+	case current != fee:
+		_, err = tx.Exec(`
+			INSERT INTO client_fees (client_id, monthly_fee, effective_month)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (client_id, effective_month) DO UPDATE SET monthly_fee = EXCLUDED.monthly_fee
+		`, clientID, fee, month)
+	}
+
+	return mapError(err, "client fee")
+}
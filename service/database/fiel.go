@@ -0,0 +1,192 @@
+package database
+
+import (
+	"contabi-be/models"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// SaveFielCertificate stores the data of a FIEL certificate and its file, that
+// may be nil, sets the FIEL expiration of the client to the end of its validity
+// and clears the renewal status
+func (cs *ClientsService) SaveFielCertificate(cert models.FielCertificate, file []byte) (models.FielCertificate, error) {
+	tx, err := cs.db.Begin()
+	if err != nil {
+		return cert, err
+	}
+	defer tx.Rollback()
+
+	q := `
+		INSERT INTO client_fiel_certificates (
+			client_id, serial_number, rfc, name, valid_from, valid_to, file)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, uploaded_at
+	`
+
+	var uploadedAt time.Time
+	row := tx.QueryRow(q, cert.ClientID, cert.SerialNumber, cert.RFC, cert.Name, cert.ValidFrom, cert.ValidTo, file)
+	if err := row.Scan(&cert.ID, &uploadedAt); err != nil {
+		return cert, mapError(err, "fiel certificate")
+	}
+	cert.UploadedAt = uploadedAt.UTC().Format(time.RFC3339)
+	cert.FileStored = file != nil
+
+	expiration, _, _ := strings.Cut(cert.ValidTo, "T")
+	result, err := tx.Exec(`UPDATE clients SET fiel_expiration = $1, version = version + 1 WHERE id = $2`, expiration, cert.ClientID)
+	if err != nil {
+		return cert, mapError(err, "client")
+	}
+	if err := checkAffected(result, "client"); err != nil {
+		return cert, err
+	}
+
+	// the new certificate completes the renewal of the previous one
+	if _, err := tx.Exec(`DELETE FROM client_fiel_renewals WHERE client_id = $1`, cert.ClientID); err != nil {
+		return cert, mapError(err, "fiel renewal")
+	}
+
+	return cert, tx.Commit()
+}
+
+// GetFielCertificates retrieves the FIEL certificates of a client, the one
+// that expires last first
+func (cs *ClientsService) GetFielCertificates(clientID string) ([]models.FielCertificate, error) {
+	q := `
+		SELECT id, client_id, serial_number, rfc, name, valid_from, valid_to, file IS NOT NULL, uploaded_at
+		FROM client_fiel_certificates
+		WHERE client_id = $1
+		ORDER BY valid_to DESC, id DESC
+	`
+
+	rows, err := cs.db.Query(q, clientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	certs := []models.FielCertificate{}
+	for rows.Next() {
+		var c models.FielCertificate
+		var validFrom, validTo, uploadedAt time.Time
+		if err := rows.Scan(&c.ID, &c.ClientID, &c.SerialNumber, &c.RFC, &c.Name, &validFrom, &validTo, &c.FileStored, &uploadedAt); err != nil {
+			return nil, err
+		}
+		c.ValidFrom = validFrom.UTC().Format(time.RFC3339)
+		c.ValidTo = validTo.UTC().Format(time.RFC3339)
+		c.UploadedAt = uploadedAt.UTC().Format(time.RFC3339)
+
+		certs = append(certs, c)
+	}
+
+	return certs, rows.Err()
+}
+
+// GetExpiringFiel retrieves the active clients whose FIEL expires up to a date,
+// including the expired ones, with the renewal set for their current expiration
+func (cs *ClientsService) GetExpiringFiel(until string) ([]models.FielExpiration, error) {
+	q := `
+		SELECT
+			v.id,
+			v.name,
+			v.rfc,
+			CAST(v.fiel_expiration AS TEXT),
+			v.supervisor_id,
+			v.supervisor_name,
+			v.responsible_id,
+			v.responsible_name,
+			r.status,
+			r.appointment_date,
+			r.notes,
+			r.updated_at
+		FROM client_info_view v
+		JOIN clients c ON c.id = v.id
+		LEFT JOIN client_fiel_renewals r
+			ON r.client_id = v.id
+			AND r.fiel_expiration = CAST(v.fiel_expiration AS TEXT)
+		WHERE v.active = true
+			AND c.merged_into IS NULL
+			AND CAST(v.fiel_expiration AS TEXT) <> ''
+			AND substr(CAST(v.fiel_expiration AS TEXT), 1, 10) <= $1
+		ORDER BY substr(CAST(v.fiel_expiration AS TEXT), 1, 10) ASC, v.name ASC
+	`
+
+	rows, err := cs.db.Query(q, until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	expirations := []models.FielExpiration{}
+	for rows.Next() {
+		var e models.FielExpiration
+		var status, notes sql.NullString
+		var appointment, updatedAt sql.NullTime
+		if err := rows.Scan(
+			&e.ClientID, &e.Name, &e.RFC, &e.FielExpiration,
+			&e.SupervisorID, &e.SupervisorName, &e.ResponsibleID, &e.ResponsibleName,
+			&status, &appointment, &notes, &updatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		if status.Valid {
+			e.Renewal = &models.FielRenewal{
+				Status:         status.String,
+				Notes:          notes.String,
+				FielExpiration: e.FielExpiration,
+				UpdatedAt:      updatedAt.Time.UTC().Format(time.RFC3339),
+			}
+			if appointment.Valid {
+				e.Renewal.AppointmentDate = appointment.Time.Format(time.DateOnly)
+			}
+		}
+
+		expirations = append(expirations, e)
+	}
+
+	return expirations, rows.Err()
+}
+
+// SaveFielRenewal sets the renewal status of the FIEL of a client for its
+// current expiration, it fails when the client is no longer in the version given
+func (cs *ClientsService) SaveFielRenewal(clientID string, request models.FielRenewalRequest, version models.ClientVersion) (models.FielRenewal, error) {
+	tx, err := cs.db.Begin()
+	if err != nil {
+		return models.FielRenewal{}, err
+	}
+	defer tx.Rollback()
+
+	if err := claimClientVersion(tx, clientID, version); err != nil {
+		return models.FielRenewal{}, err
+	}
+
+	q := `
+		INSERT INTO client_fiel_renewals (client_id, status, appointment_date, notes, fiel_expiration, updated_at)
+		SELECT id, $2, $3, $4, CAST(fiel_expiration AS TEXT), $5
+		FROM clients
+		WHERE id = $1
+		ON CONFLICT (client_id) DO UPDATE SET
+			status = excluded.status,
+			appointment_date = excluded.appointment_date,
+			notes = excluded.notes,
+			fiel_expiration = excluded.fiel_expiration,
+			updated_at = excluded.updated_at
+		RETURNING fiel_expiration
+	`
+
+	renewal := models.FielRenewal{
+		Status:          request.Status,
+		AppointmentDate: request.AppointmentDate,
+		Notes:           request.Notes,
+	}
+	renewal.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	appointment := sql.NullString{String: request.AppointmentDate, Valid: request.AppointmentDate != ""}
+
+	row := tx.QueryRow(q, clientID, request.Status, appointment, request.Notes, renewal.UpdatedAt)
+	if err := row.Scan(&renewal.FielExpiration); err != nil {
+		return renewal, mapError(err, "client")
+	}
+
+	return renewal, tx.Commit()
+}
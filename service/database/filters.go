@@ -0,0 +1,96 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"contabi-be/models"
+)
+
+// feeValue reads the monthly fee of client_info_view as a number, an empty fee
+// counts as zero like in the memory store
+const feeValue = "COALESCE(CAST(NULLIF(TRIM(monthly_fee), '') AS NUMERIC), 0)"
+
+// clientSorts maps the sort options of the client listings to columns of client_info_view
+var clientSorts = map[string]string{
+	"name":            "name",
+	"rfc":             "rfc",
+	"fiel_expiration": "fiel_expiration",
+	"monthly_fee":     feeValue,
+}
+
+// clientListFilters builds the WHERE clause of a client listing over client_info_view
+func clientListFilters(params models.ClientListParams) (string, []any) {
+	var conditions []string
+	var args []any
+	add := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if params.RegimenID != "" {
+		add("CAST(regimen_id AS TEXT) = $%d", params.RegimenID)
+	}
+	if params.SupervisorID != "" {
+		add("CAST(supervisor_id AS TEXT) = $%d", params.SupervisorID)
+	}
+	if params.ResponsibleID != "" {
+		add("CAST(responsible_id AS TEXT) = $%d", params.ResponsibleID)
+	}
+	if params.EmisorID != "" {
+		add("CAST(emisor_id AS TEXT) = $%d", params.EmisorID)
+	}
+	if params.Active != nil {
+		add("active = $%d", *params.Active)
+	}
+	if params.State != "" {
+		add("id IN (SELECT s.id FROM clients s WHERE s.state = $%d)", params.State)
+	}
+	if params.MinFee != nil {
+		add(feeValue+" >= $%d", *params.MinFee)
+	}
+	if params.MaxFee != nil {
+		add(feeValue+" <= $%d", *params.MaxFee)
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// clientListOrder builds the ORDER BY and LIMIT of a client listing, the id
+// breaks the ties so the pages do not overlap
+func clientListOrder(params models.ClientListParams, argCount int) string {
+	column, ok := clientSorts[params.Sort]
+	if !ok {
+		column = clientSorts["name"]
+	}
+
+	order := "ASC"
+	if params.Order == "desc" {
+		order = "DESC"
+	}
+
+	return fmt.Sprintf(" ORDER BY %s %s, id ASC LIMIT $%d OFFSET $%d", column, order, argCount+1, argCount+2)
+}
+
+// clientListPage returns the arguments of the LIMIT and OFFSET of a client listing
+func clientListPage(params models.ClientListParams) []any {
+	return []any{params.PageSize, (params.Page - 1) * params.PageSize}
+}
+
+// servicedClients keeps the clients of client_info_view whose accountancy is
+// worked on, the suspended ones wait until they pay again
+const servicedClients = `id IN (SELECT s.id FROM clients s WHERE s.state IN ('onboarding', 'active'))`
+
+// clientState selects the state of the clients of a view with their id
+func clientState(view string) string {
+	return `(SELECT s.state FROM clients s WHERE s.id = ` + view + `.id) AS state`
+}
+
+// likePattern builds a LIKE pattern that matches texts containing the value, its wildcards are escaped
+func likePattern(value string) string {
+	return "%" + strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value) + "%"
+}
@@ -0,0 +1,278 @@
+package database
+
+import (
+	"contabi-be/models"
+	"database/sql"
+	"time"
+)
+
+// mergeQueries are the statements of a merge. The discard statements receive
+// the client whose records lose a month conflict and the one whose records are
+// kept, the move statements receive the source and the target. The months are
+// compared by their YYYY-MM prefix like the views do.
+var mergeQueries = struct {
+	discardPayments, discardHRPayments                           string
+	fillAssignments, discardStatusAssignments, discardStatuses   string
+	movePayments, moveHRPayments, moveStatuses                   string
+	moveContacts, moveDocuments, moveEvents                      string
+	copyAssignmentTypes, deleteAssignmentTypes, deactivateSource string
+	moveFees                                                     string
+	closeAssignmentPeriod, discardAssignmentPeriods              string
+	moveAssignmentPeriods                                        string
+	moveStateChanges, discardStateChanges                        string
+	moveFielCertificates, moveCSDCertificates                    string
+	moveFielRenewal, discardFielRenewal                          string
+}{
+	discardPayments: `
+		DELETE FROM client_payments
+		WHERE client_id = $1
+			AND EXISTS (
+				SELECT 1
+				FROM client_payments kept
+				WHERE kept.client_id = $2
+					AND substr(CAST(kept.last_payment_month AS TEXT), 1, 7) = substr(CAST(client_payments.last_payment_month AS TEXT), 1, 7)
+			)
+	`,
+	discardHRPayments: `
+		DELETE FROM client_hr_payments
+		WHERE client_id = $1
+			AND EXISTS (
+				SELECT 1
+				FROM client_hr_payments kept
+				WHERE kept.client_id = $2
+					AND kept.hr_entity_id = client_hr_payments.hr_entity_id
+					AND substr(CAST(kept.payment_month AS TEXT), 1, 7) = substr(CAST(client_hr_payments.payment_month AS TEXT), 1, 7)
+			)
+	`,
+	// the kept status of a month receives the assignment types it lacks
+	fillAssignments: `
+		INSERT INTO client_accountancy_assignments (status_id, assignment_type_id, assignment_status_id)
+		SELECT kept.id, a.assignment_type_id, a.assignment_status_id
+		FROM client_accountancy_status discarded
+		JOIN client_accountancy_status kept
+			ON kept.client_id = $2
+			AND substr(CAST(kept.month AS TEXT), 1, 7) = substr(CAST(discarded.month AS TEXT), 1, 7)
+		JOIN client_accountancy_assignments a ON a.status_id = discarded.id
+		WHERE discarded.client_id = $1
+		ON CONFLICT DO NOTHING
+	`,
+	discardStatusAssignments: `
+		DELETE FROM client_accountancy_assignments
+		WHERE status_id IN (
+			SELECT discarded.id
+			FROM client_accountancy_status discarded
+			JOIN client_accountancy_status kept
+				ON kept.client_id = $2
+				AND substr(CAST(kept.month AS TEXT), 1, 7) = substr(CAST(discarded.month AS TEXT), 1, 7)
+			WHERE discarded.client_id = $1
+		)
+	`,
+	discardStatuses: `
+		DELETE FROM client_accountancy_status
+		WHERE client_id = $1
+			AND EXISTS (
+				SELECT 1
+				FROM client_accountancy_status kept
+				WHERE kept.client_id = $2
+					AND substr(CAST(kept.month AS TEXT), 1, 7) = substr(CAST(client_accountancy_status.month AS TEXT), 1, 7)
+			)
+	`,
+	movePayments:   `UPDATE client_payments SET client_id = $2 WHERE client_id = $1`,
+	moveHRPayments: `UPDATE client_hr_payments SET client_id = $2 WHERE client_id = $1`,
+	moveStatuses:   `UPDATE client_accountancy_status SET client_id = $2 WHERE client_id = $1`,
+	moveContacts:   `UPDATE client_contacts SET client_id = $2 WHERE client_id = $1`,
+	moveDocuments:  `UPDATE client_documents SET client_id = $2 WHERE client_id = $1`,
+	moveEvents:     `UPDATE client_events SET client_id = $2 WHERE client_id = $1`,
+	copyAssignmentTypes: `
+		INSERT INTO client_assignments_types (client_id, assignment_type_id)
+		SELECT c.id, t.assignment_type_id
+		FROM client_assignments_types t
+		JOIN clients c ON c.id = $2
+		WHERE t.client_id = $1
+		ON CONFLICT DO NOTHING
+	`,
+	deleteAssignmentTypes: `DELETE FROM client_assignments_types WHERE client_id = $1`,
+	moveFees:              `UPDATE client_fees SET client_id = $2 WHERE client_id = $1`,
+	// the current period of the source ends with the merge, its periods that
+	// overlap one of the target are dropped since the target keeps its own, as
+	// are the ones left empty
+	closeAssignmentPeriod: `UPDATE client_assignment_history SET valid_to = $2 WHERE client_id = $1 AND valid_to IS NULL`,
+	discardAssignmentPeriods: `
+		DELETE FROM client_assignment_history
+		WHERE client_id = $1
+			AND (
+				CAST(valid_from AS TEXT) = CAST(valid_to AS TEXT)
+				OR EXISTS (
+					SELECT 1
+					FROM client_assignment_history kept
+					WHERE kept.client_id = $2
+						AND COALESCE(CAST(kept.valid_from AS TEXT), '') < COALESCE(CAST(client_assignment_history.valid_to AS TEXT), '9999-12-31')
+						AND COALESCE(CAST(client_assignment_history.valid_from AS TEXT), '') < COALESCE(CAST(kept.valid_to AS TEXT), '9999-12-31')
+				)
+			)
+	`,
+	moveAssignmentPeriods: `UPDATE client_assignment_history SET client_id = $2 WHERE client_id = $1`,
+	// the target keeps its changes of state, the ones of the source are moved
+	// when they took effect before the first change of the target. A target
+	// without changes was always in its current state.
+	moveStateChanges: `
+		UPDATE client_state_changes
+		SET client_id = $2
+		WHERE client_id = $1
+			AND effective_date < (SELECT MIN(kept.effective_date) FROM client_state_changes kept WHERE kept.client_id = $2)
+	`,
+	discardStateChanges:  `DELETE FROM client_state_changes WHERE client_id = $1`,
+	moveFielCertificates: `UPDATE client_fiel_certificates SET client_id = $2 WHERE client_id = $1`,
+	moveCSDCertificates:  `UPDATE client_csd_certificates SET client_id = $2 WHERE client_id = $1`,
+	// a client has a single renewal, the one of the target is kept
+	moveFielRenewal: `
+		UPDATE client_fiel_renewals
+		SET client_id = $2
+		WHERE client_id = $1
+			AND NOT EXISTS (SELECT 1 FROM client_fiel_renewals kept WHERE kept.client_id = $2)
+	`,
+	discardFielRenewal: `DELETE FROM client_fiel_renewals WHERE client_id = $1`,
+	deactivateSource: `
+		UPDATE clients
+		SET active = false, state = 'terminated', merged_into = $2, version = version + 1
+		WHERE id = $1 AND merged_into IS NULL
+	`,
+}
+
+// MergeClients moves the payments, nomina payments, accountancy statuses,
+// fees, assignment types, contacts, documents, certificates and timeline
+// events of the source client to the target in one transaction and
+// deactivates the source pointing it to the target. When both clients have
+// records of a month the ones of the client named by keep survive, its fees
+// apply from the month they start. The target keeps its assignment history,
+// changes of state and FIEL renewal, the ones of the source are moved where
+// they do not overlap them.
+func (cs *ClientsService) MergeClients(sourceID, targetID, keep string) (models.ClientMergeResult, error) {
+	result := models.ClientMergeResult{SourceID: sourceID, TargetID: targetID, Conflicts: keep}
+
+	tx, err := cs.db.Begin()
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback()
+
+	// locks the source first so two merges of the same client can not interleave
+	res, err := tx.Exec(mergeQueries.deactivateSource, sourceID, targetID)
+	if err != nil {
+		return result, mapError(err, "client")
+	}
+	if err := checkAffected(res, "unmerged source client"); err != nil {
+		return result, err
+	}
+
+	discardedID, keptID := sourceID, targetID
+	if keep == models.MergeKeepSource {
+		discardedID, keptID = targetID, sourceID
+	}
+
+	steps := []struct {
+		query   string
+		args    []any
+		counter *int
+	}{
+		{mergeQueries.discardPayments, []any{discardedID, keptID}, &result.Payments.Discarded},
+		{mergeQueries.movePayments, []any{sourceID, targetID}, &result.Payments.Moved},
+		{mergeQueries.discardHRPayments, []any{discardedID, keptID}, &result.HRPayments.Discarded},
+		{mergeQueries.moveHRPayments, []any{sourceID, targetID}, &result.HRPayments.Moved},
+		{mergeQueries.fillAssignments, []any{discardedID, keptID}, nil},
+		{mergeQueries.discardStatusAssignments, []any{discardedID, keptID}, nil},
+		{mergeQueries.discardStatuses, []any{discardedID, keptID}, &result.AccountancyStatuses.Discarded},
+		{mergeQueries.moveStatuses, []any{sourceID, targetID}, &result.AccountancyStatuses.Moved},
+		{mergeQueries.copyAssignmentTypes, []any{sourceID, targetID}, &result.AssignmentTypes.Moved},
+		{mergeQueries.deleteAssignmentTypes, []any{sourceID}, &result.AssignmentTypes.Discarded},
+		{mergeQueries.moveContacts, []any{sourceID, targetID}, &result.Contacts.Moved},
+		{mergeQueries.moveDocuments, []any{sourceID, targetID}, &result.Documents.Moved},
+		{mergeQueries.moveEvents, []any{sourceID, targetID}, &result.Events.Moved},
+		{mergeQueries.closeAssignmentPeriod, []any{sourceID, time.Now().Format(time.DateOnly)}, nil},
+		{mergeQueries.discardAssignmentPeriods, []any{sourceID, targetID}, &result.AssignmentHistory.Discarded},
+		{mergeQueries.moveAssignmentPeriods, []any{sourceID, targetID}, &result.AssignmentHistory.Moved},
+		{mergeQueries.moveStateChanges, []any{sourceID, targetID}, &result.StateChanges.Moved},
+		{mergeQueries.discardStateChanges, []any{sourceID}, &result.StateChanges.Discarded},
+		{mergeQueries.moveFielCertificates, []any{sourceID, targetID}, &result.FielCertificates.Moved},
+		{mergeQueries.moveCSDCertificates, []any{sourceID, targetID}, &result.CSDCertificates.Moved},
+		{mergeQueries.moveFielRenewal, []any{sourceID, targetID}, &result.FielRenewals.Moved},
+		{mergeQueries.discardFielRenewal, []any{sourceID}, &result.FielRenewals.Discarded},
+	}
+
+	for _, step := range steps {
+		res, err := tx.Exec(step.query, step.args...)
+		if err != nil {
+			return result, mapError(err, "client merge")
+		}
+		if step.counter == nil {
+			continue
+		}
+		if *step.counter, err = rowsAffected(res); err != nil {
+			return result, err
+		}
+	}
+
+	if result.Fees, err = mergeFees(tx, sourceID, targetID, discardedID, keptID); err != nil {
+		return result, err
+	}
+
+	// the deleted assignment types that were not copied already existed in the target
+	result.AssignmentTypes.Discarded -= result.AssignmentTypes.Moved
+
+	if err := tx.Commit(); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// mergeFees moves the fees of the source to the target as planned by
+// models.MergeFees
+func mergeFees(tx *sql.Tx, sourceID, targetID, discardedID, keptID string) (models.MergeCount, error) {
+	var count models.MergeCount
+
+	fees := map[string][]models.ClientFee{}
+	rows, err := tx.Query(`SELECT `+feeColumns+` FROM client_fees WHERE client_id IN ($1, $2)`, sourceID, targetID)
+	if err != nil {
+		return count, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		f, err := scanFee(rows)
+		if err != nil {
+			return count, err
+		}
+		fees[f.ClientID] = append(fees[f.ClientID], f)
+	}
+	if err := rows.Err(); err != nil {
+		return count, err
+	}
+
+	merge := models.MergeFees(fees[keptID], fees[discardedID])
+	for _, id := range merge.Discard {
+		if _, err := tx.Exec(`DELETE FROM client_fees WHERE id = $1`, id); err != nil {
+			return count, mapError(err, "client fee")
+		}
+	}
+	count.Discarded = len(merge.Discard)
+
+	if merge.DateFirst != "" {
+		if _, err := tx.Exec(`UPDATE client_fees SET effective_month = $1 WHERE id = $2`, merge.Month, merge.DateFirst); err != nil {
+			return count, mapError(err, "client fee")
+		}
+	}
+
+	res, err := tx.Exec(mergeQueries.moveFees, sourceID, targetID)
+	if err != nil {
+		return count, mapError(err, "client fee")
+	}
+	count.Moved, err = rowsAffected(res)
+
+	return count, err
+}
+
+// rowsAffected returns the rows touched by a statement as an int
+func rowsAffected(result sql.Result) (int, error) {
+	rows, err := result.RowsAffected()
+	return int(rows), err
+}
@@ -0,0 +1,176 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+
+	"contabi-be/config"
+	"contabi-be/models"
+)
+
+// Users and emisor the SQLite migrations seed
+const (
+	testSupervisorID  = "00000000-0000-4000-8000-000000000002"
+	testResponsibleID = "00000000-0000-4000-8000-000000000003"
+)
+
+// newTestClients opens a migrated SQLite database in a temporary directory
+func newTestClients(t *testing.T) *ClientsService {
+	t.Helper()
+
+	dbs, err := NewDatabaseService(config.Config{DBDriver: DriverSQLite, DBName: filepath.Join(t.TempDir(), "contabi.db")})
+	if err != nil {
+		t.Fatalf("opening the database: %v", err)
+	}
+	t.Cleanup(func() { dbs.DB.Close() })
+
+	return NewClientsService(dbs.DB)
+}
+
+// createTestClient creates an active client and returns its id
+func createTestClient(t *testing.T, cs *ClientsService, name, rfc string) string {
+	t.Helper()
+
+	client := models.Client{Name: name, RFC: rfc, RegimenID: "612", MonthlyFee: "1000"}
+	assignments := models.ClientAssignments{SupervisorID: testSupervisorID, ResponsibleID: testResponsibleID, EmisorID: "1"}
+	if err := cs.CreateClient(client, assignments); err != nil {
+		t.Fatalf("creating %s: %v", name, err)
+	}
+
+	summaries, err := cs.GetClientSummaries()
+	if err != nil {
+		t.Fatalf("listing the clients: %v", err)
+	}
+	for _, s := range summaries {
+		if s.Name == name {
+			return s.ID
+		}
+	}
+
+	t.Fatalf("client %s not created", name)
+	return ""
+}
+
+// payTestMonths records a payment of each month for a client
+func payTestMonths(t *testing.T, cs *ClientsService, clientID string, months ...string) {
+	t.Helper()
+
+	for _, m := range months {
+		version, err := cs.GetClientVersion(clientID)
+		if err != nil {
+			t.Fatalf("reading the version: %v", err)
+		}
+		payment := models.ClientPayment{LastPaymentMonth: m + "-01", LastPaymentDate: m + "-05"}
+		event := models.ClientEvent{ClientID: clientID, Type: models.EventPayment, Message: "Payment of " + m}
+		if err := cs.UpdateClientPayment(clientID, payment, version, event); err != nil {
+			t.Fatalf("paying %s: %v", m, err)
+		}
+	}
+}
+
+func TestMergeClients(t *testing.T) {
+	// both clients paid 2024-02, the target 2024-01 and the source 2024-03, the
+	// events of the payments of the source move with it
+	tests := []struct {
+		keep string
+		want models.ClientMergeResult
+	}{
+		{
+			keep: models.MergeKeepTarget,
+			want: models.ClientMergeResult{
+				Payments:          models.MergeCount{Moved: 1, Discarded: 1},
+				Events:            models.MergeCount{Moved: 2},
+				Fees:              models.MergeCount{Discarded: 1},
+				AssignmentHistory: models.MergeCount{Discarded: 1},
+			},
+		},
+		{
+			keep: models.MergeKeepSource,
+			want: models.ClientMergeResult{
+				Payments:          models.MergeCount{Moved: 2, Discarded: 1},
+				Events:            models.MergeCount{Moved: 2},
+				Fees:              models.MergeCount{Moved: 1, Discarded: 1},
+				AssignmentHistory: models.MergeCount{Discarded: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		cs := newTestClients(t)
+		targetID := createTestClient(t, cs, "Papelería Torres", "TOPA850203KJA")
+		sourceID := createTestClient(t, cs, "Papeleria Torres", "MUGA800101ABA")
+		payTestMonths(t, cs, targetID, "2024-01", "2024-02")
+		payTestMonths(t, cs, sourceID, "2024-02", "2024-03")
+
+		result, err := cs.MergeClients(sourceID, targetID, tt.keep)
+		if err != nil {
+			t.Fatalf("keeping the %s: %v", tt.keep, err)
+		}
+
+		tt.want.SourceID, tt.want.TargetID, tt.want.Conflicts = sourceID, targetID, tt.keep
+		if result != tt.want {
+			t.Errorf("keeping the %s the merge is %+v, want %+v", tt.keep, result, tt.want)
+		}
+
+		payments, err := cs.GetClientPayments(targetID)
+		if err != nil {
+			t.Fatalf("reading the payments: %v", err)
+		}
+		months := map[string]int{}
+		for _, p := range payments {
+			months[p.LastPaymentMonth[:7]]++
+		}
+		for _, m := range []string{"2024-01", "2024-02", "2024-03"} {
+			if months[m] != 1 {
+				t.Errorf("keeping the %s the target has %d payments of %s, want 1", tt.keep, months[m], m)
+			}
+		}
+
+		fees, err := cs.GetClientFees(targetID)
+		if err != nil {
+			t.Fatalf("reading the fees: %v", err)
+		}
+		if len(fees) != 1 {
+			t.Errorf("keeping the %s the target has the fees %+v, want 1", tt.keep, fees)
+		}
+
+		source, err := cs.GetClientSummary(sourceID)
+		if err != nil {
+			t.Fatalf("reading the source: %v", err)
+		}
+		if source.Active || source.State != models.ClientTerminated || source.MergedInto != targetID {
+			t.Errorf("keeping the %s the source is %+v, want it terminated and merged into the target", tt.keep, source)
+		}
+
+		if _, err := cs.MergeClients(sourceID, targetID, tt.keep); err == nil {
+			t.Errorf("keeping the %s the source was merged twice", tt.keep)
+		}
+	}
+}
+
+func TestPaymentEventIsPartOfThePayment(t *testing.T) {
+	cs := newTestClients(t)
+	clientID := createTestClient(t, cs, "Papelería Torres", "TOPA850203KJA")
+	version, err := cs.GetClientVersion(clientID)
+	if err != nil {
+		t.Fatalf("reading the version: %v", err)
+	}
+
+	// an event of a user that does not exist fails after the payment is inserted
+	payment := models.ClientPayment{LastPaymentMonth: "2024-01-01", LastPaymentDate: "2024-01-05"}
+	event := models.ClientEvent{ClientID: clientID, Type: models.EventPayment, Message: "Payment of 2024-01", UserID: testResponsibleID + "0"}
+	if err := cs.UpdateClientPayment(clientID, payment, version, event); err == nil {
+		t.Fatal("the payment was registered with an event that can not be recorded")
+	}
+
+	payments, err := cs.GetClientPayments(clientID)
+	if err != nil {
+		t.Fatalf("reading the payments: %v", err)
+	}
+	if len(payments) != 0 {
+		t.Errorf("the payments are %+v, want none", payments)
+	}
+	if current, _ := cs.GetClientVersion(clientID); current != version {
+		t.Errorf("the version moved from %v to %v", version, current)
+	}
+}
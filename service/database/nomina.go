@@ -35,11 +35,8 @@ func (ns *NominasService) CreateClientPaymentRecord(clientPaymentRecord models.C
 		clientPaymentRecord.PaymentMonth, clientPaymentRecord.Amount,
 		clientPaymentRecord.Paid, clientPaymentRecord.Month,
 	)
-	if err != nil {
-		return err
-	}
 
-	return nil
+	return mapError(err, "client payment record")
 }
 
 // GetClientsWithPendingPaymentsByHREntityID gets the list of all clients with pending payments of specific HR entity
@@ -117,21 +114,47 @@ func (ns *NominasService) GetClientPendingPaymentsByHREntityIDDetails(clientID,
 
 // UpdateClientPaymentRecord updates a client payment record
 func (ns *NominasService) UpdateClientPaymentRecord(clientPaymentRecord models.UpdateClientHRPayment) error {
+	paid, err := parseBool(clientPaymentRecord.Paid, "client payment record paid")
+	if err != nil {
+		return err
+	}
+
 	q := `
 		UPDATE client_hr_payments 
 		SET paid = $1, payment_month = $2, amount = $3, month = $4
 		WHERE id = $5
 	`
 
-	_, err := ns.db.Exec(
-		q, clientPaymentRecord.Paid, clientPaymentRecord.PaymentMonth,
+	result, err := ns.db.Exec(
+		q, paid, clientPaymentRecord.PaymentMonth,
 		clientPaymentRecord.Amount, clientPaymentRecord.Month, clientPaymentRecord.ID,
 	)
 	if err != nil {
-		return err
+		return mapError(err, "client payment record")
 	}
 
-	return nil
+	return checkAffected(result, "client payment record")
+}
+
+// GetClientPaymentRecord gets a client payment record
+func (ns *NominasService) GetClientPaymentRecord(id string) (models.ClientHRPayment, error) {
+	q := `
+		SELECT
+			chp.id,
+			chp.client_id,
+			chp.hr_entity_id,
+			chp.payment_month,
+			chp.amount,
+			chp.paid,
+			chp.month
+		FROM client_hr_payments chp
+		WHERE chp.id = $1
+	`
+
+	var p models.ClientHRPayment
+	err := ns.db.QueryRow(q, id).Scan(&p.ID, &p.ClientID, &p.HREntityID, &p.PaymentMonth, &p.Amount, &p.Paid, &p.Month)
+
+	return p, mapError(err, "client payment record")
 }
 
 // GetClientHRPaymentsHistory gets all the payments of a specific client and HR entity
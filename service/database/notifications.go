@@ -0,0 +1,101 @@
+package database
+
+import (
+	"contabi-be/models"
+	"database/sql"
+	"time"
+)
+
+// NotificationsService keeps the notifications of the users
+type NotificationsService struct {
+	db *sql.DB
+}
+
+func NewNotificationsService(db *sql.DB) *NotificationsService {
+	return &NotificationsService{db: db}
+}
+
+// CreateNotifications stores the notifications whose key was not sent to the
+// user before and returns how many were stored
+func (ns *NotificationsService) CreateNotifications(notifications []models.Notification) (int, error) {
+	q := `
+		INSERT INTO notifications (user_id, client_id, kind, message, key)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, key) DO NOTHING
+	`
+
+	tx, err := ns.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	created := 0
+	for _, n := range notifications {
+		clientID := sql.NullString{String: n.ClientID, Valid: n.ClientID != ""}
+		result, err := tx.Exec(q, n.UserID, clientID, n.Kind, n.Message, n.Key)
+		if err != nil {
+			return 0, mapError(err, "notification")
+		}
+
+		rows, err := rowsAffected(result)
+		if err != nil {
+			return 0, err
+		}
+		created += rows
+	}
+
+	return created, tx.Commit()
+}
+
+// GetNotifications retrieves the notifications of a user, the newest first
+func (ns *NotificationsService) GetNotifications(userID string, params models.NotificationParams) ([]models.Notification, error) {
+	q := `
+		SELECT id, user_id, client_id, kind, message, created_at, read_at
+		FROM notifications
+		WHERE user_id = $1 AND ($2 = false OR read_at IS NULL)
+		ORDER BY created_at DESC, id DESC
+	`
+
+	rows, err := ns.db.Query(q, userID, params.Unread)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := []models.Notification{}
+	for rows.Next() {
+		var n models.Notification
+		var clientID sql.NullString
+		var createdAt time.Time
+		var readAt sql.NullTime
+		if err := rows.Scan(&n.ID, &n.UserID, &clientID, &n.Kind, &n.Message, &createdAt, &readAt); err != nil {
+			return nil, err
+		}
+		n.ClientID = clientID.String
+		n.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+		if readAt.Valid {
+			n.ReadAt = readAt.Time.UTC().Format(time.RFC3339)
+		}
+
+		notifications = append(notifications, n)
+	}
+
+	return notifications, rows.Err()
+}
+
+// MarkNotificationRead marks a notification of a user as read, reading it again keeps the first time
+func (ns *NotificationsService) MarkNotificationRead(userID, notificationID string) error {
+	q := `
+		UPDATE notifications
+		SET read_at = COALESCE(read_at, $3)
+		WHERE id = $1 AND user_id = $2
+	`
+
+	result, err := ns.db.Exec(q, notificationID, userID, time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return mapError(err, "notification")
+	}
+
+	return checkAffected(result, "notification")
+}
@@ -0,0 +1,141 @@
+package database
+
+import (
+	"contabi-be/models"
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+const offboardingColumns = `id, client_id, reason, last_serviced_month, CAST(end_date AS TEXT), months_due, outstanding_balance,
+	closed_months, revoked_clave_ciec, revoked_clave_fiel, revoked_csd_certificates, revoked_fiel_files, handover, user_id, created_at`
+
+// scanOffboarding reads an offboarding selected with offboardingColumns
+func scanOffboarding(row interface{ Scan(dest ...any) error }) (models.ClientOffboarding, error) {
+	var o models.ClientOffboarding
+	var handover []byte
+	var userID sql.NullString
+	var createdAt time.Time
+	if err := row.Scan(&o.ID, &o.ClientID, &o.Reason, &o.LastServicedMonth, &o.EndDate, &o.MonthsDue, &o.OutstandingBalance,
+		&o.ClosedMonths, &o.Revoked.ClaveCIEC, &o.Revoked.ClaveFiel, &o.Revoked.CSDCertificates, &o.Revoked.FielFiles,
+		&handover, &userID, &createdAt); err != nil {
+		return o, err
+	}
+	if err := json.Unmarshal(handover, &o.Handover); err != nil {
+		return o, err
+	}
+	o.UserID = userID.String
+	o.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+
+	return o, nil
+}
+
+// GetClientOffboarding retrieves the latest offboarding of a client
+func (cs *ClientsService) GetClientOffboarding(clientID string) (models.ClientOffboarding, error) {
+	q := `SELECT ` + offboardingColumns + `
+		FROM client_offboardings
+		WHERE client_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1
+	`
+
+	o, err := scanOffboarding(cs.db.QueryRow(q, clientID))
+	return o, mapError(err, "client offboarding")
+}
+
+// OffboardClient terminates a client with the change of state given, none
+// when it was already terminated, closes its open accountancy months, revokes
+// its stored credentials and records the offboarding with its event in the
+// timeline, all of it or nothing. It fails when the client is no longer in the
+// version given.
+func (cs *ClientsService) OffboardClient(change *models.ClientStateChange, offboarding models.ClientOffboarding, event models.ClientEvent, version models.ClientVersion) (models.ClientOffboarding, error) {
+	tx, err := cs.db.Begin()
+	if err != nil {
+		return offboarding, err
+	}
+	defer tx.Rollback()
+
+	if err := claimClientVersion(tx, offboarding.ClientID, version); err != nil {
+		return offboarding, err
+	}
+
+	if change != nil {
+		if _, err := changeClientState(tx, *change); err != nil {
+			return offboarding, err
+		}
+	}
+
+	result, err := tx.Exec(`
+		UPDATE client_accountancy_status
+		SET closed_at = $1, version = version + 1
+		WHERE client_id = $2 AND closed_at IS NULL
+	`, time.Now().UTC(), offboarding.ClientID)
+	if err != nil {
+		return offboarding, mapError(err, "accountancy status")
+	}
+	if offboarding.ClosedMonths, err = rowsAffected(result); err != nil {
+		return offboarding, err
+	}
+
+	if offboarding.Revoked, err = revokeCredentials(tx, offboarding.ClientID); err != nil {
+		return offboarding, err
+	}
+
+	handover, err := json.Marshal(offboarding.Handover)
+	if err != nil {
+		return offboarding, err
+	}
+
+	insert := `
+		INSERT INTO client_offboardings (
+			client_id, reason, last_serviced_month, end_date, months_due, outstanding_balance, closed_months,
+			revoked_clave_ciec, revoked_clave_fiel, revoked_csd_certificates, revoked_fiel_files, handover, user_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING ` + offboardingColumns
+
+	userID := sql.NullString{String: offboarding.UserID, Valid: offboarding.UserID != ""}
+	saved, err := scanOffboarding(tx.QueryRow(insert, offboarding.ClientID, offboarding.Reason, offboarding.LastServicedMonth,
+		offboarding.EndDate, offboarding.MonthsDue, offboarding.OutstandingBalance, offboarding.ClosedMonths,
+		offboarding.Revoked.ClaveCIEC, offboarding.Revoked.ClaveFiel, offboarding.Revoked.CSDCertificates,
+		offboarding.Revoked.FielFiles, string(handover), userID))
+	if err != nil {
+		return offboarding, mapError(err, "client offboarding")
+	}
+
+	if _, err := createClientEvent(tx, event); err != nil {
+		return offboarding, err
+	}
+
+	return saved, tx.Commit()
+}
+
+// revokeCredentials clears the claves of a client, deletes its CSD with their
+// keys and clears the files of its FIEL certificates
+func revokeCredentials(tx *sql.Tx, clientID string) (models.RevokedCredentials, error) {
+	var revoked models.RevokedCredentials
+
+	q := `SELECT COALESCE(clave_ciec, '') <> '', COALESCE(clave_fiel, '') <> '' FROM clients WHERE id = $1`
+	if err := tx.QueryRow(q, clientID).Scan(&revoked.ClaveCIEC, &revoked.ClaveFiel); err != nil {
+		return revoked, mapError(err, "client")
+	}
+
+	if _, err := tx.Exec(`UPDATE clients SET clave_ciec = '', clave_fiel = '' WHERE id = $1`, clientID); err != nil {
+		return revoked, mapError(err, "client")
+	}
+
+	result, err := tx.Exec(`DELETE FROM client_csd_certificates WHERE client_id = $1`, clientID)
+	if err != nil {
+		return revoked, mapError(err, "csd certificate")
+	}
+	if revoked.CSDCertificates, err = rowsAffected(result); err != nil {
+		return revoked, err
+	}
+
+	result, err = tx.Exec(`UPDATE client_fiel_certificates SET file = NULL WHERE client_id = $1 AND file IS NOT NULL`, clientID)
+	if err != nil {
+		return revoked, mapError(err, "fiel certificate")
+	}
+	revoked.FielFiles, err = rowsAffected(result)
+
+	return revoked, err
+}
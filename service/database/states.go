@@ -0,0 +1,126 @@
+package database
+
+import (
+	"contabi-be/models"
+	"database/sql"
+	"time"
+)
+
+const stateChangeColumns = `id, client_id, from_state, to_state, reason, CAST(effective_date AS TEXT), user_id, created_at`
+
+// scanStateChange reads a change of state selected with stateChangeColumns
+func scanStateChange(row interface{ Scan(dest ...any) error }) (models.ClientStateChange, error) {
+	var c models.ClientStateChange
+	var userID sql.NullString
+	var createdAt time.Time
+	if err := row.Scan(&c.ID, &c.ClientID, &c.FromState, &c.ToState, &c.Reason, &c.EffectiveDate, &userID, &createdAt); err != nil {
+		return c, err
+	}
+	c.UserID = userID.String
+	c.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+
+	return c, nil
+}
+
+// GetClientStateChanges retrieves the changes of state of a client, the
+// latest effective date first
+func (cs *ClientsService) GetClientStateChanges(clientID string) ([]models.ClientStateChange, error) {
+	return cs.queryStateChanges(`SELECT `+stateChangeColumns+`
+		FROM client_state_changes
+		WHERE client_id = $1
+		ORDER BY effective_date DESC, id DESC
+	`, clientID)
+}
+
+// GetAllClientStateChanges retrieves the changes of state of every client
+func (cs *ClientsService) GetAllClientStateChanges() ([]models.ClientStateChange, error) {
+	return cs.queryStateChanges(`SELECT ` + stateChangeColumns + `
+		FROM client_state_changes
+		ORDER BY client_id, effective_date DESC, id DESC
+	`)
+}
+
+// queryStateChanges runs a query of the changes selected with stateChangeColumns
+func (cs *ClientsService) queryStateChanges(q string, args ...any) ([]models.ClientStateChange, error) {
+	rows, err := cs.db.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []models.ClientStateChange{}
+	for rows.Next() {
+		c, err := scanStateChange(rows)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+	}
+
+	return changes, rows.Err()
+}
+
+// ChangeClientState moves a client from the state of the change to its new
+// one and records the change in its history and its timeline, it fails with a
+// conflict when the client is no longer in the state the change starts from
+// or in the version given
+func (cs *ClientsService) ChangeClientState(change models.ClientStateChange, version models.ClientVersion) (models.ClientStateChange, error) {
+	tx, err := cs.db.Begin()
+	if err != nil {
+		return change, err
+	}
+	defer tx.Rollback()
+
+	if err := claimClientVersion(tx, change.ClientID, version); err != nil {
+		return change, err
+	}
+
+	saved, err := changeClientState(tx, change)
+	if err != nil {
+		return saved, err
+	}
+	if _, err := createClientEvent(tx, change.Event()); err != nil {
+		return saved, err
+	}
+
+	return saved, tx.Commit()
+}
+
+// changeClientState runs the statements of a change of state of a client, the
+// version of the client was claimed in the same transaction
+func changeClientState(tx *sql.Tx, change models.ClientStateChange) (models.ClientStateChange, error) {
+	q := `
+		UPDATE clients
+		SET state = $1, active = $2
+		WHERE id = $3 AND state = $4
+	`
+
+	result, err := tx.Exec(q, change.ToState, models.IsActiveState(change.ToState), change.ClientID, change.FromState)
+	if err != nil {
+		return change, mapError(err, "client")
+	}
+	if changed, err := rowsAffected(result); err != nil {
+		return change, err
+	} else if changed == 0 {
+		var state string
+		if err := tx.QueryRow(`SELECT state FROM clients WHERE id = $1`, change.ClientID).Scan(&state); err != nil {
+			return change, mapError(err, "client")
+		}
+
+		return change, models.NewConflictError("The client is "+state+", it changed state since it was read", nil)
+	}
+
+	insert := `
+		INSERT INTO client_state_changes (client_id, from_state, to_state, reason, effective_date, user_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + stateChangeColumns
+
+	userID := sql.NullString{String: change.UserID, Valid: change.UserID != ""}
+	saved, err := scanStateChange(tx.QueryRow(insert, change.ClientID, change.FromState, change.ToState,
+		change.Reason, change.EffectiveDate, userID))
+	if err != nil {
+		return change, mapError(err, "client state change")
+	}
+
+	return saved, nil
+}
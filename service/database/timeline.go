@@ -0,0 +1,89 @@
+package database
+
+import (
+	"contabi-be/models"
+	"database/sql"
+	"time"
+)
+
+// TimelineService keeps the notes and the recorded events of the clients
+type TimelineService struct {
+	db *sql.DB
+}
+
+func NewTimelineService(db *sql.DB) *TimelineService {
+	return &TimelineService{db: db}
+}
+
+// CreateClientEvent adds an event to the timeline of a client
+func (ts *TimelineService) CreateClientEvent(event models.ClientEvent) (models.ClientEvent, error) {
+	return createClientEvent(ts.db, event)
+}
+
+// createClientEvent inserts an event with the connection or the transaction
+// of the write it records
+func createClientEvent(db interface {
+	QueryRow(query string, args ...any) *sql.Row
+}, event models.ClientEvent) (models.ClientEvent, error) {
+	q := `
+		INSERT INTO client_events (client_id, type, message, user_id)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+
+	var createdAt time.Time
+	userID := sql.NullString{String: event.UserID, Valid: event.UserID != ""}
+	if err := db.QueryRow(q, event.ClientID, event.Type, event.Message, userID).Scan(&event.ID, &createdAt); err != nil {
+		return event, mapError(err, "client event")
+	}
+	event.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+
+	if event.UserID != "" {
+		if err := db.QueryRow(`SELECT username FROM users WHERE id = $1`, event.UserID).Scan(&event.UserName); err != nil {
+			return event, mapError(err, "user")
+		}
+	}
+
+	return event, nil
+}
+
+// GetClientTimeline retrieves a page of the events of a client, the newest
+// first, of every type when the params have none
+func (ts *TimelineService) GetClientTimeline(clientID string, params models.TimelineParams) (models.ClientEventPage, error) {
+	page := models.ClientEventPage{Page: params.Page, PageSize: params.PageSize, Items: []models.ClientEvent{}}
+
+	count := `SELECT COUNT(*) FROM client_events WHERE client_id = $1 AND ($2 = '' OR type = $2)`
+	if err := ts.db.QueryRow(count, clientID, params.Type).Scan(&page.Total); err != nil {
+		return page, mapError(err, "client events")
+	}
+
+	q := `
+		SELECT e.id, e.client_id, e.type, e.message, e.user_id, COALESCE(u.username, ''), e.created_at
+		FROM client_events e
+		LEFT JOIN users u ON u.id = e.user_id
+		WHERE e.client_id = $1 AND ($2 = '' OR e.type = $2)
+		ORDER BY e.created_at DESC, e.id DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := ts.db.Query(q, clientID, params.Type, params.PageSize, (params.Page-1)*params.PageSize)
+	if err != nil {
+		return page, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e models.ClientEvent
+		var userID sql.NullString
+		var createdAt time.Time
+		if err := rows.Scan(&e.ID, &e.ClientID, &e.Type, &e.Message, &userID, &e.UserName, &createdAt); err != nil {
+			return page, err
+		}
+		e.UserID = userID.String
+		e.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+
+		page.Items = append(page.Items, e)
+	}
+
+	return page, rows.Err()
+}
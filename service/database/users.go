@@ -2,7 +2,6 @@ package database
 
 import (
 	"database/sql"
-	"fmt"
 
 	"contabi-be/models"
 )
@@ -66,10 +65,7 @@ func (us *UsersService) GetUserByID(userID string) (models.User, error) {
 	row := us.db.QueryRow(q, userID)
 	var u models.User
 	if err := row.Scan(&u.ID, &u.Username, &u.Active, &u.Role); err != nil {
-		if err == sql.ErrNoRows {
-			return models.User{}, nil
-		}
-		return u, err
+		return models.User{}, mapError(err, "user")
 	}
 
 	return u, nil
@@ -92,7 +88,7 @@ func (us *UsersService) CreateUser(user models.User) error {
 	row := tx.QueryRow(q, user.Username, user.Password, user.Active)
 	var id string
 	if err := row.Scan(&id); err != nil {
-		return err
+		return mapError(err, "user")
 	}
 
 	queryRole := `
@@ -102,7 +98,7 @@ func (us *UsersService) CreateUser(user models.User) error {
 
 	_, err = tx.Exec(queryRole, id, user.Role)
 	if err != nil {
-		return err
+		return mapError(err, "user role")
 	}
 
 	if err := tx.Commit(); err != nil {
@@ -120,12 +116,12 @@ func (us *UsersService) UpdateUser(user models.User) error {
 		WHERE id = $2
 	`
 
-	_, err := us.db.Exec(q, user.Username, user.ID)
+	result, err := us.db.Exec(q, user.Username, user.ID)
 	if err != nil {
-		return err
+		return mapError(err, "user")
 	}
 
-	return nil
+	return checkAffected(result, "user")
 }
 
 // UpdateUserRole updates the user role
@@ -136,12 +132,12 @@ func (us *UsersService) UpdateUserRole(user models.User) error {
 		WHERE user_id = $2
 	`
 
-	_, err := us.db.Exec(q, user.Role, user.ID)
+	result, err := us.db.Exec(q, user.Role, user.ID)
 	if err != nil {
-		return err
+		return mapError(err, "user role")
 	}
 
-	return nil
+	return checkAffected(result, "user role")
 }
 
 // PutUserPassword updtaes the user password
@@ -152,12 +148,49 @@ func (us *UsersService) PutUserPassword(user models.User) error {
 		WHERE id = $2
 		`
 
-	_, err := us.db.Exec(query, user.Password, user.ID)
+	result, err := us.db.Exec(query, user.Password, user.ID)
+	if err != nil {
+		return mapError(err, "user")
+	}
+
+	return checkAffected(result, "user")
+}
+
+// PatchUser changes the username, role and password set in the patch in a
+// single transaction, the password comes already hashed
+func (us *UsersService) PatchUser(userID string, patch models.UserPatch) error {
+	tx, err := us.db.Begin()
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
+
+	var id string
+	if err := tx.QueryRow(`SELECT id FROM users WHERE id = $1`, userID).Scan(&id); err != nil {
+		return mapError(err, "user")
+	}
 
-	return nil
+	if patch.Username.Set {
+		if _, err := tx.Exec(`UPDATE users SET username = $1 WHERE id = $2`, patch.Username.Value, userID); err != nil {
+			return mapError(err, "user")
+		}
+	}
+	if patch.Role.Set {
+		result, err := tx.Exec(`UPDATE user_roles SET role_id = $1 WHERE user_id = $2`, patch.Role.Value, userID)
+		if err != nil {
+			return mapError(err, "user role")
+		}
+		if err := checkAffected(result, "user role"); err != nil {
+			return err
+		}
+	}
+	if patch.Password.Set {
+		if _, err := tx.Exec(`UPDATE users SET password = $1 WHERE id = $2`, patch.Password.Value, userID); err != nil {
+			return mapError(err, "user")
+		}
+	}
+
+	return tx.Commit()
 }
 
 // DeleteUser deletes an user
@@ -167,13 +200,12 @@ func (us *UsersService) DeleteUser(userID string) error {
 		SET active = false 
 		WHERE id = $1
 	`
-	fmt.Println(userID)
-	_, err := us.db.Exec(q, userID)
+	result, err := us.db.Exec(q, userID)
 	if err != nil {
-		return err
+		return mapError(err, "user")
 	}
 
-	return nil
+	return checkAffected(result, "user")
 }
 
 // GetRoles gets all the roles
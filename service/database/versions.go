@@ -0,0 +1,80 @@
+package database
+
+import (
+	"contabi-be/models"
+	"database/sql"
+)
+
+// clientVersionQuery selects the versions of a client and its assignments, 0
+// when the client has no assignments
+const clientVersionQuery = `
+	SELECT c.version, COALESCE((SELECT ca.version FROM client_assignments ca WHERE ca.client_id = c.id), 0)
+	FROM clients c
+	WHERE c.id = $1
+`
+
+// GetClientVersion gets the versions of a client and its assignments
+func (cs *ClientsService) GetClientVersion(clientID string) (models.ClientVersion, error) {
+	return clientVersion(cs.db, clientID)
+}
+
+// claimClientVersion moves the version of a client forward in the
+// transaction of a change when it and the one of its assignments are the ones
+// given, so of two changes read from the same version only the first one goes
+// on and a change that fails leaves the version as it was
+func claimClientVersion(tx *sql.Tx, clientID string, version models.ClientVersion) error {
+	q := `
+		UPDATE clients
+		SET version = version + 1
+		WHERE id = $1 AND version = $2
+			AND COALESCE((SELECT ca.version FROM client_assignments ca WHERE ca.client_id = clients.id), 0) = $3
+	`
+
+	return claimClient(tx, q, "client", clientID, version)
+}
+
+// claimAssignmentsVersion moves the version of the assignments of a client
+// forward in the transaction of a change when it and the one of the client
+// are the ones given
+func claimAssignmentsVersion(tx *sql.Tx, clientID string, version models.ClientVersion) error {
+	q := `
+		UPDATE client_assignments
+		SET version = version + 1
+		WHERE client_id = $1 AND version = $3
+			AND (SELECT c.version FROM clients c WHERE c.id = client_assignments.client_id) = $2
+	`
+
+	return claimClient(tx, q, "client assignments", clientID, version)
+}
+
+// clientVersion reads the versions of a client and its assignments
+func clientVersion(db interface {
+	QueryRow(query string, args ...any) *sql.Row
+}, clientID string) (models.ClientVersion, error) {
+	var v models.ClientVersion
+	err := db.QueryRow(clientVersionQuery, clientID).Scan(&v.Client, &v.Assignments)
+
+	return v, mapError(err, "client")
+}
+
+// claimClient runs the update of a claim of a client version, when no row
+// changed it tells a missing client from a newer version
+func claimClient(tx *sql.Tx, q, name, clientID string, version models.ClientVersion) error {
+	result, err := tx.Exec(q, clientID, version.Client, version.Assignments)
+	if err != nil {
+		return mapError(err, name)
+	}
+	if claimed, err := rowsAffected(result); err != nil || claimed > 0 {
+		return err
+	}
+
+	current, err := clientVersion(tx, clientID)
+	if err != nil {
+		return err
+	}
+	if current == version {
+		return models.NewNotFoundError(name + " not found")
+	}
+
+	return models.NewPreconditionFailedError("The client changed since it was read", map[string]string{"etag": current.ETag()})
+}
@@ -0,0 +1,272 @@
+package memory
+
+import (
+	"sort"
+	"strconv"
+
+	"contabi-be/models"
+)
+
+// AccountancyService
+type AccountancyService struct {
+	store *Store
+}
+
+// NewAccountancyService creates a new instance of AccountancyService
+func NewAccountancyService(store *Store) *AccountancyService {
+	return &AccountancyService{store: store}
+}
+
+// GetClientsBySupervisor retrieves all the onboarding and active clients of a specific supervisor
+func (as *AccountancyService) GetClientsBySupervisor(supervisorID string) ([]models.AccountancyClientInfo, error) {
+	as.store.mu.RLock()
+	defer as.store.mu.RUnlock()
+
+	return as.clients(func(c *clientRecord) bool {
+		return c.serviced() && as.store.assignments[c.ID].SupervisorID == supervisorID
+	}), nil
+}
+
+// GetClientAssignmentsMatrix retrieves, for all onboarding and active clients, the list of assignment types and whether each client has each assignment
+func (as *AccountancyService) GetClientAssignmentsMatrix() ([]models.ClientAssignmentMatrixRow, error) {
+	as.store.mu.RLock()
+	defer as.store.mu.RUnlock()
+
+	types := append([]models.AccountancyType(nil), as.store.accountancyTypes...)
+	sort.Slice(types, func(i, j int) bool { return types[i].Name < types[j].Name })
+
+	var result []models.ClientAssignmentMatrixRow
+	for _, c := range as.store.sortedClients((*clientRecord).serviced) {
+		for _, t := range types {
+			id, _ := strconv.Atoi(t.ID)
+			result = append(result, models.ClientAssignmentMatrixRow{
+				ClientID:           c.ID,
+				ClientName:         c.Name,
+				AssignmentTypeID:   id,
+				AssignmentTypeName: t.Name,
+				Selected:           as.store.clientAssignmentTypes[c.ID][id],
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// UpdateClientAssignments updates assignments of a client according to the
+// state and records the added and removed ones in the timeline, it fails when
+// the client is no longer in the version given
+func (as *AccountancyService) UpdateClientAssignments(clientID string, assignments []models.AssignmentSelection, version models.ClientVersion) error {
+	as.store.mu.Lock()
+	defer as.store.mu.Unlock()
+
+	if err := as.store.checkClientVersion(clientID, version); err != nil {
+		return err
+	}
+	before := as.store.activeAssignments(clientID)
+
+	selected, ok := as.store.clientAssignmentTypes[clientID]
+	if !ok {
+		selected = map[int]bool{}
+		as.store.clientAssignmentTypes[clientID] = selected
+	}
+
+	for _, a := range assignments {
+		if a.Selected {
+			selected[a.AssignmentTypeID] = true
+		} else {
+			delete(selected, a.AssignmentTypeID)
+		}
+	}
+	as.store.touchAssignments(clientID)
+
+	if changes := models.AccountancyAssignmentChanges(before, as.store.activeAssignments(clientID)); changes != "" {
+		as.store.addEvent(models.ClientEvent{ClientID: clientID, Type: models.EventAssignments, Message: changes})
+	}
+
+	return nil
+}
+
+// activeAssignments returns the accountancy types assigned to a client by name
+func (s *Store) activeAssignments(clientID string) []models.AccountancyType {
+	var types []models.AccountancyType
+	for typeID := range s.clientAssignmentTypes[clientID] {
+		types = append(types, models.AccountancyType{
+			ID:   strconv.Itoa(typeID),
+			Name: s.accountancyTypeName(typeID),
+		})
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].Name < types[j].Name })
+
+	return types
+}
+
+// GetClientsByResonsible retrieves all the onboarding and active clients of a specific responsible
+func (as *AccountancyService) GetClientsByResonsible(responsibleID string) ([]models.AccountancyClientInfo, error) {
+	as.store.mu.RLock()
+	defer as.store.mu.RUnlock()
+
+	return as.clients(func(c *clientRecord) bool {
+		return c.serviced() && as.store.assignments[c.ID].ResponsibleID == responsibleID
+	}), nil
+}
+
+// CreateClientAccountancyStatusWithAssignments creates a new monthly record
+// for a client and records the event of it in the timeline
+func (as *AccountancyService) CreateClientAccountancyStatusWithAssignments(status models.ClientAccountancyStatus, assignments []models.ClientAccountancyAssignment, event models.ClientEvent) error {
+	as.store.mu.Lock()
+	defer as.store.mu.Unlock()
+
+	if _, ok := as.store.clients[status.ClientID]; !ok {
+		return models.NewValidationError("accountancy status references a record that does not exist", map[string]string{"column": "client_id"})
+	}
+
+	for _, s := range as.store.statuses {
+		if s.ClientID == status.ClientID && s.Month == status.Month {
+			return models.NewConflictError("accountancy status already exists", nil)
+		}
+	}
+
+	status.ID = as.store.nextStatusID
+	status.Version = 1
+	status.ClosedAt = ""
+	as.store.nextStatusID++
+	as.store.statuses[status.ID] = &status
+
+	for _, a := range assignments {
+		as.store.setStatusAssignment(status.ID, a.AssignmentTypeID, a.AssignmentStatusID)
+	}
+	as.store.addEvent(event)
+
+	return nil
+}
+
+// UpdateClientAccountancyStatusWithAssignments updates an existing monthly
+// record for a client when it is still in the version given and records the
+// event of it in the timeline
+func (as *AccountancyService) UpdateClientAccountancyStatusWithAssignments(statusID int, clientID string, status models.ClientAccountancyStatus, assignments []models.ClientAccountancyAssignment, version int, event models.ClientEvent) error {
+	as.store.mu.Lock()
+	defer as.store.mu.Unlock()
+
+	s, ok := as.store.statuses[statusID]
+	if !ok || s.ClientID != clientID {
+		return models.NewNotFoundError("accountancy status not found or does not belong to the specified client")
+	}
+	if s.ClosedAt != "" {
+		return models.NewConflictError("The accountancy month was closed when the client left", nil)
+	}
+	if s.Version != version {
+		return models.NewPreconditionFailedError("The accountancy status changed since it was read", map[string]string{"etag": models.StatusETag(s.Version)})
+	}
+
+	s.DueDate = status.DueDate
+	s.Observacion = status.Observacion
+	s.Version++
+
+	for _, a := range assignments {
+		as.store.setStatusAssignment(statusID, a.AssignmentTypeID, a.AssignmentStatusID)
+	}
+	as.store.addEvent(event)
+
+	return nil
+}
+
+// GetClientAccountancyHistory gets the history for a client accountancy behavior
+func (as *AccountancyService) GetClientAccountancyHistory(clientID string) (models.ClientAccountancyHistoryWithAssignments, error) {
+	as.store.mu.RLock()
+	defer as.store.mu.RUnlock()
+
+	var result models.ClientAccountancyHistoryWithAssignments
+	result.ActiveAssignments = as.store.activeAssignments(clientID)
+	active := as.store.clientAssignmentTypes[clientID]
+
+	var statuses []models.ClientAccountancyStatus
+	for _, s := range as.store.statuses {
+		if s.ClientID == clientID {
+			statuses = append(statuses, *s)
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Month > statuses[j].Month })
+
+	for _, s := range statuses {
+		var assignments []models.ClientAccountancyAssignment
+		for typeID, a := range as.store.statusAssignments[s.ID] {
+			// only the assignments still active for the client are reported
+			if !active[typeID] {
+				continue
+			}
+
+			assignments = append(assignments, models.ClientAccountancyAssignment{
+				ID:                   a.ID,
+				StatusID:             s.ID,
+				AssignmentTypeID:     typeID,
+				AssignmentTypeName:   as.store.accountancyTypeName(typeID),
+				AssignmentStatusID:   a.AssignmentStatusID,
+				AssignmentStatusName: as.store.assignmentStatusName(a.AssignmentStatusID),
+			})
+		}
+		sort.Slice(assignments, func(i, j int) bool {
+			return assignments[i].AssignmentTypeID < assignments[j].AssignmentTypeID
+		})
+
+		result.History = append(result.History, models.ClientAccountancyHistoryEntry{
+			Status:      s,
+			Assignments: assignments,
+		})
+	}
+
+	return result, nil
+}
+
+// GetAllClients retrieves a page of the clients whose accountancy is worked on
+func (as *AccountancyService) GetAllClients(params models.ClientListParams) (models.AccountancyClientInfoPage, error) {
+	as.store.mu.RLock()
+	defer as.store.mu.RUnlock()
+
+	clients, total := as.store.listClients(params, (*clientRecord).serviced)
+
+	page := models.AccountancyClientInfoPage{Items: []models.AccountancyClientInfo{}, Total: total, Page: params.Page, PageSize: params.PageSize}
+	for _, c := range clients {
+		page.Items = append(page.Items, as.store.accountancyClientInfo(c))
+	}
+
+	return page, nil
+}
+
+// UpdateClientResponsible updates the responsible of a client, keeps the
+// previous one in the assignment history and records the change in the
+// timeline, it fails when the client is no longer in the version given
+func (as *AccountancyService) UpdateClientResponsible(clientID string, responsibleID string, version models.ClientVersion) error {
+	as.store.mu.Lock()
+	defer as.store.mu.Unlock()
+
+	if err := as.store.checkClientVersion(clientID, version); err != nil {
+		return err
+	}
+
+	a, ok := as.store.assignments[clientID]
+	if !ok {
+		return models.NewNotFoundError("client assignments not found")
+	}
+
+	if as.store.userName(responsibleID) == "" {
+		return models.NewValidationError("client assignments references a record that does not exist", map[string]string{"column": "responsible_id"})
+	}
+
+	before := as.store.clientInfo(as.store.clients[clientID])
+	a.ResponsibleID = responsibleID
+	as.store.setAssignments(a)
+	as.store.touchAssignments(clientID)
+	as.store.recordAssignmentChanges(clientID, before)
+
+	return nil
+}
+
+// clients returns the accountancy info of the clients that match the filter
+func (as *AccountancyService) clients(filter func(c *clientRecord) bool) []models.AccountancyClientInfo {
+	var clients []models.AccountancyClientInfo
+	for _, c := range as.store.sortedClients(filter) {
+		clients = append(clients, as.store.accountancyClientInfo(c))
+	}
+
+	return clients
+}
@@ -0,0 +1,108 @@
+package memory
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"contabi-be/models"
+)
+
+// assignmentPeriodRecord is a stored period of the assignments of a client,
+// valid_to is the day after it ended
+type assignmentPeriodRecord struct {
+	models.ClientAssignments
+	ValidFrom string
+	ValidTo   string
+}
+
+// setAssignments stores the assignments of a client and opens a period with
+// them when they differ from the current one, which is closed the same day.
+// A period opened the same day is replaced instead.
+func (s *Store) setAssignments(assignments models.ClientAssignments) {
+	s.assignments[assignments.ClientID] = assignments
+
+	day := time.Now().Format(time.DateOnly)
+	for i := range s.assignmentHistory {
+		current := &s.assignmentHistory[i]
+		if current.ClientID != assignments.ClientID || current.ValidTo != "" {
+			continue
+		}
+
+		switch {
+		case current.SupervisorID == assignments.SupervisorID && current.ResponsibleID == assignments.ResponsibleID && current.EmisorID == assignments.EmisorID:
+			return
+		case current.ValidFrom == day:
+			current.ClientAssignments = assignments
+			return
+		}
+		current.ValidTo = day
+	}
+
+	s.assignmentHistory = append(s.assignmentHistory, assignmentPeriodRecord{ClientAssignments: assignments, ValidFrom: day})
+}
+
+// assignmentPeriod builds a period of the assignment history with its names
+func (s *Store) assignmentPeriod(p assignmentPeriodRecord) models.AssignmentPeriod {
+	var clientName string
+	if c, ok := s.clients[p.ClientID]; ok {
+		clientName = c.Name
+	}
+
+	return models.AssignmentPeriod{
+		ClientID:        p.ClientID,
+		ClientName:      clientName,
+		SupervisorID:    p.SupervisorID,
+		SupervisorName:  s.userName(p.SupervisorID),
+		ResponsibleID:   p.ResponsibleID,
+		ResponsibleName: s.userName(p.ResponsibleID),
+		EmisorID:        p.EmisorID,
+		EmisorName:      s.emisorName(p.EmisorID),
+		ValidFrom:       p.ValidFrom,
+		ValidTo:         p.ValidTo,
+	}
+}
+
+// GetAssignmentHistory retrieves the assignment periods of a client, the
+// newest first
+func (cs *ClientsService) GetAssignmentHistory(clientID string) ([]models.AssignmentPeriod, error) {
+	cs.store.mu.RLock()
+	defer cs.store.mu.RUnlock()
+
+	// the periods are stored as they open, the newest last
+	periods := []models.AssignmentPeriod{}
+	for i := len(cs.store.assignmentHistory) - 1; i >= 0; i-- {
+		if p := cs.store.assignmentHistory[i]; p.ClientID == clientID {
+			periods = append(periods, cs.store.assignmentPeriod(p))
+		}
+	}
+
+	return periods, nil
+}
+
+// GetAssignmentsAsOf retrieves the assignments every client had on a day,
+// of a supervisor or a responsible when the params have them
+func (cs *ClientsService) GetAssignmentsAsOf(params models.AssignmentsAsOfParams) ([]models.AssignmentPeriod, error) {
+	cs.store.mu.RLock()
+	defer cs.store.mu.RUnlock()
+
+	periods := []models.AssignmentPeriod{}
+	for _, p := range cs.store.assignmentHistory {
+		if params.SupervisorID != "" && p.SupervisorID != params.SupervisorID {
+			continue
+		}
+		if params.ResponsibleID != "" && p.ResponsibleID != params.ResponsibleID {
+			continue
+		}
+
+		if period := cs.store.assignmentPeriod(p); period.Covers(params.AsOf) {
+			periods = append(periods, period)
+		}
+	}
+
+	sort.SliceStable(periods, func(i, j int) bool {
+		return strings.ToLower(periods[i].ClientName) < strings.ToLower(periods[j].ClientName)
+	})
+
+	return periods, nil
+}
@@ -0,0 +1,397 @@
+package memory
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"contabi-be/models"
+	"contabi-be/search"
+)
+
+// ClientsService
+type ClientsService struct {
+	store *Store
+}
+
+// NewClientsService creates a new instance of ClientsService
+func NewClientsService(store *Store) *ClientsService {
+	return &ClientsService{store: store}
+}
+
+// GetAllClientsInfo retrieves a page of the clients with complete information
+func (cs *ClientsService) GetAllClientsInfo(params models.ClientListParams) (models.ClientInfoPage, error) {
+	cs.store.mu.RLock()
+	defer cs.store.mu.RUnlock()
+
+	return cs.listClientsInfo(params), nil
+}
+
+// GetActiveClientsInfo retrieves a page of the active clients with complete information
+func (cs *ClientsService) GetActiveClientsInfo(params models.ClientListParams) (models.ClientInfoPage, error) {
+	cs.store.mu.RLock()
+	defer cs.store.mu.RUnlock()
+
+	active := true
+	params.Active = &active
+	return cs.listClientsInfo(params), nil
+}
+
+// listClientsInfo builds a page of the client listings
+func (cs *ClientsService) listClientsInfo(params models.ClientListParams) models.ClientInfoPage {
+	clients, total := cs.store.listClients(params, nil)
+	page := models.ClientInfoPage{Items: []models.ClientInfo{}, Total: total, Page: params.Page, PageSize: params.PageSize}
+	for _, c := range clients {
+		page.Items = append(page.Items, cs.store.clientInfo(c))
+	}
+
+	return page
+}
+
+// GetClientInfo retrieves complete client information
+func (cs *ClientsService) GetClientInfo(clientID string) (models.ClientInfo, error) {
+	cs.store.mu.RLock()
+	defer cs.store.mu.RUnlock()
+
+	c, ok := cs.store.clients[clientID]
+	if !ok {
+		return models.ClientInfo{}, models.NewNotFoundError("client not found")
+	}
+
+	return cs.store.clientInfo(c), nil
+}
+
+// CreateClient creates a new client with its assignments
+func (cs *ClientsService) CreateClient(client models.Client, assignments models.ClientAssignments) error {
+	cs.store.mu.Lock()
+	defer cs.store.mu.Unlock()
+
+	if err := cs.checkClient(client, assignments); err != nil {
+		return err
+	}
+	cs.insertClient(client, assignments)
+
+	return nil
+}
+
+// CreateClients creates several clients with their assignments, none of them
+// is created when one fails like the transaction of the database
+func (cs *ClientsService) CreateClients(requests []models.CreateClientRequest) error {
+	cs.store.mu.Lock()
+	defer cs.store.mu.Unlock()
+
+	for _, r := range requests {
+		if err := cs.checkClient(r.Client, r.Assignments); err != nil {
+			return err
+		}
+	}
+
+	for _, r := range requests {
+		cs.insertClient(r.Client, r.Assignments)
+	}
+
+	return nil
+}
+
+// checkClient fails when a new client references a missing regimen, user or emisor
+func (cs *ClientsService) checkClient(client models.Client, assignments models.ClientAssignments) error {
+	if cs.store.regimenName(client.RegimenID) == "" {
+		return models.NewValidationError("client references a record that does not exist", map[string]string{"column": "regimen_id"})
+	}
+
+	return cs.checkAssignments(assignments)
+}
+
+// insertClient stores a new client with its assignments, active unless it
+// comes in another state
+func (cs *ClientsService) insertClient(client models.Client, assignments models.ClientAssignments) {
+	state := client.State
+	if state == "" {
+		state = models.ClientActive
+	}
+
+	id := newID()
+	cs.store.clients[id] = &clientRecord{
+		ID:             id,
+		Name:           client.Name,
+		RegimenID:      client.RegimenID,
+		RFC:            client.RFC,
+		ClaveCIEC:      client.ClaveCIEC,
+		ClaveFiel:      client.ClaveFiel,
+		FielExpiration: client.FielExpiration,
+		MonthlyFee:     client.MonthlyFee,
+		Active:         models.IsActiveState(state),
+		State:          state,
+	}
+
+	cs.store.saveCurrentFee(id, client.MonthlyFee)
+
+	assignments.ClientID = id
+	cs.store.setAssignments(assignments)
+}
+
+// UpdateClient updates client information, a new monthly fee is recorded as
+// the one in effect from this month. The active flag follows the state of the
+// client, it changes with the change of state given if any, which is recorded
+// in the timeline. It fails when the client is no longer in the version given.
+func (cs *ClientsService) UpdateClient(clientID string, client models.Client, change *models.ClientStateChange, version models.ClientVersion) error {
+	cs.store.mu.Lock()
+	defer cs.store.mu.Unlock()
+
+	if err := cs.store.checkClientVersion(clientID, version); err != nil {
+		return err
+	}
+	c := cs.store.clients[clientID]
+
+	if cs.store.regimenName(client.RegimenID) == "" {
+		return models.NewValidationError("client references a record that does not exist", map[string]string{"column": "regimen_id"})
+	}
+
+	if change != nil {
+		if _, err := cs.store.changeClientState(*change); err != nil {
+			return err
+		}
+		cs.store.addEvent(change.Event())
+	}
+
+	c.Name = client.Name
+	c.RFC = client.RFC
+	c.ClaveCIEC = client.ClaveCIEC
+	c.ClaveFiel = client.ClaveFiel
+	c.FielExpiration = client.FielExpiration
+	c.MonthlyFee = client.MonthlyFee
+	c.RegimenID = client.RegimenID
+	cs.store.touchClient(clientID)
+	cs.store.saveCurrentFee(clientID, client.MonthlyFee)
+
+	return nil
+}
+
+// UpdateClientAssignments updates client assignments (supervisor, responsible,
+// emisor), keeps the previous ones in their history and records the ones that
+// changed in the timeline, it fails when the client is no longer in the
+// version given
+func (cs *ClientsService) UpdateClientAssignments(clientID string, assignments models.ClientAssignments, version models.ClientVersion) error {
+	cs.store.mu.Lock()
+	defer cs.store.mu.Unlock()
+
+	if err := cs.store.checkClientVersion(clientID, version); err != nil {
+		return err
+	}
+	if _, ok := cs.store.assignments[clientID]; !ok {
+		return models.NewNotFoundError("client assignments not found")
+	}
+
+	if err := cs.checkAssignments(assignments); err != nil {
+		return err
+	}
+
+	before := cs.store.clientInfo(cs.store.clients[clientID])
+	assignments.ClientID = clientID
+	cs.store.setAssignments(assignments)
+	cs.store.touchAssignments(clientID)
+	cs.store.recordAssignmentChanges(clientID, before)
+
+	return nil
+}
+
+// recordAssignmentChanges adds to the timeline of a client the assignments
+// that differ from the ones it had before
+func (s *Store) recordAssignmentChanges(clientID string, before models.ClientInfo) {
+	after := s.clientInfo(s.clients[clientID])
+	if changes := models.AssignmentChanges(before, after); changes != "" {
+		s.addEvent(models.ClientEvent{ClientID: clientID, Type: models.EventAssignments, Message: changes})
+	}
+}
+
+// GetClientsWithPendingPayments retrieves the active clients that have not paid the current month
+func (cs *ClientsService) GetClientsWithPendingPayments() ([]models.ClientWithPendingPayment, error) {
+	cs.store.mu.RLock()
+	defer cs.store.mu.RUnlock()
+
+	currentMonth := time.Now().Format("2006-01")
+
+	var clients []models.ClientWithPendingPayment
+	for _, c := range cs.store.sortedClients(func(c *clientRecord) bool { return c.Active }) {
+		info := cs.store.clientInfo(c)
+		status := models.PaymentStatusNeverPaid
+		if info.LastPaymentMonth != "" {
+			if monthOf(info.LastPaymentMonth) >= currentMonth {
+				continue
+			}
+			status = models.PaymentStatusPending
+		}
+
+		clients = append(clients, models.ClientWithPendingPayment{
+			ID:               info.ID,
+			Name:             info.Name,
+			RFC:              info.RFC,
+			RegimenName:      info.RegimenName,
+			MonthlyFee:       info.MonthlyFee,
+			LastPaymentMonth: info.LastPaymentMonth,
+			LastPaymentDate:  info.LastPaymentDate,
+			UpdatedAt:        info.UpdatedAt,
+			SupervisorName:   info.SupervisorName,
+			ResponsibleName:  info.ResponsibleName,
+			EmisorName:       info.EmisorName,
+			State:            info.State,
+			PaymentStatus:    status,
+		})
+	}
+
+	return clients, nil
+}
+
+// UpdateClientPayment registers a payment of a client and records it in the
+// timeline, it fails when the client is no longer in the version given
+func (cs *ClientsService) UpdateClientPayment(clientID string, payment models.ClientPayment, version models.ClientVersion, event models.ClientEvent) error {
+	cs.store.mu.Lock()
+	defer cs.store.mu.Unlock()
+
+	if err := cs.store.checkClientVersion(clientID, version); err != nil {
+		return err
+	}
+
+	cs.store.payments = append(cs.store.payments, paymentRecord{
+		ClientID:         clientID,
+		LastPaymentMonth: payment.LastPaymentMonth,
+		LastPaymentDate:  payment.LastPaymentDate,
+		FolioFactura:     payment.FolioFactura,
+		UpdatedAt:        time.Now(),
+	})
+	cs.store.touchClient(clientID)
+	cs.store.addEvent(event)
+
+	return nil
+}
+
+// GetClientPayments gets the payments history of a specific client
+func (cs *ClientsService) GetClientPayments(clientID string) ([]models.ClientPaymentHistory, error) {
+	cs.store.mu.RLock()
+	defer cs.store.mu.RUnlock()
+
+	c, ok := cs.store.clients[clientID]
+	if !ok {
+		return nil, nil
+	}
+
+	var payments []models.ClientPaymentHistory
+	for _, p := range cs.store.payments {
+		if p.ClientID != clientID {
+			continue
+		}
+
+		payments = append(payments, models.ClientPaymentHistory{
+			ClientID:         clientID,
+			ClientName:       c.Name,
+			LastPaymentMonth: p.LastPaymentMonth,
+			LastPaymentDate:  p.LastPaymentDate,
+			MonthlyFee:       c.MonthlyFee,
+			FolioFactura:     p.FolioFactura,
+		})
+	}
+
+	sort.SliceStable(payments, func(i, j int) bool {
+		return payments[i].LastPaymentMonth > payments[j].LastPaymentMonth
+	})
+
+	return payments, nil
+}
+
+// checkAssignments fails when an assignment references a missing user or emisor
+func (cs *ClientsService) checkAssignments(assignments models.ClientAssignments) error {
+	if cs.store.userName(assignments.SupervisorID) == "" ||
+		cs.store.userName(assignments.ResponsibleID) == "" ||
+		cs.store.emisorName(assignments.EmisorID) == "" {
+		return models.NewValidationError("client assignments references a record that does not exist", nil)
+	}
+
+	return nil
+}
+
+// monthOf returns the YYYY-MM part of a month or date
+func monthOf(value string) string {
+	if len(value) > 7 {
+		return value[:7]
+	}
+
+	return value
+}
+
+// SearchClients finds the clients whose name or RFC resemble the query
+func (cs *ClientsService) SearchClients(params models.ClientSearchParams) ([]models.ClientSearchResult, error) {
+	cs.store.mu.RLock()
+	defer cs.store.mu.RUnlock()
+
+	clients := cs.store.sortedClients(func(c *clientRecord) bool {
+		return params.Active == nil || c.Active == *params.Active
+	})
+
+	candidates := make([][]string, 0, len(clients))
+	for _, c := range clients {
+		candidates = append(candidates, []string{c.Name, c.RFC})
+	}
+
+	results := []models.ClientSearchResult{}
+	for _, m := range search.Rank(params.Q, candidates) {
+		if len(results) == params.Limit {
+			break
+		}
+		c := clients[m.Index]
+		results = append(results, models.ClientSearchResult{
+			ID:     c.ID,
+			Name:   c.Name,
+			RFC:    c.RFC,
+			Active: c.Active,
+			Score:  m.Score,
+		})
+	}
+
+	return results, nil
+}
+
+// GetClientSummary retrieves the id, name, RFC and merge pointer of a client
+func (cs *ClientsService) GetClientSummary(clientID string) (models.ClientSummary, error) {
+	cs.store.mu.RLock()
+	defer cs.store.mu.RUnlock()
+
+	c, ok := cs.store.clients[clientID]
+	if !ok {
+		return models.ClientSummary{}, models.NewNotFoundError("client not found")
+	}
+
+	return clientSummary(c), nil
+}
+
+// GetActiveClientByRFC retrieves the active client with an RFC, the comparison ignores case
+func (cs *ClientsService) GetActiveClientByRFC(rfc string) (models.ClientSummary, error) {
+	cs.store.mu.RLock()
+	defer cs.store.mu.RUnlock()
+
+	clients := cs.store.sortedClients(func(c *clientRecord) bool {
+		return c.Active && strings.EqualFold(c.RFC, rfc)
+	})
+	if len(clients) == 0 {
+		return models.ClientSummary{}, models.NewNotFoundError("client not found")
+	}
+
+	return clientSummary(clients[0]), nil
+}
+
+// GetClientSummaries retrieves the id, name and RFC of every client that was not merged
+func (cs *ClientsService) GetClientSummaries() ([]models.ClientSummary, error) {
+	cs.store.mu.RLock()
+	defer cs.store.mu.RUnlock()
+
+	clients := []models.ClientSummary{}
+	for _, c := range cs.store.sortedClients(func(c *clientRecord) bool { return c.MergedInto == "" }) {
+		clients = append(clients, clientSummary(c))
+	}
+
+	return clients, nil
+}
+
+// clientSummary builds the summary of a client
+func clientSummary(c *clientRecord) models.ClientSummary {
+	return models.ClientSummary{ID: c.ID, Name: c.Name, RFC: c.RFC, Active: c.Active, State: c.State, MergedInto: c.MergedInto}
+}
@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"contabi-be/models"
+)
+
+// GetClientContacts retrieves the contacts of a client, the billing ones first
+func (cs *ClientsService) GetClientContacts(clientID string) ([]models.ClientContact, error) {
+	cs.store.mu.RLock()
+	defer cs.store.mu.RUnlock()
+
+	contacts := []models.ClientContact{}
+	for _, c := range cs.store.contacts {
+		if c.ClientID == clientID {
+			contacts = append(contacts, *c)
+		}
+	}
+
+	sort.Slice(contacts, func(i, j int) bool {
+		a, b := contacts[i], contacts[j]
+		if a.IsBilling != b.IsBilling {
+			return a.IsBilling
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		ai, _ := strconv.Atoi(a.ID)
+		bi, _ := strconv.Atoi(b.ID)
+		return ai < bi
+	})
+
+	return contacts, nil
+}
+
+// CreateClientContact adds a contact to a client
+func (cs *ClientsService) CreateClientContact(contact models.ClientContact) (models.ClientContact, error) {
+	cs.store.mu.Lock()
+	defer cs.store.mu.Unlock()
+
+	if _, ok := cs.store.clients[contact.ClientID]; !ok {
+		return contact, models.NewValidationError("contact references a record that does not exist", map[string]string{"column": "client_id"})
+	}
+
+	contact.ID = strconv.Itoa(cs.store.nextContactID)
+	cs.store.nextContactID++
+	contact.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+	contact.UpdatedAt = contact.CreatedAt
+	cs.store.contacts[contact.ID] = &contact
+
+	return contact, nil
+}
+
+// UpdateClientContact replaces the data of a contact of a client, it fails
+// when the client is no longer in the version given
+func (cs *ClientsService) UpdateClientContact(contact models.ClientContact, version models.ClientVersion) (models.ClientContact, error) {
+	cs.store.mu.Lock()
+	defer cs.store.mu.Unlock()
+
+	if err := cs.store.checkClientVersion(contact.ClientID, version); err != nil {
+		return contact, err
+	}
+
+	stored, ok := cs.store.contacts[contact.ID]
+	if !ok || stored.ClientID != contact.ClientID {
+		return contact, models.NewNotFoundError("contact not found")
+	}
+
+	contact.CreatedAt = stored.CreatedAt
+	contact.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	*stored = contact
+	cs.store.touchClient(contact.ClientID)
+
+	return contact, nil
+}
+
+// DeleteClientContact removes a contact of a client
+func (cs *ClientsService) DeleteClientContact(clientID, contactID string) error {
+	cs.store.mu.Lock()
+	defer cs.store.mu.Unlock()
+
+	if c, ok := cs.store.contacts[contactID]; !ok || c.ClientID != clientID {
+		return models.NewNotFoundError("contact not found")
+	}
+	delete(cs.store.contacts, contactID)
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"contabi-be/models"
+)
+
+// SaveCSDCertificate stores the data of a CSD of a client with its files
+func (cs *ClientsService) SaveCSDCertificate(cert models.CSDCertificate, files models.CSDFiles) (models.CSDCertificate, error) {
+	cs.store.mu.Lock()
+	defer cs.store.mu.Unlock()
+
+	if _, ok := cs.store.clients[cert.ClientID]; !ok {
+		return cert, models.NewValidationError("csd certificate references a record that does not exist", map[string]string{"column": "client_id"})
+	}
+	for _, c := range cs.store.csdCertificates {
+		if c.SerialNumber == cert.SerialNumber {
+			return cert, models.NewConflictError("csd certificate already exists", map[string]string{"column": "serial_number"})
+		}
+	}
+
+	cert.ID = strconv.Itoa(cs.store.nextCSDID)
+	cs.store.nextCSDID++
+	cert.UploadedAt = time.Now().UTC().Format(time.RFC3339)
+	cs.store.csdCertificates = append(cs.store.csdCertificates, csdRecord{CSDCertificate: cert, Files: files})
+
+	return cert, nil
+}
+
+// GetCSDCertificates retrieves the CSD of a client, the one that expires last first
+func (cs *ClientsService) GetCSDCertificates(clientID string) ([]models.CSDCertificate, error) {
+	cs.store.mu.RLock()
+	defer cs.store.mu.RUnlock()
+
+	certs := []models.CSDCertificate{}
+	for _, c := range cs.store.csdCertificates {
+		if c.ClientID == clientID {
+			certs = append(certs, c.CSDCertificate)
+		}
+	}
+
+	// the records are appended as they are uploaded, the newest are the last ones
+	slices.Reverse(certs)
+	sort.SliceStable(certs, func(i, j int) bool { return certs[i].ValidTo > certs[j].ValidTo })
+
+	return certs, nil
+}
+
+// DeleteCSDCertificate removes a CSD of a client with its files
+func (cs *ClientsService) DeleteCSDCertificate(clientID, csdID string) error {
+	cs.store.mu.Lock()
+	defer cs.store.mu.Unlock()
+
+	for i, c := range cs.store.csdCertificates {
+		if c.ID == csdID && c.ClientID == clientID {
+			cs.store.csdCertificates = slices.Delete(cs.store.csdCertificates, i, i+1)
+			return nil
+		}
+	}
+
+	return models.NewNotFoundError("csd certificate not found")
+}
+
+// GetExpiringCSD retrieves the CSD of the active clients that expire before a
+// time, including the expired ones, leaving out the ones replaced by a CSD
+// that expires later
+func (cs *ClientsService) GetExpiringCSD(before string) ([]models.CSDExpiration, error) {
+	cs.store.mu.RLock()
+	defer cs.store.mu.RUnlock()
+
+	latest := map[string]string{}
+	for _, c := range cs.store.csdCertificates {
+		if c.ValidTo > latest[c.ClientID] {
+			latest[c.ClientID] = c.ValidTo
+		}
+	}
+
+	expirations := []models.CSDExpiration{}
+	for _, d := range cs.store.csdCertificates {
+		c, ok := cs.store.clients[d.ClientID]
+		if !ok || !c.Active || c.MergedInto != "" || d.ValidTo >= before || d.ValidTo < latest[d.ClientID] {
+			continue
+		}
+
+		info := cs.store.clientInfo(c)
+		expirations = append(expirations, models.CSDExpiration{
+			CSDID:           d.ID,
+			SerialNumber:    d.SerialNumber,
+			ValidTo:         d.ValidTo,
+			ClientID:        c.ID,
+			Name:            c.Name,
+			RFC:             c.RFC,
+			SupervisorID:    info.SupervisorID,
+			SupervisorName:  info.SupervisorName,
+			ResponsibleID:   info.ResponsibleID,
+			ResponsibleName: info.ResponsibleName,
+		})
+	}
+
+	sort.SliceStable(expirations, func(i, j int) bool {
+		if expirations[i].ValidTo != expirations[j].ValidTo {
+			return expirations[i].ValidTo < expirations[j].ValidTo
+		}
+		return expirations[i].Name < expirations[j].Name
+	})
+
+	return expirations, nil
+}
@@ -0,0 +1,165 @@
+package memory
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"contabi-be/models"
+)
+
+// documentRecord is a document with its versions, the oldest first
+type documentRecord struct {
+	models.ClientDocument
+	versions []models.DocumentVersion
+}
+
+// DocumentsService keeps the documents of the clients in the store
+type DocumentsService struct {
+	store *Store
+}
+
+// NewDocumentsService creates a new instance of DocumentsService
+func NewDocumentsService(store *Store) *DocumentsService {
+	return &DocumentsService{store: store}
+}
+
+// GetDocuments retrieves the documents of a client with their current
+// version, the last updated first
+func (ds *DocumentsService) GetDocuments(clientID string, params models.DocumentParams) ([]models.ClientDocument, error) {
+	ds.store.mu.RLock()
+	defer ds.store.mu.RUnlock()
+
+	documents := []models.ClientDocument{}
+	for _, d := range ds.store.documents {
+		if d.ClientID != clientID || (params.Category != "" && d.Category != params.Category) {
+			continue
+		}
+		documents = append(documents, d.withUploaders(ds.store, false))
+	}
+
+	sort.Slice(documents, func(i, j int) bool {
+		if documents[i].UpdatedAt != documents[j].UpdatedAt {
+			return documents[i].UpdatedAt > documents[j].UpdatedAt
+		}
+		a, _ := strconv.Atoi(documents[i].ID)
+		b, _ := strconv.Atoi(documents[j].ID)
+		return a > b
+	})
+
+	return documents, nil
+}
+
+// GetDocument retrieves a document of a client with all its versions, the
+// newest first
+func (ds *DocumentsService) GetDocument(clientID, documentID string) (models.ClientDocument, error) {
+	ds.store.mu.RLock()
+	defer ds.store.mu.RUnlock()
+
+	d, ok := ds.store.documents[documentID]
+	if !ok || d.ClientID != clientID {
+		return models.ClientDocument{}, models.NewNotFoundError("document not found")
+	}
+
+	return d.withUploaders(ds.store, true), nil
+}
+
+// CreateDocument stores a document with its first version
+func (ds *DocumentsService) CreateDocument(document models.ClientDocument) (models.ClientDocument, error) {
+	ds.store.mu.Lock()
+	defer ds.store.mu.Unlock()
+
+	if _, ok := ds.store.clients[document.ClientID]; !ok {
+		return document, models.NewValidationError("document references a record that does not exist", map[string]string{"column": "client_id"})
+	}
+
+	document.ID = strconv.Itoa(ds.store.nextDocumentID)
+	ds.store.nextDocumentID++
+	document.Current.Version = 1
+	document.Current.UploadedAt = time.Now().UTC().Format(time.RFC3339)
+	document.CreatedAt = document.Current.UploadedAt
+	document.UpdatedAt = document.Current.UploadedAt
+
+	ds.store.documents[document.ID] = &documentRecord{ClientDocument: document, versions: []models.DocumentVersion{document.Current}}
+
+	return document, nil
+}
+
+// AddDocumentVersion stores a new version of a document of a client, it gets
+// the number that follows the last version
+func (ds *DocumentsService) AddDocumentVersion(clientID, documentID string, version models.DocumentVersion) (models.DocumentVersion, error) {
+	ds.store.mu.Lock()
+	defer ds.store.mu.Unlock()
+
+	d, ok := ds.store.documents[documentID]
+	if !ok || d.ClientID != clientID {
+		return version, models.NewNotFoundError("document not found")
+	}
+
+	version.Version = len(d.versions) + 1
+	version.UploadedAt = time.Now().UTC().Format(time.RFC3339)
+	d.versions = append(d.versions, version)
+	d.Current = version
+	d.UpdatedAt = version.UploadedAt
+
+	return version, nil
+}
+
+// GetDocumentVersion retrieves a version of a document of a client, the
+// current one when the version is 0
+func (ds *DocumentsService) GetDocumentVersion(clientID, documentID string, version int) (models.DocumentVersion, error) {
+	ds.store.mu.RLock()
+	defer ds.store.mu.RUnlock()
+
+	d, ok := ds.store.documents[documentID]
+	if !ok || d.ClientID != clientID || version > len(d.versions) {
+		return models.DocumentVersion{}, models.NewNotFoundError("document version not found")
+	}
+	if version == 0 {
+		version = len(d.versions)
+	}
+
+	v := d.versions[version-1]
+	v.UploadedByName = ds.store.userName(v.UploadedBy)
+
+	return v, nil
+}
+
+// DeleteDocument removes a document of a client with its versions and
+// returns the storage keys of their files
+func (ds *DocumentsService) DeleteDocument(clientID, documentID string) ([]string, error) {
+	ds.store.mu.Lock()
+	defer ds.store.mu.Unlock()
+
+	d, ok := ds.store.documents[documentID]
+	if !ok || d.ClientID != clientID {
+		return nil, models.NewNotFoundError("document not found")
+	}
+	delete(ds.store.documents, documentID)
+
+	keys := make([]string, 0, len(d.versions))
+	for _, v := range d.versions {
+		keys = append(keys, v.StorageKey)
+	}
+
+	return keys, nil
+}
+
+// withUploaders returns the document with the names of the users that
+// uploaded its versions, the previous versions are included when asked
+func (d *documentRecord) withUploaders(s *Store, versions bool) models.ClientDocument {
+	document := d.ClientDocument
+	document.Current.UploadedByName = s.userName(document.Current.UploadedBy)
+	if !versions {
+		return document
+	}
+
+	document.Versions = make([]models.DocumentVersion, 0, len(d.versions))
+	for i := len(d.versions) - 1; i >= 0; i-- {
+		v := d.versions[i]
+		v.UploadedByName = s.userName(v.UploadedBy)
+		document.Versions = append(document.Versions, v)
+	}
+
+	return document
+}
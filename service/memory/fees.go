@@ -0,0 +1,158 @@
+package memory
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"contabi-be/models"
+)
+
+// GetClientFees retrieves the monthly fees of a client, the latest effective
+// month first
+func (cs *ClientsService) GetClientFees(clientID string) ([]models.ClientFee, error) {
+	cs.store.mu.RLock()
+	defer cs.store.mu.RUnlock()
+
+	return cs.store.clientFees(clientID), nil
+}
+
+// GetAllClientFees retrieves the monthly fees of every client
+func (cs *ClientsService) GetAllClientFees() ([]models.ClientFee, error) {
+	cs.store.mu.RLock()
+	defer cs.store.mu.RUnlock()
+
+	fees := append([]models.ClientFee{}, cs.store.fees...)
+	sortFees(fees)
+
+	return fees, nil
+}
+
+// SaveClientFee sets the monthly fee of a client from a month, replacing the
+// one of the same month, and updates the current fee of the client. It fails
+// when the client is no longer in the version given.
+func (cs *ClientsService) SaveClientFee(fee models.ClientFee, version models.ClientVersion) (models.ClientFee, error) {
+	cs.store.mu.Lock()
+	defer cs.store.mu.Unlock()
+
+	if err := cs.store.checkClientVersion(fee.ClientID, version); err != nil {
+		return fee, err
+	}
+
+	saved := cs.store.saveFee(fee)
+	cs.store.applyCurrentFee(fee.ClientID)
+	cs.store.touchClient(fee.ClientID)
+
+	return saved, nil
+}
+
+// DeleteClientFee removes a fee of a client
+func (cs *ClientsService) DeleteClientFee(clientID, feeID string) error {
+	cs.store.mu.Lock()
+	defer cs.store.mu.Unlock()
+
+	for i, f := range cs.store.fees {
+		if f.ID == feeID && f.ClientID == clientID {
+			cs.store.fees = append(cs.store.fees[:i], cs.store.fees[i+1:]...)
+			return nil
+		}
+	}
+
+	return models.NewNotFoundError("client fee not found")
+}
+
+// ApplyClientFees sets the current fee of the clients that have a fee
+// effective on a month, it returns the clients whose fee changed
+func (cs *ClientsService) ApplyClientFees(month string) (int, error) {
+	cs.store.mu.Lock()
+	defer cs.store.mu.Unlock()
+
+	changed := 0
+	for _, f := range cs.store.fees {
+		if c, ok := cs.store.clients[f.ClientID]; ok && f.EffectiveMonth == month && c.MonthlyFee != f.MonthlyFee {
+			c.MonthlyFee = f.MonthlyFee
+			cs.store.touchClient(f.ClientID)
+			changed++
+		}
+	}
+
+	return changed, nil
+}
+
+// clientFees returns the fees of a client, the latest effective month first
+func (s *Store) clientFees(clientID string) []models.ClientFee {
+	fees := []models.ClientFee{}
+	for _, f := range s.fees {
+		if f.ClientID == clientID {
+			fees = append(fees, f)
+		}
+	}
+	sortFees(fees)
+
+	return fees
+}
+
+// feeInEffect returns the fee of a client in effect on a month, false when the
+// client has none
+func (s *Store) feeInEffect(clientID, month string) (string, bool) {
+	for _, f := range s.clientFees(clientID) {
+		if f.EffectiveMonth <= month {
+			return f.MonthlyFee, true
+		}
+	}
+
+	return "", false
+}
+
+// saveFee inserts a fee or replaces the one of the same client and month
+func (s *Store) saveFee(fee models.ClientFee) models.ClientFee {
+	for i, f := range s.fees {
+		if f.ClientID == fee.ClientID && f.EffectiveMonth == fee.EffectiveMonth && f.EffectiveMonth != "" {
+			s.fees[i].MonthlyFee = fee.MonthlyFee
+			return s.fees[i]
+		}
+	}
+
+	fee.ID = strconv.Itoa(s.nextFeeID)
+	s.nextFeeID++
+	fee.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+	s.fees = append(s.fees, fee)
+
+	return fee
+}
+
+// applyCurrentFee sets the fee of a client to the one in effect this month
+func (s *Store) applyCurrentFee(clientID string) {
+	if fee, ok := s.feeInEffect(clientID, time.Now().Format("2006-01")); ok && s.clients[clientID].MonthlyFee != fee {
+		s.clients[clientID].MonthlyFee = fee
+	}
+}
+
+// saveCurrentFee records the fee of a client set on its data as the one in
+// effect from this month, or as its first fee when it had none
+func (s *Store) saveCurrentFee(clientID, fee string) {
+	if fee == "" {
+		return
+	}
+
+	month := time.Now().Format("2006-01")
+	current, ok := s.feeInEffect(clientID, month)
+	switch {
+	case !ok:
+		s.saveFee(models.ClientFee{ClientID: clientID, MonthlyFee: fee})
+	case current != fee:
+		s.saveFee(models.ClientFee{ClientID: clientID, MonthlyFee: fee, EffectiveMonth: month})
+	}
+}
+
+// sortFees orders fees by client and the latest effective month first, the
+// first fee of each client last
+func sortFees(fees []models.ClientFee) {
+	sort.SliceStable(fees, func(i, j int) bool {
+		a, b := fees[i], fees[j]
+		if a.ClientID != b.ClientID {
+			return a.ClientID < b.ClientID
+		}
+		return a.EffectiveMonth > b.EffectiveMonth
+	})
+}
@@ -0,0 +1,128 @@
+package memory
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"contabi-be/models"
+)
+
+// SaveFielCertificate stores the data of a FIEL certificate and its file, that
+// may be nil, sets the FIEL expiration of the client to the end of its validity
+// and clears the renewal status
+func (cs *ClientsService) SaveFielCertificate(cert models.FielCertificate, file []byte) (models.FielCertificate, error) {
+	cs.store.mu.Lock()
+	defer cs.store.mu.Unlock()
+
+	c, ok := cs.store.clients[cert.ClientID]
+	if !ok {
+		return cert, models.NewValidationError("fiel certificate references a record that does not exist", map[string]string{"column": "client_id"})
+	}
+	for _, f := range cs.store.fielCertificates {
+		if f.SerialNumber == cert.SerialNumber {
+			return cert, models.NewConflictError("fiel certificate already exists", map[string]string{"column": "serial_number"})
+		}
+	}
+
+	cert.ID = strconv.Itoa(cs.store.nextFielID)
+	cs.store.nextFielID++
+	cert.UploadedAt = time.Now().UTC().Format(time.RFC3339)
+	cert.FileStored = file != nil
+	cs.store.fielCertificates = append(cs.store.fielCertificates, fielRecord{FielCertificate: cert, File: file})
+
+	c.FielExpiration, _, _ = strings.Cut(cert.ValidTo, "T")
+	cs.store.touchClient(cert.ClientID)
+
+	// the new certificate completes the renewal of the previous one
+	delete(cs.store.fielRenewals, cert.ClientID)
+
+	return cert, nil
+}
+
+// GetFielCertificates retrieves the FIEL certificates of a client, the one
+// that expires last first
+func (cs *ClientsService) GetFielCertificates(clientID string) ([]models.FielCertificate, error) {
+	cs.store.mu.RLock()
+	defer cs.store.mu.RUnlock()
+
+	certs := []models.FielCertificate{}
+	for _, f := range cs.store.fielCertificates {
+		if f.ClientID == clientID {
+			certs = append(certs, f.FielCertificate)
+		}
+	}
+
+	// the ids grow with every upload, the timestamps are RFC 3339 in UTC so they sort as text
+	sort.SliceStable(certs, func(i, j int) bool {
+		if certs[i].ValidTo != certs[j].ValidTo {
+			return certs[i].ValidTo > certs[j].ValidTo
+		}
+		a, _ := strconv.Atoi(certs[i].ID)
+		b, _ := strconv.Atoi(certs[j].ID)
+		return a > b
+	})
+
+	return certs, nil
+}
+
+// GetExpiringFiel retrieves the active clients whose FIEL expires up to a date,
+// including the expired ones, with the renewal set for their current expiration
+func (cs *ClientsService) GetExpiringFiel(until string) ([]models.FielExpiration, error) {
+	cs.store.mu.RLock()
+	defer cs.store.mu.RUnlock()
+
+	clients := cs.store.sortedClients(func(c *clientRecord) bool {
+		return c.Active && c.MergedInto == "" && c.FielExpiration != "" && c.FielExpiration <= until
+	})
+	sort.SliceStable(clients, func(i, j int) bool {
+		return clients[i].FielExpiration < clients[j].FielExpiration
+	})
+
+	expirations := []models.FielExpiration{}
+	for _, c := range clients {
+		info := cs.store.clientInfo(c)
+		e := models.FielExpiration{
+			ClientID:        c.ID,
+			Name:            c.Name,
+			RFC:             c.RFC,
+			FielExpiration:  c.FielExpiration,
+			SupervisorID:    info.SupervisorID,
+			SupervisorName:  info.SupervisorName,
+			ResponsibleID:   info.ResponsibleID,
+			ResponsibleName: info.ResponsibleName,
+		}
+		if r, ok := cs.store.fielRenewals[c.ID]; ok && r.FielExpiration == c.FielExpiration {
+			e.Renewal = &r
+		}
+
+		expirations = append(expirations, e)
+	}
+
+	return expirations, nil
+}
+
+// SaveFielRenewal sets the renewal status of the FIEL of a client for its
+// current expiration, it fails when the client is no longer in the version given
+func (cs *ClientsService) SaveFielRenewal(clientID string, request models.FielRenewalRequest, version models.ClientVersion) (models.FielRenewal, error) {
+	cs.store.mu.Lock()
+	defer cs.store.mu.Unlock()
+
+	if err := cs.store.checkClientVersion(clientID, version); err != nil {
+		return models.FielRenewal{}, err
+	}
+	c := cs.store.clients[clientID]
+
+	renewal := models.FielRenewal{
+		Status:          request.Status,
+		AppointmentDate: request.AppointmentDate,
+		Notes:           request.Notes,
+		FielExpiration:  c.FielExpiration,
+		UpdatedAt:       time.Now().UTC().Format(time.RFC3339),
+	}
+	cs.store.fielRenewals[clientID] = renewal
+	cs.store.touchClient(clientID)
+
+	return renewal, nil
+}
@@ -0,0 +1,94 @@
+package memory
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"contabi-be/models"
+)
+
+// listClients returns the page of clients that match the filters of the params
+// and the total of matching clients, with the same ordering as the database
+func (s *Store) listClients(params models.ClientListParams, keep func(c *clientRecord) bool) ([]*clientRecord, int) {
+	var clients []*clientRecord
+	for _, c := range s.clients {
+		if s.matchesListParams(c, params) && (keep == nil || keep(c)) {
+			clients = append(clients, c)
+		}
+	}
+
+	less := func(a, b *clientRecord) int {
+		switch params.Sort {
+		case "rfc":
+			return strings.Compare(a.RFC, b.RFC)
+		case "fiel_expiration":
+			return strings.Compare(a.FielExpiration, b.FielExpiration)
+		case "monthly_fee":
+			fa, fb := feeOf(a.MonthlyFee), feeOf(b.MonthlyFee)
+			switch {
+			case fa < fb:
+				return -1
+			case fa > fb:
+				return 1
+			}
+			return 0
+		default:
+			return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+		}
+	}
+
+	sort.Slice(clients, func(i, j int) bool {
+		cmp := less(clients[i], clients[j])
+		if params.Order == "desc" {
+			cmp = -cmp
+		}
+		if cmp == 0 {
+			return clients[i].ID < clients[j].ID
+		}
+		return cmp < 0
+	})
+
+	total := len(clients)
+	start := (params.Page - 1) * params.PageSize
+	if start > total {
+		start = total
+	}
+	end := start + params.PageSize
+	if end > total {
+		end = total
+	}
+
+	return clients[start:end], total
+}
+
+// matchesListParams reports whether a client matches the filters of a listing
+func (s *Store) matchesListParams(c *clientRecord, params models.ClientListParams) bool {
+	a := s.assignments[c.ID]
+	switch {
+	case params.RegimenID != "" && c.RegimenID != params.RegimenID:
+		return false
+	case params.SupervisorID != "" && a.SupervisorID != params.SupervisorID:
+		return false
+	case params.ResponsibleID != "" && a.ResponsibleID != params.ResponsibleID:
+		return false
+	case params.EmisorID != "" && a.EmisorID != params.EmisorID:
+		return false
+	case params.Active != nil && c.Active != *params.Active:
+		return false
+	case params.State != "" && c.State != params.State:
+		return false
+	case params.MinFee != nil && feeOf(c.MonthlyFee) < *params.MinFee:
+		return false
+	case params.MaxFee != nil && feeOf(c.MonthlyFee) > *params.MaxFee:
+		return false
+	}
+
+	return true
+}
+
+// feeOf reads a monthly fee stored as text, an empty fee counts as zero
+func feeOf(value string) float64 {
+	fee, _ := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	return fee
+}
@@ -0,0 +1,40 @@
+package memory
+
+import (
+	"contabi-be/models"
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// LoginService
+type LoginService struct {
+	store *Store
+}
+
+// NewLoginService creates a new instance of LoginService
+func NewLoginService(store *Store) *LoginService {
+	return &LoginService{store: store}
+}
+
+// Login checks the credentials of an active user
+func (ls *LoginService) Login(login string, password string) (models.User, error) {
+	ls.store.mu.RLock()
+	defer ls.store.mu.RUnlock()
+
+	for _, u := range ls.store.users {
+		if u.Username != login || !u.Active {
+			continue
+		}
+
+		if err := bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password)); err != nil {
+			return models.User{}, fmt.Errorf("invalid password")
+		}
+
+		user := u.User
+		user.Password = u.Password
+		return user, nil
+	}
+
+	return models.User{}, fmt.Errorf("user not found")
+}
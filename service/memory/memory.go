@@ -0,0 +1,313 @@
+package memory
+
+import (
+	"crypto/rand"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"contabi-be/models"
+)
+
+// userRecord is a stored user with its hashed password
+type userRecord struct {
+	models.User
+	Password string
+}
+
+// clientRecord is a stored client, the flags are kept typed unlike models.Client
+type clientRecord struct {
+	ID             string
+	Name           string
+	RegimenID      string
+	RFC            string
+	ClaveCIEC      string
+	ClaveFiel      string
+	FielExpiration string
+	MonthlyFee     string
+	Active         bool
+	State          string
+	MergedInto     string
+}
+
+// serviced tells whether the accountancy of a client is worked on, the
+// suspended clients wait until they pay again
+func (c *clientRecord) serviced() bool {
+	return c.State == models.ClientOnboarding || c.State == models.ClientActive
+}
+
+// paymentRecord is a stored client payment
+type paymentRecord struct {
+	ClientID         string
+	LastPaymentMonth string
+	LastPaymentDate  string
+	FolioFactura     string
+	UpdatedAt        time.Time
+}
+
+// fielRecord is a stored FIEL certificate with its encrypted file
+type fielRecord struct {
+	models.FielCertificate
+	File []byte
+}
+
+// csdRecord is a stored CSD with its files
+type csdRecord struct {
+	models.CSDCertificate
+	Files models.CSDFiles
+}
+
+// assignmentRecord is the status of an assignment type in a monthly accountancy record
+type assignmentRecord struct {
+	ID                 int
+	AssignmentStatusID int
+}
+
+// Store keeps every table of the in-memory backend, it is safe for concurrent use
+type Store struct {
+	mu sync.RWMutex
+
+	users                  map[string]*userRecord
+	roles                  []models.Role
+	emisors                []models.Emisor
+	regimenes              []models.Regimen
+	supervisorResponsibles map[string][]string
+	accountancyTypes       []models.AccountancyType
+	assignmentStatuses     []models.AccountancyAssignmentStatus
+
+	clients           map[string]*clientRecord
+	versions          map[string]models.ClientVersion
+	assignments       map[string]models.ClientAssignments
+	assignmentHistory []assignmentPeriodRecord
+	payments          []paymentRecord
+	hrPayments        map[string]*models.ClientHRPayment
+
+	clientAssignmentTypes map[string]map[int]bool
+	statuses              map[int]*models.ClientAccountancyStatus
+	statusAssignments     map[int]map[int]assignmentRecord
+	nextStatusID          int
+	nextAssignmentID      int
+
+	fielCertificates []fielRecord
+	nextFielID       int
+	fielRenewals     map[string]models.FielRenewal
+	csdCertificates  []csdRecord
+	nextCSDID        int
+	fees             []models.ClientFee
+	nextFeeID        int
+	contacts         map[string]*models.ClientContact
+	nextContactID    int
+	documents        map[string]*documentRecord
+	nextDocumentID   int
+
+	notifications      []models.Notification
+	nextNotificationID int
+
+	events      []models.ClientEvent
+	nextEventID int
+
+	stateChanges      []models.ClientStateChange
+	nextStateChangeID int
+	offboardings      []models.ClientOffboarding
+	nextOffboardingID int
+}
+
+// NewStore creates an empty store
+func NewStore() *Store {
+	return &Store{
+		users:                  map[string]*userRecord{},
+		supervisorResponsibles: map[string][]string{},
+		clients:                map[string]*clientRecord{},
+		versions:               map[string]models.ClientVersion{},
+		assignments:            map[string]models.ClientAssignments{},
+		hrPayments:             map[string]*models.ClientHRPayment{},
+		clientAssignmentTypes:  map[string]map[int]bool{},
+		statuses:               map[int]*models.ClientAccountancyStatus{},
+		statusAssignments:      map[int]map[int]assignmentRecord{},
+		nextStatusID:           1,
+		nextAssignmentID:       1,
+		nextFielID:             1,
+		fielRenewals:           map[string]models.FielRenewal{},
+		nextCSDID:              1,
+		nextFeeID:              1,
+		contacts:               map[string]*models.ClientContact{},
+		nextContactID:          1,
+		documents:              map[string]*documentRecord{},
+		nextDocumentID:         1,
+		nextNotificationID:     1,
+		nextEventID:            1,
+		nextStateChangeID:      1,
+		nextOffboardingID:      1,
+	}
+}
+
+// newID generates a random UUID like the database defaults do
+func newID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// userName returns the username of a user id, the supervisors and responsibles are users
+func (s *Store) userName(id string) string {
+	if u, ok := s.users[id]; ok {
+		return u.Username
+	}
+
+	return ""
+}
+
+// emisorName returns the name of an emisor id
+func (s *Store) emisorName(id string) string {
+	for _, e := range s.emisors {
+		if e.ID == id {
+			return e.Name
+		}
+	}
+
+	return ""
+}
+
+// regimenName returns the name of a regimen id
+func (s *Store) regimenName(id string) string {
+	for _, r := range s.regimenes {
+		if r.ID == id {
+			return r.Name
+		}
+	}
+
+	return ""
+}
+
+// accountancyTypeName returns the name of an accountancy type id
+func (s *Store) accountancyTypeName(id int) string {
+	for _, t := range s.accountancyTypes {
+		if t.ID == fmt.Sprint(id) {
+			return t.Name
+		}
+	}
+
+	return ""
+}
+
+// assignmentStatusName returns the name of an assignment status id
+func (s *Store) assignmentStatusName(id int) string {
+	for _, st := range s.assignmentStatuses {
+		if st.ID == fmt.Sprint(id) {
+			return st.Name
+		}
+	}
+
+	return ""
+}
+
+// setStatusAssignment inserts or updates the status of an assignment type in a monthly record
+func (s *Store) setStatusAssignment(statusID, assignmentTypeID, assignmentStatusID int) {
+	assignments, ok := s.statusAssignments[statusID]
+	if !ok {
+		assignments = map[int]assignmentRecord{}
+		s.statusAssignments[statusID] = assignments
+	}
+
+	a, ok := assignments[assignmentTypeID]
+	if !ok {
+		a.ID = s.nextAssignmentID
+		s.nextAssignmentID++
+	}
+	a.AssignmentStatusID = assignmentStatusID
+	assignments[assignmentTypeID] = a
+}
+
+// lastPayment returns the most recent payment of a client
+func (s *Store) lastPayment(clientID string) (paymentRecord, bool) {
+	var last paymentRecord
+	found := false
+	for _, p := range s.payments {
+		if p.ClientID != clientID {
+			continue
+		}
+		if !found || p.LastPaymentMonth > last.LastPaymentMonth {
+			last = p
+			found = true
+		}
+	}
+
+	return last, found
+}
+
+// clientInfo builds the row of client_info_view for a client
+func (s *Store) clientInfo(c *clientRecord) models.ClientInfo {
+	a := s.assignments[c.ID]
+	info := models.ClientInfo{
+		ID:              c.ID,
+		Name:            c.Name,
+		RFC:             c.RFC,
+		RegimenName:     s.regimenName(c.RegimenID),
+		RegimenID:       c.RegimenID,
+		ClaveCIEC:       c.ClaveCIEC,
+		ClaveFiel:       c.ClaveFiel,
+		FielExpiration:  c.FielExpiration,
+		MonthlyFee:      c.MonthlyFee,
+		Active:          c.Active,
+		State:           c.State,
+		SupervisorID:    a.SupervisorID,
+		SupervisorName:  s.userName(a.SupervisorID),
+		ResponsibleID:   a.ResponsibleID,
+		ResponsibleName: s.userName(a.ResponsibleID),
+		EmisorID:        a.EmisorID,
+		EmisorName:      s.emisorName(a.EmisorID),
+	}
+
+	if p, ok := s.lastPayment(c.ID); ok {
+		info.LastPaymentMonth = p.LastPaymentMonth
+		info.LastPaymentDate = p.LastPaymentDate
+		info.UpdatedAt = p.UpdatedAt.Format(time.RFC3339)
+	}
+
+	return info
+}
+
+// accountancyClientInfo builds the accountancy row of a client
+func (s *Store) accountancyClientInfo(c *clientRecord) models.AccountancyClientInfo {
+	info := s.clientInfo(c)
+
+	return models.AccountancyClientInfo{
+		ID:              info.ID,
+		Name:            info.Name,
+		RFC:             info.RFC,
+		RegimenName:     info.RegimenName,
+		RegimenID:       info.RegimenID,
+		ClaveCIEC:       info.ClaveCIEC,
+		ClaveFiel:       info.ClaveFiel,
+		FielExpiration:  info.FielExpiration,
+		SupervisorID:    info.SupervisorID,
+		SupervisorName:  info.SupervisorName,
+		ResponsibleID:   info.ResponsibleID,
+		ResponsibleName: info.ResponsibleName,
+		EmisorID:        info.EmisorID,
+		EmisorName:      info.EmisorName,
+	}
+}
+
+// sortedClients returns the clients that match the filter ordered by name
+func (s *Store) sortedClients(filter func(c *clientRecord) bool) []*clientRecord {
+	var clients []*clientRecord
+	for _, c := range s.clients {
+		if filter(c) {
+			clients = append(clients, c)
+		}
+	}
+
+	sort.Slice(clients, func(i, j int) bool {
+		return strings.ToLower(clients[i].Name) < strings.ToLower(clients[j].Name)
+	})
+
+	return clients
+}
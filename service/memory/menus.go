@@ -0,0 +1,93 @@
+package memory
+
+import (
+	"sort"
+
+	"contabi-be/models"
+)
+
+// MenusService
+type MenusService struct {
+	store *Store
+}
+
+// NewMenusService creates a new instance of MenusService
+func NewMenusService(store *Store) *MenusService {
+	return &MenusService{store: store}
+}
+
+// GetEmisors retrieves all emisors
+func (ms *MenusService) GetEmisors() ([]models.Emisor, error) {
+	ms.store.mu.RLock()
+	defer ms.store.mu.RUnlock()
+
+	emisors := append([]models.Emisor(nil), ms.store.emisors...)
+	sort.Slice(emisors, func(i, j int) bool { return emisors[i].Name < emisors[j].Name })
+
+	return emisors, nil
+}
+
+// GetSupervisors retrieves the active users with the supervisor role
+func (ms *MenusService) GetSupervisors() ([]models.Supervisor, error) {
+	ms.store.mu.RLock()
+	defer ms.store.mu.RUnlock()
+
+	var supervisors []models.Supervisor
+	for _, u := range ms.store.users {
+		if u.Active && u.Role == models.RoleSupervisor {
+			supervisors = append(supervisors, models.Supervisor{ID: u.ID, Name: u.Username})
+		}
+	}
+	sort.Slice(supervisors, func(i, j int) bool { return supervisors[i].Name < supervisors[j].Name })
+
+	return supervisors, nil
+}
+
+// GetResponsiblesBySupervisor retrieves the active responsibles of a supervisor
+func (ms *MenusService) GetResponsiblesBySupervisor(supervisorID string) ([]models.Responsible, error) {
+	ms.store.mu.RLock()
+	defer ms.store.mu.RUnlock()
+
+	var responsibles []models.Responsible
+	for _, id := range ms.store.supervisorResponsibles[supervisorID] {
+		if u, ok := ms.store.users[id]; ok && u.Active {
+			responsibles = append(responsibles, models.Responsible{ID: u.ID, Name: u.Username})
+		}
+	}
+	sort.Slice(responsibles, func(i, j int) bool { return responsibles[i].Name < responsibles[j].Name })
+
+	return responsibles, nil
+}
+
+// GetRegimenes retrieves all regimenes
+func (ms *MenusService) GetRegimenes() ([]models.Regimen, error) {
+	ms.store.mu.RLock()
+	defer ms.store.mu.RUnlock()
+
+	regimenes := append([]models.Regimen(nil), ms.store.regimenes...)
+	sort.Slice(regimenes, func(i, j int) bool { return regimenes[i].Name < regimenes[j].Name })
+
+	return regimenes, nil
+}
+
+// GetAccountancyTypes retrieves all accountancy types
+func (ms *MenusService) GetAccountancyTypes() ([]models.AccountancyType, error) {
+	ms.store.mu.RLock()
+	defer ms.store.mu.RUnlock()
+
+	types := append([]models.AccountancyType(nil), ms.store.accountancyTypes...)
+	sort.Slice(types, func(i, j int) bool { return types[i].Name < types[j].Name })
+
+	return types, nil
+}
+
+// GetAccountancyStatuses retrieves all accountancy assignment statuses
+func (ms *MenusService) GetAccountancyStatuses() ([]models.AccountancyAssignmentStatus, error) {
+	ms.store.mu.RLock()
+	defer ms.store.mu.RUnlock()
+
+	statuses := append([]models.AccountancyAssignmentStatus(nil), ms.store.assignmentStatuses...)
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+
+	return statuses, nil
+}
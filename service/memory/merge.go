@@ -0,0 +1,275 @@
+package memory
+
+import (
+	"time"
+
+	"contabi-be/models"
+)
+
+// MergeClients moves the payments, nomina payments, accountancy statuses,
+// fees, assignment types, contacts, documents, certificates and timeline
+// events of the source client to the target and deactivates the source
+// pointing it to the target. When both clients have records of a month the
+// ones of the client named by keep survive, its fees apply from the month they
+// start. The target keeps its assignment history, changes of state and FIEL
+// renewal, the ones of the source are moved where they do not overlap them.
+func (cs *ClientsService) MergeClients(sourceID, targetID, keep string) (models.ClientMergeResult, error) {
+	cs.store.mu.Lock()
+	defer cs.store.mu.Unlock()
+
+	result := models.ClientMergeResult{SourceID: sourceID, TargetID: targetID, Conflicts: keep}
+
+	source, ok := cs.store.clients[sourceID]
+	if !ok || source.MergedInto != "" {
+		return result, models.NewNotFoundError("unmerged source client not found")
+	}
+	if _, ok := cs.store.clients[targetID]; !ok {
+		return result, models.NewNotFoundError("client not found")
+	}
+
+	discardedID, keptID := sourceID, targetID
+	if keep == models.MergeKeepSource {
+		discardedID, keptID = targetID, sourceID
+	}
+
+	// payments, a month of the discarded client is dropped when the kept one has it
+	keptMonths := map[string]bool{}
+	for _, p := range cs.store.payments {
+		if p.ClientID == keptID {
+			keptMonths[monthOf(p.LastPaymentMonth)] = true
+		}
+	}
+	payments := cs.store.payments[:0]
+	for _, p := range cs.store.payments {
+		if p.ClientID == discardedID && keptMonths[monthOf(p.LastPaymentMonth)] {
+			result.Payments.Discarded++
+			continue
+		}
+		if p.ClientID == sourceID {
+			p.ClientID = targetID
+			result.Payments.Moved++
+		}
+		payments = append(payments, p)
+	}
+	cs.store.payments = payments
+
+	// nomina payments conflict when they share the HR entity and the month
+	keptHRMonths := map[[2]string]bool{}
+	for _, p := range cs.store.hrPayments {
+		if p.ClientID == keptID {
+			keptHRMonths[[2]string{p.HREntityID, monthOf(p.PaymentMonth)}] = true
+		}
+	}
+	for id, p := range cs.store.hrPayments {
+		if p.ClientID == discardedID && keptHRMonths[[2]string{p.HREntityID, monthOf(p.PaymentMonth)}] {
+			delete(cs.store.hrPayments, id)
+			result.HRPayments.Discarded++
+		}
+	}
+	for _, p := range cs.store.hrPayments {
+		if p.ClientID == sourceID {
+			p.ClientID = targetID
+			result.HRPayments.Moved++
+		}
+	}
+
+	// the kept status of a month receives the assignment types it lacks
+	keptStatuses := map[string][]int{}
+	for _, st := range cs.store.statuses {
+		if st.ClientID == keptID {
+			keptStatuses[monthOf(st.Month)] = append(keptStatuses[monthOf(st.Month)], st.ID)
+		}
+	}
+	for id, st := range cs.store.statuses {
+		kept, ok := keptStatuses[monthOf(st.Month)]
+		if st.ClientID != discardedID || !ok {
+			continue
+		}
+		for typeID, a := range cs.store.statusAssignments[id] {
+			for _, keptStatusID := range kept {
+				if _, exists := cs.store.statusAssignments[keptStatusID][typeID]; !exists {
+					cs.store.setStatusAssignment(keptStatusID, typeID, a.AssignmentStatusID)
+				}
+			}
+		}
+		delete(cs.store.statusAssignments, id)
+		delete(cs.store.statuses, id)
+		result.AccountancyStatuses.Discarded++
+	}
+	for _, st := range cs.store.statuses {
+		if st.ClientID == sourceID {
+			st.ClientID = targetID
+			result.AccountancyStatuses.Moved++
+		}
+	}
+
+	// assignment types are united, the ones the target already had are discarded
+	targetTypes, ok := cs.store.clientAssignmentTypes[targetID]
+	if !ok {
+		targetTypes = map[int]bool{}
+		cs.store.clientAssignmentTypes[targetID] = targetTypes
+	}
+	for typeID := range cs.store.clientAssignmentTypes[sourceID] {
+		if targetTypes[typeID] {
+			result.AssignmentTypes.Discarded++
+			continue
+		}
+		targetTypes[typeID] = true
+		result.AssignmentTypes.Moved++
+	}
+	delete(cs.store.clientAssignmentTypes, sourceID)
+
+	for _, c := range cs.store.contacts {
+		if c.ClientID == sourceID {
+			c.ClientID = targetID
+			result.Contacts.Moved++
+		}
+	}
+
+	for _, d := range cs.store.documents {
+		if d.ClientID == sourceID {
+			d.ClientID = targetID
+			result.Documents.Moved++
+		}
+	}
+
+	for i := range cs.store.events {
+		if cs.store.events[i].ClientID == sourceID {
+			cs.store.events[i].ClientID = targetID
+			result.Events.Moved++
+		}
+	}
+
+	// the fees of the kept client apply from the month they start
+	var keptFees, discardedFees []models.ClientFee
+	for _, f := range cs.store.fees {
+		switch f.ClientID {
+		case keptID:
+			keptFees = append(keptFees, f)
+		case discardedID:
+			discardedFees = append(discardedFees, f)
+		}
+	}
+	merge := models.MergeFees(keptFees, discardedFees)
+	discardedFee := map[string]bool{}
+	for _, id := range merge.Discard {
+		discardedFee[id] = true
+	}
+	fees := cs.store.fees[:0]
+	for _, f := range cs.store.fees {
+		if discardedFee[f.ID] {
+			result.Fees.Discarded++
+			continue
+		}
+		if f.ID == merge.DateFirst {
+			f.EffectiveMonth = merge.Month
+		}
+		if f.ClientID == sourceID {
+			f.ClientID = targetID
+			result.Fees.Moved++
+		}
+		fees = append(fees, f)
+	}
+	cs.store.fees = fees
+
+	// the current period of the source ends with the merge, its periods that
+	// overlap one of the target are dropped, as are the ones left empty
+	today := time.Now().Format(time.DateOnly)
+	var targetPeriods []assignmentPeriodRecord
+	for _, p := range cs.store.assignmentHistory {
+		if p.ClientID == targetID {
+			targetPeriods = append(targetPeriods, p)
+		}
+	}
+	periods := cs.store.assignmentHistory[:0]
+	for _, p := range cs.store.assignmentHistory {
+		if p.ClientID == sourceID {
+			if p.ValidTo == "" {
+				p.ValidTo = today
+			}
+			if p.ValidFrom == p.ValidTo || overlapsPeriod(p, targetPeriods) {
+				result.AssignmentHistory.Discarded++
+				continue
+			}
+			p.ClientID = targetID
+			result.AssignmentHistory.Moved++
+		}
+		periods = append(periods, p)
+	}
+	cs.store.assignmentHistory = periods
+
+	// the target keeps its changes of state, the ones of the source are moved
+	// when they took effect before the first change of the target. A target
+	// without changes was always in its current state.
+	firstChange := ""
+	for _, c := range cs.store.stateChanges {
+		if c.ClientID == targetID && (firstChange == "" || c.EffectiveDate < firstChange) {
+			firstChange = c.EffectiveDate
+		}
+	}
+	changes := cs.store.stateChanges[:0]
+	for _, c := range cs.store.stateChanges {
+		if c.ClientID == sourceID {
+			if c.EffectiveDate >= firstChange {
+				result.StateChanges.Discarded++
+				continue
+			}
+			c.ClientID = targetID
+			result.StateChanges.Moved++
+		}
+		changes = append(changes, c)
+	}
+	cs.store.stateChanges = changes
+
+	for i := range cs.store.fielCertificates {
+		if cs.store.fielCertificates[i].ClientID == sourceID {
+			cs.store.fielCertificates[i].ClientID = targetID
+			result.FielCertificates.Moved++
+		}
+	}
+
+	for i := range cs.store.csdCertificates {
+		if cs.store.csdCertificates[i].ClientID == sourceID {
+			cs.store.csdCertificates[i].ClientID = targetID
+			result.CSDCertificates.Moved++
+		}
+	}
+
+	// a client has a single renewal, the one of the target is kept
+	if renewal, ok := cs.store.fielRenewals[sourceID]; ok {
+		if _, exists := cs.store.fielRenewals[targetID]; exists {
+			result.FielRenewals.Discarded++
+		} else {
+			cs.store.fielRenewals[targetID] = renewal
+			result.FielRenewals.Moved++
+		}
+		delete(cs.store.fielRenewals, sourceID)
+	}
+
+	source.Active = false
+	source.State = models.ClientTerminated
+	source.MergedInto = targetID
+	cs.store.touchClient(sourceID)
+
+	return result, nil
+}
+
+// overlapsPeriod tells whether an assignment period overlaps one of the
+// periods given, a period without start began before any other and one
+// without end has not ended
+func overlapsPeriod(p assignmentPeriodRecord, periods []assignmentPeriodRecord) bool {
+	until := func(validTo string) string {
+		if validTo == "" {
+			return "9999-12-31"
+		}
+		return validTo
+	}
+
+	for _, other := range periods {
+		if other.ValidFrom < until(p.ValidTo) && p.ValidFrom < until(other.ValidTo) {
+			return true
+		}
+	}
+
+	return false
+}
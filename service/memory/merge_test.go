@@ -0,0 +1,146 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"contabi-be/models"
+)
+
+// demoClientID finds a client of the demo store by its name
+func demoClientID(t *testing.T, s *Store, name string) string {
+	t.Helper()
+
+	for id, c := range s.clients {
+		if c.Name == name {
+			return id
+		}
+	}
+
+	t.Fatalf("client %q not in the demo store", name)
+	return ""
+}
+
+func TestMergeClients(t *testing.T) {
+	month := func(offset int) string {
+		now := time.Now()
+		return time.Date(now.Year(), now.Month()+time.Month(offset), 1, 0, 0, 0, 0, time.UTC).Format("2006-01")
+	}
+
+	// Abarrotes Muñoz paid the last three months and Ramírez Ortega Lucía the
+	// last two, both have a fee since they were created and an accountancy
+	// record of last month
+	tests := []struct {
+		keep string
+		// payments are the months paid by the target after the merge
+		payments []string
+		want     models.ClientMergeResult
+	}{
+		{
+			keep:     models.MergeKeepTarget,
+			payments: []string{month(-2), month(-1), month(0)},
+			want: models.ClientMergeResult{
+				Payments:            models.MergeCount{Discarded: 2},
+				AccountancyStatuses: models.MergeCount{Discarded: 1},
+				AssignmentTypes:     models.MergeCount{Discarded: 2},
+				Fees:                models.MergeCount{Discarded: 1},
+				AssignmentHistory:   models.MergeCount{Discarded: 1},
+			},
+		},
+		{
+			keep:     models.MergeKeepSource,
+			payments: []string{month(-2), month(-1), month(0)},
+			want: models.ClientMergeResult{
+				Payments:            models.MergeCount{Moved: 2, Discarded: 2},
+				AccountancyStatuses: models.MergeCount{Moved: 1, Discarded: 1},
+				AssignmentTypes:     models.MergeCount{Discarded: 2},
+				Fees:                models.MergeCount{Moved: 1, Discarded: 1},
+				AssignmentHistory:   models.MergeCount{Discarded: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		s, err := NewDemoStore()
+		if err != nil {
+			t.Fatalf("seeding the demo store: %v", err)
+		}
+		cs := NewClientsService(s)
+		targetID := demoClientID(t, s, "Abarrotes Muñoz")
+		sourceID := demoClientID(t, s, "Ramírez Ortega Lucía")
+
+		result, err := cs.MergeClients(sourceID, targetID, tt.keep)
+		if err != nil {
+			t.Fatalf("keeping the %s: %v", tt.keep, err)
+		}
+
+		tt.want.SourceID, tt.want.TargetID, tt.want.Conflicts = sourceID, targetID, tt.keep
+		if result != tt.want {
+			t.Errorf("keeping the %s the merge is %+v, want %+v", tt.keep, result, tt.want)
+		}
+
+		paid := map[string]int{}
+		for _, p := range s.payments {
+			if p.ClientID == sourceID {
+				t.Errorf("keeping the %s the payment of %s stayed on the source", tt.keep, p.LastPaymentMonth)
+			}
+			if p.ClientID == targetID {
+				paid[monthOf(p.LastPaymentMonth)]++
+			}
+		}
+		for _, m := range tt.payments {
+			if paid[m] != 1 {
+				t.Errorf("keeping the %s the target has %d payments of %s, want 1", tt.keep, paid[m], m)
+			}
+		}
+
+		fees := 0
+		for _, f := range s.fees {
+			if f.ClientID == sourceID {
+				t.Errorf("keeping the %s the fee %s stayed on the source", tt.keep, f.ID)
+			}
+			if f.ClientID == targetID {
+				fees++
+			}
+		}
+		if fees != 1 {
+			t.Errorf("keeping the %s the target has %d fees, want 1", tt.keep, fees)
+		}
+
+		source := s.clients[sourceID]
+		if source.Active || source.State != models.ClientTerminated || source.MergedInto != targetID {
+			t.Errorf("keeping the %s the source is %+v, want it terminated and merged into the target", tt.keep, source)
+		}
+
+		if _, err := cs.MergeClients(sourceID, targetID, tt.keep); err == nil {
+			t.Errorf("keeping the %s the source was merged twice", tt.keep)
+		}
+	}
+}
+
+func TestOverlapsPeriod(t *testing.T) {
+	periods := []assignmentPeriodRecord{
+		{ValidFrom: "2024-01-01", ValidTo: "2024-03-01"},
+		{ValidFrom: "2024-06-01"},
+	}
+
+	tests := []struct {
+		from, to string
+		overlaps bool
+	}{
+		{"2023-06-01", "2024-01-01", false},
+		{"2023-06-01", "2024-01-02", true},
+		{"2024-03-01", "2024-06-01", false},
+		{"2024-05-01", "2024-06-02", true},
+		{"2025-01-01", "", true},
+		{"", "2023-01-01", false},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		p := assignmentPeriodRecord{ValidFrom: tt.from, ValidTo: tt.to}
+		if got := overlapsPeriod(p, periods); got != tt.overlaps {
+			t.Errorf("overlapsPeriod(%s to %s) = %v, want %v", tt.from, tt.to, got, tt.overlaps)
+		}
+	}
+}
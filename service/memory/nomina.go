@@ -0,0 +1,142 @@
+package memory
+
+import (
+	"sort"
+	"strconv"
+
+	"contabi-be/models"
+)
+
+// NominasService
+type NominasService struct {
+	store *Store
+}
+
+// NewNominasService creates a new instance of NominasService
+func NewNominasService(store *Store) *NominasService {
+	return &NominasService{store: store}
+}
+
+// CreateClientPaymentRecord creates a new client payment record
+func (ns *NominasService) CreateClientPaymentRecord(clientPaymentRecord models.ClientHRPayment) error {
+	ns.store.mu.Lock()
+	defer ns.store.mu.Unlock()
+
+	if _, ok := ns.store.clients[clientPaymentRecord.ClientID]; !ok {
+		return models.NewValidationError("client payment record references a record that does not exist", map[string]string{"column": "client_id"})
+	}
+
+	clientPaymentRecord.ID = newID()
+	ns.store.hrPayments[clientPaymentRecord.ID] = &clientPaymentRecord
+
+	return nil
+}
+
+// GetClientsWithPendingPaymentsByHREntityID gets the list of all clients with pending payments of specific HR entity
+func (ns *NominasService) GetClientsWithPendingPaymentsByHREntityID(hrEntityID string) ([]models.ClientWithPendingHRPayment, error) {
+	ns.store.mu.RLock()
+	defer ns.store.mu.RUnlock()
+
+	seen := map[string]bool{}
+	var clients []models.ClientWithPendingHRPayment
+	for _, p := range ns.store.hrPayments {
+		if p.HREntityID != hrEntityID || p.Paid || seen[p.ClientID] {
+			continue
+		}
+		seen[p.ClientID] = true
+
+		clients = append(clients, models.ClientWithPendingHRPayment{
+			ClientID:   p.ClientID,
+			ClientName: ns.store.clients[p.ClientID].Name,
+			HREntityID: p.HREntityID,
+		})
+	}
+	sort.Slice(clients, func(i, j int) bool { return clients[i].ClientName < clients[j].ClientName })
+
+	return clients, nil
+}
+
+// GetClientPendingPaymentsByHREntityIDDetails gets all the pending payments of a specific client and HR entity
+func (ns *NominasService) GetClientPendingPaymentsByHREntityIDDetails(clientID, hrEntityID string) ([]models.ClientWithPendingHRPaymentDetails, error) {
+	ns.store.mu.RLock()
+	defer ns.store.mu.RUnlock()
+
+	var payments []models.ClientWithPendingHRPaymentDetails
+	for _, p := range ns.store.hrPayments {
+		if p.HREntityID != hrEntityID || p.ClientID != clientID || p.Paid {
+			continue
+		}
+
+		payments = append(payments, models.ClientWithPendingHRPaymentDetails{
+			ID:           p.ID,
+			ClientID:     p.ClientID,
+			ClientName:   ns.store.clients[p.ClientID].Name,
+			HREntityID:   p.HREntityID,
+			PaymentMonth: p.PaymentMonth,
+			Amount:       p.Amount,
+			Paid:         p.Paid,
+			Month:        p.Month,
+		})
+	}
+	sort.Slice(payments, func(i, j int) bool { return payments[i].PaymentMonth > payments[j].PaymentMonth })
+
+	return payments, nil
+}
+
+// UpdateClientPaymentRecord updates a client payment record
+func (ns *NominasService) UpdateClientPaymentRecord(clientPaymentRecord models.UpdateClientHRPayment) error {
+	ns.store.mu.Lock()
+	defer ns.store.mu.Unlock()
+
+	p, ok := ns.store.hrPayments[clientPaymentRecord.ID]
+	if !ok {
+		return models.NewNotFoundError("client payment record not found")
+	}
+
+	paid, err := strconv.ParseBool(clientPaymentRecord.Paid)
+	if err != nil {
+		return models.NewValidationError("client payment record has a value with an invalid format", nil)
+	}
+
+	p.Paid = paid
+	p.PaymentMonth = clientPaymentRecord.PaymentMonth
+	p.Amount = clientPaymentRecord.Amount
+	p.Month = clientPaymentRecord.Month
+
+	return nil
+}
+
+// GetClientPaymentRecord gets a client payment record
+func (ns *NominasService) GetClientPaymentRecord(id string) (models.ClientHRPayment, error) {
+	ns.store.mu.RLock()
+	defer ns.store.mu.RUnlock()
+
+	p, ok := ns.store.hrPayments[id]
+	if !ok {
+		return models.ClientHRPayment{}, models.NewNotFoundError("client payment record not found")
+	}
+
+	return *p, nil
+}
+
+// GetClientHRPaymentsHistory gets all the payments of a specific client and HR entity
+func (ns *NominasService) GetClientHRPaymentsHistory(clientID, hrEntityID string) ([]models.ClientHRPayment, error) {
+	ns.store.mu.RLock()
+	defer ns.store.mu.RUnlock()
+
+	var payments []models.ClientHRPayment
+	for _, p := range ns.store.hrPayments {
+		if p.HREntityID == hrEntityID && p.ClientID == clientID {
+			payments = append(payments, models.ClientHRPayment{
+				ID:           p.ID,
+				PaymentMonth: p.PaymentMonth,
+				Amount:       p.Amount,
+				Paid:         p.Paid,
+				Month:        p.Month,
+			})
+		}
+	}
+	sort.Slice(payments, func(i, j int) bool { return payments[i].PaymentMonth > payments[j].PaymentMonth })
+
+	return payments, nil
+}
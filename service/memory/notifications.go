@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"slices"
+	"strconv"
+	"time"
+
+	"contabi-be/models"
+)
+
+// NotificationsService keeps the notifications of the users in the store
+type NotificationsService struct {
+	store *Store
+}
+
+// NewNotificationsService creates a new instance of NotificationsService
+func NewNotificationsService(store *Store) *NotificationsService {
+	return &NotificationsService{store: store}
+}
+
+// CreateNotifications stores the notifications whose key was not sent to the
+// user before and returns how many were stored
+func (ns *NotificationsService) CreateNotifications(notifications []models.Notification) (int, error) {
+	ns.store.mu.Lock()
+	defer ns.store.mu.Unlock()
+
+	sent := map[string]bool{}
+	for _, n := range ns.store.notifications {
+		sent[n.UserID+"\x00"+n.Key] = true
+	}
+
+	created := 0
+	for _, n := range notifications {
+		if _, ok := ns.store.users[n.UserID]; !ok {
+			return created, models.NewValidationError("notification references a record that does not exist", map[string]string{"column": "user_id"})
+		}
+		if sent[n.UserID+"\x00"+n.Key] {
+			continue
+		}
+		sent[n.UserID+"\x00"+n.Key] = true
+
+		n.ID = strconv.Itoa(ns.store.nextNotificationID)
+		ns.store.nextNotificationID++
+		n.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+		ns.store.notifications = append(ns.store.notifications, n)
+		created++
+	}
+
+	return created, nil
+}
+
+// GetNotifications retrieves the notifications of a user, the newest first
+func (ns *NotificationsService) GetNotifications(userID string, params models.NotificationParams) ([]models.Notification, error) {
+	ns.store.mu.RLock()
+	defer ns.store.mu.RUnlock()
+
+	notifications := []models.Notification{}
+	for _, n := range ns.store.notifications {
+		if n.UserID == userID && (!params.Unread || n.ReadAt == "") {
+			notifications = append(notifications, n)
+		}
+	}
+
+	// the notifications are appended in order, the newest are the last ones
+	slices.Reverse(notifications)
+
+	return notifications, nil
+}
+
+// MarkNotificationRead marks a notification of a user as read, reading it again keeps the first time
+func (ns *NotificationsService) MarkNotificationRead(userID, notificationID string) error {
+	ns.store.mu.Lock()
+	defer ns.store.mu.Unlock()
+
+	for i := range ns.store.notifications {
+		n := &ns.store.notifications[i]
+		if n.ID == notificationID && n.UserID == userID {
+			if n.ReadAt == "" {
+				n.ReadAt = time.Now().UTC().Format(time.RFC3339)
+			}
+			return nil
+		}
+	}
+
+	return models.NewNotFoundError("notification not found")
+}
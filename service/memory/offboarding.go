@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"slices"
+	"strconv"
+	"time"
+
+	"contabi-be/models"
+)
+
+// GetClientOffboarding retrieves the latest offboarding of a client
+func (cs *ClientsService) GetClientOffboarding(clientID string) (models.ClientOffboarding, error) {
+	cs.store.mu.RLock()
+	defer cs.store.mu.RUnlock()
+
+	for i := len(cs.store.offboardings) - 1; i >= 0; i-- {
+		if o := cs.store.offboardings[i]; o.ClientID == clientID {
+			return o, nil
+		}
+	}
+
+	return models.ClientOffboarding{}, models.NewNotFoundError("client offboarding not found")
+}
+
+// OffboardClient terminates a client with the change of state given, none
+// when it was already terminated, closes its open accountancy months, revokes
+// its stored credentials and records the offboarding with its event in the
+// timeline. It fails when the client is no longer in the version given.
+func (cs *ClientsService) OffboardClient(change *models.ClientStateChange, offboarding models.ClientOffboarding, event models.ClientEvent, version models.ClientVersion) (models.ClientOffboarding, error) {
+	cs.store.mu.Lock()
+	defer cs.store.mu.Unlock()
+
+	if err := cs.store.checkClientVersion(offboarding.ClientID, version); err != nil {
+		return offboarding, err
+	}
+	if change != nil {
+		if _, err := cs.store.changeClientState(*change); err != nil {
+			return offboarding, err
+		}
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	offboarding.ClosedMonths = 0
+	for _, s := range cs.store.statuses {
+		if s.ClientID == offboarding.ClientID && s.ClosedAt == "" {
+			s.ClosedAt = now
+			s.Version++
+			offboarding.ClosedMonths++
+		}
+	}
+
+	offboarding.Revoked = cs.store.revokeCredentials(offboarding.ClientID)
+	cs.store.touchClient(offboarding.ClientID)
+
+	offboarding.ID = strconv.Itoa(cs.store.nextOffboardingID)
+	cs.store.nextOffboardingID++
+	offboarding.CreatedAt = now
+	cs.store.offboardings = append(cs.store.offboardings, offboarding)
+	cs.store.addEvent(event)
+
+	return offboarding, nil
+}
+
+// revokeCredentials clears the claves of a client, deletes its CSD with their
+// keys and clears the files of its FIEL certificates
+func (s *Store) revokeCredentials(clientID string) models.RevokedCredentials {
+	c := s.clients[clientID]
+	revoked := models.RevokedCredentials{ClaveCIEC: c.ClaveCIEC != "", ClaveFiel: c.ClaveFiel != ""}
+	c.ClaveCIEC, c.ClaveFiel = "", ""
+
+	kept := len(s.csdCertificates)
+	s.csdCertificates = slices.DeleteFunc(s.csdCertificates, func(r csdRecord) bool { return r.ClientID == clientID })
+	revoked.CSDCertificates = kept - len(s.csdCertificates)
+
+	for i, f := range s.fielCertificates {
+		if f.ClientID == clientID && f.File != nil {
+			s.fielCertificates[i].File = nil
+			revoked.FielFiles++
+		}
+	}
+
+	return revoked
+}
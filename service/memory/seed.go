@@ -0,0 +1,154 @@
+package memory
+
+import (
+	"fmt"
+	"time"
+
+	"contabi-be/models"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// DemoPassword is the password of every seeded user
+const DemoPassword = "contabi-demo"
+
+// NewDemoStore creates a store seeded with catalogs, users and clients to try the API
+func NewDemoStore() (*Store, error) {
+	s := NewStore()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(DemoPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	s.roles = []models.Role{
+		{ID: "1", Name: "Administrador"},
+		{ID: "2", Name: "Supervisor"},
+		{ID: "3", Name: "Responsable"},
+	}
+
+	addUser := func(username string, role int) string {
+		id := newID()
+		s.users[id] = &userRecord{
+			User:     models.User{ID: id, Username: username, Active: true, Role: role},
+			Password: string(hash),
+		}
+		return id
+	}
+	addUser("admin", 1)
+	laura := addUser("laura", models.RoleSupervisor)
+	miguel := addUser("miguel", models.RoleSupervisor)
+	carlos := addUser("carlos", 3)
+	sofia := addUser("sofia", 3)
+	diego := addUser("diego", 3)
+	s.supervisorResponsibles[laura] = []string{carlos, sofia}
+	s.supervisorResponsibles[miguel] = []string{diego}
+
+	s.emisors = []models.Emisor{
+		{ID: "1", Name: "Despacho Contable Principal"},
+		{ID: "2", Name: "Servicios Fiscales del Centro"},
+	}
+
+	s.regimenes = []models.Regimen{
+		{ID: "601", Name: "General de Ley Personas Morales"},
+		{ID: "603", Name: "Personas Morales con Fines no Lucrativos"},
+		{ID: "605", Name: "Sueldos y Salarios e Ingresos Asimilados a Salarios"},
+		{ID: "606", Name: "Arrendamiento"},
+		{ID: "612", Name: "Personas Físicas con Actividades Empresariales y Profesionales"},
+		{ID: "626", Name: "Régimen Simplificado de Confianza"},
+	}
+
+	s.accountancyTypes = []models.AccountancyType{
+		{ID: "1", Name: "Declaración mensual"},
+		{ID: "2", Name: "DIOT"},
+		{ID: "3", Name: "Contabilidad electrónica"},
+		{ID: "4", Name: "Nómina"},
+	}
+
+	s.assignmentStatuses = []models.AccountancyAssignmentStatus{
+		{ID: "1", Name: "Pendiente"},
+		{ID: "2", Name: "En proceso"},
+		{ID: "3", Name: "Presentada"},
+	}
+
+	now := time.Now()
+	month := func(offset int) string {
+		return time.Date(now.Year(), now.Month()+time.Month(offset), 1, 0, 0, 0, 0, time.UTC).Format(time.DateOnly)
+	}
+
+	clients := []struct {
+		client      clientRecord
+		assignments models.ClientAssignments
+		paidMonths  int
+		// previousResponsible had the client until the start of last month
+		previousResponsible string
+		// nextFee is the fee scheduled from next month
+		nextFee string
+	}{
+		{clientRecord{Name: "Abarrotes Muñoz", RFC: "MUGA800101ABA", RegimenID: "612", MonthlyFee: "1500", FielExpiration: month(2)},
+			models.ClientAssignments{SupervisorID: laura, ResponsibleID: carlos, EmisorID: "1"}, 3, sofia, ""},
+		{clientRecord{Name: "Constructora del Bajío", RFC: "CBA050315QW2", RegimenID: "601", MonthlyFee: "4800", FielExpiration: month(14)},
+			models.ClientAssignments{SupervisorID: laura, ResponsibleID: sofia, EmisorID: "1"}, 1, "", "5200"},
+		{clientRecord{Name: "Fundación Peña Azul", RFC: "FPA101010HJ9", RegimenID: "603", MonthlyFee: "2000", FielExpiration: month(1)},
+			models.ClientAssignments{SupervisorID: laura, ResponsibleID: sofia, EmisorID: "2"}, 0, "", ""},
+		{clientRecord{Name: "Ramírez Ortega Lucía", RFC: "RAOL900712PL7", RegimenID: "626", MonthlyFee: "900", FielExpiration: month(24)},
+			models.ClientAssignments{SupervisorID: miguel, ResponsibleID: diego, EmisorID: "2"}, 2, "", ""},
+		{clientRecord{Name: "Transportes Núñez", RFC: "TNU120601RT4", RegimenID: "601", MonthlyFee: "3500", FielExpiration: month(-1)},
+			models.ClientAssignments{SupervisorID: miguel, ResponsibleID: diego, EmisorID: "1"}, 0, "", ""},
+	}
+
+	for i, c := range clients {
+		id := newID()
+		record := c.client
+		record.ID = id
+		record.Active = true
+		record.State = models.ClientActive
+		record.ClaveCIEC = fmt.Sprintf("ciec-demo-%d", i+1)
+		record.ClaveFiel = fmt.Sprintf("fiel-demo-%d", i+1)
+		s.clients[id] = &record
+
+		a := c.assignments
+		a.ClientID = id
+		s.assignments[id] = a
+
+		period := assignmentPeriodRecord{ClientAssignments: a}
+		if c.previousResponsible != "" {
+			previous := period
+			previous.ResponsibleID = c.previousResponsible
+			previous.ValidTo = month(-1)
+			period.ValidFrom = month(-1)
+			s.assignmentHistory = append(s.assignmentHistory, previous)
+		}
+		s.assignmentHistory = append(s.assignmentHistory, period)
+
+		s.saveFee(models.ClientFee{ClientID: id, MonthlyFee: record.MonthlyFee})
+		if c.nextFee != "" {
+			s.saveFee(models.ClientFee{ClientID: id, MonthlyFee: c.nextFee, EffectiveMonth: month(1)[:7]})
+		}
+
+		for m := c.paidMonths; m > 0; m-- {
+			s.payments = append(s.payments, paymentRecord{
+				ClientID:         id,
+				LastPaymentMonth: month(1 - m),
+				LastPaymentDate:  month(1 - m),
+				FolioFactura:     fmt.Sprintf("F-%d%02d", i+1, m),
+				UpdatedAt:        now,
+			})
+		}
+
+		s.clientAssignmentTypes[id] = map[int]bool{1: true, 3: true}
+		status := &models.ClientAccountancyStatus{
+			ID:       s.nextStatusID,
+			ClientID: id,
+			Month:    month(-1),
+			DueDate:  time.Date(now.Year(), now.Month(), 17, 0, 0, 0, 0, time.UTC).Format(time.DateOnly),
+			Version:  1,
+		}
+		s.nextStatusID++
+		s.statuses[status.ID] = status
+		s.setStatusAssignment(status.ID, 1, 3)
+		s.setStatusAssignment(status.ID, 3, 2)
+	}
+
+	return s, nil
+}
@@ -0,0 +1,99 @@
+package memory
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"contabi-be/models"
+)
+
+// GetClientStateChanges retrieves the changes of state of a client, the
+// latest effective date first
+func (cs *ClientsService) GetClientStateChanges(clientID string) ([]models.ClientStateChange, error) {
+	cs.store.mu.RLock()
+	defer cs.store.mu.RUnlock()
+
+	changes := []models.ClientStateChange{}
+	for _, c := range cs.store.stateChanges {
+		if c.ClientID == clientID {
+			changes = append(changes, c)
+		}
+	}
+	sortStateChanges(changes)
+
+	return changes, nil
+}
+
+// GetAllClientStateChanges retrieves the changes of state of every client
+func (cs *ClientsService) GetAllClientStateChanges() ([]models.ClientStateChange, error) {
+	cs.store.mu.RLock()
+	defer cs.store.mu.RUnlock()
+
+	changes := append([]models.ClientStateChange{}, cs.store.stateChanges...)
+	sortStateChanges(changes)
+
+	return changes, nil
+}
+
+// ChangeClientState moves a client from the state of the change to its new
+// one and records the change in its history and its timeline, it fails with a
+// conflict when the client is no longer in the state the change starts from
+// or in the version given
+func (cs *ClientsService) ChangeClientState(change models.ClientStateChange, version models.ClientVersion) (models.ClientStateChange, error) {
+	cs.store.mu.Lock()
+	defer cs.store.mu.Unlock()
+
+	if err := cs.store.checkClientVersion(change.ClientID, version); err != nil {
+		return change, err
+	}
+
+	saved, err := cs.store.changeClientState(change)
+	if err != nil {
+		return saved, err
+	}
+	cs.store.touchClient(change.ClientID)
+	cs.store.addEvent(change.Event())
+
+	return saved, nil
+}
+
+// changeClientState moves a client to another state and records the change,
+// the callers check and move the version of the client
+func (s *Store) changeClientState(change models.ClientStateChange) (models.ClientStateChange, error) {
+	c, ok := s.clients[change.ClientID]
+	if !ok {
+		return change, models.NewNotFoundError("client not found")
+	}
+	if c.State != change.FromState {
+		return change, models.NewConflictError("The client is "+c.State+", it changed state since it was read", nil)
+	}
+
+	c.State = change.ToState
+	c.Active = models.IsActiveState(change.ToState)
+
+	change.ID = strconv.Itoa(s.nextStateChangeID)
+	s.nextStateChangeID++
+	change.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+	s.stateChanges = append(s.stateChanges, change)
+
+	return change, nil
+}
+
+// sortStateChanges orders changes of state by client and latest effective
+// date first like the database does
+func sortStateChanges(changes []models.ClientStateChange) {
+	sort.SliceStable(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if a.ClientID != b.ClientID {
+			return a.ClientID < b.ClientID
+		}
+		if a.EffectiveDate != b.EffectiveDate {
+			return a.EffectiveDate > b.EffectiveDate
+		}
+		idA, _ := strconv.Atoi(a.ID)
+		idB, _ := strconv.Atoi(b.ID)
+
+		return idA > idB
+	})
+}
@@ -0,0 +1,70 @@
+package memory
+
+import (
+	"strconv"
+	"time"
+
+	"contabi-be/models"
+)
+
+// TimelineService keeps the notes and the recorded events of the clients in the store
+type TimelineService struct {
+	store *Store
+}
+
+// NewTimelineService creates a new instance of TimelineService
+func NewTimelineService(store *Store) *TimelineService {
+	return &TimelineService{store: store}
+}
+
+// CreateClientEvent adds an event to the timeline of a client
+func (ts *TimelineService) CreateClientEvent(event models.ClientEvent) (models.ClientEvent, error) {
+	ts.store.mu.Lock()
+	defer ts.store.mu.Unlock()
+
+	if _, ok := ts.store.clients[event.ClientID]; !ok {
+		return event, models.NewValidationError("client event references a record that does not exist", map[string]string{"column": "client_id"})
+	}
+	if event.UserID != "" {
+		if _, ok := ts.store.users[event.UserID]; !ok {
+			return event, models.NewValidationError("client event references a record that does not exist", map[string]string{"column": "user_id"})
+		}
+	}
+
+	return ts.store.addEvent(event), nil
+}
+
+// addEvent stores an event of a client that exists, with its user when it has one
+func (s *Store) addEvent(event models.ClientEvent) models.ClientEvent {
+	event.ID = strconv.Itoa(s.nextEventID)
+	s.nextEventID++
+	event.UserName = s.userName(event.UserID)
+	event.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+	s.events = append(s.events, event)
+
+	return event
+}
+
+// GetClientTimeline retrieves a page of the events of a client, the newest
+// first, of every type when the params have none
+func (ts *TimelineService) GetClientTimeline(clientID string, params models.TimelineParams) (models.ClientEventPage, error) {
+	ts.store.mu.RLock()
+	defer ts.store.mu.RUnlock()
+
+	// the events are appended in order, the newest are the last ones
+	var events []models.ClientEvent
+	for i := len(ts.store.events) - 1; i >= 0; i-- {
+		e := ts.store.events[i]
+		if e.ClientID == clientID && (params.Type == "" || e.Type == params.Type) {
+			e.UserName = ts.store.userName(e.UserID)
+			events = append(events, e)
+		}
+	}
+
+	page := models.ClientEventPage{Items: []models.ClientEvent{}, Total: len(events), Page: params.Page, PageSize: params.PageSize}
+	start := min((params.Page-1)*params.PageSize, len(events))
+	end := min(start+params.PageSize, len(events))
+	page.Items = append(page.Items, events[start:end]...)
+
+	return page, nil
+}
@@ -0,0 +1,202 @@
+package memory
+
+import (
+	"sort"
+	"strconv"
+
+	"contabi-be/models"
+)
+
+// UsersService
+type UsersService struct {
+	store *Store
+}
+
+// NewUsersService creates a new instance of UsersService
+func NewUsersService(store *Store) *UsersService {
+	return &UsersService{store: store}
+}
+
+// GetUsers retrieves all users
+func (us *UsersService) GetUsers() ([]models.User, error) {
+	us.store.mu.RLock()
+	defer us.store.mu.RUnlock()
+
+	var users []models.User
+	for _, u := range us.store.users {
+		users = append(users, u.User)
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Username < users[j].Username
+	})
+
+	return users, nil
+}
+
+// GetUserByID retrieves user info by ID
+func (us *UsersService) GetUserByID(userID string) (models.User, error) {
+	us.store.mu.RLock()
+	defer us.store.mu.RUnlock()
+
+	u, ok := us.store.users[userID]
+	if !ok {
+		return models.User{}, models.NewNotFoundError("user not found")
+	}
+
+	return u.User, nil
+}
+
+// CreateUser creates a new user
+func (us *UsersService) CreateUser(user models.User) error {
+	us.store.mu.Lock()
+	defer us.store.mu.Unlock()
+
+	if err := us.checkUsername(user.Username, ""); err != nil {
+		return err
+	}
+
+	if !us.roleExists(user.Role) {
+		return models.NewValidationError("user role references a record that does not exist", nil)
+	}
+
+	id := newID()
+	password := user.Password
+	user.ID = id
+	user.Password = ""
+	us.store.users[id] = &userRecord{User: user, Password: password}
+
+	return nil
+}
+
+// UpdateUser updates an user
+func (us *UsersService) UpdateUser(user models.User) error {
+	us.store.mu.Lock()
+	defer us.store.mu.Unlock()
+
+	u, ok := us.store.users[user.ID]
+	if !ok {
+		return models.NewNotFoundError("user not found")
+	}
+
+	if err := us.checkUsername(user.Username, user.ID); err != nil {
+		return err
+	}
+	u.Username = user.Username
+
+	return nil
+}
+
+// UpdateUserRole updates the user role
+func (us *UsersService) UpdateUserRole(user models.User) error {
+	us.store.mu.Lock()
+	defer us.store.mu.Unlock()
+
+	u, ok := us.store.users[user.ID]
+	if !ok {
+		return models.NewNotFoundError("user role not found")
+	}
+
+	if !us.roleExists(user.Role) {
+		return models.NewValidationError("user role references a record that does not exist", nil)
+	}
+	u.Role = user.Role
+
+	return nil
+}
+
+// PutUserPassword updates the user password
+func (us *UsersService) PutUserPassword(user models.User) error {
+	us.store.mu.Lock()
+	defer us.store.mu.Unlock()
+
+	u, ok := us.store.users[user.ID]
+	if !ok {
+		return models.NewNotFoundError("user not found")
+	}
+	u.Password = user.Password
+
+	return nil
+}
+
+// PatchUser changes the username, role and password set in the patch, it
+// checks all of them before changing any, the password comes already hashed
+func (us *UsersService) PatchUser(userID string, patch models.UserPatch) error {
+	us.store.mu.Lock()
+	defer us.store.mu.Unlock()
+
+	u, ok := us.store.users[userID]
+	if !ok {
+		return models.NewNotFoundError("user not found")
+	}
+
+	if patch.Username.Set {
+		if err := us.checkUsername(patch.Username.Value, userID); err != nil {
+			return err
+		}
+	}
+	if patch.Role.Set && !us.roleExists(patch.Role.Value) {
+		return models.NewValidationError("user role references a record that does not exist", nil)
+	}
+
+	if patch.Username.Set {
+		u.Username = patch.Username.Value
+	}
+	if patch.Role.Set {
+		u.Role = patch.Role.Value
+	}
+	if patch.Password.Set {
+		u.Password = patch.Password.Value
+	}
+
+	return nil
+}
+
+// DeleteUser deactivates an user
+func (us *UsersService) DeleteUser(userID string) error {
+	us.store.mu.Lock()
+	defer us.store.mu.Unlock()
+
+	u, ok := us.store.users[userID]
+	if !ok {
+		return models.NewNotFoundError("user not found")
+	}
+	u.Active = false
+
+	return nil
+}
+
+// GetRoles gets all the roles
+func (us *UsersService) GetRoles() ([]models.Role, error) {
+	us.store.mu.RLock()
+	defer us.store.mu.RUnlock()
+
+	roles := append([]models.Role(nil), us.store.roles...)
+	sort.Slice(roles, func(i, j int) bool {
+		return roles[i].Name < roles[j].Name
+	})
+
+	return roles, nil
+}
+
+// checkUsername fails when another user already has the username
+func (us *UsersService) checkUsername(username, userID string) error {
+	for _, u := range us.store.users {
+		if u.Username == username && u.ID != userID {
+			return models.NewConflictError("user already exists", nil)
+		}
+	}
+
+	return nil
+}
+
+// roleExists reports whether the role id is in the roles catalog
+func (us *UsersService) roleExists(roleID int) bool {
+	for _, r := range us.store.roles {
+		if r.ID == strconv.Itoa(roleID) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,57 @@
+package memory
+
+import "contabi-be/models"
+
+// GetClientVersion gets the versions of a client and its assignments
+func (cs *ClientsService) GetClientVersion(clientID string) (models.ClientVersion, error) {
+	cs.store.mu.RLock()
+	defer cs.store.mu.RUnlock()
+
+	if _, ok := cs.store.clients[clientID]; !ok {
+		return models.ClientVersion{}, models.NewNotFoundError("client not found")
+	}
+
+	return cs.store.clientVersion(clientID), nil
+}
+
+// clientVersion returns the versions of a client, the ones never changed are
+// at 1 and a client without assignments has them at 0 like the database does
+func (s *Store) clientVersion(clientID string) models.ClientVersion {
+	v, ok := s.versions[clientID]
+	if !ok {
+		v = models.ClientVersion{Client: 1, Assignments: 1}
+	}
+	if _, ok := s.assignments[clientID]; !ok {
+		v.Assignments = 0
+	}
+
+	return v
+}
+
+// checkClientVersion fails when a client is missing or its versions are not
+// the ones given, the changes check it before they change anything and move
+// the version forward once they are done
+func (s *Store) checkClientVersion(clientID string, version models.ClientVersion) error {
+	if _, ok := s.clients[clientID]; !ok {
+		return models.NewNotFoundError("client not found")
+	}
+	if current := s.clientVersion(clientID); current != version {
+		return models.NewPreconditionFailedError("The client changed since it was read", map[string]string{"etag": current.ETag()})
+	}
+
+	return nil
+}
+
+// touchClient moves the version of a client forward
+func (s *Store) touchClient(clientID string) {
+	v := s.clientVersion(clientID)
+	v.Client++
+	s.versions[clientID] = v
+}
+
+// touchAssignments moves the version of the assignments of a client forward
+func (s *Store) touchAssignments(clientID string) {
+	v := s.clientVersion(clientID)
+	v.Assignments++
+	s.versions[clientID] = v
+}
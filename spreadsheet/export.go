@@ -0,0 +1,275 @@
+package spreadsheet
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/xuri/excelize/v2"
+)
+
+// FormatPDF can only be exported, the PDF files are a printable version of the listings
+const FormatPDF = "pdf"
+
+// ContentTypes are the media types of the formats, they are also the values
+// of the Accept header that select a format
+var ContentTypes = map[string]string{
+	FormatCSV:  "text/csv",
+	FormatXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	FormatPDF:  "application/pdf",
+}
+
+// Table is a titled grid of text cells
+type Table struct {
+	Title   string
+	Columns []string
+	Rows    [][]string
+}
+
+// Tabulate builds a table from a slice of structs, the columns are the json
+// names of the fields. Fields tagged export:"-" are left out and the ones
+// tagged export:"credential" are only included when credentials is true.
+func Tabulate(title string, items any, credentials bool) Table {
+	table := Table{Title: title, Rows: [][]string{}}
+
+	v := reflect.ValueOf(items)
+	t := v.Type().Elem()
+
+	var fields []int
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+		export := f.Tag.Get("export")
+		if name == "" || name == "-" || export == "-" || (export == "credential" && !credentials) {
+			continue
+		}
+		fields = append(fields, i)
+		table.Columns = append(table.Columns, name)
+	}
+
+	for i := 0; i < v.Len(); i++ {
+		row := make([]string, 0, len(fields))
+		for _, f := range fields {
+			row = append(row, fmt.Sprint(v.Index(i).Field(f).Interface()))
+		}
+		table.Rows = append(table.Rows, row)
+	}
+
+	return table
+}
+
+// Write writes a table in one of the formats
+func Write(w io.Writer, format string, table Table) error {
+	switch format {
+	case FormatCSV:
+		return writeCSV(w, table)
+	case FormatXLSX:
+		return writeXLSX(w, table)
+	case FormatPDF:
+		return writePDF(w, table)
+	default:
+		return fmt.Errorf("unknown export format %q", format)
+	}
+}
+
+// writeCSV writes a table as CSV, the byte order mark lets Excel detect UTF-8
+func writeCSV(w io.Writer, table Table) error {
+	if _, err := io.WriteString(w, "\xef\xbb\xbf"); err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(table.Columns); err != nil {
+		return err
+	}
+	if err := writer.WriteAll(escapeFormulas(table.Rows)); err != nil {
+		return err
+	}
+
+	return writer.Error()
+}
+
+// writeXLSX writes a table in a single sheet with a bold header row
+func writeXLSX(w io.Writer, table Table) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	sheet := f.GetSheetName(0)
+	if table.Title != "" {
+		// sheet names are limited to 31 characters
+		title := []rune(table.Title)
+		if len(title) > 31 {
+			title = title[:31]
+		}
+		if err := f.SetSheetName(sheet, string(title)); err != nil {
+			return err
+		}
+		sheet = string(title)
+	}
+
+	// the cells are stored as text, a value starting with = is not a formula
+	// and is kept as it is
+	for i, row := range append([][]string{table.Columns}, table.Rows...) {
+		cells := make([]any, len(row))
+		for j, c := range row {
+			cells[j] = c
+		}
+		cell, err := excelize.CoordinatesToCellName(1, i+1)
+		if err != nil {
+			return err
+		}
+		if err := f.SetSheetRow(sheet, cell, &cells); err != nil {
+			return err
+		}
+	}
+
+	if len(table.Columns) > 0 {
+		bold, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+		if err != nil {
+			return err
+		}
+		last, err := excelize.CoordinatesToCellName(len(table.Columns), 1)
+		if err != nil {
+			return err
+		}
+		if err := f.SetCellStyle(sheet, "A1", last, bold); err != nil {
+			return err
+		}
+	}
+
+	return f.Write(w)
+}
+
+// escapeFormulas prefixes with a quote the cells a spreadsheet would run as a
+// formula when it opens a CSV file, like a client name starting with =
+func escapeFormulas(rows [][]string) [][]string {
+	escaped := make([][]string, len(rows))
+	for i, row := range rows {
+		escaped[i] = make([]string, len(row))
+		for j, c := range row {
+			if c != "" && strings.ContainsRune("=+-@\t\r", rune(c[0])) {
+				c = "'" + c
+			}
+			escaped[i][j] = c
+		}
+	}
+
+	return escaped
+}
+
+// PDF page layout in millimeters
+const (
+	pdfMargin     = 10.0
+	pdfRowHeight  = 5.0
+	pdfFontSize   = 7.0
+	pdfCellMargin = 1.0
+)
+
+// writePDF writes a table in landscape pages repeating the header row, the
+// columns share the width of the page by the length of their content
+func writePDF(w io.Writer, table Table) error {
+	pdf := fpdf.New("L", "mm", "A4", "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(false, pdfMargin)
+
+	// the core fonts use cp1252, it covers the accents of the Spanish names
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pageWidth, pageHeight := pdf.GetPageSize()
+	widths := pdfColumnWidths(pdf, table, pageWidth-2*pdfMargin)
+
+	header := func() {
+		pdf.SetFont("Helvetica", "B", pdfFontSize)
+		pdf.SetFillColor(230, 230, 230)
+		for i, c := range table.Columns {
+			pdf.CellFormat(widths[i], pdfRowHeight, fitText(pdf, tr(c), widths[i]), "1", 0, "L", true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Helvetica", "", pdfFontSize)
+	}
+
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.CellFormat(0, 8, tr(table.Title), "", 1, "L", false, 0, "")
+	header()
+
+	for _, row := range table.Rows {
+		if pdf.GetY()+pdfRowHeight > pageHeight-pdfMargin {
+			pdf.AddPage()
+			header()
+		}
+		for i, c := range row {
+			pdf.CellFormat(widths[i], pdfRowHeight, fitText(pdf, tr(c), widths[i]), "1", 0, "L", false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+
+	return pdf.Output(w)
+}
+
+// pdfColumnWidths gives every column the width of its widest text, the extra
+// space is shared and the widest columns are cut when the page is too narrow
+func pdfColumnWidths(pdf *fpdf.Fpdf, table Table, available float64) []float64 {
+	widths := make([]float64, len(table.Columns))
+	total := 0.0
+	for i, c := range table.Columns {
+		// the header row is bold
+		pdf.SetFont("Helvetica", "B", pdfFontSize)
+		widths[i] = pdf.GetStringWidth(c)
+
+		pdf.SetFont("Helvetica", "", pdfFontSize)
+		for _, row := range table.Rows {
+			if i < len(row) {
+				widths[i] = max(widths[i], pdf.GetStringWidth(row[i]))
+			}
+		}
+		widths[i] += 2 * pdfCellMargin
+		total += widths[i]
+	}
+	if total == 0 {
+		return widths
+	}
+
+	if total <= available {
+		for i := range widths {
+			widths[i] = widths[i] * available / total
+		}
+		return widths
+	}
+
+	// caps the widest columns so the short ones like the RFC keep their whole text
+	sorted := slices.Sorted(slices.Values(widths))
+	remaining := available
+	limit := 0.0
+	for i, w := range sorted {
+		n := float64(len(sorted) - i)
+		if w*n >= remaining {
+			limit = remaining / n
+			break
+		}
+		remaining -= w
+	}
+	for i := range widths {
+		widths[i] = min(widths[i], limit)
+	}
+
+	return widths
+}
+
+// fitText cuts a text that does not fit in a cell adding an ellipsis
+func fitText(pdf *fpdf.Fpdf, text string, width float64) string {
+	width -= 2 * pdfCellMargin
+	if pdf.GetStringWidth(text) <= width {
+		return text
+	}
+
+	for len(text) > 0 && pdf.GetStringWidth(text+"...") > width {
+		text = text[:len(text)-1]
+	}
+
+	return text + "..."
+}
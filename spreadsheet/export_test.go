@@ -0,0 +1,41 @@
+package spreadsheet
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestWriteEscapesFormulasOnlyInCSV(t *testing.T) {
+	table := Table{
+		Title:   "Clientes",
+		Columns: []string{"name", "monthly_fee"},
+		Rows:    [][]string{{"=HYPERLINK(\"http://example.com\")", "-1500"}, {"Abarrotes", "1500.00"}},
+	}
+
+	var csv bytes.Buffer
+	if err := Write(&csv, FormatCSV, table); err != nil {
+		t.Fatalf("writing the csv: %v", err)
+	}
+	want := "\xef\xbb\xbfname,monthly_fee\n\"'=HYPERLINK(\"\"http://example.com\"\")\",'-1500\nAbarrotes,1500.00\n"
+	if csv.String() != want {
+		t.Errorf("csv %q, want %q", csv.String(), want)
+	}
+
+	var xlsx bytes.Buffer
+	if err := Write(&xlsx, FormatXLSX, table); err != nil {
+		t.Fatalf("writing the xlsx: %v", err)
+	}
+	rows, err := Read("clientes.xlsx", &xlsx, 10)
+	if err != nil {
+		t.Fatalf("reading the xlsx: %v", err)
+	}
+	if !slices.EqualFunc(rows, append([][]string{table.Columns}, table.Rows...), slices.Equal) {
+		t.Errorf("xlsx rows %q, want the cells unchanged", rows)
+	}
+
+	if table.Rows[0][0] != "=HYPERLINK(\"http://example.com\")" || strings.HasPrefix(table.Rows[0][1], "'") {
+		t.Errorf("writing changed the rows of the table: %q", table.Rows)
+	}
+}
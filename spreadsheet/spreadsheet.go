@@ -0,0 +1,143 @@
+// Package spreadsheet reads and writes the CSV, XLSX and PDF files used to move
+// clients in and out of the API. The formats are handled as rows of text cells.
+package spreadsheet
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Supported formats, named after the extension of their files
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// ErrFormat is returned for files that are not CSV or XLSX
+var ErrFormat = errors.New("the file must be a .csv or .xlsx spreadsheet")
+
+// FormatOf returns the format of a file by its extension
+func FormatOf(filename string) (string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".xlsx":
+		return FormatXLSX, nil
+	default:
+		return "", ErrFormat
+	}
+}
+
+// xlsxUnzipLimit limits the uncompressed size of the parts of an XLSX file
+const xlsxUnzipLimit = 256 << 20
+
+// Read returns the rows of a CSV file or of the first sheet of an XLSX file,
+// the format is chosen by the extension of the file name. The reading stops
+// once maxRows rows with some cell are read, the blank rows are kept so the
+// rows keep their numbers but do not count.
+func Read(filename string, r io.Reader, maxRows int) ([][]string, error) {
+	format, err := FormatOf(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == FormatXLSX {
+		return readXLSX(r, maxRows)
+	}
+
+	return readCSV(r, maxRows)
+}
+
+// isBlank reports whether a row has no cell with text
+func isBlank(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// readCSV reads a CSV file, the spreadsheets exported in Spanish locales use
+// semicolons so the separator is taken from the header line
+func readCSV(r io.Reader, maxRows int) ([][]string, error) {
+	br := bufio.NewReader(r)
+
+	// Excel prefixes the UTF-8 files it saves with a byte order mark
+	if bom, err := br.Peek(3); err == nil && bytes.Equal(bom, []byte("\xef\xbb\xbf")) {
+		br.Discard(3)
+	}
+
+	reader := csv.NewReader(br)
+	reader.FieldsPerRecord = -1
+
+	// peeking the mark filled the buffer with the start of the file
+	header, _ := br.Peek(br.Buffered())
+	line, _, _ := bytes.Cut(header, []byte("\n"))
+	if bytes.Count(line, []byte(";")) > bytes.Count(line, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	var rows [][]string
+	for filled := 0; filled < maxRows; {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading csv: %w", err)
+		}
+		rows = append(rows, row)
+		if !isBlank(row) {
+			filled++
+		}
+	}
+
+	return rows, nil
+}
+
+// readXLSX reads the first sheet of an XLSX file row by row
+func readXLSX(r io.Reader, maxRows int) ([][]string, error) {
+	f, err := excelize.OpenReader(r, excelize.Options{UnzipSizeLimit: xlsxUnzipLimit})
+	if err != nil {
+		return nil, fmt.Errorf("reading xlsx: %w", err)
+	}
+	defer f.Close()
+
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, errors.New("reading xlsx: the file has no sheets")
+	}
+
+	iter, err := f.Rows(sheets[0])
+	if err != nil {
+		return nil, fmt.Errorf("reading xlsx: %w", err)
+	}
+	defer iter.Close()
+
+	var rows [][]string
+	for filled := 0; filled < maxRows && iter.Next(); {
+		row, err := iter.Columns()
+		if err != nil {
+			return nil, fmt.Errorf("reading xlsx: %w", err)
+		}
+		rows = append(rows, row)
+		if !isBlank(row) {
+			filled++
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return nil, fmt.Errorf("reading xlsx: %w", err)
+	}
+
+	return rows, nil
+}
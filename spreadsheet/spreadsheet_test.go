@@ -0,0 +1,85 @@
+package spreadsheet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestReadCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		maxRows int
+		want    [][]string
+	}{
+		{
+			name:    "comma separated",
+			file:    "nombre,rfc\nAbarrotes,TOPA850203KJA\n",
+			maxRows: 10,
+			want:    [][]string{{"nombre", "rfc"}, {"Abarrotes", "TOPA850203KJA"}},
+		},
+		{
+			name:    "semicolon separated with a byte order mark",
+			file:    "\xef\xbb\xbfnombre;rfc;honorario\nAbarrotes, S.A.;TOPA850203KJA;1234,50\n",
+			maxRows: 10,
+			want:    [][]string{{"nombre", "rfc", "honorario"}, {"Abarrotes, S.A.", "TOPA850203KJA", "1234,50"}},
+		},
+		{
+			name:    "the reading stops at the limit",
+			file:    "nombre\nuno\ndos\ntres\n",
+			maxRows: 2,
+			want:    [][]string{{"nombre"}, {"uno"}},
+		},
+		{
+			name:    "the blank rows do not count",
+			file:    "nombre\n,\nuno\n ,\ndos\ntres\n",
+			maxRows: 3,
+			want:    [][]string{{"nombre"}, {"", ""}, {"uno"}, {" ", ""}, {"dos"}},
+		},
+	}
+
+	for _, tt := range tests {
+		rows, err := Read("clientes.CSV", strings.NewReader(tt.file), tt.maxRows)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !slices.EqualFunc(rows, tt.want, slices.Equal) {
+			t.Errorf("%s: rows %q, want %q", tt.name, rows, tt.want)
+		}
+	}
+}
+
+func TestReadXLSX(t *testing.T) {
+	table := Table{Columns: []string{"nombre", "rfc"}}
+	for i := 1; i <= 5; i++ {
+		table.Rows = append(table.Rows, []string{fmt.Sprintf("Cliente %d", i), "TOPA850203KJA"})
+	}
+	var file bytes.Buffer
+	if err := Write(&file, FormatXLSX, table); err != nil {
+		t.Fatalf("writing the xlsx: %v", err)
+	}
+
+	rows, err := Read("clientes.xlsx", bytes.NewReader(file.Bytes()), 3)
+	if err != nil {
+		t.Fatalf("reading the xlsx: %v", err)
+	}
+	want := [][]string{{"nombre", "rfc"}, {"Cliente 1", "TOPA850203KJA"}, {"Cliente 2", "TOPA850203KJA"}}
+	if !slices.EqualFunc(rows, want, slices.Equal) {
+		t.Errorf("rows %q, want %q", rows, want)
+	}
+
+	if _, err := Read("clientes.xlsx", strings.NewReader("nombre,rfc\n"), 3); err == nil {
+		t.Error("a csv named .xlsx was read")
+	}
+}
+
+func TestReadFormat(t *testing.T) {
+	if _, err := Read("clientes.ods", strings.NewReader(""), 10); !errors.Is(err, ErrFormat) {
+		t.Errorf("reading a .ods file the error is %v, want %v", err, ErrFormat)
+	}
+}
@@ -0,0 +1,32 @@
+// Package storage keeps the files uploaded to the API, like the documents of
+// the clients, in a local directory or in an S3 compatible bucket
+package storage
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+)
+
+// ErrNotFound is returned when no file is stored under a key
+var ErrNotFound = errors.New("the file does not exist")
+
+// ErrInvalidKey is returned for keys that are not clean slash separated paths
+var ErrInvalidKey = errors.New("invalid storage key")
+
+// Storage saves and reads files by key, the keys are slash separated paths
+// like clients/<id>/documents/<name>
+type Storage interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// checkKey rejects the keys that could escape the directory or the bucket
+func checkKey(key string) error {
+	if !fs.ValidPath(key) || key == "." {
+		return ErrInvalidKey
+	}
+
+	return nil
+}
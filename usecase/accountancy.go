@@ -1,16 +1,26 @@
 package usecase
 
-import "contabi-be/models"
+import (
+	"fmt"
+	"strings"
+
+	"contabi-be/models"
+)
 
 // AccountancyInteractor implements the MenusUseCase interface
 type AccountancyInteractor struct {
 	accountancyService AccountancyService
+	clientsService     ClientsService
+	menusService       MenusService
 }
 
-// NewAccountancyUseCase creates a new instance of AccountancyService
-func NewAccountancyUseCase(accountancyService AccountancyService) AccountancyService {
+// NewAccountancyUseCase creates a new instance of AccountancyService, the
+// menus name the accountancy types and statuses in the timeline of the clients
+func NewAccountancyUseCase(accountancyService AccountancyService, clientsService ClientsService, menusService MenusService) AccountancyUseCase {
 	return &AccountancyInteractor{
 		accountancyService: accountancyService,
+		clientsService:     clientsService,
+		menusService:       menusService,
 	}
 }
 
@@ -24,9 +34,15 @@ func (ai *AccountancyInteractor) GetClientAssignmentsMatrix() ([]models.ClientAs
 	return ai.accountancyService.GetClientAssignmentsMatrix()
 }
 
-// UpdateClientAssignments updates assignments of a client according to the state
-func (ai *AccountancyInteractor) UpdateClientAssignments(clientID string, assignments []models.AssignmentSelection) error {
-	return ai.accountancyService.UpdateClientAssignments(clientID, assignments)
+// UpdateClientAssignments updates assignments of a client according to the
+// state, the service records the added and removed ones in the timeline
+func (ai *AccountancyInteractor) UpdateClientAssignments(clientID string, assignments []models.AssignmentSelection, ifMatch string) error {
+	version, err := clientVersionOf(ai.clientsService, clientID, ifMatch)
+	if err != nil {
+		return err
+	}
+
+	return ai.accountancyService.UpdateClientAssignments(clientID, assignments, version)
 }
 
 // GetClientsBySResonsible retrieves all the clients of a specific responsible
@@ -36,25 +52,115 @@ func (ai *AccountancyInteractor) GetClientsByResonsible(responsibleID string) ([
 
 // CreateClientAccountancyStatusWithAssignments creates a new monthly record for a client
 func (ai *AccountancyInteractor) CreateClientAccountancyStatusWithAssignments(status models.ClientAccountancyStatus, assignments []models.ClientAccountancyAssignment) error {
-	return ai.accountancyService.CreateClientAccountancyStatusWithAssignments(status, assignments)
+	if err := checkServicedMonth(ai.clientsService, status.ClientID, status.Month); err != nil {
+		return err
+	}
+
+	event, err := ai.statusEvent(status.ClientID, "registered", status, assignments)
+	if err != nil {
+		return err
+	}
+
+	return ai.accountancyService.CreateClientAccountancyStatusWithAssignments(status, assignments, event)
 }
 
-// GetClientAccountancyHistory gets the hisotory for a client accountancy behavior
+// GetClientAccountancyHistory gets the hisotory for a client accountancy
+// behavior with the assignments the client had at the end of each month
 func (ai *AccountancyInteractor) GetClientAccountancyHistory(clientID string) (models.ClientAccountancyHistoryWithAssignments, error) {
-	return ai.accountancyService.GetClientAccountancyHistory(clientID)
+	history, err := ai.accountancyService.GetClientAccountancyHistory(clientID)
+	if err != nil {
+		return history, err
+	}
+
+	periods, err := ai.clientsService.GetAssignmentHistory(clientID)
+	if err != nil {
+		return history, err
+	}
+	for i, entry := range history.History {
+		history.History[i].AssignedTo = periodOn(periods, lastDayOf(entry.Status.Month))
+	}
+
+	return history, nil
 }
 
-// UpdateClientAccountancyStatusWithAssignments updates an existing monthly record for a client
-func (ai *AccountancyInteractor) UpdateClientAccountancyStatusWithAssignments(statusID int, clientID string, status models.ClientAccountancyStatus, assignments []models.ClientAccountancyAssignment) error {
-	return ai.accountancyService.UpdateClientAccountancyStatusWithAssignments(statusID, clientID, status, assignments)
+// GetClientAccountancyStatus gets a monthly record of the accountancy history
+// of a client
+func (ai *AccountancyInteractor) GetClientAccountancyStatus(clientID string, statusID int) (models.ClientAccountancyHistoryEntry, error) {
+	history, err := ai.GetClientAccountancyHistory(clientID)
+	if err != nil {
+		return models.ClientAccountancyHistoryEntry{}, err
+	}
+
+	for _, entry := range history.History {
+		if entry.Status.ID == statusID {
+			return entry, nil
+		}
+	}
+
+	return models.ClientAccountancyHistoryEntry{}, models.NewNotFoundError("accountancy status not found")
 }
 
-// GetAllClients retrieves all the clients
-func (ai *AccountancyInteractor) GetAllClients() ([]models.AccountancyClientInfo, error) {
-	return ai.accountancyService.GetAllClients()
+// UpdateClientAccountancyStatusWithAssignments updates an existing monthly
+// record for a client, the If-Match has the ETag of the record
+func (ai *AccountancyInteractor) UpdateClientAccountancyStatusWithAssignments(statusID int, clientID string, status models.ClientAccountancyStatus, assignments []models.ClientAccountancyAssignment, ifMatch string) error {
+	version, err := ai.statusVersionOf(clientID, statusID, ifMatch)
+	if err != nil {
+		return err
+	}
+
+	event, err := ai.statusEvent(clientID, "updated", status, assignments)
+	if err != nil {
+		return err
+	}
+
+	return ai.accountancyService.UpdateClientAccountancyStatusWithAssignments(statusID, clientID, status, assignments, version, event)
 }
 
-// UpdateClientResponsible updates the responsible of a client
-func (ai *AccountancyInteractor) UpdateClientResponsible(clientID string, responsibleID string) error {
-	return ai.accountancyService.UpdateClientResponsible(clientID, responsibleID)
+// statusEvent is the event of a monthly accountancy record of a client with
+// the status of each of its assignments
+func (ai *AccountancyInteractor) statusEvent(clientID, action string, status models.ClientAccountancyStatus, assignments []models.ClientAccountancyAssignment) (models.ClientEvent, error) {
+	types, err := ai.menusService.GetAccountancyTypes()
+	if err != nil {
+		return models.ClientEvent{}, err
+	}
+	statuses, err := ai.menusService.GetAccountancyStatuses()
+	if err != nil {
+		return models.ClientEvent{}, err
+	}
+
+	typeNames := map[string]string{}
+	for _, t := range types {
+		typeNames[t.ID] = t.Name
+	}
+	statusNames := map[string]string{}
+	for _, s := range statuses {
+		statusNames[s.ID] = s.Name
+	}
+
+	message := fmt.Sprintf("Accountancy of %s %s", monthOf(status.Month), action)
+	var parts []string
+	for _, a := range assignments {
+		parts = append(parts, fmt.Sprintf("%s %s", typeNames[fmt.Sprint(a.AssignmentTypeID)], statusNames[fmt.Sprint(a.AssignmentStatusID)]))
+	}
+	if len(parts) > 0 {
+		message += ": " + strings.Join(parts, ", ")
+	}
+
+	return models.ClientEvent{ClientID: clientID, Type: models.EventAccountancyStatus, Message: message}, nil
+}
+
+// GetAllClients retrieves a page of the active clients
+func (ai *AccountancyInteractor) GetAllClients(params models.ClientListParams) (models.AccountancyClientInfoPage, error) {
+	return ai.accountancyService.GetAllClients(normalizeListParams(params))
+}
+
+// UpdateClientResponsible updates the responsible of a client, the service
+// records the change in the timeline
+func (ai *AccountancyInteractor) UpdateClientResponsible(clientID string, responsibleID string, ifMatch string) error {
+	version, err := clientVersionOf(ai.clientsService, clientID, ifMatch)
+	if err != nil {
+		return err
+	}
+
+	return ai.accountancyService.UpdateClientResponsible(clientID, responsibleID, version)
 }
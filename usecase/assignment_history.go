@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"time"
+
+	"contabi-be/models"
+)
+
+// GetAssignmentHistory retrieves the periods of the supervisor, responsible
+// and emisor of a client, the newest first, or only the one in effect on the
+// day of the params
+func (ci *ClientsInteractor) GetAssignmentHistory(clientID string, params models.AssignmentHistoryParams) ([]models.AssignmentPeriod, error) {
+	if _, err := ci.clientsService.GetClientSummary(clientID); err != nil {
+		return nil, err
+	}
+
+	periods, err := ci.clientsService.GetAssignmentHistory(clientID)
+	if err != nil || params.AsOf == "" {
+		return periods, err
+	}
+
+	inEffect := []models.AssignmentPeriod{}
+	if p := periodOn(periods, params.AsOf); p != nil {
+		inEffect = append(inEffect, *p)
+	}
+
+	return inEffect, nil
+}
+
+// GetAssignmentsAsOf retrieves the assignments every client had on a day
+func (ci *ClientsInteractor) GetAssignmentsAsOf(params models.AssignmentsAsOfParams) ([]models.AssignmentPeriod, error) {
+	return ci.clientsService.GetAssignmentsAsOf(params)
+}
+
+// periodOn returns the assignment period in effect on a day, nil when the
+// client had no assignments then
+func periodOn(periods []models.AssignmentPeriod, day string) *models.AssignmentPeriod {
+	for i := range periods {
+		if periods[i].Covers(day) {
+			return &periods[i]
+		}
+	}
+
+	return nil
+}
+
+// lastDayOf returns the last day of a month sent as YYYY-MM or YYYY-MM-DD,
+// empty when it is not a month
+func lastDayOf(month string) string {
+	start, err := time.Parse("2006-01", monthOf(month))
+	if err != nil {
+		return ""
+	}
+
+	return start.AddDate(0, 1, -1).Format(time.DateOnly)
+}
@@ -1,72 +1,492 @@
 package usecase
 
 import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
 	"contabi-be/models"
+	"contabi-be/sat"
+	"contabi-be/search"
+	"contabi-be/secret"
 )
 
-// ClientsInteractor implements the ClientsService interface
+// ClientsInteractor implements the ClientsUseCase interface
 type ClientsInteractor struct {
-	clientsService ClientsService
+	clientsService       ClientsService
+	menusService         MenusService
+	notificationsService NotificationsService
+	timelineService      TimelineService
+	documentsService     DocumentsService
+	accountancyService   AccountancyService
+	box                  *secret.Box
 }
 
-// NewClientsUseCase creates a new instance of ClientsService
-func NewClientsUseCase(clientsService ClientsService) ClientsService {
+// NewClientsUseCase creates a new instance of ClientsUseCase, the box encrypts
+// the uploaded certificate files and is nil when they are not kept. The
+// documents are listed to hand over when a client leaves and its accountancy
+// months tell since when it owes.
+func NewClientsUseCase(clientsService ClientsService, menusService MenusService, notificationsService NotificationsService, timelineService TimelineService, documentsService DocumentsService, accountancyService AccountancyService, box *secret.Box) ClientsUseCase {
 	return &ClientsInteractor{
-		clientsService: clientsService,
+		clientsService:       clientsService,
+		menusService:         menusService,
+		notificationsService: notificationsService,
+		timelineService:      timelineService,
+		documentsService:     documentsService,
+		accountancyService:   accountancyService,
+		box:                  box,
 	}
 }
 
-// GetAllClientsInfo retrieves all clients with complete information
-func (ci *ClientsInteractor) GetAllClientsInfo() ([]models.ClientInfo, error) {
-	return ci.clientsService.GetAllClientsInfo()
+// GetAllClientsInfo retrieves a page of the clients with complete information
+func (ci *ClientsInteractor) GetAllClientsInfo(params models.ClientListParams) (models.ClientInfoPage, error) {
+	return ci.clientsService.GetAllClientsInfo(normalizeListParams(params))
+}
+
+// GetActiveClientsInfo retrieves a page of the active clients with complete information
+func (ci *ClientsInteractor) GetActiveClientsInfo(params models.ClientListParams) (models.ClientInfoPage, error) {
+	return ci.clientsService.GetActiveClientsInfo(normalizeListParams(params))
 }
 
-// GetActiveClientsInfo retrieves only active clients with complete information
-func (ci *ClientsInteractor) GetActiveClientsInfo() ([]models.ClientInfo, error) {
-	return ci.clientsService.GetActiveClientsInfo()
+// SearchClients finds the clients whose name or RFC resemble the query and highlights the matches
+func (ci *ClientsInteractor) SearchClients(params models.ClientSearchParams) ([]models.ClientSearchResult, error) {
+	params.Q = strings.TrimSpace(params.Q)
+	if params.Limit < 1 || params.Limit > models.MaxSearchLimit {
+		params.Limit = models.DefaultSearchLimit
+	}
+
+	results, err := ci.clientsService.SearchClients(params)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range results {
+		results[i].NameHighlight = search.Highlight(results[i].Name, params.Q)
+		results[i].RFCHighlight = search.Highlight(results[i].RFC, params.Q)
+	}
+
+	return results, nil
 }
 
 // GetClientInfo retrieves complete information for a specific client
 func (ci *ClientsInteractor) GetClientInfo(clientID string) (models.ClientInfo, error) {
-	return ci.clientsService.GetClientInfo(clientID)
+	info, err := ci.clientsService.GetClientInfo(clientID)
+	if err != nil {
+		return info, err
+	}
+
+	if info.Contacts, err = ci.clientsService.GetClientContacts(clientID); err != nil {
+		return info, err
+	}
+
+	info.CSDCertificates, err = ci.clientsService.GetCSDCertificates(clientID)
+	return info, err
 }
 
-// CreateClient creates a new client with assignments
+// CreateClient creates a new client with assignments, active unless it comes
+// as a prospect or in onboarding
 func (ci *ClientsInteractor) CreateClient(client models.Client, assignments models.ClientAssignments) error {
+	regimenes, err := ci.menusService.GetRegimenes()
+	if err != nil {
+		return err
+	}
+	if err := validateClientRFC(&client, regimenes); err != nil {
+		return err
+	}
+
+	if client.State == "" || models.IsActiveState(client.State) {
+		if err := ci.checkDuplicateRFC("", client.RFC); err != nil {
+			return err
+		}
+	}
+
 	return ci.clientsService.CreateClient(client, assignments)
 }
 
-// UpdateClient updates client basic information
-func (ci *ClientsInteractor) UpdateClient(clientID string, client models.Client) error {
-	return ci.clientsService.UpdateClient(clientID, client)
+// UpdateClient updates client basic information, a change of the active flag
+// terminates the client or makes it active again in the same write. A client
+// that left only changes when it is made active again.
+func (ci *ClientsInteractor) UpdateClient(clientID string, client models.Client, ifMatch string) error {
+	version, err := clientVersionOf(ci.clientsService, clientID, ifMatch)
+	if err != nil {
+		return err
+	}
+
+	return ci.updateClient(clientID, client, version, true)
+}
+
+// updateClient updates a client that is still in the version given. Its RFC
+// and regimen are checked when checkRFC is set, otherwise they are the ones
+// stored and only a client made active again checks its RFC is not shared.
+func (ci *ClientsInteractor) updateClient(clientID string, client models.Client, version models.ClientVersion, checkRFC bool) error {
+	if checkRFC {
+		regimenes, err := ci.menusService.GetRegimenes()
+		if err != nil {
+			return err
+		}
+		if err := validateClientRFC(&client, regimenes); err != nil {
+			return err
+		}
+	}
+
+	current, err := ci.clientsService.GetClientSummary(clientID)
+	if err != nil {
+		return err
+	}
+
+	active := current.Active
+	if client.Active != "" {
+		active = client.Active == "true"
+	}
+
+	if active {
+		if _, err := ci.checkNotMerged(clientID); err != nil {
+			return err
+		}
+		if checkRFC {
+			if err := ci.checkDuplicateRFC(clientID, client.RFC); err != nil {
+				return err
+			}
+		}
+	} else if err := checkNotOffboarded(ci.clientsService, clientID); err != nil {
+		return err
+	}
+
+	var change *models.ClientStateChange
+	if active != current.Active {
+		request := models.ClientStateRequest{State: models.ClientTerminated, Reason: "Client deactivated"}
+		if active {
+			request = models.ClientStateRequest{State: models.ClientActive, Reason: "Client activated"}
+		}
+		current.RFC = client.RFC
+		c, err := ci.stateChange(current, "", request)
+		if err != nil {
+			return err
+		}
+		change = &c
+	}
+
+	return ci.clientsService.UpdateClient(clientID, client, change, version)
+}
+
+// DeactivateClient terminates a client (soft delete), a terminated client is
+// left as it is
+func (ci *ClientsInteractor) DeactivateClient(clientID, ifMatch string) error {
+	version, err := clientVersionOf(ci.clientsService, clientID, ifMatch)
+	if err != nil {
+		return err
+	}
+
+	client, err := ci.clientsService.GetClientSummary(clientID)
+	if err != nil || client.State == models.ClientTerminated {
+		return err
+	}
+
+	_, err = ci.changeState(client, "", models.ClientStateRequest{State: models.ClientTerminated, Reason: "Client deactivated"}, version)
+
+	return err
 }
 
-// DeactivateClient sets a client as inactive (soft delete)
-func (ci *ClientsInteractor) DeactivateClient(clientID string) error {
-	return ci.clientsService.DeactivateClient(clientID)
+// ActivateClient makes a client active, an active client is left as it is
+func (ci *ClientsInteractor) ActivateClient(clientID, ifMatch string) error {
+	version, err := clientVersionOf(ci.clientsService, clientID, ifMatch)
+	if err != nil {
+		return err
+	}
+
+	client, err := ci.checkNotMerged(clientID)
+	if err != nil || client.State == models.ClientActive {
+		return err
+	}
+
+	_, err = ci.changeState(client, "", models.ClientStateRequest{State: models.ClientActive, Reason: "Client activated"}, version)
+
+	return err
 }
 
-// ActivateClient sets a client as active
-func (ci *ClientsInteractor) ActivateClient(clientID string) error {
-	return ci.clientsService.ActivateClient(clientID)
+// MergeClients moves the history of the source client of the request to the
+// target client and deactivates the source, the target survives the merge
+func (ci *ClientsInteractor) MergeClients(targetID string, request models.MergeClientsRequest) (models.ClientMergeResult, error) {
+	if request.Conflicts == "" {
+		request.Conflicts = models.MergeKeepTarget
+	}
+
+	if request.SourceID == targetID {
+		return models.ClientMergeResult{}, models.NewValidationError("A client can not be merged into itself", []models.FieldError{{
+			Field:   "source_id",
+			Rule:    "merge_self",
+			Message: "must be a different client than the target",
+		}})
+	}
+
+	if _, err := ci.checkNotMerged(targetID); err != nil {
+		return models.ClientMergeResult{}, err
+	}
+
+	source, err := ci.clientsService.GetClientSummary(request.SourceID)
+	if errors.Is(err, models.ErrNotFound) {
+		return models.ClientMergeResult{}, models.NewValidationError("The source client does not exist", []models.FieldError{{
+			Field:   "source_id",
+			Rule:    "client",
+			Message: "must be an existing client",
+		}})
+	}
+	if err != nil {
+		return models.ClientMergeResult{}, err
+	}
+	if source.MergedInto != "" {
+		return models.ClientMergeResult{}, models.NewConflictError("The source client was already merged", map[string]string{"merged_into": source.MergedInto})
+	}
+
+	return ci.clientsService.MergeClients(request.SourceID, targetID, request.Conflicts)
 }
 
-// UpdateClientAssignments updates client assignments (supervisor, responsible, emisor)
-func (ci *ClientsInteractor) UpdateClientAssignments(clientID string, assignments models.ClientAssignments) error {
-	return ci.clientsService.UpdateClientAssignments(clientID, assignments)
+// UpdateClientAssignments updates client assignments (supervisor, responsible,
+// emisor), the service records the ones that changed in the timeline
+func (ci *ClientsInteractor) UpdateClientAssignments(clientID string, assignments models.ClientAssignments, ifMatch string) error {
+	version, err := clientVersionOf(ci.clientsService, clientID, ifMatch)
+	if err != nil {
+		return err
+	}
+
+	return ci.clientsService.UpdateClientAssignments(clientID, assignments, version)
 }
 
 // GetClientsWithPendingPayments returns clients that have pending payments
+// with the fee in effect this month and the amount of the months due, only
+// the months a client was active are charged
 func (ci *ClientsInteractor) GetClientsWithPendingPayments() ([]models.ClientWithPendingPayment, error) {
-	return ci.clientsService.GetClientsWithPendingPayments()
+	pending, err := ci.clientsService.GetClientsWithPendingPayments()
+	if err != nil {
+		return nil, err
+	}
+
+	fees, err := ci.clientsService.GetAllClientFees()
+	if err != nil {
+		return nil, err
+	}
+	byClient := feesByClient(fees)
+
+	changes, err := ci.clientsService.GetAllClientStateChanges()
+	if err != nil {
+		return nil, err
+	}
+	states := statesByClient(changes)
+
+	now := time.Now()
+	clients := []models.ClientWithPendingPayment{}
+	for _, c := range pending {
+		if fee := feeOn(byClient[c.ID], now.Format("2006-01")); fee != "" {
+			c.MonthlyFee = fee
+		}
+		charged := func(month string) bool {
+			return models.IsChargedState(stateOn(states[c.ID], c.State, month))
+		}
+		if c.MonthsDue, c.AmountDue, err = amountDue(byClient[c.ID], c.MonthlyFee, c.LastPaymentMonth, now, charged); err != nil {
+			return nil, fmt.Errorf("the amount due of client %s: %w", c.ID, err)
+		}
+		if c.MonthsDue > 0 {
+			clients = append(clients, c)
+		}
+	}
+
+	return clients, nil
 }
 
-// UpdateClientPayment updates client payment information
-func (ci *ClientsInteractor) UpdateClientPayment(clientID string, payment models.ClientPayment) error {
-	return ci.clientsService.UpdateClientPayment(clientID, payment)
+// UpdateClientPayment updates client payment information, a client that left
+// only pays the months it was serviced
+func (ci *ClientsInteractor) UpdateClientPayment(clientID string, payment models.ClientPayment, ifMatch string) error {
+	version, err := clientVersionOf(ci.clientsService, clientID, ifMatch)
+	if err != nil {
+		return err
+	}
+
+	if err := checkServicedMonth(ci.clientsService, clientID, payment.LastPaymentMonth); err != nil {
+		return err
+	}
+
+	return ci.clientsService.UpdateClientPayment(clientID, payment, version, paymentEvent(clientID, payment))
 }
 
-// GetClientPayments gets the payments history of a specific client
+// GetClientPayments gets the payments history of a specific client with the
+// fee in effect on the month of each payment
 func (ci *ClientsInteractor) GetClientPayments(clientID string) ([]models.ClientPaymentHistory, error) {
-	return ci.clientsService.GetClientPayments(clientID)
+	payments, err := ci.clientsService.GetClientPayments(clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	fees, err := ci.clientsService.GetClientFees(clientID)
+	if err != nil {
+		return nil, err
+	}
+	for i, p := range payments {
+		if fee := feeOn(fees, monthOf(p.LastPaymentMonth)); fee != "" {
+			payments[i].MonthlyFee = fee
+		}
+	}
+
+	return payments, nil
+}
+
+// normalizeListParams fills the page and sorting defaults of the client listings
+func normalizeListParams(params models.ClientListParams) models.ClientListParams {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.PageSize < 1 {
+		params.PageSize = models.DefaultPageSize
+	}
+	if params.PageSize > models.MaxPageSize {
+		params.PageSize = models.MaxPageSize
+	}
+	if params.Sort == "" {
+		params.Sort = "name"
+	}
+	if params.Order == "" {
+		params.Order = "asc"
+	}
+
+	return params
+}
+
+// rfcRules describes the RFC errors as failed validation rules
+var rfcRules = map[error]models.FieldError{
+	sat.ErrRFCFormat:     {Field: "rfc", Rule: "rfc", Message: "must have 3 or 4 letters, a YYMMDD date, a 2 character homoclave and a check digit"},
+	sat.ErrRFCDate:       {Field: "rfc", Rule: "rfc_date", Message: "must contain a valid YYMMDD date"},
+	sat.ErrRFCCheckDigit: {Field: "rfc", Rule: "rfc_check_digit", Message: "has an invalid check digit"},
+}
+
+// validateClientRFC normalizes the RFC of a client, validates it and checks
+// its regimen is one of the SAT that accepts its persona type
+func validateClientRFC(client *models.Client, regimenes []models.Regimen) error {
+	client.RFC = sat.NormalizeRFC(client.RFC)
+
+	rfc, err := sat.ParseRFC(client.RFC)
+	if err != nil {
+		return models.NewValidationError("The client has an invalid RFC", []models.FieldError{rfcRules[err]})
+	}
+
+	code, ok := satRegimenCode(regimenes, client.RegimenID)
+	if !ok {
+		return models.NewValidationError("The regimen is not in the catalog of the SAT", []models.FieldError{{
+			Field:   "regimen_id",
+			Rule:    "regimen_sat",
+			Message: "must be a regimen of the catalog of the SAT",
+		}})
+	}
+	if !sat.RegimenAllows(code, rfc.Persona) {
+		return models.NewValidationError("The regimen does not apply to the RFC", []models.FieldError{{
+			Field:   "regimen_id",
+			Rule:    "regimen_persona",
+			Message: fmt.Sprintf("does not apply to personas %s", rfc.Persona),
+		}})
+	}
+
+	return nil
+}
+
+// satRegimenCode returns the code of the SAT of a regimen of the catalog
+func satRegimenCode(regimenes []models.Regimen, regimenID string) (string, bool) {
+	for _, r := range regimenes {
+		if r.ID == regimenID {
+			return sat.RegimenCode(r.ID, r.Name)
+		}
+	}
+
+	return sat.RegimenCode(regimenID, "")
+}
+
+// checkNotMerged returns the summary of a client, or a conflict when it was
+// merged into another client and can not be used anymore
+func (ci *ClientsInteractor) checkNotMerged(clientID string) (models.ClientSummary, error) {
+	client, err := ci.clientsService.GetClientSummary(clientID)
+	if err != nil {
+		return client, err
+	}
+
+	if client.MergedInto != "" {
+		return client, models.NewConflictError("The client was merged into another client", map[string]string{"merged_into": client.MergedInto})
+	}
+
+	return client, nil
+}
+
+// checkDuplicateRFC returns a conflict with the id of the active client that
+// already has the RFC, the client being updated is not a duplicate of itself.
+// The generic RFCs are shared by every client of the general public or abroad.
+func (ci *ClientsInteractor) checkDuplicateRFC(clientID, rfc string) error {
+	if sat.IsGenericRFC(sat.NormalizeRFC(rfc)) {
+		return nil
+	}
+
+	existing, err := ci.clientsService.GetActiveClientByRFC(rfc)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if existing.ID == clientID {
+		return nil
+	}
+
+	return models.NewConflictError("An active client already has the RFC", models.ClientConflict{
+		ExistingClientID: existing.ID,
+		RFC:              existing.RFC,
+	})
+}
+
+// GetDuplicateClients reports the clients that share an RFC or a normalized
+// name, name groups are only reported when their clients have different RFCs.
+// The clients with a generic RFC only share it with clients of the same kind.
+func (ci *ClientsInteractor) GetDuplicateClients() ([]models.DuplicateClientGroup, error) {
+	clients, err := ci.clientsService.GetClientSummaries()
+	if err != nil {
+		return nil, err
+	}
+
+	byRFC := map[string][]models.ClientSummary{}
+	byName := map[string][]models.ClientSummary{}
+	for _, c := range clients {
+		if rfc := sat.NormalizeRFC(c.RFC); !sat.IsGenericRFC(rfc) {
+			byRFC[rfc] = append(byRFC[rfc], c)
+		}
+		if key := search.NameKey(c.Name); key != "" {
+			byName[key] = append(byName[key], c)
+		}
+	}
+
+	groups := []models.DuplicateClientGroup{}
+	for _, key := range sortedKeys(byRFC) {
+		if len(byRFC[key]) > 1 {
+			groups = append(groups, models.DuplicateClientGroup{Reason: models.DuplicateReasonRFC, Key: key, Clients: byRFC[key]})
+		}
+	}
+
+	for _, key := range sortedKeys(byName) {
+		rfcs := map[string]bool{}
+		for _, c := range byName[key] {
+			rfcs[sat.NormalizeRFC(c.RFC)] = true
+		}
+		if len(rfcs) > 1 {
+			groups = append(groups, models.DuplicateClientGroup{Reason: models.DuplicateReasonName, Key: key, Clients: byName[key]})
+		}
+	}
+
+	return groups, nil
+}
+
+// sortedKeys returns the keys of a map in order so the reports are stable
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
 }
@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"regexp"
+	"strings"
+
+	"contabi-be/models"
+)
+
+// phonePattern is a phone with its country code optionally, once the spaces,
+// dashes, dots and parentheses are removed
+var phonePattern = regexp.MustCompile(`^\+?[0-9]{10,15}$`)
+
+// GetClientContacts retrieves the contacts of a client
+func (ci *ClientsInteractor) GetClientContacts(clientID string) ([]models.ClientContact, error) {
+	if _, err := ci.clientsService.GetClientSummary(clientID); err != nil {
+		return nil, err
+	}
+
+	return ci.clientsService.GetClientContacts(clientID)
+}
+
+// CreateClientContact adds a contact to a client that was not merged and did
+// not leave
+func (ci *ClientsInteractor) CreateClientContact(clientID string, request models.ClientContactRequest) (models.ClientContact, error) {
+	contact, err := newClientContact(clientID, request)
+	if err != nil {
+		return contact, err
+	}
+
+	if _, err := ci.checkNotMerged(clientID); err != nil {
+		return contact, err
+	}
+	if err := checkNotOffboarded(ci.clientsService, clientID); err != nil {
+		return contact, err
+	}
+
+	return ci.clientsService.CreateClientContact(contact)
+}
+
+// UpdateClientContact replaces the data of a contact of a client
+func (ci *ClientsInteractor) UpdateClientContact(clientID, contactID string, request models.ClientContactRequest, ifMatch string) (models.ClientContact, error) {
+	version, err := clientVersionOf(ci.clientsService, clientID, ifMatch)
+	if err != nil {
+		return models.ClientContact{}, err
+	}
+
+	contact, err := newClientContact(clientID, request)
+	if err != nil {
+		return contact, err
+	}
+	contact.ID = contactID
+
+	if err := checkNotOffboarded(ci.clientsService, clientID); err != nil {
+		return contact, err
+	}
+
+	return ci.clientsService.UpdateClientContact(contact, version)
+}
+
+// DeleteClientContact removes a contact of a client that did not leave
+func (ci *ClientsInteractor) DeleteClientContact(clientID, contactID string) error {
+	if err := checkNotOffboarded(ci.clientsService, clientID); err != nil {
+		return err
+	}
+
+	return ci.clientsService.DeleteClientContact(clientID, contactID)
+}
+
+// newClientContact normalizes the fields of a contact request and checks the
+// preferred channel has the email or the phone it uses, the channel defaults
+// to the email when there is one and to the phone otherwise
+func newClientContact(clientID string, request models.ClientContactRequest) (models.ClientContact, error) {
+	contact := models.ClientContact{
+		ClientID:         clientID,
+		Name:             strings.TrimSpace(request.Name),
+		Role:             strings.TrimSpace(request.Role),
+		Email:            strings.ToLower(strings.TrimSpace(request.Email)),
+		Phone:            strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "").Replace(request.Phone),
+		PreferredChannel: request.PreferredChannel,
+		IsBilling:        request.IsBilling,
+	}
+
+	var details []models.FieldError
+	if contact.Name == "" {
+		details = append(details, models.FieldError{Field: "name", Rule: "required", Message: "is required"})
+	}
+	if contact.Phone != "" && !phonePattern.MatchString(contact.Phone) {
+		details = append(details, models.FieldError{Field: "phone", Rule: "phone", Message: "must have 10 to 15 digits and may start with +"})
+	}
+
+	if contact.PreferredChannel == "" {
+		contact.PreferredChannel = models.ContactChannelPhone
+		if contact.Email != "" {
+			contact.PreferredChannel = models.ContactChannelEmail
+		}
+	}
+	if contact.Address() == "" {
+		field := "phone"
+		if contact.PreferredChannel == models.ContactChannelEmail {
+			field = "email"
+		}
+		details = append(details, models.FieldError{Field: field, Rule: "required", Message: "is required to reach the contact by " + contact.PreferredChannel})
+	}
+
+	if len(details) > 0 {
+		return contact, models.NewValidationError("The contact has invalid fields", details)
+	}
+
+	return contact, nil
+}
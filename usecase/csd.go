@@ -0,0 +1,195 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"contabi-be/models"
+	"contabi-be/sat"
+)
+
+// ErrNoEncryptionKey is returned when a CSD is uploaded and no encryption key
+// is configured, its private key is never stored in clear
+var ErrNoEncryptionKey = models.NewConflictError("The CSD can not be stored without an encryption key, set ENCRYPTION_KEY in the server", nil)
+
+// UploadCSDCertificate checks the .cer and .key files of a CSD of a client and
+// stores them, the certificate must belong to the RFC of the client and be
+// valid and the password must open the key of the certificate
+func (ci *ClientsInteractor) UploadCSDCertificate(clientID string, certificate, key []byte, password string) (models.CSDCertificate, error) {
+	if ci.box == nil {
+		return models.CSDCertificate{}, ErrNoEncryptionKey
+	}
+
+	client, err := ci.checkNotMerged(clientID)
+	if err != nil {
+		return models.CSDCertificate{}, err
+	}
+	if err := checkNotOffboarded(ci.clientsService, clientID); err != nil {
+		return models.CSDCertificate{}, err
+	}
+
+	cert, err := sat.ParseCertificate(certificate)
+	if err != nil {
+		return models.CSDCertificate{}, models.NewValidationError("The file is not a valid CSD certificate", []models.FieldError{{
+			Field:   "certificate",
+			Rule:    "certificate",
+			Message: "must be a .cer certificate issued by the SAT",
+		}})
+	}
+	if cert.Type == sat.CertificateFiel {
+		return models.CSDCertificate{}, models.NewValidationError("The certificate is a FIEL", []models.FieldError{{
+			Field:   "certificate",
+			Rule:    "csd",
+			Message: "must be a CSD, the FIEL is uploaded to /clients/:id/fiel",
+		}})
+	}
+	if err := checkCertificate(cert, client, "certificate"); err != nil {
+		return models.CSDCertificate{}, err
+	}
+
+	signer, err := sat.ParsePrivateKey(key, password)
+	switch {
+	case errors.Is(err, sat.ErrKeyPassword):
+		return models.CSDCertificate{}, models.NewValidationError("The password does not open the private key", []models.FieldError{{
+			Field:   "password",
+			Rule:    "password",
+			Message: "does not open the private key",
+		}})
+	case err != nil:
+		return models.CSDCertificate{}, models.NewValidationError("The file is not a valid private key", []models.FieldError{{
+			Field:   "key",
+			Rule:    "key",
+			Message: "must be the .key file issued by the SAT with the certificate",
+		}})
+	case !cert.MatchesKey(signer):
+		return models.CSDCertificate{}, models.NewValidationError("The private key belongs to another certificate", []models.FieldError{{
+			Field:   "key",
+			Rule:    "key_mismatch",
+			Message: "does not belong to the certificate",
+		}})
+	}
+
+	files := models.CSDFiles{Certificate: certificate}
+	if files.Key, err = ci.box.Seal(key); err != nil {
+		return models.CSDCertificate{}, err
+	}
+	if files.Password, err = ci.box.Seal([]byte(password)); err != nil {
+		return models.CSDCertificate{}, err
+	}
+
+	return ci.clientsService.SaveCSDCertificate(models.CSDCertificate{
+		ClientID:     clientID,
+		SerialNumber: cert.SerialNumber,
+		RFC:          cert.RFC,
+		Name:         cert.Name,
+		Branch:       cert.Branch,
+		ValidFrom:    cert.ValidFrom.Format(time.RFC3339),
+		ValidTo:      cert.ValidTo.Format(time.RFC3339),
+	}, files)
+}
+
+// GetCSDCertificates retrieves the CSD of a client
+func (ci *ClientsInteractor) GetCSDCertificates(clientID string) ([]models.CSDCertificate, error) {
+	if _, err := ci.clientsService.GetClientSummary(clientID); err != nil {
+		return nil, err
+	}
+
+	return ci.clientsService.GetCSDCertificates(clientID)
+}
+
+// DeleteCSDCertificate removes a CSD of a client with its files
+func (ci *ClientsInteractor) DeleteCSDCertificate(clientID, csdID string) error {
+	return ci.clientsService.DeleteCSDCertificate(clientID, csdID)
+}
+
+// GetExpiringCSD retrieves the CSD of the active clients that expire in the
+// next days, 30 by default, or already expired
+func (ci *ClientsInteractor) GetExpiringCSD(params models.ExpiringParams) ([]models.CSDExpiration, error) {
+	if params.Days < 1 {
+		params.Days = models.DefaultExpiringDays
+	}
+
+	return ci.expiringCSD(today(time.Now()), params.Days)
+}
+
+// NotifyCSDExpirations notifies the supervisor and the responsible of the
+// clients whose CSD expires within a threshold like NotifyFielExpirations does.
+// It returns the notifications sent.
+func (ci *ClientsInteractor) NotifyCSDExpirations(now time.Time, thresholds []int) (int, error) {
+	if len(thresholds) == 0 {
+		return 0, nil
+	}
+
+	expirations, err := ci.expiringCSD(today(now), thresholds[0])
+	if err != nil {
+		return 0, err
+	}
+
+	var notifications []models.Notification
+	for _, e := range expirations {
+		validTo := e.ValidTo[:len(time.DateOnly)]
+		message := fmt.Sprintf("The CSD %s of %s (%s) expires on %s, in %d days", e.SerialNumber, e.Name, e.RFC, validTo, e.DaysLeft)
+		if e.DaysLeft < 0 {
+			message = fmt.Sprintf("The CSD %s of %s (%s) expired on %s", e.SerialNumber, e.Name, e.RFC, validTo)
+		}
+
+		for _, userID := range recipients(e.SupervisorID, e.ResponsibleID) {
+			notifications = append(notifications, models.Notification{
+				UserID:   userID,
+				ClientID: e.ClientID,
+				Kind:     models.NotificationCSDExpiration,
+				Message:  message,
+				Key:      fmt.Sprintf("%s:%s:%d", models.NotificationCSDExpiration, e.SerialNumber, threshold(e.DaysLeft, thresholds)),
+			})
+		}
+	}
+
+	if len(notifications) == 0 {
+		return 0, nil
+	}
+
+	return ci.notificationsService.CreateNotifications(notifications)
+}
+
+// expiringCSD retrieves the CSD expiring up to some days after a date with the
+// days left of each one
+func (ci *ClientsInteractor) expiringCSD(day time.Time, days int) ([]models.CSDExpiration, error) {
+	before := day.AddDate(0, 0, days+1).UTC().Format(time.RFC3339)
+	expirations, err := ci.clientsService.GetExpiringCSD(before)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range expirations {
+		validTo, err := time.Parse(time.RFC3339, expirations[i].ValidTo)
+		if err != nil {
+			return nil, err
+		}
+		expirations[i].DaysLeft = daysBetween(day, validTo)
+	}
+
+	return expirations, nil
+}
+
+// checkCertificate checks a certificate was issued to the RFC of a client and
+// is still valid, the field names the file in the errors
+func checkCertificate(cert sat.Certificate, client models.ClientSummary, field string) error {
+	if cert.RFC != sat.NormalizeRFC(client.RFC) {
+		return models.NewValidationError("The certificate belongs to another RFC", []models.FieldError{{
+			Field:   "rfc",
+			Rule:    "rfc_mismatch",
+			Message: "the certificate was issued to " + cert.RFC + " and the client has " + client.RFC,
+		}})
+	}
+
+	if cert.Expired(time.Now()) {
+		return models.NewValidationError("The certificate has expired", []models.FieldError{{
+			Field:   field,
+			Rule:    "expired",
+			Message: "expired on " + cert.ValidTo.Format(time.DateOnly),
+		}})
+	}
+
+	return nil
+}
@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"contabi-be/models"
+	"contabi-be/pdftext"
+	"contabi-be/sat"
+	"contabi-be/search"
+)
+
+// regimenCode matches a regimen written with its code before the name, like
+// "612 - Personas Físicas con Actividades Empresariales y Profesionales"
+var regimenCode = regexp.MustCompile(`^(\d{3})\b`)
+
+// csfRegimenScore is the minimum score of a catalog name within the name of a
+// regimen of a constancia, the SAT adds words like "Régimen de las" to them
+const csfRegimenScore = 0.8
+
+// ReadCSF extracts the data of a client from the PDF of its constancia de
+// situación fiscal. Nothing is stored, the draft is reviewed and sent to
+// POST /clients to create the client.
+func (ci *ClientsInteractor) ReadCSF(file []byte) (models.CSFDraft, error) {
+	text, err := pdftext.Extract(file)
+	if err != nil {
+		rule, message := "pdf", "must be a PDF file"
+		switch {
+		case errors.Is(err, pdftext.ErrEncrypted):
+			message = "must not be encrypted"
+		case errors.Is(err, pdftext.ErrTooLarge):
+			rule, message = "max", "must have at most 64 MB of content once decompressed"
+		}
+		return models.CSFDraft{}, models.NewValidationError("The file is not a readable PDF", []models.FieldError{{
+			Field:   "file",
+			Rule:    rule,
+			Message: message,
+		}})
+	}
+
+	csf, err := sat.ParseCSF(text)
+	if err != nil {
+		return models.CSFDraft{}, models.NewValidationError("The file is not a constancia de situación fiscal", []models.FieldError{{
+			Field:   "file",
+			Rule:    "csf",
+			Message: "must be the constancia de situación fiscal issued by the SAT, scanned copies have no text to read",
+		}})
+	}
+
+	regimenes, err := ci.menusService.GetRegimenes()
+	if err != nil {
+		return models.CSFDraft{}, err
+	}
+
+	draft := models.CSFDraft{
+		Client: models.Client{
+			Name:   csfName(csf),
+			RFC:    csf.RFC,
+			Active: "true",
+		},
+		CURP:        csf.CURP,
+		IDCIF:       csf.IDCIF,
+		PostalCode:  csf.PostalCode,
+		Status:      csf.Status,
+		Regimenes:   []models.CSFRegimen{},
+		Obligations: []models.CSFObligation{},
+		Warnings:    []string{},
+	}
+
+	warn := func(format string, args ...any) {
+		draft.Warnings = append(draft.Warnings, fmt.Sprintf(format, args...))
+	}
+
+	rfc, rfcErr := sat.ParseRFC(csf.RFC)
+	if rfcErr != nil {
+		warn("The RFC %s %s", csf.RFC, rfcRules[rfcErr].Message)
+	}
+	if csf.Status != "" && !strings.EqualFold(csf.Status, "ACTIVO") {
+		warn("The taxpayer is %s in the SAT registry", csf.Status)
+	}
+
+	var current []string
+	for _, r := range csf.Regimenes {
+		regimen := models.CSFRegimen{Name: r.Name, StartDate: r.StartDate, EndDate: r.EndDate}
+		if match, ok := matchRegimen(r.Name, regimenes); ok {
+			regimen.RegimenID = match.ID
+		}
+		draft.Regimenes = append(draft.Regimenes, regimen)
+
+		if r.EndDate != "" {
+			continue
+		}
+		if regimen.RegimenID == "" {
+			warn("The regimen %q is not in the catalog", r.Name)
+			continue
+		}
+		current = append(current, regimen.RegimenID)
+	}
+
+	// the client gets the first current regimen that accepts its persona type
+	for _, id := range current {
+		if code, ok := satRegimenCode(regimenes, id); rfcErr != nil || (ok && sat.RegimenAllows(code, rfc.Persona)) {
+			draft.Client.RegimenID = id
+			break
+		}
+	}
+	switch {
+	case draft.Client.RegimenID == "":
+		warn("No current regimen of the constancia is in the catalog, the regimen must be chosen")
+	case len(current) > 1:
+		warn("The constancia has %d current regimenes, the client is created with %s", len(current), draft.Client.RegimenID)
+	}
+
+	for _, o := range csf.Obligations {
+		draft.Obligations = append(draft.Obligations, models.CSFObligation(o))
+	}
+
+	if rfcErr == nil && !sat.IsGenericRFC(csf.RFC) {
+		existing, err := ci.clientsService.GetActiveClientByRFC(csf.RFC)
+		switch {
+		case err == nil:
+			draft.ExistingClientID = existing.ID
+			warn("The active client %s already has the RFC", existing.Name)
+		case !errors.Is(err, models.ErrNotFound):
+			return models.CSFDraft{}, err
+		}
+	}
+
+	return draft, nil
+}
+
+// csfName returns the name of the client of a constancia, the personas físicas
+// are named by their surnames followed by their names
+func csfName(csf sat.CSF) string {
+	if csf.LegalName != "" {
+		return csf.LegalName
+	}
+
+	return strings.Join(strings.Fields(strings.Join([]string{csf.FirstSurname, csf.SecondSurname, csf.GivenNames}, " ")), " ")
+}
+
+// matchRegimen finds the regimen of the catalog named in a constancia by its
+// code or by its name, ignoring case and accents. The longest catalog name
+// wins between equal scores since it is the most specific one.
+func matchRegimen(name string, regimenes []models.Regimen) (models.Regimen, bool) {
+	if m := regimenCode.FindStringSubmatch(name); m != nil {
+		for _, r := range regimenes {
+			if r.ID == m[1] {
+				return r, true
+			}
+		}
+	}
+
+	var best models.Regimen
+	bestScore := 0.0
+	for _, r := range regimenes {
+		score := search.Score(r.Name, name)
+		if score > bestScore || (score == bestScore && len(r.Name) > len(best.Name)) {
+			best, bestScore = r, score
+		}
+	}
+
+	return best, bestScore >= csfRegimenScore
+}
@@ -0,0 +1,228 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"contabi-be/models"
+	"contabi-be/storage"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+// documentTypes are the content types accepted for the client documents, the
+// type is detected from the content and not taken from the upload
+var documentTypes = []string{
+	"application/pdf",
+	"image/jpeg",
+	"image/png",
+	"text/xml",
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// ErrDocumentChecksum is returned when a stored file no longer matches the
+// checksum taken when it was uploaded
+var ErrDocumentChecksum = errors.New("the stored file does not match its checksum")
+
+// DocumentsInteractor implements the DocumentsUseCase interface
+type DocumentsInteractor struct {
+	documentsService DocumentsService
+	clientsService   ClientsService
+	storage          storage.Storage
+}
+
+// NewDocumentsUseCase creates a new instance of DocumentsUseCase
+func NewDocumentsUseCase(documentsService DocumentsService, clientsService ClientsService, storage storage.Storage) DocumentsUseCase {
+	return &DocumentsInteractor{
+		documentsService: documentsService,
+		clientsService:   clientsService,
+		storage:          storage,
+	}
+}
+
+// GetDocuments retrieves the documents of a client
+func (di *DocumentsInteractor) GetDocuments(clientID string, params models.DocumentParams) ([]models.ClientDocument, error) {
+	if _, err := di.clientsService.GetClientSummary(clientID); err != nil {
+		return nil, err
+	}
+
+	return di.documentsService.GetDocuments(clientID, params)
+}
+
+// GetDocument retrieves a document of a client with its versions
+func (di *DocumentsInteractor) GetDocument(clientID, documentID string) (models.ClientDocument, error) {
+	return di.documentsService.GetDocument(clientID, documentID)
+}
+
+// UploadDocument stores a file as a new document of a client that was not
+// merged and did not leave, the title defaults to the name of the file
+func (di *DocumentsInteractor) UploadDocument(clientID, userID string, upload models.DocumentUpload, file models.DocumentFile) (models.ClientDocument, error) {
+	client, err := di.clientsService.GetClientSummary(clientID)
+	if err != nil {
+		return models.ClientDocument{}, err
+	}
+	if client.MergedInto != "" {
+		return models.ClientDocument{}, models.NewConflictError("The client was merged into another client", map[string]string{"merged_into": client.MergedInto})
+	}
+	if err := checkNotOffboarded(di.clientsService, clientID); err != nil {
+		return models.ClientDocument{}, err
+	}
+
+	version, err := di.store(clientID, userID, upload.Checksum, file)
+	if err != nil {
+		return models.ClientDocument{}, err
+	}
+
+	title := strings.TrimSpace(upload.Title)
+	if title == "" {
+		title = version.FileName
+	}
+
+	document, err := di.documentsService.CreateDocument(models.ClientDocument{
+		ClientID: clientID,
+		Category: upload.Category,
+		Title:    title,
+		Current:  version,
+	})
+	if err != nil {
+		di.storage.Delete(context.Background(), version.StorageKey)
+		return document, err
+	}
+
+	return di.documentsService.GetDocument(clientID, document.ID)
+}
+
+// AddDocumentVersion stores a file as the new current version of a document
+func (di *DocumentsInteractor) AddDocumentVersion(clientID, documentID, userID string, upload models.DocumentVersionUpload, file models.DocumentFile) (models.ClientDocument, error) {
+	if _, err := di.documentsService.GetDocument(clientID, documentID); err != nil {
+		return models.ClientDocument{}, err
+	}
+	if err := checkNotOffboarded(di.clientsService, clientID); err != nil {
+		return models.ClientDocument{}, err
+	}
+
+	version, err := di.store(clientID, userID, upload.Checksum, file)
+	if err != nil {
+		return models.ClientDocument{}, err
+	}
+
+	if _, err := di.documentsService.AddDocumentVersion(clientID, documentID, version); err != nil {
+		di.storage.Delete(context.Background(), version.StorageKey)
+		return models.ClientDocument{}, err
+	}
+
+	return di.documentsService.GetDocument(clientID, documentID)
+}
+
+// DownloadDocument reads the file of a version of a document, the current
+// one by default, checking it was not altered since it was uploaded
+func (di *DocumentsInteractor) DownloadDocument(clientID, documentID string, params models.DocumentVersionParams) (models.DocumentVersion, []byte, error) {
+	version, err := di.documentsService.GetDocumentVersion(clientID, documentID, params.Version)
+	if err != nil {
+		return version, nil, err
+	}
+
+	data, err := di.storage.Get(context.Background(), version.StorageKey)
+	if err != nil {
+		return version, nil, fmt.Errorf("reading %s: %w", version.StorageKey, err)
+	}
+	if checksum(data) != version.Checksum {
+		return version, nil, fmt.Errorf("reading %s: %w", version.StorageKey, ErrDocumentChecksum)
+	}
+
+	return version, data, nil
+}
+
+// DeleteDocument removes a document of a client with the files of its
+// versions, the documents of a client that left are kept to hand over
+func (di *DocumentsInteractor) DeleteDocument(clientID, documentID string) error {
+	if err := checkNotOffboarded(di.clientsService, clientID); err != nil {
+		return err
+	}
+
+	keys, err := di.documentsService.DeleteDocument(clientID, documentID)
+	if err != nil {
+		return err
+	}
+
+	// the document is gone once its rows are, a file that fails to be removed
+	// is only an orphan in the storage
+	for _, key := range keys {
+		di.storage.Delete(context.Background(), key)
+	}
+
+	return nil
+}
+
+// store checks the type and the checksum of an uploaded file and saves it
+// under a random key of the client
+func (di *DocumentsInteractor) store(clientID, userID, expected string, file models.DocumentFile) (models.DocumentVersion, error) {
+	contentType := ""
+	detected := mimetype.Detect(file.Data)
+	for _, t := range documentTypes {
+		if detected.Is(t) {
+			contentType = t
+			break
+		}
+	}
+	if contentType == "" {
+		return models.DocumentVersion{}, models.NewValidationError("The file type is not accepted", []models.FieldError{{
+			Field:   "file",
+			Rule:    "type",
+			Message: "must be a PDF, JPEG, PNG, XML, DOCX or XLSX file, got " + detected.String(),
+		}})
+	}
+
+	sum := checksum(file.Data)
+	if expected != "" && !strings.EqualFold(expected, sum) {
+		return models.DocumentVersion{}, models.NewValidationError("The file was altered during the upload", []models.FieldError{{
+			Field:   "checksum",
+			Rule:    "checksum",
+			Message: "does not match the SHA-256 of the received file, " + sum,
+		}})
+	}
+
+	suffix := make([]byte, 16)
+	if _, err := rand.Read(suffix); err != nil {
+		return models.DocumentVersion{}, err
+	}
+
+	version := models.DocumentVersion{
+		FileName:    documentFileName(file.Name, detected.Extension()),
+		ContentType: contentType,
+		Size:        int64(len(file.Data)),
+		Checksum:    sum,
+		StorageKey:  path.Join("clients", clientID, "documents", hex.EncodeToString(suffix)),
+		UploadedBy:  userID,
+	}
+
+	if err := di.storage.Put(context.Background(), version.StorageKey, file.Data, contentType); err != nil {
+		return version, fmt.Errorf("storing %s: %w", version.StorageKey, err)
+	}
+
+	return version, nil
+}
+
+// checksum returns the SHA-256 of a file in hex
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// documentFileName keeps the base name of an uploaded file, without the
+// directories some browsers send, naming it by its type when it is empty
+func documentFileName(name, extension string) string {
+	name = path.Base(strings.ReplaceAll(strings.TrimSpace(name), `\`, "/"))
+	if name == "." || name == "/" || name == "" {
+		return "document" + extension
+	}
+
+	return name
+}
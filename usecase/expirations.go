@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"math"
+	"time"
+)
+
+// today returns the start of the day of a time
+func today(now time.Time) time.Time {
+	y, m, d := now.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+}
+
+// daysBetween returns the days from the start of a day to the day of a time,
+// negative when the time is before the day
+func daysBetween(day, t time.Time) int {
+	return int(math.Round(today(t.In(day.Location())).Sub(day).Hours() / 24))
+}
+
+// threshold returns the smallest of the alert thresholds, sorted from the
+// largest to the smallest, that the days left reached
+func threshold(daysLeft int, thresholds []int) int {
+	reached := thresholds[0]
+	for _, t := range thresholds {
+		if daysLeft <= t {
+			reached = t
+		}
+	}
+
+	return reached
+}
+
+// recipients returns the users notified about a client, its supervisor and
+// its responsible when they are different users
+func recipients(supervisorID, responsibleID string) []string {
+	if responsibleID == supervisorID {
+		return []string{supervisorID}
+	}
+
+	return []string{supervisorID, responsibleID}
+}
@@ -0,0 +1,138 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"contabi-be/models"
+)
+
+// GetClientFees retrieves the monthly fees of a client, the latest effective
+// month first
+func (ci *ClientsInteractor) GetClientFees(clientID string) ([]models.ClientFee, error) {
+	if _, err := ci.clientsService.GetClientSummary(clientID); err != nil {
+		return nil, err
+	}
+
+	return ci.clientsService.GetClientFees(clientID)
+}
+
+// SaveClientFee sets the monthly fee of a client that was not merged and did
+// not leave from a month, a month after the current one schedules the change
+func (ci *ClientsInteractor) SaveClientFee(clientID string, request models.ClientFeeRequest, ifMatch string) (models.ClientFee, error) {
+	version, err := clientVersionOf(ci.clientsService, clientID, ifMatch)
+	if err != nil {
+		return models.ClientFee{}, err
+	}
+
+	if _, err := ci.checkNotMerged(clientID); err != nil {
+		return models.ClientFee{}, err
+	}
+	if err := checkNotOffboarded(ci.clientsService, clientID); err != nil {
+		return models.ClientFee{}, err
+	}
+
+	return ci.clientsService.SaveClientFee(models.ClientFee{
+		ClientID:       clientID,
+		MonthlyFee:     strings.TrimSpace(request.MonthlyFee),
+		EffectiveMonth: monthOf(request.EffectiveMonth),
+	}, version)
+}
+
+// DeleteClientFee cancels a fee scheduled for a month after the current one,
+// the fees already in effect are part of the payment history
+func (ci *ClientsInteractor) DeleteClientFee(clientID, feeID string) error {
+	fees, err := ci.GetClientFees(clientID)
+	if err != nil {
+		return err
+	}
+	if err := checkNotOffboarded(ci.clientsService, clientID); err != nil {
+		return err
+	}
+
+	currentMonth := time.Now().Format("2006-01")
+	for _, f := range fees {
+		if f.ID != feeID {
+			continue
+		}
+		if f.EffectiveMonth <= currentMonth {
+			return models.NewConflictError("Only the fees scheduled for a future month can be removed", map[string]string{"effective_month": f.EffectiveMonth})
+		}
+
+		return ci.clientsService.DeleteClientFee(clientID, feeID)
+	}
+
+	return models.NewNotFoundError("client fee not found")
+}
+
+// ApplyScheduledFees sets the current fee of the clients with a fee scheduled
+// for the month of a day, it returns the clients whose fee changed
+func (ci *ClientsInteractor) ApplyScheduledFees(now time.Time) (int, error) {
+	return ci.clientsService.ApplyClientFees(now.Format("2006-01"))
+}
+
+// feesByClient groups fees by their client
+func feesByClient(fees []models.ClientFee) map[string][]models.ClientFee {
+	grouped := map[string][]models.ClientFee{}
+	for _, f := range fees {
+		grouped[f.ClientID] = append(grouped[f.ClientID], f)
+	}
+
+	return grouped
+}
+
+// feeOn returns the fee in effect on a month, the one of the latest effective
+// month up to it, empty when the fees start after the month
+func feeOn(fees []models.ClientFee, month string) string {
+	fee, latest := "", ""
+	for _, f := range fees {
+		if f.EffectiveMonth <= month && (fee == "" || f.EffectiveMonth > latest) {
+			fee, latest = f.MonthlyFee, f.EffectiveMonth
+		}
+	}
+
+	return fee
+}
+
+// amountDue adds the fees in effect on the charged months after the last paid
+// one up to the current, the current one only when nothing was paid
+func amountDue(fees []models.ClientFee, currentFee, lastPaidMonth string, now time.Time, charged func(month string) bool) (int, string, error) {
+	current := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	from := current
+	if last, err := time.Parse("2006-01", monthOf(lastPaidMonth)); err == nil {
+		from = last.AddDate(0, 1, 0)
+	}
+
+	return feesDue(fees, currentFee, from, current, charged)
+}
+
+// feesDue adds the fees in effect on the charged months from the first of the
+// months given up to the last, the current fee when none was in effect. The
+// amounts are added in cents, a month without a fee adds nothing and a fee
+// that is not an amount fails.
+func feesDue(fees []models.ClientFee, currentFee string, from, until time.Time, charged func(month string) bool) (int, string, error) {
+	months, total := 0, int64(0)
+	for month := from; !month.After(until); month = month.AddDate(0, 1, 0) {
+		if !charged(month.Format("2006-01")) {
+			continue
+		}
+
+		fee := feeOn(fees, month.Format("2006-01"))
+		if fee == "" {
+			fee = currentFee
+		}
+		months++
+		if strings.TrimSpace(fee) == "" {
+			continue
+		}
+
+		amount, err := models.ParseAmount(fee)
+		if err != nil {
+			return 0, "", fmt.Errorf("the fee of %s: %w", month.Format("2006-01"), err)
+		}
+		total += amount
+	}
+
+	return months, models.FormatAmount(total), nil
+}
@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"contabi-be/models"
+)
+
+func TestFeesDue(t *testing.T) {
+	month := func(m string) time.Time {
+		t, _ := time.Parse("2006-01", m)
+		return t
+	}
+	always := func(string) bool { return true }
+
+	tests := []struct {
+		name       string
+		fees       []models.ClientFee
+		currentFee string
+		from, to   string
+		charged    func(string) bool
+		months     int
+		amount     string
+		fails      bool
+	}{
+		{
+			name:       "the cents add up exactly",
+			currentFee: "1234.10",
+			from:       "2024-01", to: "2024-03",
+			charged: always,
+			months:  3, amount: "3702.30",
+		},
+		{
+			name:       "each month is charged the fee in effect on it, the current one before the first",
+			fees:       []models.ClientFee{{MonthlyFee: "1000", EffectiveMonth: "2024-01"}, {MonthlyFee: "1500.5", EffectiveMonth: "2024-03"}},
+			currentFee: "1500.5",
+			from:       "2023-12", to: "2024-04",
+			charged: always,
+			months:  5, amount: "6501.50",
+		},
+		{
+			name:       "only the charged months count",
+			currentFee: "0.10",
+			from:       "2024-01", to: "2024-06",
+			charged: func(m string) bool { return m != "2024-02" && m != "2024-03" },
+			months:  4, amount: "0.40",
+		},
+		{
+			name: "a client without a fee owes the months and nothing",
+			from: "2024-01", to: "2024-02",
+			charged: always,
+			months:  2, amount: "0.00",
+		},
+		{
+			name: "nothing is due before the first month",
+			from: "2024-05", to: "2024-04",
+			currentFee: "100",
+			charged:    always,
+			months:     0, amount: "0.00",
+		},
+		{
+			name:       "a fee that is not an amount fails",
+			fees:       []models.ClientFee{{MonthlyFee: "1e3", EffectiveMonth: "2024-02"}},
+			currentFee: "1000",
+			from:       "2024-01", to: "2024-02",
+			charged: always,
+			fails:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		months, amount, err := feesDue(tt.fees, tt.currentFee, month(tt.from), month(tt.to), tt.charged)
+		if (err != nil) != tt.fails {
+			t.Errorf("%s: error %v, want failure %v", tt.name, err, tt.fails)
+			continue
+		}
+		if err == nil && (months != tt.months || amount != tt.amount) {
+			t.Errorf("%s: feesDue = %d months, %s, want %d months, %s", tt.name, months, amount, tt.months, tt.amount)
+		}
+	}
+}
+
+func TestAmountDue(t *testing.T) {
+	now := time.Date(2024, time.June, 15, 0, 0, 0, 0, time.UTC)
+	always := func(string) bool { return true }
+
+	if months, amount, _ := amountDue(nil, "999.99", "2024-03-01", now, always); months != 3 || amount != "2999.97" {
+		t.Errorf("paid up to 2024-03 the client owes %d months, %s, want 3 months, 2999.97", months, amount)
+	}
+	if months, amount, _ := amountDue(nil, "999.99", "", now, always); months != 1 || amount != "999.99" {
+		t.Errorf("never paid the client owes %d months, %s, want the current month, 999.99", months, amount)
+	}
+	if months, _, _ := amountDue(nil, "999.99", "2024-06", now, always); months != 0 {
+		t.Errorf("paid up to the current month the client owes %d months, want none", months)
+	}
+}
@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"time"
+
+	"contabi-be/models"
+	"contabi-be/sat"
+)
+
+// UploadFielCertificate reads the .cer file of the FIEL of a client and stores
+// its data, the certificate must belong to the RFC of the client and be valid.
+// The file is kept encrypted when an encryption key is configured.
+func (ci *ClientsInteractor) UploadFielCertificate(clientID string, file []byte) (models.FielCertificate, error) {
+	client, err := ci.checkNotMerged(clientID)
+	if err != nil {
+		return models.FielCertificate{}, err
+	}
+	if err := checkNotOffboarded(ci.clientsService, clientID); err != nil {
+		return models.FielCertificate{}, err
+	}
+
+	cert, err := sat.ParseCertificate(file)
+	if err != nil {
+		return models.FielCertificate{}, models.NewValidationError("The file is not a valid FIEL certificate", []models.FieldError{{
+			Field:   "file",
+			Rule:    "certificate",
+			Message: "must be a .cer certificate issued by the SAT",
+		}})
+	}
+
+	if cert.Type == sat.CertificateCSD {
+		return models.FielCertificate{}, models.NewValidationError("The certificate is a CSD", []models.FieldError{{
+			Field:   "file",
+			Rule:    "fiel",
+			Message: "must be a FIEL, the CSD are uploaded to /clients/:id/csd",
+		}})
+	}
+	if err := checkCertificate(cert, client, "file"); err != nil {
+		return models.FielCertificate{}, err
+	}
+
+	var stored []byte
+	if ci.box != nil {
+		if stored, err = ci.box.Seal(file); err != nil {
+			return models.FielCertificate{}, err
+		}
+	}
+
+	return ci.clientsService.SaveFielCertificate(models.FielCertificate{
+		ClientID:     clientID,
+		SerialNumber: cert.SerialNumber,
+		RFC:          cert.RFC,
+		Name:         cert.Name,
+		ValidFrom:    cert.ValidFrom.Format(time.RFC3339),
+		ValidTo:      cert.ValidTo.Format(time.RFC3339),
+	}, stored)
+}
+
+// GetFielCertificates retrieves the FIEL certificates uploaded for a client
+func (ci *ClientsInteractor) GetFielCertificates(clientID string) ([]models.FielCertificate, error) {
+	if _, err := ci.clientsService.GetClientSummary(clientID); err != nil {
+		return nil, err
+	}
+
+	return ci.clientsService.GetFielCertificates(clientID)
+}
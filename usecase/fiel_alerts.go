@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"fmt"
+	"time"
+
+	"contabi-be/models"
+)
+
+// GetExpiringFiel retrieves the active clients whose FIEL expires in the next
+// days, 30 by default, and the ones whose FIEL already expired
+func (ci *ClientsInteractor) GetExpiringFiel(params models.ExpiringParams) ([]models.FielExpiration, error) {
+	if params.Days < 1 {
+		params.Days = models.DefaultExpiringDays
+	}
+
+	return ci.expiringFiel(today(time.Now()), params.Days)
+}
+
+// SaveFielRenewal sets the renewal status of the FIEL of a client
+func (ci *ClientsInteractor) SaveFielRenewal(clientID string, request models.FielRenewalRequest, ifMatch string) (models.FielRenewal, error) {
+	version, err := clientVersionOf(ci.clientsService, clientID, ifMatch)
+	if err != nil {
+		return models.FielRenewal{}, err
+	}
+
+	if _, err := ci.checkNotMerged(clientID); err != nil {
+		return models.FielRenewal{}, err
+	}
+	if err := checkNotOffboarded(ci.clientsService, clientID); err != nil {
+		return models.FielRenewal{}, err
+	}
+
+	if request.Status != models.FielRenewalAppointment {
+		request.AppointmentDate = ""
+	}
+
+	return ci.clientsService.SaveFielRenewal(clientID, request, version)
+}
+
+// NotifyFielExpirations notifies the supervisor and the responsible of the
+// clients whose FIEL expires within a threshold, the thresholds go from the
+// largest to the smallest. Each threshold of an expiration is notified once,
+// the smallest one reached, and clients whose renewal is done are skipped.
+// It returns the notifications sent.
+func (ci *ClientsInteractor) NotifyFielExpirations(now time.Time, thresholds []int) (int, error) {
+	if len(thresholds) == 0 {
+		return 0, nil
+	}
+
+	expirations, err := ci.expiringFiel(today(now), thresholds[0])
+	if err != nil {
+		return 0, err
+	}
+
+	var notifications []models.Notification
+	for _, e := range expirations {
+		if e.Renewal != nil && e.Renewal.Status == models.FielRenewalRenewed {
+			continue
+		}
+
+		message := fmt.Sprintf("The FIEL of %s (%s) expires on %s, in %d days", e.Name, e.RFC, e.FielExpiration, e.DaysLeft)
+		if e.DaysLeft < 0 {
+			message = fmt.Sprintf("The FIEL of %s (%s) expired on %s", e.Name, e.RFC, e.FielExpiration)
+		}
+
+		for _, userID := range recipients(e.SupervisorID, e.ResponsibleID) {
+			notifications = append(notifications, models.Notification{
+				UserID:   userID,
+				ClientID: e.ClientID,
+				Kind:     models.NotificationFielExpiration,
+				Message:  message,
+				Key:      fmt.Sprintf("%s:%s:%s:%d", models.NotificationFielExpiration, e.ClientID, e.FielExpiration, threshold(e.DaysLeft, thresholds)),
+			})
+		}
+	}
+
+	if len(notifications) == 0 {
+		return 0, nil
+	}
+
+	return ci.notificationsService.CreateNotifications(notifications)
+}
+
+// expiringFiel retrieves the FIEL expiring up to some days after a date with
+// the days left of each one
+func (ci *ClientsInteractor) expiringFiel(day time.Time, days int) ([]models.FielExpiration, error) {
+	expirations, err := ci.clientsService.GetExpiringFiel(day.AddDate(0, 0, days).Format(time.DateOnly))
+	if err != nil {
+		return nil, err
+	}
+
+	// the expirations typed by hand before the certificates were uploaded may
+	// not be dates, their days left can not be known so they are left out
+	valid := expirations[:0]
+	for _, e := range expirations {
+		expiration, err := time.ParseInLocation(time.DateOnly, e.FielExpiration[:min(len(e.FielExpiration), len(time.DateOnly))], day.Location())
+		if err != nil {
+			continue
+		}
+		e.FielExpiration = expiration.Format(time.DateOnly)
+		e.DaysLeft = daysBetween(day, expiration)
+		valid = append(valid, e)
+	}
+
+	return valid, nil
+}
@@ -0,0 +1,358 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"contabi-be/models"
+	"contabi-be/sat"
+	"contabi-be/search"
+)
+
+// Fields of a client an import file can fill
+const (
+	importName           = "name"
+	importRFC            = "rfc"
+	importRegimen        = "regimen"
+	importCIEC           = "clave_ciec"
+	importFiel           = "clave_fiel"
+	importFielExpiration = "fiel_expiration"
+	importMonthlyFee     = "monthly_fee"
+	importSupervisor     = "supervisor"
+	importResponsible    = "responsible"
+	importEmisor         = "emisor"
+)
+
+// importHeaders maps the normalized headers of an import file to the fields,
+// the offices name their columns in English or Spanish
+var importHeaders = map[string]string{
+	"name":             importName,
+	"nombre":           importName,
+	"razon social":     importName,
+	"rfc":              importRFC,
+	"regimen":          importRegimen,
+	"regimen id":       importRegimen,
+	"regimen fiscal":   importRegimen,
+	"ciec":             importCIEC,
+	"clave ciec":       importCIEC,
+	"fiel":             importFiel,
+	"clave fiel":       importFiel,
+	"fiel expiration":  importFielExpiration,
+	"vencimiento fiel": importFielExpiration,
+	"fee":              importMonthlyFee,
+	"monthly fee":      importMonthlyFee,
+	"honorarios":       importMonthlyFee,
+	"supervisor":       importSupervisor,
+	"supervisor id":    importSupervisor,
+	"responsible":      importResponsible,
+	"responsible id":   importResponsible,
+	"responsable":      importResponsible,
+	"emisor":           importEmisor,
+	"emisor id":        importEmisor,
+}
+
+// importRequired are the columns an import file must have
+var importRequired = []string{importName, importRFC, importRegimen, importSupervisor, importResponsible, importEmisor}
+
+// importCatalogs holds the catalogs the names of an import file are resolved with
+type importCatalogs struct {
+	regimenes    []models.Regimen
+	supervisors  []models.Supervisor
+	emisors      []models.Emisor
+	responsibles map[string][]models.Responsible
+}
+
+// ImportClients validates the rows of a client spreadsheet, the first row
+// holds the headers. Unless it is a dry run the valid rows are created in a
+// single transaction, the invalid ones are only reported.
+func (ci *ClientsInteractor) ImportClients(rows [][]string, dryRun bool) (models.ClientImportReport, error) {
+	report := models.ClientImportReport{DryRun: dryRun, Rows: []models.ClientImportRow{}}
+
+	if len(rows) == 0 {
+		return report, models.NewValidationError("The file is empty", nil)
+	}
+
+	columns, err := importColumns(rows[0])
+	if err != nil {
+		return report, err
+	}
+
+	catalogs, err := ci.importCatalogs()
+	if err != nil {
+		return report, err
+	}
+
+	var requests []models.CreateClientRequest
+	seen := map[string]int{}
+	for i, cells := range rows[1:] {
+		if isBlankRow(cells) {
+			continue
+		}
+		if report.Total == models.MaxImportRows {
+			return report, models.NewValidationError(fmt.Sprintf("The file has more than %d clients", models.MaxImportRows), nil)
+		}
+		report.Total++
+
+		value := func(field string) string {
+			if c, ok := columns[field]; ok && c < len(cells) {
+				return strings.TrimSpace(cells[c])
+			}
+			return ""
+		}
+
+		request, fieldErrs, err := ci.importRow(value, catalogs)
+		if err != nil {
+			return report, err
+		}
+
+		row := models.ClientImportRow{Row: i + 2, Name: request.Client.Name, RFC: request.Client.RFC}
+		if first, ok := seen[request.Client.RFC]; ok && request.Client.RFC != "" && !sat.IsGenericRFC(request.Client.RFC) {
+			fieldErrs = append(fieldErrs, models.FieldError{
+				Field:   "rfc",
+				Rule:    "duplicate_row",
+				Message: fmt.Sprintf("is repeated from row %d", first),
+			})
+		} else {
+			seen[request.Client.RFC] = row.Row
+		}
+
+		row.Errors = fieldErrs
+		row.Valid = len(fieldErrs) == 0
+		if row.Valid {
+			report.Valid++
+			requests = append(requests, request)
+		} else {
+			report.Invalid++
+		}
+		report.Rows = append(report.Rows, row)
+	}
+
+	if dryRun || len(requests) == 0 {
+		return report, nil
+	}
+
+	if err := ci.clientsService.CreateClients(requests); err != nil {
+		return report, err
+	}
+	report.Imported = len(requests)
+
+	return report, nil
+}
+
+// importColumns finds the column of every field in the headers of the file
+func importColumns(headers []string) (map[string]int, error) {
+	columns := map[string]int{}
+	for i, h := range headers {
+		key := strings.Join(strings.FieldsFunc(search.Normalize(h), func(r rune) bool {
+			return r == ' ' || r == '_' || r == '-'
+		}), " ")
+		if field, ok := importHeaders[key]; ok {
+			if _, dup := columns[field]; !dup {
+				columns[field] = i
+			}
+		}
+	}
+
+	var missing []models.FieldError
+	for _, field := range importRequired {
+		if _, ok := columns[field]; !ok {
+			missing = append(missing, models.FieldError{Field: field, Rule: "required_column", Message: "is a required column"})
+		}
+	}
+	if len(missing) > 0 {
+		return nil, models.NewValidationError("The file is missing required columns", missing)
+	}
+
+	return columns, nil
+}
+
+// importCatalogs loads the catalogs used to resolve the names of an import file
+func (ci *ClientsInteractor) importCatalogs() (importCatalogs, error) {
+	catalogs := importCatalogs{responsibles: map[string][]models.Responsible{}}
+
+	var err error
+	if catalogs.regimenes, err = ci.menusService.GetRegimenes(); err != nil {
+		return catalogs, err
+	}
+	if catalogs.supervisors, err = ci.menusService.GetSupervisors(); err != nil {
+		return catalogs, err
+	}
+	if catalogs.emisors, err = ci.menusService.GetEmisors(); err != nil {
+		return catalogs, err
+	}
+
+	return catalogs, nil
+}
+
+// importRow builds the client of a row and validates it like the create
+// endpoint does, the catalogs accept either the id or the name of a record
+func (ci *ClientsInteractor) importRow(value func(string) string, catalogs importCatalogs) (models.CreateClientRequest, []models.FieldError, error) {
+	var fieldErrs []models.FieldError
+	invalid := func(field, rule, message string) {
+		fieldErrs = append(fieldErrs, models.FieldError{Field: field, Rule: rule, Message: message})
+	}
+
+	client := models.Client{
+		Name:           value(importName),
+		RFC:            value(importRFC),
+		ClaveCIEC:      value(importCIEC),
+		ClaveFiel:      value(importFiel),
+		FielExpiration: value(importFielExpiration),
+		MonthlyFee:     value(importMonthlyFee),
+	}
+
+	switch {
+	case client.Name == "":
+		invalid(importName, "required", "is required")
+	case len(client.Name) > 255:
+		invalid(importName, "max", "must have at most 255 characters")
+	}
+	if len(client.ClaveCIEC) > 100 {
+		invalid(importCIEC, "max", "must have at most 100 characters")
+	}
+	if len(client.ClaveFiel) > 100 {
+		invalid(importFiel, "max", "must have at most 100 characters")
+	}
+	if client.FielExpiration != "" {
+		if date, ok := importDate(client.FielExpiration); ok {
+			client.FielExpiration = date
+		} else {
+			invalid(importFielExpiration, "date", "must be a date formatted as YYYY-MM-DD or DD/MM/YYYY")
+		}
+	}
+	if client.MonthlyFee != "" {
+		if cents, err := models.ParseAmount(client.MonthlyFee); err != nil || cents <= 0 {
+			invalid(importMonthlyFee, "positive_amount", "must be an amount greater than zero with at most two decimals")
+		}
+	}
+
+	for _, r := range catalogs.regimenes {
+		if matchesCatalog(value(importRegimen), r.ID, r.Name) {
+			client.RegimenID = r.ID
+			break
+		}
+	}
+	if client.RegimenID == "" {
+		invalid(importRegimen, "regimen", "must be an existing regimen")
+	}
+
+	rfcValid := client.RFC != ""
+	if !rfcValid {
+		invalid(importRFC, "required", "is required")
+	} else if err := validateClientRFC(&client, catalogs.regimenes); err != nil {
+		var domainErr *models.Error
+		if !errors.As(err, &domainErr) {
+			return models.CreateClientRequest{}, nil, err
+		}
+		details, _ := domainErr.Details.([]models.FieldError)
+		for _, d := range details {
+			if d.Field == importRFC {
+				rfcValid = false
+			} else {
+				d.Field = importRegimen
+			}
+			fieldErrs = append(fieldErrs, d)
+		}
+	}
+
+	if rfcValid {
+		if err := ci.checkDuplicateRFC("", client.RFC); errors.Is(err, models.ErrConflict) {
+			invalid(importRFC, "duplicate", "an active client already has the RFC")
+		} else if err != nil {
+			return models.CreateClientRequest{}, nil, err
+		}
+	}
+
+	assignments, err := ci.importAssignments(value, catalogs, invalid)
+	if err != nil {
+		return models.CreateClientRequest{}, nil, err
+	}
+
+	return models.CreateClientRequest{Client: client, Assignments: assignments}, fieldErrs, nil
+}
+
+// importAssignments resolves the supervisor, responsible and emisor of a row,
+// the responsible must be one of the responsibles of the supervisor
+func (ci *ClientsInteractor) importAssignments(value func(string) string, catalogs importCatalogs, invalid func(field, rule, message string)) (models.ClientAssignments, error) {
+	var assignments models.ClientAssignments
+
+	for _, s := range catalogs.supervisors {
+		if matchesCatalog(value(importSupervisor), s.ID, s.Name) {
+			assignments.SupervisorID = s.ID
+			break
+		}
+	}
+	if assignments.SupervisorID == "" {
+		invalid(importSupervisor, "supervisor", "must be an existing supervisor")
+	} else {
+		responsibles, ok := catalogs.responsibles[assignments.SupervisorID]
+		if !ok {
+			var err error
+			if responsibles, err = ci.menusService.GetResponsiblesBySupervisor(assignments.SupervisorID); err != nil {
+				return assignments, err
+			}
+			catalogs.responsibles[assignments.SupervisorID] = responsibles
+		}
+
+		for _, r := range responsibles {
+			if matchesCatalog(value(importResponsible), r.ID, r.Name) {
+				assignments.ResponsibleID = r.ID
+				break
+			}
+		}
+		if assignments.ResponsibleID == "" {
+			invalid(importResponsible, "responsible", "must be one of the responsibles of the supervisor")
+		}
+	}
+
+	for _, e := range catalogs.emisors {
+		if matchesCatalog(value(importEmisor), e.ID, e.Name) {
+			assignments.EmisorID = e.ID
+			break
+		}
+	}
+	if assignments.EmisorID == "" {
+		invalid(importEmisor, "emisor", "must be an existing emisor")
+	}
+
+	return assignments, nil
+}
+
+// importDateLayouts are the date formats accepted in import files, the
+// spreadsheets of the offices show the dates day first
+var importDateLayouts = []string{time.DateOnly, "02/01/2006", "2/1/2006"}
+
+// importDate converts a date of an import file to YYYY-MM-DD
+func importDate(value string) (string, bool) {
+	for _, layout := range importDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Format(time.DateOnly), true
+		}
+	}
+
+	return "", false
+}
+
+// matchesCatalog reports whether a cell names a catalog record by its id or
+// by its name ignoring case and accents
+func matchesCatalog(cell, id, name string) bool {
+	if cell == "" {
+		return false
+	}
+
+	return cell == id || search.Normalize(cell) == search.Normalize(name)
+}
+
+// isBlankRow reports whether every cell of a row is empty, spreadsheets often
+// keep formatted rows after the data
+func isBlankRow(cells []string) bool {
+	for _, c := range cells {
+		if strings.TrimSpace(c) != "" {
+			return false
+		}
+	}
+
+	return true
+}
@@ -1,21 +1,33 @@
 package usecase
 
-import "contabi-be/models"
+import (
+	"strconv"
+
+	"contabi-be/models"
+)
 
 // NominasInteractor implements the NominasUseCase interface
 type NominasInteractor struct {
 	nominasService NominasService
+	clientsService ClientsService
 }
 
-// NewNominasUseCase creates a new instance of NominasService
-func NewNominasUseCase(nominasService NominasService) NominasService {
+// NewNominasUseCase creates a new instance of NominasService, the clients
+// service tells the months a client that left was serviced
+func NewNominasUseCase(nominasService NominasService, clientsService ClientsService) NominasUseCase {
 	return &NominasInteractor{
 		nominasService: nominasService,
+		clientsService: clientsService,
 	}
 }
 
-// CreateClientPaymentRecord creates a new client payment record
+// CreateClientPaymentRecord creates a new client payment record, a client that
+// left only has records of the months it was serviced
 func (ni *NominasInteractor) CreateClientPaymentRecord(clientPaymentRecord models.ClientHRPayment) error {
+	if err := checkServicedMonth(ni.clientsService, clientPaymentRecord.ClientID, clientPaymentRecord.PaymentMonth); err != nil {
+		return err
+	}
+
 	return ni.nominasService.CreateClientPaymentRecord(clientPaymentRecord)
 }
 
@@ -29,11 +41,55 @@ func (ni *NominasInteractor) GetClientPendingPaymentsByHREntityIDDetails(clientI
 	return ni.nominasService.GetClientPendingPaymentsByHREntityIDDetails(clientID, hrEntityID)
 }
 
-// UpdateClientPaymentRecord updates a client payment record
+// UpdateClientPaymentRecord updates a client payment record, a client that
+// left only has records of the months it was serviced
 func (ni *NominasInteractor) UpdateClientPaymentRecord(clientPaymentRecord models.UpdateClientHRPayment) error {
+	record, err := ni.nominasService.GetClientPaymentRecord(clientPaymentRecord.ID)
+	if err != nil {
+		return err
+	}
+	if err := checkServicedMonth(ni.clientsService, record.ClientID, clientPaymentRecord.PaymentMonth); err != nil {
+		return err
+	}
+
 	return ni.nominasService.UpdateClientPaymentRecord(clientPaymentRecord)
 }
 
+// PatchClientPaymentRecord changes only the fields of a client payment record
+// sent in a merge patch
+func (ni *NominasInteractor) PatchClientPaymentRecord(id string, patch models.ClientHRPaymentPatch) error {
+	if err := rejectNull(map[string]bool{
+		"payment_month": patch.PaymentMonth.Null,
+		"amount":        patch.Amount.Null,
+		"paid":          patch.Paid.Null,
+		"month":         patch.Month.Null,
+	}); err != nil {
+		return err
+	}
+
+	record, err := ni.nominasService.GetClientPaymentRecord(id)
+	if err != nil {
+		return err
+	}
+
+	applyPatch(&record.PaymentMonth, patch.PaymentMonth)
+	applyPatch(&record.Amount, patch.Amount)
+	applyPatch(&record.Paid, patch.Paid)
+	applyPatch(&record.Month, patch.Month)
+
+	if err := checkServicedMonth(ni.clientsService, record.ClientID, record.PaymentMonth); err != nil {
+		return err
+	}
+
+	return ni.nominasService.UpdateClientPaymentRecord(models.UpdateClientHRPayment{
+		ID:           id,
+		PaymentMonth: record.PaymentMonth,
+		Amount:       record.Amount,
+		Month:        record.Month,
+		Paid:         strconv.FormatBool(record.Paid),
+	})
+}
+
 // GetClientHRPaymentsHistory gets all the payments of a specific client and HR entity
 func (ni *NominasInteractor) GetClientHRPaymentsHistory(clientID, hrEntityID string) ([]models.ClientHRPayment, error) {
 	return ni.nominasService.GetClientHRPaymentsHistory(clientID, hrEntityID)
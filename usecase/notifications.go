@@ -0,0 +1,25 @@
+package usecase
+
+import "contabi-be/models"
+
+// NotificationsInteractor implements the NotificationsUseCase interface
+type NotificationsInteractor struct {
+	notificationsService NotificationsService
+}
+
+// NewNotificationsUseCase creates a new instance of NotificationsUseCase
+func NewNotificationsUseCase(notificationsService NotificationsService) NotificationsUseCase {
+	return &NotificationsInteractor{
+		notificationsService: notificationsService,
+	}
+}
+
+// GetNotifications retrieves the notifications of a user
+func (ni *NotificationsInteractor) GetNotifications(userID string, params models.NotificationParams) ([]models.Notification, error) {
+	return ni.notificationsService.GetNotifications(userID, params)
+}
+
+// MarkNotificationRead marks a notification of a user as read
+func (ni *NotificationsInteractor) MarkNotificationRead(userID, notificationID string) error {
+	return ni.notificationsService.MarkNotificationRead(userID, notificationID)
+}
@@ -0,0 +1,305 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"contabi-be/models"
+)
+
+// OffboardClient terminates a client that leaves. It computes what it owes up
+// to its end date, lists its documents and certificates to hand over, then
+// closes its accountancy months and revokes its credentials. A client already
+// terminated without an offboarding is offboarded from the date it was
+// terminated, without changing its state again.
+func (ci *ClientsInteractor) OffboardClient(clientID, userID string, request models.OffboardingRequest, ifMatch string) (models.ClientOffboarding, error) {
+	version, err := clientVersionOf(ci.clientsService, clientID, ifMatch)
+	if err != nil {
+		return models.ClientOffboarding{}, err
+	}
+
+	client, err := ci.checkNotMerged(clientID)
+	if err != nil {
+		return models.ClientOffboarding{}, err
+	}
+
+	var change *models.ClientStateChange
+	endDate := request.EndDate
+	if client.State == models.ClientTerminated {
+		if endDate, err = ci.terminationDate(client, request.EndDate); err != nil {
+			return models.ClientOffboarding{}, err
+		}
+	} else {
+		terminated, err := ci.stateChange(client, userID, models.ClientStateRequest{
+			State:         models.ClientTerminated,
+			Reason:        request.Reason,
+			EffectiveDate: request.EndDate,
+		})
+		if err != nil {
+			return models.ClientOffboarding{}, err
+		}
+		change, endDate = &terminated, terminated.EffectiveDate
+	}
+
+	lastServiced := monthOf(request.LastServicedMonth)
+	if lastServiced > monthOf(endDate) {
+		return models.ClientOffboarding{}, models.NewValidationError("The last serviced month can not be after the end date", []models.FieldError{{
+			Field:   "last_serviced_month",
+			Rule:    "end_date",
+			Message: "must not be after the month of the end date",
+		}})
+	}
+
+	offboarding := models.ClientOffboarding{
+		ClientID:          clientID,
+		Reason:            request.Reason,
+		LastServicedMonth: lastServiced,
+		EndDate:           endDate,
+		UserID:            userID,
+	}
+
+	if offboarding.MonthsDue, offboarding.OutstandingBalance, err = ci.outstandingBalance(client, monthOf(offboarding.EndDate)); err != nil {
+		return offboarding, err
+	}
+	if offboarding.Handover, err = ci.handoverItems(clientID); err != nil {
+		return offboarding, err
+	}
+
+	event := models.ClientEvent{
+		ClientID: clientID,
+		Type:     models.EventDeactivated,
+		Message: fmt.Sprintf("Client offboarded on %s, last serviced month %s with an outstanding balance of %s: %s",
+			offboarding.EndDate, offboarding.LastServicedMonth, offboarding.OutstandingBalance, offboarding.Reason),
+	}
+
+	return ci.clientsService.OffboardClient(change, offboarding, event, version)
+}
+
+// terminationDate returns the end date of the offboarding of a client already
+// terminated, the effective date of its termination. It fails when the client
+// was offboarded since or an end date other than that one is sent.
+func (ci *ClientsInteractor) terminationDate(client models.ClientSummary, endDate string) (string, error) {
+	offboarding, offboarded, err := offboardingOf(ci.clientsService, client.ID)
+	if err != nil {
+		return "", err
+	}
+	if offboarded {
+		return "", models.NewConflictError(
+			fmt.Sprintf("The client was already offboarded on %s", offboarding.EndDate),
+			map[string]string{"end_date": offboarding.EndDate},
+		)
+	}
+
+	changes, err := ci.clientsService.GetClientStateChanges(client.ID)
+	if err != nil {
+		return "", err
+	}
+
+	terminated := ""
+	if len(changes) > 0 && changes[0].ToState == models.ClientTerminated {
+		terminated = changes[0].EffectiveDate
+	}
+	switch {
+	case terminated == "" && endDate == "":
+		return time.Now().Format("2006-01-02"), nil
+	case terminated == "" && endDate > time.Now().Format("2006-01-02"):
+		return "", models.NewValidationError("The end date can not be in the future", []models.FieldError{{
+			Field:   "end_date",
+			Rule:    "not_future",
+			Message: "must not be after today",
+		}})
+	case terminated == "":
+		return endDate, nil
+	case endDate != "" && endDate != terminated:
+		return "", models.NewValidationError("The end date of a terminated client is the date it was terminated", []models.FieldError{{
+			Field:   "end_date",
+			Rule:    "terminated",
+			Message: "must be " + terminated + " or not be sent",
+		}})
+	}
+
+	return terminated, nil
+}
+
+// GetClientOffboarding gets the latest offboarding of a client
+func (ci *ClientsInteractor) GetClientOffboarding(clientID string) (models.ClientOffboarding, error) {
+	return ci.clientsService.GetClientOffboarding(clientID)
+}
+
+// outstandingBalance adds the fees of the months a client was charged after
+// its last paid month up to the month it leaves, from its first month when it
+// never paid
+func (ci *ClientsInteractor) outstandingBalance(client models.ClientSummary, endMonth string) (int, string, error) {
+	until, err := time.Parse("2006-01", endMonth)
+	if err != nil {
+		return 0, "", err
+	}
+
+	info, err := ci.clientsService.GetClientInfo(client.ID)
+	if err != nil {
+		return 0, "", err
+	}
+	payments, err := ci.clientsService.GetClientPayments(client.ID)
+	if err != nil {
+		return 0, "", err
+	}
+	fees, err := ci.clientsService.GetClientFees(client.ID)
+	if err != nil {
+		return 0, "", err
+	}
+	changes, err := ci.clientsService.GetClientStateChanges(client.ID)
+	if err != nil {
+		return 0, "", err
+	}
+
+	lastPaid := ""
+	for _, p := range payments {
+		if month := monthOf(p.LastPaymentMonth); month > lastPaid {
+			lastPaid = month
+		}
+	}
+
+	from := until
+	if last, err := time.Parse("2006-01", lastPaid); err == nil {
+		from = last.AddDate(0, 1, 0)
+	} else {
+		first, err := ci.firstMonth(client.ID, fees, changes)
+		if err != nil {
+			return 0, "", err
+		}
+		if start, err := time.Parse("2006-01", first); err == nil && start.Before(until) {
+			from = start
+		}
+	}
+
+	charged := func(month string) bool {
+		return models.IsChargedState(stateOn(changes, client.State, month))
+	}
+	months, amount, err := feesDue(fees, info.MonthlyFee, from, until, charged)
+	if err != nil {
+		return 0, "", fmt.Errorf("the balance of client %s: %w", client.ID, err)
+	}
+
+	return months, amount, nil
+}
+
+// firstMonth returns the earliest month a client has a record of, its fees,
+// assignments, changes of state and accountancy months, empty when it has none
+// with a date
+func (ci *ClientsInteractor) firstMonth(clientID string, fees []models.ClientFee, changes []models.ClientStateChange) (string, error) {
+	periods, err := ci.clientsService.GetAssignmentHistory(clientID)
+	if err != nil {
+		return "", err
+	}
+	history, err := ci.accountancyService.GetClientAccountancyHistory(clientID)
+	if err != nil {
+		return "", err
+	}
+
+	first := ""
+	earliest := func(month string) {
+		if month = monthOf(month); month != "" && (first == "" || month < first) {
+			first = month
+		}
+	}
+	for _, f := range fees {
+		earliest(models.FeeStart(f))
+	}
+	for _, p := range periods {
+		earliest(p.ValidFrom)
+	}
+	for _, c := range changes {
+		earliest(c.EffectiveDate)
+	}
+	for _, h := range history.History {
+		earliest(h.Status.Month)
+	}
+
+	return first, nil
+}
+
+// handoverItems lists the documents and the certificates of a client to hand
+// over when it leaves, the CSD and the FIEL with a stored file are marked as
+// revoked since the offboarding removes them
+func (ci *ClientsInteractor) handoverItems(clientID string) ([]models.HandoverItem, error) {
+	items := []models.HandoverItem{}
+
+	documents, err := ci.documentsService.GetDocuments(clientID, models.DocumentParams{})
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range documents {
+		items = append(items, models.HandoverItem{Type: models.HandoverDocument, ID: d.ID, Name: d.Title, Detail: d.Category + ", " + d.Current.FileName})
+	}
+
+	fiel, err := ci.clientsService.GetFielCertificates(clientID)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range fiel {
+		items = append(items, models.HandoverItem{Type: models.HandoverFiel, ID: f.ID, Name: f.SerialNumber, Detail: "valid to " + f.ValidTo, Revoked: f.FileStored})
+	}
+
+	csd, err := ci.clientsService.GetCSDCertificates(clientID)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range csd {
+		items = append(items, models.HandoverItem{Type: models.HandoverCSD, ID: c.ID, Name: c.SerialNumber, Detail: "valid to " + c.ValidTo, Revoked: true})
+	}
+
+	return items, nil
+}
+
+// offboardingOf returns the offboarding of a client that left and was not
+// active again since
+func offboardingOf(clientsService ClientsService, clientID string) (models.ClientOffboarding, bool, error) {
+	client, err := clientsService.GetClientSummary(clientID)
+	if errors.Is(err, models.ErrNotFound) {
+		return models.ClientOffboarding{}, false, nil
+	}
+	if err != nil || client.State != models.ClientTerminated {
+		return models.ClientOffboarding{}, false, err
+	}
+
+	offboarding, err := clientsService.GetClientOffboarding(clientID)
+	if errors.Is(err, models.ErrNotFound) {
+		return offboarding, false, nil
+	}
+
+	return offboarding, err == nil, err
+}
+
+// checkNotOffboarded fails when a client left, its data can not change until
+// it is active again
+func checkNotOffboarded(clientsService ClientsService, clientID string) error {
+	offboarding, offboarded, err := offboardingOf(clientsService, clientID)
+	if err != nil || !offboarded {
+		return err
+	}
+
+	return models.NewConflictError(
+		fmt.Sprintf("The client left on %s, it can not change until it is active again", offboarding.EndDate),
+		map[string]string{"end_date": offboarding.EndDate},
+	)
+}
+
+// checkServicedMonth fails when a client left before the month of a record,
+// the months up to the one of its end date are still accepted so what it owed
+// can be recorded
+func checkServicedMonth(clientsService ClientsService, clientID, month string) error {
+	offboarding, offboarded, err := offboardingOf(clientsService, clientID)
+	if err != nil || !offboarded {
+		return err
+	}
+
+	if monthOf(month) > monthOf(offboarding.EndDate) {
+		return models.NewConflictError(
+			fmt.Sprintf("The client left on %s, it has no records after that month", offboarding.EndDate),
+			map[string]string{"end_date": offboarding.EndDate},
+		)
+	}
+
+	return nil
+}
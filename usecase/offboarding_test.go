@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"contabi-be/models"
+)
+
+func TestOffboardingBalance(t *testing.T) {
+	// active since January, suspended in March and April, terminated in June
+	changes := []models.ClientStateChange{
+		{FromState: models.ClientActive, ToState: models.ClientTerminated, EffectiveDate: "2024-06-10"},
+		{FromState: models.ClientSuspended, ToState: models.ClientActive, EffectiveDate: "2024-05-02"},
+		{FromState: models.ClientActive, ToState: models.ClientSuspended, EffectiveDate: "2024-03-01"},
+		{FromState: models.ClientOnboarding, ToState: models.ClientActive, EffectiveDate: "2024-01-15"},
+	}
+	fees := []models.ClientFee{{MonthlyFee: "1234.10", EffectiveMonth: "2024-01"}, {MonthlyFee: "1300.05", EffectiveMonth: "2024-05"}}
+	charged := func(month string) bool {
+		return models.IsChargedState(stateOn(changes, models.ClientTerminated, month))
+	}
+
+	tests := []struct {
+		name   string
+		from   string
+		months int
+		amount string
+	}{
+		{name: "never paid", from: "2023-12", months: 3, amount: "3768.25"},
+		{name: "paid up to January", from: "2024-02", months: 2, amount: "2534.15"},
+		{name: "paid up to the suspension", from: "2024-04", months: 1, amount: "1300.05"},
+		{name: "paid up to the end", from: "2024-07", months: 0, amount: "0.00"},
+	}
+
+	until := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		from, _ := time.Parse("2006-01", tt.from)
+		months, amount, err := feesDue(fees, "1300.05", from, until, charged)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if months != tt.months || amount != tt.amount {
+			t.Errorf("%s: the client owes %d months, %s, want %d months, %s", tt.name, months, amount, tt.months, tt.amount)
+		}
+	}
+}
+
+func TestStateOn(t *testing.T) {
+	changes := []models.ClientStateChange{
+		{FromState: models.ClientActive, ToState: models.ClientSuspended, EffectiveDate: "2024-03-20"},
+		{FromState: models.ClientOnboarding, ToState: models.ClientActive, EffectiveDate: "2024-01-15"},
+	}
+
+	for month, want := range map[string]string{
+		"2023-12": models.ClientOnboarding,
+		"2024-01": models.ClientActive,
+		"2024-02": models.ClientActive,
+		"2024-03": models.ClientSuspended,
+		"2024-09": models.ClientSuspended,
+	} {
+		if state := stateOn(changes, models.ClientSuspended, month); state != want {
+			t.Errorf("state on %s is %s, want %s", month, state, want)
+		}
+	}
+	if state := stateOn(nil, models.ClientActive, "2024-01"); state != models.ClientActive {
+		t.Errorf("without changes the state is %s, want the current one", state)
+	}
+}
+
+func TestOffboardClient(t *testing.T) {
+	ci, cs := newTestClients(t)
+
+	page, err := cs.GetAllClientsInfo(models.ClientListParams{Page: 1, PageSize: 10})
+	if err != nil {
+		t.Fatalf("listing the demo clients: %v", err)
+	}
+	i := slices.IndexFunc(page.Items, func(c models.ClientInfo) bool { return c.Name == "Transportes Núñez" })
+	if i < 0 {
+		t.Fatal("the demo client Transportes Núñez is missing")
+	}
+	client := page.Items[i]
+
+	for _, cert := range []struct {
+		serial string
+		file   []byte
+	}{{"00001000000500000001", []byte("fiel")}, {"00001000000500000002", nil}} {
+		if _, err := cs.SaveFielCertificate(models.FielCertificate{ClientID: client.ID, SerialNumber: cert.serial, ValidTo: "2027-01-01"}, cert.file); err != nil {
+			t.Fatalf("saving the FIEL: %v", err)
+		}
+	}
+	csd, err := cs.SaveCSDCertificate(models.CSDCertificate{ClientID: client.ID, SerialNumber: "00001000000500000003", ValidTo: "2027-01-01"}, models.CSDFiles{})
+	if err != nil {
+		t.Fatalf("saving the CSD: %v", err)
+	}
+
+	// the demo client never paid and has an accountancy month since last month
+	request := models.OffboardingRequest{Reason: "Moved to another firm", LastServicedMonth: time.Now().Format("2006-01")}
+	offboarding, err := ci.OffboardClient(client.ID, "", request, "*")
+	if err != nil {
+		t.Fatalf("offboarding: %v", err)
+	}
+
+	if offboarding.MonthsDue != 2 || offboarding.OutstandingBalance != "7000.00" {
+		t.Errorf("offboarding owes %d months, %s, want 2 months, 7000.00", offboarding.MonthsDue, offboarding.OutstandingBalance)
+	}
+	if want := (models.RevokedCredentials{ClaveCIEC: true, ClaveFiel: true, CSDCertificates: 1, FielFiles: 1}); offboarding.Revoked != want {
+		t.Errorf("revoked %+v, want %+v", offboarding.Revoked, want)
+	}
+
+	revoked := map[string]bool{}
+	for _, item := range offboarding.Handover {
+		revoked[item.Name] = item.Revoked
+	}
+	if want := map[string]bool{"00001000000500000001": true, "00001000000500000002": false, csd.SerialNumber: true}; !maps.Equal(revoked, want) {
+		t.Errorf("handover revoked %v, want %v", revoked, want)
+	}
+
+	if left, _ := cs.GetCSDCertificates(client.ID); len(left) != 0 {
+		t.Errorf("the CSD %+v were kept", left)
+	}
+	if _, err := ci.OffboardClient(client.ID, "", request, "*"); errorCode(err) != models.CodeConflict {
+		t.Errorf("a second offboarding gave %v, want a conflict", err)
+	}
+}
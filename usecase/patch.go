@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"sort"
+	"strconv"
+
+	"contabi-be/models"
+)
+
+// PatchClient changes only the fields of a client sent in a merge patch, the
+// result is checked like a full update. The patch applies to the version of
+// If-Match, so the client is read after it is pinned, and the RFC is checked
+// only when the RFC or the regimen are sent.
+func (ci *ClientsInteractor) PatchClient(clientID string, patch models.ClientPatch, ifMatch string) error {
+	if err := rejectNull(map[string]bool{
+		"name":       patch.Name.Null,
+		"regimen_id": patch.RegimenID.Null,
+		"rfc":        patch.RFC.Null,
+		"active":     patch.Active.Null,
+	}); err != nil {
+		return err
+	}
+
+	version, err := clientVersionOf(ci.clientsService, clientID, ifMatch)
+	if err != nil {
+		return err
+	}
+
+	info, err := ci.clientsService.GetClientInfo(clientID)
+	if err != nil {
+		return err
+	}
+
+	client := models.Client{
+		Name:           info.Name,
+		RegimenID:      info.RegimenID,
+		RFC:            info.RFC,
+		ClaveCIEC:      info.ClaveCIEC,
+		ClaveFiel:      info.ClaveFiel,
+		FielExpiration: info.FielExpiration,
+		MonthlyFee:     info.MonthlyFee,
+	}
+	active := info.Active
+
+	applyPatch(&client.Name, patch.Name)
+	applyPatch(&client.RegimenID, patch.RegimenID)
+	applyPatch(&client.RFC, patch.RFC)
+	applyPatch(&client.ClaveCIEC, patch.ClaveCIEC)
+	applyPatch(&client.ClaveFiel, patch.ClaveFiel)
+	applyPatch(&client.FielExpiration, patch.FielExpiration)
+	applyPatch(&client.MonthlyFee, patch.MonthlyFee)
+	applyPatch(&active, patch.Active)
+	client.Active = strconv.FormatBool(active)
+
+	return ci.updateClient(clientID, client, version, patch.RFC.Set || patch.RegimenID.Set)
+}
+
+// applyPatch sets a value to the one of a patch field when it was sent, null
+// sets the zero value
+func applyPatch[T any](value *T, field models.PatchField[T]) {
+	if !field.Set {
+		return
+	}
+
+	var zero T
+	*value = zero
+	if !field.Null {
+		*value = field.Value
+	}
+}
+
+// rejectNull fails with the fields of a merge patch that were sent as null
+// and cannot be cleared
+func rejectNull(nulls map[string]bool) error {
+	var fields []string
+	for field, null := range nulls {
+		if null {
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	sort.Strings(fields)
+
+	details := make([]models.FieldError, 0, len(fields))
+	for _, field := range fields {
+		details = append(details, models.FieldError{Field: field, Rule: "required", Message: "is required"})
+	}
+
+	return models.NewValidationError("The request has invalid fields", details)
+}
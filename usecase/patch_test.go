@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"contabi-be/models"
+	"contabi-be/service/memory"
+)
+
+// newTestClients returns the clients use case over a demo store with its
+// clients service
+func newTestClients(t *testing.T) (*ClientsInteractor, *memory.ClientsService) {
+	t.Helper()
+
+	store, err := memory.NewDemoStore()
+	if err != nil {
+		t.Fatalf("seeding the demo store: %v", err)
+	}
+	cs := memory.NewClientsService(store)
+	ci := NewClientsUseCase(cs, memory.NewMenusService(store), memory.NewNotificationsService(store), memory.NewTimelineService(store),
+		memory.NewDocumentsService(store), memory.NewAccountancyService(store), nil).(*ClientsInteractor)
+
+	return ci, cs
+}
+
+// errorCode returns the code of an error of the models, empty for the others
+func errorCode(err error) models.ErrorCode {
+	var e *models.Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return ""
+}
+
+func TestPatchClient(t *testing.T) {
+	ci, cs := newTestClients(t)
+
+	page, err := cs.GetAllClientsInfo(models.ClientListParams{Page: 1, PageSize: 10})
+	if err != nil || len(page.Items) == 0 {
+		t.Fatalf("listing the demo clients: %v", err)
+	}
+	info := page.Items[0]
+
+	// a client recorded before the RFCs were checked
+	legacy := models.Client{Name: info.Name, RegimenID: info.RegimenID, RFC: "XYZ123", MonthlyFee: info.MonthlyFee, Active: "true"}
+	version, _ := cs.GetClientVersion(info.ID)
+	if err := cs.UpdateClient(info.ID, legacy, nil, version); err != nil {
+		t.Fatalf("recording the legacy RFC: %v", err)
+	}
+
+	name := models.PatchField[string]{Set: true, Value: "Abarrotes Muñoz e Hijos"}
+	if err := ci.PatchClient(info.ID, models.ClientPatch{Name: name}, "*"); err != nil {
+		t.Fatalf("a patch without the RFC failed on the legacy RFC: %v", err)
+	}
+
+	rfc := models.PatchField[string]{Set: true, Value: "XYZ124"}
+	if err := ci.PatchClient(info.ID, models.ClientPatch{RFC: rfc}, "*"); errorCode(err) != models.CodeValidation {
+		t.Errorf("a patch of an invalid RFC gave %v, want a validation error", err)
+	}
+
+	stale := version.ETag()
+	if err := ci.PatchClient(info.ID, models.ClientPatch{Name: name}, stale); errorCode(err) != models.CodePreconditionFailed {
+		t.Errorf("a patch of an old version gave %v, want a failed precondition", err)
+	}
+
+	current, _ := cs.GetClientInfo(info.ID)
+	if current.Name != name.Value || current.RFC != "XYZ123" {
+		t.Errorf("patched client %s %s, want %s with the legacy RFC", current.Name, current.RFC, name.Value)
+	}
+}
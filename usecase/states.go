@@ -0,0 +1,124 @@
+package usecase
+
+import (
+	"fmt"
+	"time"
+
+	"contabi-be/models"
+)
+
+// ChangeClientState moves a client to the state of the request when its
+// current state allows it and records the change in the timeline
+func (ci *ClientsInteractor) ChangeClientState(clientID, userID string, request models.ClientStateRequest, ifMatch string) (models.ClientStateChange, error) {
+	version, err := clientVersionOf(ci.clientsService, clientID, ifMatch)
+	if err != nil {
+		return models.ClientStateChange{}, err
+	}
+
+	client, err := ci.checkNotMerged(clientID)
+	if err != nil {
+		return models.ClientStateChange{}, err
+	}
+
+	return ci.changeState(client, userID, request, version)
+}
+
+// GetClientStateHistory gets the changes of state of a client, the latest
+// effective date first
+func (ci *ClientsInteractor) GetClientStateHistory(clientID string) ([]models.ClientStateChange, error) {
+	if _, err := ci.clientsService.GetClientSummary(clientID); err != nil {
+		return nil, err
+	}
+
+	return ci.clientsService.GetClientStateChanges(clientID)
+}
+
+// changeState checks a change of state of a client and makes it when the
+// client is still in the version given, the service records it in the timeline
+func (ci *ClientsInteractor) changeState(client models.ClientSummary, userID string, request models.ClientStateRequest, version models.ClientVersion) (models.ClientStateChange, error) {
+	change, err := ci.stateChange(client, userID, request)
+	if err != nil {
+		return change, err
+	}
+
+	return ci.clientsService.ChangeClientState(change, version)
+}
+
+// stateChange checks a change of state of a client and returns it with its
+// effective date, which can not be before the last change of the client. A
+// client that becomes active again must not share its RFC with an active
+// client.
+func (ci *ClientsInteractor) stateChange(client models.ClientSummary, userID string, request models.ClientStateRequest) (models.ClientStateChange, error) {
+	if !models.CanChangeClientState(client.State, request.State) {
+		return models.ClientStateChange{}, models.NewConflictError(
+			fmt.Sprintf("A %s client can not change to %s", client.State, request.State),
+			map[string]any{"state": client.State, "allowed": models.ClientTransitions(client.State)},
+		)
+	}
+
+	today := time.Now().Format("2006-01-02")
+	if request.EffectiveDate == "" {
+		request.EffectiveDate = today
+	}
+	if request.EffectiveDate > today {
+		return models.ClientStateChange{}, models.NewValidationError("The effective date can not be in the future", []models.FieldError{{
+			Field:   "effective_date",
+			Rule:    "not_future",
+			Message: "must not be after today",
+		}})
+	}
+
+	changes, err := ci.clientsService.GetClientStateChanges(client.ID)
+	if err != nil {
+		return models.ClientStateChange{}, err
+	}
+	if len(changes) > 0 && request.EffectiveDate < changes[0].EffectiveDate {
+		return models.ClientStateChange{}, models.NewValidationError("The effective date can not be before the last change of state", []models.FieldError{{
+			Field:   "effective_date",
+			Rule:    "after_last_change",
+			Message: "must not be before " + changes[0].EffectiveDate,
+		}})
+	}
+
+	if models.IsActiveState(request.State) && !models.IsActiveState(client.State) {
+		if err := ci.checkDuplicateRFC(client.ID, client.RFC); err != nil {
+			return models.ClientStateChange{}, err
+		}
+	}
+
+	return models.ClientStateChange{
+		ClientID:      client.ID,
+		FromState:     client.State,
+		ToState:       request.State,
+		Reason:        request.Reason,
+		EffectiveDate: request.EffectiveDate,
+		UserID:        userID,
+	}, nil
+}
+
+// statesByClient groups the changes of state by client, in the order given
+func statesByClient(changes []models.ClientStateChange) map[string][]models.ClientStateChange {
+	grouped := map[string][]models.ClientStateChange{}
+	for _, c := range changes {
+		grouped[c.ClientID] = append(grouped[c.ClientID], c)
+	}
+
+	return grouped
+}
+
+// stateOn returns the state a client had at the end of a month from its
+// changes, the latest effective date first. Before its first change a client
+// was in the state the change started from, without changes it was always in
+// the current one.
+func stateOn(changes []models.ClientStateChange, current, month string) string {
+	for _, c := range changes {
+		if monthOf(c.EffectiveDate) <= month {
+			return c.ToState
+		}
+	}
+	if len(changes) > 0 {
+		return changes[len(changes)-1].FromState
+	}
+
+	return current
+}
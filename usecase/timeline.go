@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+
+	"contabi-be/models"
+)
+
+// AddClientNote adds a note of a user to the timeline of a client
+func (ci *ClientsInteractor) AddClientNote(clientID, userID string, request models.ClientNoteRequest) (models.ClientEvent, error) {
+	if _, err := ci.checkNotMerged(clientID); err != nil {
+		return models.ClientEvent{}, err
+	}
+
+	text := strings.TrimSpace(request.Text)
+	if text == "" {
+		return models.ClientEvent{}, models.NewValidationError("The note is empty", []models.FieldError{{
+			Field:   "text",
+			Rule:    "required",
+			Message: "is required",
+		}})
+	}
+
+	return ci.timelineService.CreateClientEvent(models.ClientEvent{
+		ClientID: clientID,
+		Type:     models.EventNote,
+		Message:  text,
+		UserID:   userID,
+	})
+}
+
+// GetClientTimeline retrieves a page of the notes and events of a client, the
+// newest first
+func (ci *ClientsInteractor) GetClientTimeline(clientID string, params models.TimelineParams) (models.ClientEventPage, error) {
+	if _, err := ci.clientsService.GetClientSummary(clientID); err != nil {
+		return models.ClientEventPage{}, err
+	}
+
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.PageSize < 1 {
+		params.PageSize = models.DefaultPageSize
+	}
+	if params.PageSize > models.MaxPageSize {
+		params.PageSize = models.MaxPageSize
+	}
+
+	return ci.timelineService.GetClientTimeline(clientID, params)
+}
+
+// paymentEvent is the event of a registered payment of a client
+func paymentEvent(clientID string, payment models.ClientPayment) models.ClientEvent {
+	message := fmt.Sprintf("Payment of %s registered, paid on %s", monthOf(payment.LastPaymentMonth), payment.LastPaymentDate)
+	if payment.FolioFactura != "" {
+		message += ", invoice " + payment.FolioFactura
+	}
+
+	return models.ClientEvent{ClientID: clientID, Type: models.EventPayment, Message: message}
+}
+
+// monthOf returns the YYYY-MM of a month sent as YYYY-MM or YYYY-MM-DD
+func monthOf(month string) string {
+	if len(month) > 7 {
+		return month[:7]
+	}
+
+	return month
+}
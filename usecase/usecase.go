@@ -1,6 +1,10 @@
 package usecase
 
-import "contabi-be/models"
+import (
+	"time"
+
+	"contabi-be/models"
+)
 
 // LoginUseCase defines the interface for login-related operations
 type LoginUseCase interface {
@@ -12,6 +16,20 @@ type LoginService interface {
 	Login(login, password string) (models.User, error)
 }
 
+// UsersUseCase defines the interface for the user operations, it adds the
+// merge patch of a user to the ones of the service
+type UsersUseCase interface {
+	GetUsers() ([]models.User, error)
+	GetUserByID(id string) (models.User, error)
+	CreateUser(user models.User) error
+	UpdateUser(user models.User) error
+	PatchUser(userID string, patch models.UserPatch) error
+	UpdateUserRole(user models.User) error
+	PutUserPassword(user models.User) error
+	DeleteUser(id string) error
+	GetRoles() ([]models.Role, error)
+}
+
 type UsersService interface {
 	GetUsers() ([]models.User, error)
 	GetUserByID(id string) (models.User, error)
@@ -19,23 +37,102 @@ type UsersService interface {
 	UpdateUser(user models.User) error
 	UpdateUserRole(user models.User) error
 	PutUserPassword(user models.User) error
+	PatchUser(userID string, patch models.UserPatch) error
 	DeleteUser(id string) error
 	GetRoles() ([]models.Role, error)
 }
 
+// ClientsUseCase defines the interface for the client operations, it adds the
+// reports computed by the interactor to the ones of the service
+type ClientsUseCase interface {
+	GetAllClientsInfo(params models.ClientListParams) (models.ClientInfoPage, error)
+	GetActiveClientsInfo(params models.ClientListParams) (models.ClientInfoPage, error)
+	SearchClients(params models.ClientSearchParams) ([]models.ClientSearchResult, error)
+	GetClientInfo(clientID string) (models.ClientInfo, error)
+	CreateClient(client models.Client, assignments models.ClientAssignments) error
+	UpdateClient(clientID string, client models.Client, ifMatch string) error
+	PatchClient(clientID string, patch models.ClientPatch, ifMatch string) error
+	DeactivateClient(clientID, ifMatch string) error
+	ActivateClient(clientID, ifMatch string) error
+	ChangeClientState(clientID, userID string, request models.ClientStateRequest, ifMatch string) (models.ClientStateChange, error)
+	GetClientStateHistory(clientID string) ([]models.ClientStateChange, error)
+	OffboardClient(clientID, userID string, request models.OffboardingRequest, ifMatch string) (models.ClientOffboarding, error)
+	GetClientOffboarding(clientID string) (models.ClientOffboarding, error)
+	UpdateClientAssignments(clientID string, assignments models.ClientAssignments, ifMatch string) error
+	GetClientETag(clientID string) (string, error)
+	GetAssignmentHistory(clientID string, params models.AssignmentHistoryParams) ([]models.AssignmentPeriod, error)
+	GetAssignmentsAsOf(params models.AssignmentsAsOfParams) ([]models.AssignmentPeriod, error)
+	GetClientsWithPendingPayments() ([]models.ClientWithPendingPayment, error)
+	UpdateClientPayment(clientID string, payment models.ClientPayment, ifMatch string) error
+	GetClientPayments(clientID string) ([]models.ClientPaymentHistory, error)
+	GetClientFees(clientID string) ([]models.ClientFee, error)
+	SaveClientFee(clientID string, request models.ClientFeeRequest, ifMatch string) (models.ClientFee, error)
+	DeleteClientFee(clientID, feeID string) error
+	ApplyScheduledFees(now time.Time) (int, error)
+	GetDuplicateClients() ([]models.DuplicateClientGroup, error)
+	MergeClients(targetID string, request models.MergeClientsRequest) (models.ClientMergeResult, error)
+	ImportClients(rows [][]string, dryRun bool) (models.ClientImportReport, error)
+	ReadCSF(file []byte) (models.CSFDraft, error)
+	AddClientNote(clientID, userID string, request models.ClientNoteRequest) (models.ClientEvent, error)
+	GetClientTimeline(clientID string, params models.TimelineParams) (models.ClientEventPage, error)
+	UploadFielCertificate(clientID string, file []byte) (models.FielCertificate, error)
+	GetFielCertificates(clientID string) ([]models.FielCertificate, error)
+	GetExpiringFiel(params models.ExpiringParams) ([]models.FielExpiration, error)
+	SaveFielRenewal(clientID string, request models.FielRenewalRequest, ifMatch string) (models.FielRenewal, error)
+	NotifyFielExpirations(now time.Time, thresholds []int) (int, error)
+	UploadCSDCertificate(clientID string, certificate, key []byte, password string) (models.CSDCertificate, error)
+	GetCSDCertificates(clientID string) ([]models.CSDCertificate, error)
+	DeleteCSDCertificate(clientID, csdID string) error
+	GetExpiringCSD(params models.ExpiringParams) ([]models.CSDExpiration, error)
+	GetClientContacts(clientID string) ([]models.ClientContact, error)
+	CreateClientContact(clientID string, request models.ClientContactRequest) (models.ClientContact, error)
+	UpdateClientContact(clientID, contactID string, request models.ClientContactRequest, ifMatch string) (models.ClientContact, error)
+	DeleteClientContact(clientID, contactID string) error
+	NotifyCSDExpirations(now time.Time, thresholds []int) (int, error)
+}
+
 // ClientsService defines the interface for client CRUD operations
 type ClientsService interface {
-	GetAllClientsInfo() ([]models.ClientInfo, error)
-	GetActiveClientsInfo() ([]models.ClientInfo, error)
+	GetAllClientsInfo(params models.ClientListParams) (models.ClientInfoPage, error)
+	GetActiveClientsInfo(params models.ClientListParams) (models.ClientInfoPage, error)
+	SearchClients(params models.ClientSearchParams) ([]models.ClientSearchResult, error)
 	GetClientInfo(clientID string) (models.ClientInfo, error)
 	CreateClient(client models.Client, assignments models.ClientAssignments) error
-	UpdateClient(clientID string, client models.Client) error
-	DeactivateClient(clientID string) error
-	ActivateClient(clientID string) error
-	UpdateClientAssignments(clientID string, assignments models.ClientAssignments) error
+	UpdateClient(clientID string, client models.Client, change *models.ClientStateChange, version models.ClientVersion) error
+	ChangeClientState(change models.ClientStateChange, version models.ClientVersion) (models.ClientStateChange, error)
+	GetClientStateChanges(clientID string) ([]models.ClientStateChange, error)
+	GetAllClientStateChanges() ([]models.ClientStateChange, error)
+	OffboardClient(change *models.ClientStateChange, offboarding models.ClientOffboarding, event models.ClientEvent, version models.ClientVersion) (models.ClientOffboarding, error)
+	GetClientOffboarding(clientID string) (models.ClientOffboarding, error)
+	UpdateClientAssignments(clientID string, assignments models.ClientAssignments, version models.ClientVersion) error
+	GetClientVersion(clientID string) (models.ClientVersion, error)
+	GetAssignmentHistory(clientID string) ([]models.AssignmentPeriod, error)
+	GetAssignmentsAsOf(params models.AssignmentsAsOfParams) ([]models.AssignmentPeriod, error)
 	GetClientsWithPendingPayments() ([]models.ClientWithPendingPayment, error)
-	UpdateClientPayment(clientID string, payment models.ClientPayment) error
+	UpdateClientPayment(clientID string, payment models.ClientPayment, version models.ClientVersion, event models.ClientEvent) error
 	GetClientPayments(clientID string) ([]models.ClientPaymentHistory, error)
+	GetClientFees(clientID string) ([]models.ClientFee, error)
+	GetAllClientFees() ([]models.ClientFee, error)
+	SaveClientFee(fee models.ClientFee, version models.ClientVersion) (models.ClientFee, error)
+	DeleteClientFee(clientID, feeID string) error
+	ApplyClientFees(month string) (int, error)
+	GetActiveClientByRFC(rfc string) (models.ClientSummary, error)
+	GetClientSummaries() ([]models.ClientSummary, error)
+	GetClientSummary(clientID string) (models.ClientSummary, error)
+	CreateClients(requests []models.CreateClientRequest) error
+	MergeClients(sourceID, targetID, keep string) (models.ClientMergeResult, error)
+	SaveFielCertificate(cert models.FielCertificate, file []byte) (models.FielCertificate, error)
+	GetFielCertificates(clientID string) ([]models.FielCertificate, error)
+	GetExpiringFiel(until string) ([]models.FielExpiration, error)
+	SaveFielRenewal(clientID string, request models.FielRenewalRequest, version models.ClientVersion) (models.FielRenewal, error)
+	SaveCSDCertificate(cert models.CSDCertificate, files models.CSDFiles) (models.CSDCertificate, error)
+	GetCSDCertificates(clientID string) ([]models.CSDCertificate, error)
+	DeleteCSDCertificate(clientID, csdID string) error
+	GetExpiringCSD(before string) ([]models.CSDExpiration, error)
+	GetClientContacts(clientID string) ([]models.ClientContact, error)
+	CreateClientContact(contact models.ClientContact) (models.ClientContact, error)
+	UpdateClientContact(contact models.ClientContact, version models.ClientVersion) (models.ClientContact, error)
+	DeleteClientContact(clientID, contactID string) error
 }
 
 type MenusService interface {
@@ -47,22 +144,90 @@ type MenusService interface {
 	GetAccountancyStatuses() ([]models.AccountancyAssignmentStatus, error)
 }
 
+// NominasUseCase defines the interface for the nomina payment operations, it
+// adds the merge patch of a payment record to the ones of the service
+type NominasUseCase interface {
+	CreateClientPaymentRecord(clientPaymentRecord models.ClientHRPayment) error
+	GetClientsWithPendingPaymentsByHREntityID(hrEntityID string) ([]models.ClientWithPendingHRPayment, error)
+	GetClientPendingPaymentsByHREntityIDDetails(clientID, hrEntityID string) ([]models.ClientWithPendingHRPaymentDetails, error)
+	UpdateClientPaymentRecord(clientPaymentRecord models.UpdateClientHRPayment) error
+	PatchClientPaymentRecord(id string, patch models.ClientHRPaymentPatch) error
+	GetClientHRPaymentsHistory(clientID, hrEntityID string) ([]models.ClientHRPayment, error)
+}
+
 type NominasService interface {
 	CreateClientPaymentRecord(clientPaymentRecord models.ClientHRPayment) error
 	GetClientsWithPendingPaymentsByHREntityID(hrEntityID string) ([]models.ClientWithPendingHRPayment, error)
 	GetClientPendingPaymentsByHREntityIDDetails(clientID, hrEntityID string) ([]models.ClientWithPendingHRPaymentDetails, error)
+	GetClientPaymentRecord(id string) (models.ClientHRPayment, error)
 	UpdateClientPaymentRecord(clientPaymentRecord models.UpdateClientHRPayment) error
 	GetClientHRPaymentsHistory(clientID, hrEntityID string) ([]models.ClientHRPayment, error)
 }
 
-type AccountancyService interface {
+// AccountancyUseCase defines the interface for the accountancy operations, it
+// adds the checks of the versions sent in If-Match to the ones of the service
+type AccountancyUseCase interface {
 	GetClientsBySupervisor(supervisorID string) ([]models.AccountancyClientInfo, error)
 	GetClientAssignmentsMatrix() ([]models.ClientAssignmentMatrixRow, error)
-	UpdateClientAssignments(clientID string, assignments []models.AssignmentSelection) error
+	UpdateClientAssignments(clientID string, assignments []models.AssignmentSelection, ifMatch string) error
 	GetClientsByResonsible(responsibleID string) ([]models.AccountancyClientInfo, error)
 	CreateClientAccountancyStatusWithAssignments(status models.ClientAccountancyStatus, assignments []models.ClientAccountancyAssignment) error
-	UpdateClientAccountancyStatusWithAssignments(statusID int, clientID string, status models.ClientAccountancyStatus, assignments []models.ClientAccountancyAssignment) error
+	UpdateClientAccountancyStatusWithAssignments(statusID int, clientID string, status models.ClientAccountancyStatus, assignments []models.ClientAccountancyAssignment, ifMatch string) error
+	GetClientAccountancyHistory(clientID string) (models.ClientAccountancyHistoryWithAssignments, error)
+	GetClientAccountancyStatus(clientID string, statusID int) (models.ClientAccountancyHistoryEntry, error)
+	GetAllClients(params models.ClientListParams) (models.AccountancyClientInfoPage, error)
+	UpdateClientResponsible(clientID string, responsibleID string, ifMatch string) error
+	GetClientETag(clientID string) (string, error)
+}
+
+type AccountancyService interface {
+	GetClientsBySupervisor(supervisorID string) ([]models.AccountancyClientInfo, error)
+	GetClientAssignmentsMatrix() ([]models.ClientAssignmentMatrixRow, error)
+	UpdateClientAssignments(clientID string, assignments []models.AssignmentSelection, version models.ClientVersion) error
+	GetClientsByResonsible(responsibleID string) ([]models.AccountancyClientInfo, error)
+	CreateClientAccountancyStatusWithAssignments(status models.ClientAccountancyStatus, assignments []models.ClientAccountancyAssignment, event models.ClientEvent) error
+	UpdateClientAccountancyStatusWithAssignments(statusID int, clientID string, status models.ClientAccountancyStatus, assignments []models.ClientAccountancyAssignment, version int, event models.ClientEvent) error
 	GetClientAccountancyHistory(clientID string) (models.ClientAccountancyHistoryWithAssignments, error)
-	GetAllClients() ([]models.AccountancyClientInfo, error)
-	UpdateClientResponsible(clientID string, responsibleID string) error
+	GetAllClients(params models.ClientListParams) (models.AccountancyClientInfoPage, error)
+	UpdateClientResponsible(clientID string, responsibleID string, version models.ClientVersion) error
+}
+
+// NotificationsUseCase lets the users read their notifications
+type NotificationsUseCase interface {
+	GetNotifications(userID string, params models.NotificationParams) ([]models.Notification, error)
+	MarkNotificationRead(userID, notificationID string) error
+}
+
+// TimelineService keeps the notes and the recorded events of the clients
+type TimelineService interface {
+	CreateClientEvent(event models.ClientEvent) (models.ClientEvent, error)
+	GetClientTimeline(clientID string, params models.TimelineParams) (models.ClientEventPage, error)
+}
+
+// NotificationsService keeps the notifications sent to the users
+type NotificationsService interface {
+	CreateNotifications(notifications []models.Notification) (int, error)
+	GetNotifications(userID string, params models.NotificationParams) ([]models.Notification, error)
+	MarkNotificationRead(userID, notificationID string) error
+}
+
+// DocumentsUseCase manages the documents attached to the clients
+type DocumentsUseCase interface {
+	GetDocuments(clientID string, params models.DocumentParams) ([]models.ClientDocument, error)
+	GetDocument(clientID, documentID string) (models.ClientDocument, error)
+	UploadDocument(clientID, userID string, upload models.DocumentUpload, file models.DocumentFile) (models.ClientDocument, error)
+	AddDocumentVersion(clientID, documentID, userID string, upload models.DocumentVersionUpload, file models.DocumentFile) (models.ClientDocument, error)
+	DownloadDocument(clientID, documentID string, params models.DocumentVersionParams) (models.DocumentVersion, []byte, error)
+	DeleteDocument(clientID, documentID string) error
+}
+
+// DocumentsService keeps the documents of the clients and the keys their
+// files are stored under
+type DocumentsService interface {
+	GetDocuments(clientID string, params models.DocumentParams) ([]models.ClientDocument, error)
+	GetDocument(clientID, documentID string) (models.ClientDocument, error)
+	CreateDocument(document models.ClientDocument) (models.ClientDocument, error)
+	AddDocumentVersion(clientID, documentID string, version models.DocumentVersion) (models.DocumentVersion, error)
+	GetDocumentVersion(clientID, documentID string, version int) (models.DocumentVersion, error)
+	DeleteDocument(clientID, documentID string) ([]string, error)
 }
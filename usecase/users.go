@@ -12,7 +12,7 @@ type UsersInteractor struct {
 }
 
 // NewUsersUseCase creates a new instance of UsersService
-func NewUsersUseCase(usersService UsersService) UsersService {
+func NewUsersUseCase(usersService UsersService) UsersUseCase {
 	return &UsersInteractor{
 		usersService: usersService,
 	}
@@ -44,6 +44,28 @@ func (uu *UsersInteractor) UpdateUser(user models.User) error {
 	return uu.usersService.UpdateUser(user)
 }
 
+// PatchUser changes only the username, role and password sent in a merge
+// patch, all of them or none
+func (uu *UsersInteractor) PatchUser(userID string, patch models.UserPatch) error {
+	if err := rejectNull(map[string]bool{
+		"username": patch.Username.Null,
+		"password": patch.Password.Null,
+		"role":     patch.Role.Null,
+	}); err != nil {
+		return err
+	}
+
+	if patch.Password.Set {
+		hash, err := bcrypt.GenerateFromPassword([]byte(patch.Password.Value), bcrypt.DefaultCost)
+		if err != nil {
+			return err
+		}
+		patch.Password.Value = string(hash)
+	}
+
+	return uu.usersService.PatchUser(userID, patch)
+}
+
 // UpdateUserRole updates the user role
 func (uu *UsersInteractor) UpdateUserRole(user models.User) error {
 	return uu.usersService.UpdateUserRole(user)
@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"slices"
+	"strings"
+
+	"contabi-be/models"
+)
+
+// GetClientETag returns the ETag of a client, it changes with the client and
+// its assignments
+func (ci *ClientsInteractor) GetClientETag(clientID string) (string, error) {
+	version, err := ci.clientsService.GetClientVersion(clientID)
+	return version.ETag(), err
+}
+
+// GetClientETag returns the ETag the changes of the accountancy assignments
+// and the responsible of a client check, the one of the client
+func (ai *AccountancyInteractor) GetClientETag(clientID string) (string, error) {
+	version, err := ai.clientsService.GetClientVersion(clientID)
+	return version.ETag(), err
+}
+
+// clientVersionOf returns the version of a client when If-Match matches it.
+// The change checks it and moves it forward in the same write, so of two
+// changes read from the same version only the first one goes on.
+func clientVersionOf(clientsService ClientsService, clientID, ifMatch string) (models.ClientVersion, error) {
+	tags, any, err := parseIfMatch(ifMatch)
+	if err != nil {
+		return models.ClientVersion{}, err
+	}
+
+	version, err := clientsService.GetClientVersion(clientID)
+	if err != nil {
+		return version, err
+	}
+
+	return version, matchIfMatch(tags, any, "client", version.ETag())
+}
+
+// statusVersionOf returns the version of a monthly accountancy record of a
+// client when If-Match matches it, the change checks it in its write as the
+// one of a client
+func (ai *AccountancyInteractor) statusVersionOf(clientID string, statusID int, ifMatch string) (int, error) {
+	tags, any, err := parseIfMatch(ifMatch)
+	if err != nil {
+		return 0, err
+	}
+
+	entry, err := ai.GetClientAccountancyStatus(clientID, statusID)
+	if err != nil {
+		return 0, err
+	}
+
+	return entry.Status.Version, matchIfMatch(tags, any, "accountancy status", models.StatusETag(entry.Status.Version))
+}
+
+// parseIfMatch reads an If-Match header as RFC 9110 defines it, * for any
+// version or a comma separated list of ETags. The weak ETags are left out,
+// If-Match compares the ETags strongly so they never match.
+func parseIfMatch(ifMatch string) ([]string, bool, error) {
+	header := strings.TrimSpace(ifMatch)
+	switch header {
+	case "":
+		return nil, false, models.NewPreconditionRequiredError("The If-Match header with the ETag of the record is required")
+	case "*":
+		return nil, true, nil
+	}
+
+	malformed := models.NewPreconditionFailedError("The If-Match header is not * nor a list of ETags", nil)
+	var tags []string
+	for rest := header; ; {
+		rest = strings.TrimLeft(rest, " \t,")
+		if rest == "" {
+			break
+		}
+
+		weak := strings.HasPrefix(rest, "W/")
+		rest = strings.TrimPrefix(rest, "W/")
+		if !strings.HasPrefix(rest, `"`) {
+			return nil, false, malformed
+		}
+		end := strings.IndexByte(rest[1:], '"')
+		if end < 0 {
+			return nil, false, malformed
+		}
+		tag := rest[:end+2]
+
+		rest = strings.TrimLeft(rest[end+2:], " \t")
+		if rest != "" && rest[0] != ',' {
+			return nil, false, malformed
+		}
+		if !weak {
+			tags = append(tags, tag)
+		}
+	}
+
+	if len(tags) == 0 {
+		return nil, false, models.NewPreconditionFailedError("The If-Match header must have a strong ETag", nil)
+	}
+
+	return tags, false, nil
+}
+
+// matchIfMatch fails when the ETags of an If-Match header do not have the
+// current one of a record
+func matchIfMatch(tags []string, any bool, record, current string) error {
+	if any || slices.Contains(tags, current) {
+		return nil
+	}
+
+	return models.NewPreconditionFailedError("The "+record+" changed since it was read", map[string]string{"etag": current})
+}
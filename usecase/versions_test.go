@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"contabi-be/models"
+)
+
+func TestParseIfMatch(t *testing.T) {
+	tests := []struct {
+		header string
+		tags   []string
+		any    bool
+		code   models.ErrorCode
+	}{
+		{header: `"3.1"`, tags: []string{`"3.1"`}},
+		{header: ` * `, any: true},
+		{header: `"3.1", "4.1",W/"5.1"`, tags: []string{`"3.1"`, `"4.1"`}},
+		{header: `"a,b" ,"c"`, tags: []string{`"a,b"`, `"c"`}},
+		{header: `""`, tags: []string{`""`}},
+		{code: models.CodePreconditionRequired},
+		{header: `W/"3.1"`, code: models.CodePreconditionFailed},
+		{header: `3.1`, code: models.CodePreconditionFailed},
+		{header: `"3.1`, code: models.CodePreconditionFailed},
+		{header: `"3.1" "4.1"`, code: models.CodePreconditionFailed},
+		{header: `*, "3.1"`, code: models.CodePreconditionFailed},
+	}
+
+	for _, tt := range tests {
+		tags, any, err := parseIfMatch(tt.header)
+		var code models.ErrorCode
+		if e := (*models.Error)(nil); errors.As(err, &e) {
+			code = e.Code
+		}
+		if code != tt.code {
+			t.Errorf("If-Match %q: error %v, want %s", tt.header, err, tt.code)
+			continue
+		}
+		if !slices.Equal(tags, tt.tags) || any != tt.any {
+			t.Errorf("If-Match %q: tags %q and any %v, want %q and %v", tt.header, tags, any, tt.tags, tt.any)
+		}
+	}
+}
+
+func TestMatchIfMatch(t *testing.T) {
+	if err := matchIfMatch([]string{`"1.1"`, `"2.1"`}, false, "client", `"2.1"`); err != nil {
+		t.Errorf("a list with the current ETag failed: %v", err)
+	}
+	if err := matchIfMatch(nil, true, "client", `"2.1"`); err != nil {
+		t.Errorf("* failed: %v", err)
+	}
+	var e *models.Error
+	if err := matchIfMatch([]string{`"1.1"`}, false, "client", `"2.1"`); !errors.As(err, &e) || e.Code != models.CodePreconditionFailed {
+		t.Errorf("an old ETag gave %v, want a failed precondition", err)
+	}
+}